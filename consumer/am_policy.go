@@ -22,6 +22,7 @@ import (
 func AMPolicyControlCreate(ctx context.Context, ue *amf_context.AmfUe, anType models.AccessType) (*models.ProblemDetails, error) {
 	ctx, span := tracer.Start(ctx, "HTTP POST pcf/policies")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Npcf_AMPolicyControl_Create", ue.PcfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -32,6 +33,7 @@ func AMPolicyControlCreate(ctx context.Context, ue *amf_context.AmfUe, anType mo
 	)
 
 	configuration := Npcf_AMPolicyControl.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.PcfUri
@@ -117,6 +119,7 @@ func AMPolicyControlUpdate(ctx context.Context, ue *amf_context.AmfUe, updateReq
 ) {
 	ctx, span := tracer.Start(ctx, "HTTP POST pcf/policies/{polAssoId}/update")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Npcf_AMPolicyControl_Update", ue.PcfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -127,6 +130,7 @@ func AMPolicyControlUpdate(ctx context.Context, ue *amf_context.AmfUe, updateReq
 	)
 
 	configuration := Npcf_AMPolicyControl.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.PcfUri
@@ -177,6 +181,7 @@ func AMPolicyControlUpdate(ctx context.Context, ue *amf_context.AmfUe, updateReq
 func AMPolicyControlDelete(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *models.ProblemDetails, err error) {
 	ctx, span := tracer.Start(ctx, "HTTP DELETE pcf/policies/{polAssoId}")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Npcf_AMPolicyControl_Delete", ue.PcfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "DELETE"),
@@ -187,6 +192,7 @@ func AMPolicyControlDelete(ctx context.Context, ue *amf_context.AmfUe) (problemD
 	)
 
 	configuration := Npcf_AMPolicyControl.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.PcfUri
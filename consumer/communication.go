@@ -109,6 +109,7 @@ func UEContextTransferRequest(
 ) {
 	ctx, span := tracer.Start(ctx, "HTTP POST amf/ue-contexts/{ueContextId}/transfer")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Namf_Communication_UEContextTransfer", ue.TargetAmfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -170,7 +171,7 @@ func UEContextTransferRequest(
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json, multipart/related, application/problem+json")
 
-	httpResp, localErr := http.DefaultClient.Do(req)
+	httpResp, localErr := sbiHTTPClient.Do(req)
 	if localErr != nil {
 		err = openapi.ReportError("%s: server no response", ue.TargetAmfUri)
 		return ueContextTransferRspData, problemDetails, err
@@ -202,6 +203,7 @@ func RegistrationStatusUpdate(ctx context.Context, ue *amf_context.AmfUe, reques
 ) {
 	ctx, span := tracer.Start(ctx, "HTTP POST amf/ue-contexts/{ueContextId}/transfer-update")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Namf_Communication_RegistrationStatusUpdate", ue.TargetAmfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -212,6 +214,7 @@ func RegistrationStatusUpdate(ctx context.Context, ue *amf_context.AmfUe, reques
 	)
 
 	configuration := Namf_Communication.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.TargetAmfUri
@@ -243,3 +246,65 @@ func RegistrationStatusUpdate(ctx context.Context, ue *amf_context.AmfUe, reques
 	}
 	return regStatusTransferComplete, problemDetails, err
 }
+
+// CreateUEContextRequest pushes the UE context to targetAmfUri with
+// Namf_Communication_CreateUEContext. It is used during AMF planned removal
+// (TS 23.501 5.21.2.2) to hand registered UEs over to the backup AMF.
+func CreateUEContextRequest(ctx context.Context, ue *amf_context.AmfUe, targetAmfUri string) (
+	ueContextCreatedData *models.UeContextCreatedData, problemDetails *models.ProblemDetails, err error,
+) {
+	ctx, span := tracer.Start(ctx, "HTTP PUT amf/ue-contexts/{ueContextId}")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Namf_Communication_CreateUEContext", configuredPeer(targetAmfUri))
+
+	span.SetAttributes(
+		attribute.String("http.method", "PUT"),
+		attribute.String("nf.target", "amf"),
+		attribute.String("net.peer.name", targetAmfUri),
+		attribute.String("ue.supi", ue.GetSupi()),
+		attribute.String("ue.plmn.id", ue.PlmnId.GetMcc()+ue.PlmnId.GetMnc()),
+	)
+
+	ranUe := ue.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS)
+	if ranUe == nil || ranUe.Ran == nil {
+		return nil, nil, fmt.Errorf("ue[%s] has no 3GPP RAN connection", ue.GetSupi())
+	}
+	targetId := models.NewNgRanTargetId(*models.NewNullableGlobalRanNodeId(ranUe.Ran.RanId), ranUe.Tai)
+	sourceToTargetData := models.NewN2InfoContent(models.RefToBinaryData{ContentId: "n2Info"})
+	ueContextCreateData := models.NewUeContextCreateData(BuildUeContextModel(ue), *targetId,
+		*sourceToTargetData, []models.N2SmInformation{})
+
+	configuration := Namf_Communication.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
+	serverConfig := &configuration.Servers[0]
+	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
+		apiRootVar.DefaultValue = targetAmfUri
+		serverConfig.Variables["apiRoot"] = apiRootVar
+	}
+	client := Namf_Communication.NewAPIClient(configuration)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	apiCreateUEContextRequest := client.IndividualUeContextDocumentAPI.CreateUEContext(ctx, ue.GetSupi())
+	apiCreateUEContextRequest = apiCreateUEContextRequest.JsonData(*ueContextCreateData)
+	res, httpResp, localErr := client.IndividualUeContextDocumentAPI.CreateUEContextExecute(apiCreateUEContextRequest)
+	if localErr == nil {
+		ueContextCreatedData = res
+	} else if httpResp != nil {
+		if httpResp.Status != localErr.Error() {
+			err = localErr
+			return ueContextCreatedData, problemDetails, err
+		}
+		if ueContextCreateError, ok := openapi.ErrorModel[models.UeContextCreateError](localErr); ok {
+			problemDetails = &ueContextCreateError.Error
+		} else if problem, ok := openapi.ErrorModel[models.ProblemDetails](localErr); ok {
+			problemDetails = &problem
+		} else {
+			err = localErr
+		}
+	} else {
+		err = openapi.ReportError("%s: server no response", targetAmfUri)
+	}
+	return ueContextCreatedData, problemDetails, err
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/metrics"
+	"github.com/omec-project/amf/tracing"
+)
+
+// sbiHTTPClient is shared by all SBI consumers so that every request towards a
+// peer NF goes through sbiTransport.
+var sbiHTTPClient = &http.Client{Transport: &sbiTransport{base: http.DefaultTransport}}
+
+// sbiRequest describes an SBI request for the SBI client metrics
+type sbiRequest struct {
+	operation string
+	// NF instance ID of the producer, the host of the request if unknown
+	peer  string
+	retry bool
+}
+
+type sbiRequestKey struct{}
+
+// withSbiRequest annotates ctx with the service operation of the SBI requests sent with it
+// and the NF instance ID of the peer NF, if known. A retry marked with withSbiRetry is kept.
+func withSbiRequest(ctx context.Context, operation, peerNfInstanceId string) context.Context {
+	request, _ := ctx.Value(sbiRequestKey{}).(sbiRequest)
+	request.operation, request.peer = operation, peerNfInstanceId
+	return context.WithValue(ctx, sbiRequestKey{}, request)
+}
+
+// configuredPeer returns the SBI metrics peer of an NF the AMF is configured with by URI
+// rather than one it selected from an NF profile, e.g. the NRF, whose NF instance ID the
+// AMF does not know.
+func configuredPeer(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return uri
+}
+
+// withSbiRetry marks the SBI requests sent with ctx as retries of a failed attempt.
+func withSbiRetry(ctx context.Context) context.Context {
+	request, _ := ctx.Value(sbiRequestKey{}).(sbiRequest)
+	request.retry = true
+	return context.WithValue(ctx, sbiRequestKey{}, request)
+}
+
+// sbiTransport reports the round-trip time of SBI requests to overload control, records the
+// SBI client metrics and the flight record of the UE the request is for, and propagates the
+// trace context of the requests to the peer NF.
+type sbiTransport struct {
+	base http.RoundTripper
+}
+
+func (t *sbiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	tracing.InjectTraceContext(req.Context(), req.Header)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+	amf_context.AMF_Self().OverloadControl.ObserveSbiLatency(duration)
+
+	service := metrics.SbiServiceName(req.URL.Path)
+	request, _ := req.Context().Value(sbiRequestKey{}).(sbiRequest)
+	if request.operation == "" {
+		request.operation = req.Method + " " + service
+	}
+	if request.peer == "" {
+		request.peer = req.URL.Host
+	}
+	statusClass := metrics.SbiStatusClassError
+	if err == nil {
+		statusClass = metrics.SbiStatusClass(resp.StatusCode)
+	}
+	if request.retry {
+		metrics.IncrementSbiClientRetries(service, request.operation, request.peer)
+	}
+	metrics.ObserveSbiClientRequest(service, request.operation, request.peer, statusClass, duration)
+	if ue, ok := amf_context.FlightRecorderUe(req.Context()); ok {
+		result := statusClass
+		if err == nil {
+			result = strconv.Itoa(resp.StatusCode)
+		}
+		ue.RecordFlight(amf_context.FlightRecord{
+			Time:      start,
+			Kind:      amf_context.FlightRecordSbi,
+			Direction: amf_context.FlightRecordOutgoing,
+			Name:      request.operation,
+			Result:    result,
+		})
+	}
+	return resp, err
+}
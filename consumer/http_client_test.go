@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithSbiRequestKeepsRetry(t *testing.T) {
+	ctx := withSbiRequest(withSbiRetry(context.Background()), "Nnrf_NFDiscovery_Request", "")
+	request, ok := ctx.Value(sbiRequestKey{}).(sbiRequest)
+	if !ok || !request.retry || request.operation != "Nnrf_NFDiscovery_Request" {
+		t.Errorf("expected a retried NF discovery, got %+v", request)
+	}
+
+	request, _ = withSbiRequest(context.Background(), "Nudm_SDM_Get", "udm-1").Value(sbiRequestKey{}).(sbiRequest)
+	if request.retry || request.peer != "udm-1" {
+		t.Errorf("expected a first attempt towards udm-1, got %+v", request)
+	}
+}
+
+func TestConfiguredPeer(t *testing.T) {
+	if peer := configuredPeer("https://nrf.example.com:29510"); peer != "nrf.example.com:29510" {
+		t.Errorf("expected the host of the NRF URI, got %s", peer)
+	}
+	if peer := configuredPeer("nrf"); peer != "nrf" {
+		t.Errorf("expected a URI without host to be kept, got %s", peer)
+	}
+}
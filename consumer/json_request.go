@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// sendJSONRequest sends a JSON request to an SBI service that has no client in the openapi
+// module. A success response body is decoded into target, an error response into the
+// returned problem details; the response header is returned for a Location header.
+func sendJSONRequest(ctx context.Context, method, requestURI string, body, target any) (
+	http.Header, *models.ProblemDetails, error,
+) {
+	var requestBody io.Reader
+	if body != nil {
+		buf, err := openapi.SetBody(body, "application/json")
+		if err != nil {
+			return nil, nil, err
+		}
+		requestBody = bytes.NewReader(buf.Bytes())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, requestURI, requestBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json, application/problem+json")
+
+	httpResp, localErr := sbiHTTPClient.Do(req)
+	if localErr != nil {
+		return nil, nil, openapi.ReportError("%s: server no response", requestURI)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < http.StatusMultipleChoices {
+		if target == nil {
+			return httpResp.Header, nil, nil
+		}
+		return httpResp.Header, nil, decodeSuccessResponseBody(httpResp, target)
+	}
+	problemDetails := models.NewProblemDetails()
+	if err = decodeSuccessResponseBody(httpResp, problemDetails); err != nil {
+		return httpResp.Header, nil, err
+	}
+	return httpResp.Header, problemDetails, nil
+}
@@ -25,6 +25,7 @@ type SearchNFInstancesRequestConfigurer func(
 
 func newNFDiscoveryClient(nrfUri string) *Nnrf_NFDiscovery.APIClient {
 	configuration := Nnrf_NFDiscovery.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = nrfUri
@@ -67,6 +68,7 @@ func SendNfDiscoveryToNrf(ctx context.Context, nrfUri string, targetNfType, requ
 ) (*models.SearchResult, error) {
 	ctx, span := tracer.Start(ctx, "HTTP GET nrf/nf-instances")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnrf_NFDiscovery_Request", configuredPeer(nrfUri))
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -132,6 +134,7 @@ func SendNfDiscoveryToNrfCacheQuery(ctx context.Context, nrfUri string, targetNf
 ) (*models.SearchResult, error) {
 	ctx, span := tracer.Start(ctx, "HTTP GET nrf/nf-instances")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnrf_NFDiscovery_Request", configuredPeer(nrfUri))
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -241,6 +244,30 @@ func SearchNssfNSSelectionInstance(ctx context.Context, ue *amf_context.AmfUe, n
 	return nil
 }
 
+func SearchNssaafInstance(ctx context.Context, ue *amf_context.AmfUe, nrfUri string, targetNfType, requestNfType models.NFType,
+	configure SearchNFInstancesRequestConfigurer,
+) error {
+	resp, localErr := SendSearchNFInstances(ctx, nrfUri, targetNfType, requestNfType, configure)
+	if localErr != nil {
+		return localErr
+	}
+
+	// select the first NSSAAF, TODO: select base on other info
+	var nssaafUri string
+	for _, nfProfile := range resp.NfInstances {
+		nssaafUri = util.SearchNFServiceUri(nfProfile, models.SERVICENAME_NNSSAAF_NSSAA, models.NFSERVICESTATUS_REGISTERED)
+		if nssaafUri != "" {
+			ue.NssaafId = nfProfile.NfInstanceId
+			break
+		}
+	}
+	ue.NssaafUri = nssaafUri
+	if ue.NssaafUri == "" {
+		return fmt.Errorf("AMF can not select an NSSAAF by NRF")
+	}
+	return nil
+}
+
 func SearchAmfCommunicationInstance(ctx context.Context, ue *amf_context.AmfUe, nrfUri string, targetNfType,
 	requestNfType models.NFType, configure SearchNFInstancesRequestConfigurer,
 ) (err error) {
@@ -256,6 +283,7 @@ func SearchAmfCommunicationInstance(ctx context.Context, ue *amf_context.AmfUe,
 		ue.TargetAmfProfile = &nfProfile
 		amfUri = util.SearchNFServiceUri(nfProfile, models.SERVICENAME_NAMF_COMM, models.NFSERVICESTATUS_REGISTERED)
 		if amfUri != "" {
+			ue.TargetAmfId = nfProfile.NfInstanceId
 			break
 		}
 	}
@@ -98,6 +98,7 @@ var SendRegisterNFInstance = func(ctx context.Context, accessAndMobilityConfig [
 
 	ctx, span := tracer.Start(ctx, "HTTP PUT nrf/nf-instances/{nfInstanceID}")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnrf_NFManagement_NFRegister", configuredPeer(self.NrfUri))
 
 	span.SetAttributes(
 		attribute.String("http.method", "PUT"),
@@ -108,6 +109,7 @@ var SendRegisterNFInstance = func(ctx context.Context, accessAndMobilityConfig [
 	)
 
 	configuration := Nnrf_NFManagement.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = self.NrfUri
@@ -147,6 +149,7 @@ var SendDeregisterNFInstance = func(ctx context.Context) error {
 
 	ctx, span := tracer.Start(ctx, "HTTP DELETE nrf/nf-instances/{nfInstanceID}")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnrf_NFManagement_NFDeregister", configuredPeer(amfSelf.NrfUri))
 
 	span.SetAttributes(
 		attribute.String("http.method", "DELETE"),
@@ -156,6 +159,7 @@ var SendDeregisterNFInstance = func(ctx context.Context) error {
 	)
 	// Set client and set url
 	configuration := Nnrf_NFManagement.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = amfSelf.NrfUri
@@ -182,6 +186,7 @@ var SendUpdateNFInstance = func(patchItem []models.PatchItem) (receivedNfProfile
 
 	amfSelf := amfContext.AMF_Self()
 	configuration := Nnrf_NFManagement.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = amfSelf.NrfUri
@@ -190,7 +195,8 @@ var SendUpdateNFInstance = func(patchItem []models.PatchItem) (receivedNfProfile
 	client := Nnrf_NFManagement.NewAPIClient(configuration)
 
 	var res *http.Response
-	apiUpdateNFInstanceRequest := client.NFInstanceIDDocumentAPI.UpdateNFInstance(context.Background(), amfSelf.NfId)
+	ctx := withSbiRequest(context.Background(), "Nnrf_NFManagement_NFUpdate", configuredPeer(amfSelf.NrfUri))
+	apiUpdateNFInstanceRequest := client.NFInstanceIDDocumentAPI.UpdateNFInstance(ctx, amfSelf.NfId)
 	apiUpdateNFInstanceRequest = apiUpdateNFInstanceRequest.PatchItem(patchItem)
 	receivedNfProfile, res, err = client.NFInstanceIDDocumentAPI.UpdateNFInstanceExecute(apiUpdateNFInstanceRequest)
 	if err != nil {
@@ -218,6 +224,7 @@ var SendCreateSubscription = func(ctx context.Context, nrfUri string, nrfSubscri
 
 	ctx, span := tracer.Start(ctx, "HTTP POST nrf/subscriptions")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnrf_NFManagement_NFStatusSubscribe", configuredPeer(nrfUri))
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -228,6 +235,7 @@ var SendCreateSubscription = func(ctx context.Context, nrfUri string, nrfSubscri
 
 	// Set client and set url
 	configuration := Nnrf_NFManagement.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = nrfUri
@@ -269,6 +277,7 @@ var SendRemoveSubscription = func(ctx context.Context, subscriptionId string) (p
 
 	ctx, span := tracer.Start(ctx, "HTTP DELETE nrf/subscriptions/{subscriptionID}")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnrf_NFManagement_NFStatusUnSubscribe", configuredPeer(amfSelf.NrfUri))
 
 	span.SetAttributes(
 		attribute.String("http.method", "DELETE"),
@@ -279,6 +288,7 @@ var SendRemoveSubscription = func(ctx context.Context, subscriptionId string) (p
 
 	// Set client and set url
 	configuration := Nnrf_NFManagement.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = amfSelf.NrfUri
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Nnssaaf_NSSAA data types (TS 29.526 6.1.6), which are not part of the openapi module
+
+// SliceAuthInfo starts the authentication of an S-NSSAI with the EAP identity response of the UE.
+type SliceAuthInfo struct {
+	Supi           string        `json:"supi"`
+	Gpsi           string        `json:"gpsi,omitempty"`
+	Snssai         models.Snssai `json:"snssai"`
+	EapIdRsp       string        `json:"eapIdRsp"`
+	AmfInstanceId  string        `json:"amfInstanceId,omitempty"`
+	ReauthNotifUri string        `json:"reauthNotifUri,omitempty"`
+	RevocNotifUri  string        `json:"revocNotifUri,omitempty"`
+}
+
+// SliceAuthContext is the authentication context created by the NSSAAF.
+type SliceAuthContext struct {
+	Supi       string        `json:"supi,omitempty"`
+	Gpsi       string        `json:"gpsi,omitempty"`
+	Snssai     models.Snssai `json:"snssai"`
+	AuthCtxId  string        `json:"authCtxId"`
+	EapMessage string        `json:"eapMessage"`
+}
+
+// SliceAuthConfirmationData relays an EAP message of the UE to the NSSAAF.
+type SliceAuthConfirmationData struct {
+	Supi       string        `json:"supi,omitempty"`
+	Gpsi       string        `json:"gpsi,omitempty"`
+	Snssai     models.Snssai `json:"snssai"`
+	EapMessage string        `json:"eapMessage"`
+}
+
+// SliceAuthConfirmationResponse carries the next EAP message for the UE and, once the EAP
+// exchange is over, the authentication result.
+type SliceAuthConfirmationResponse struct {
+	Supi       string             `json:"supi,omitempty"`
+	Gpsi       string             `json:"gpsi,omitempty"`
+	Snssai     models.Snssai      `json:"snssai"`
+	EapMessage string             `json:"eapMessage"`
+	AuthResult *models.AuthStatus `json:"authResult,omitempty"`
+}
+
+// notification types of SliceAuthNotification
+const (
+	SliceAuthNotifTypeReauth     = "SLICE_RE_AUTH"
+	SliceAuthNotifTypeRevocation = "SLICE_REVOCATION"
+)
+
+// SliceAuthNotification is sent by the NSSAAF when the AAA server triggers re-authentication
+// or revokes the authorization of an S-NSSAI.
+type SliceAuthNotification struct {
+	NotifType string        `json:"notifType"`
+	Supi      string        `json:"supi,omitempty"`
+	Gpsi      string        `json:"gpsi,omitempty"`
+	Snssai    models.Snssai `json:"snssai"`
+}
+
+// SendNssaaAuthenticateRequest creates a slice authentication context at the NSSAAF
+// (Nnssaaf_NSSAA_Authenticate, TS 29.526 5.2.2.2.2).
+func SendNssaaAuthenticateRequest(ctx context.Context, ue *amf_context.AmfUe, snssai models.Snssai, eapIdRsp string) (
+	*SliceAuthContext, *models.ProblemDetails, error,
+) {
+	amfSelf := amf_context.AMF_Self()
+	callbackUri := amfSelf.GetIPv4Uri() + "/namf-callback/v1/" + url.PathEscape(ue.GetSupi())
+	authInfo := SliceAuthInfo{
+		Supi:           ue.GetSupi(),
+		Gpsi:           ue.Gpsi,
+		Snssai:         snssai,
+		EapIdRsp:       eapIdRsp,
+		AmfInstanceId:  amfSelf.NfId,
+		ReauthNotifUri: callbackUri + "/nssaa-reauth",
+		RevocNotifUri:  callbackUri + "/nssaa-revoc",
+	}
+
+	ctx, span := tracer.Start(ctx, "HTTP POST nssaaf/slice-authentications")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnssaaf_NSSAA_Authenticate", ue.NssaafId)
+
+	span.SetAttributes(
+		attribute.String("http.method", "POST"),
+		attribute.String("nf.target", "nssaaf"),
+		attribute.String("net.peer.name", ue.NssaafUri),
+		attribute.String("ue.supi", ue.GetSupi()),
+	)
+
+	requestURI := strings.TrimRight(ue.NssaafUri, "/") + "/nnssaaf-nssaa/v1/slice-authentications"
+	authCtx := &SliceAuthContext{}
+	_, problemDetails, err := sendJSONRequest(ctx, http.MethodPost, requestURI, authInfo, authCtx)
+	if problemDetails != nil || err != nil {
+		return nil, problemDetails, err
+	}
+	return authCtx, nil, nil
+}
+
+// SendNssaaConfirmRequest relays an EAP message of the UE within the authentication context
+// authCtxId (TS 29.526 5.2.2.2.3).
+func SendNssaaConfirmRequest(ctx context.Context, ue *amf_context.AmfUe, authCtxId string, snssai models.Snssai,
+	eapMessage string,
+) (*SliceAuthConfirmationResponse, *models.ProblemDetails, error) {
+	confirmData := SliceAuthConfirmationData{
+		Supi:       ue.GetSupi(),
+		Gpsi:       ue.Gpsi,
+		Snssai:     snssai,
+		EapMessage: eapMessage,
+	}
+
+	ctx, span := tracer.Start(ctx, "HTTP PUT nssaaf/slice-authentications/{authCtxId}")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnssaaf_NSSAA_Authenticate", ue.NssaafId)
+
+	span.SetAttributes(
+		attribute.String("http.method", "PUT"),
+		attribute.String("nf.target", "nssaaf"),
+		attribute.String("net.peer.name", ue.NssaafUri),
+		attribute.String("ue.supi", ue.GetSupi()),
+	)
+
+	requestURI := fmt.Sprintf("%s/nnssaaf-nssaa/v1/slice-authentications/%s",
+		strings.TrimRight(ue.NssaafUri, "/"), url.PathEscape(authCtxId))
+	confirmRsp := &SliceAuthConfirmationResponse{}
+	_, problemDetails, err := sendJSONRequest(ctx, http.MethodPut, requestURI, confirmData, confirmRsp)
+	if problemDetails != nil || err != nil {
+		return nil, problemDetails, err
+	}
+	return confirmRsp, nil, nil
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestNssaaAuthenticateAndConfirm(t *testing.T) {
+	snssai := models.Snssai{Sst: 1, Sd: openapi.PtrString("000001")}
+	var authInfo SliceAuthInfo
+	var confirmData SliceAuthConfirmationData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/nnssaaf-nssaa/v1/slice-authentications":
+			if err := json.NewDecoder(r.Body).Decode(&authInfo); err != nil {
+				t.Errorf("decode SliceAuthInfo: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(SliceAuthContext{Snssai: snssai, AuthCtxId: "ctx-1", EapMessage: "AQIABQE="})
+		case r.Method == http.MethodPut && r.URL.Path == "/nnssaaf-nssaa/v1/slice-authentications/ctx-1":
+			if err := json.NewDecoder(r.Body).Decode(&confirmData); err != nil {
+				t.Errorf("decode SliceAuthConfirmationData: %v", err)
+			}
+			result := models.AUTHSTATUS_EAP_SUCCESS
+			_ = json.NewEncoder(w).Encode(SliceAuthConfirmationResponse{Snssai: snssai, EapMessage: "AwIABA==", AuthResult: &result})
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(models.ProblemDetails{Status: openapi.PtrInt32(http.StatusNotFound)})
+		}
+	}))
+	defer server.Close()
+
+	ue := &amf_context.AmfUe{Supi: "imsi-001010000000001", NssaafUri: server.URL}
+	authCtx, problemDetails, err := SendNssaaAuthenticateRequest(context.Background(), ue, snssai, "AgEACgF1c2Vy")
+	if err != nil || problemDetails != nil {
+		t.Fatalf("authenticate failed: %v %+v", err, problemDetails)
+	}
+	if authCtx.AuthCtxId != "ctx-1" || authInfo.EapIdRsp != "AgEACgF1c2Vy" || authInfo.Supi != ue.Supi {
+		t.Errorf("unexpected exchange: context %+v, request %+v", authCtx, authInfo)
+	}
+
+	confirmRsp, problemDetails, err := SendNssaaConfirmRequest(context.Background(), ue, authCtx.AuthCtxId, snssai, "AgIABgMN")
+	if err != nil || problemDetails != nil {
+		t.Fatalf("confirm failed: %v %+v", err, problemDetails)
+	}
+	if confirmRsp.AuthResult == nil || *confirmRsp.AuthResult != models.AUTHSTATUS_EAP_SUCCESS ||
+		confirmData.EapMessage != "AgIABgMN" {
+		t.Errorf("unexpected confirmation: response %+v, request %+v", confirmRsp, confirmData)
+	}
+
+	if _, problemDetails, _ = SendNssaaConfirmRequest(context.Background(), ue, "unknown", snssai, ""); problemDetails == nil ||
+		problemDetails.GetStatus() != http.StatusNotFound {
+		t.Errorf("expected 404 problem details, got %+v", problemDetails)
+	}
+}
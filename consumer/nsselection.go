@@ -23,6 +23,7 @@ func NSSelectionGetForRegistration(ctx context.Context, ue *amf_context.AmfUe, r
 ) {
 	ctx, span := tracer.Start(ctx, "HTTP GET nssf/network-slice-information")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnssf_NSSelection_Get", ue.NssfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -32,6 +33,7 @@ func NSSelectionGetForRegistration(ctx context.Context, ue *amf_context.AmfUe, r
 	)
 
 	configuration := Nnssf_NSSelection.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NssfUri
@@ -86,6 +88,7 @@ func NSSelectionGetForPduSession(ctx context.Context, ue *amf_context.AmfUe, sns
 	logger.ConsumerLog.Infoln("NSSelectionGetForPduSession")
 	ctx, span := tracer.Start(ctx, "HTTP GET nssf/network-slice-information")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nnssf_NSSelection_Get", ue.NssfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -97,6 +100,7 @@ func NSSelectionGetForPduSession(ctx context.Context, ue *amf_context.AmfUe, sns
 	)
 
 	configuration := Nnssf_NSSelection.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NssfUri
@@ -94,7 +94,11 @@ func SelectSmf(
 	if nsiInformation == nil {
 		const maxRetries = 10
 		for i := range maxRetries {
-			if err := SearchNssfNSSelectionInstance(ctx, ue, nrfUri, models.NFTYPE_NSSF, models.NFTYPE_AMF, nil); err != nil {
+			searchCtx := ctx
+			if i > 0 {
+				searchCtx = withSbiRetry(ctx)
+			}
+			if err := SearchNssfNSSelectionInstance(searchCtx, ue, nrfUri, models.NFTYPE_NSSF, models.NFTYPE_AMF, nil); err != nil {
 				ue.GmmLog.Errorf("AMF cannot select an NSSF instance via NRF [error: %+v]", err)
 				if i == maxRetries-1 {
 					return nil, nasMessage.Cause5GMMPayloadWasNotForwarded,
@@ -182,6 +186,7 @@ func SendCreateSmContextRequest(ctx context.Context, ue *amf_context.AmfUe, smCo
 ) {
 	ctx, span := tracer.Start(ctx, "HTTP POST smf/sm-contexts")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nsmf_PDUSession_CreateSMContext", smContext.SmfID())
 	snssai := smContext.Snssai()
 
 	span.SetAttributes(
@@ -212,6 +217,7 @@ func SendCreateSmContextRequest(ctx context.Context, ue *amf_context.AmfUe, smCo
 	}
 
 	configuration := Nsmf_PDUSession.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	cfg := &configuration.Servers[0]
 	if apiRootVar, exists := cfg.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = smContext.SmfUri()
@@ -516,6 +522,7 @@ func SendUpdateSmContextRequest(ctx context.Context, smContext *amf_context.SmCo
 ) {
 	ctx, span := tracer.Start(ctx, "HTTP PUT smf/sm-contexts/{smContextRef}")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nsmf_PDUSession_UpdateSMContext", smContext.SmfID())
 
 	span.SetAttributes(
 		attribute.String("http.method", "PUT"),
@@ -527,6 +534,7 @@ func SendUpdateSmContextRequest(ctx context.Context, smContext *amf_context.SmCo
 	)
 
 	configuration := Nsmf_PDUSession.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	cfg := &configuration.Servers[0]
 	if apiRootVar, exists := cfg.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = smContext.SmfUri()
@@ -566,6 +574,7 @@ func SendUpdateSmContextRequest(ctx context.Context, smContext *amf_context.SmCo
 	if err != nil {
 		if errProfile := setAltSmfProfile(smContext); errProfile == nil {
 			configuration := Nsmf_PDUSession.NewConfiguration()
+			configuration.HTTPClient = sbiHTTPClient
 			cfg := &configuration.Servers[0]
 			if apiRootVar, exists := cfg.Variables["apiRoot"]; exists {
 				apiRootVar.DefaultValue = smContext.SmfUri()
@@ -578,6 +587,7 @@ func SendUpdateSmContextRequest(ctx context.Context, smContext *amf_context.SmCo
 
 			retryCtx, span := tracer.Start(retryCtx, "HTTP PUT smf/sm-contexts/{smContextRef}/modify")
 			defer span.End()
+			retryCtx = withSbiRetry(withSbiRequest(retryCtx, "Nsmf_PDUSession_UpdateSMContext", smContext.SmfID()))
 
 			span.SetAttributes(
 				attribute.String("http.method", "PUT"),
@@ -712,6 +722,7 @@ func SendReleaseSmContextRequest(ue *amf_context.AmfUe, smContext *amf_context.S
 	n2Info []byte,
 ) (detail *models.ProblemDetails, err error) {
 	configuration := Nsmf_PDUSession.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	cfg := &configuration.Servers[0]
 	if apiRootVar, exists := cfg.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = smContext.SmfUri()
@@ -726,6 +737,7 @@ func SendReleaseSmContextRequest(ue *amf_context.AmfUe, smContext *amf_context.S
 
 	ctx, span := tracer.Start(ctx, "HTTP POST smf/sm-contexts/{smContextRef}/release")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nsmf_PDUSession_ReleaseSMContext", smContext.SmfID())
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -738,7 +750,7 @@ func SendReleaseSmContextRequest(ue *amf_context.AmfUe, smContext *amf_context.S
 	_, response, err1 := client.IndividualSMContextAPI.ReleaseSmContextExecute(apiReleaseSmContextRequest)
 
 	if err1 == nil {
-		ue.SmContextList.Delete(smContext.PduSessionID())
+		ue.DeleteSmContext(smContext.PduSessionID())
 	} else if response != nil && response.Status == err1.Error() {
 		if problem, ok := openapi.ErrorModel[models.ProblemDetails](err1); ok {
 			detail = &problem
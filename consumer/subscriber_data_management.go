@@ -8,9 +8,12 @@ package consumer
 
 import (
 	"context"
+	"net/url"
+	"strings"
 	"time"
 
 	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/util"
 	"github.com/omec-project/openapi/v2"
 	"github.com/omec-project/openapi/v2/Nudm_SDM"
 	"github.com/omec-project/openapi/v2/models"
@@ -23,6 +26,7 @@ var tracer = otel.Tracer("amf/consumer")
 func PutUpuAck(ctx context.Context, ue *amf_context.AmfUe, upuMacIue string) error {
 	ctx, span := tracer.Start(ctx, "HTTP PUT udm/{supi}/am-data/upu-ack")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Info", ue.UdmId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "PUT"),
@@ -34,6 +38,7 @@ func PutUpuAck(ctx context.Context, ue *amf_context.AmfUe, upuMacIue string) err
 	)
 
 	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmSDMUri
@@ -52,9 +57,47 @@ func PutUpuAck(ctx context.Context, ue *amf_context.AmfUe, upuMacIue string) err
 	return err
 }
 
+// PutSorAck forwards the SoR acknowledgement of the UE to the UDM (Nudm_SDM_Info, TS 29.503
+// 5.2.2.7.2).
+func PutSorAck(ctx context.Context, ue *amf_context.AmfUe, sorMacIue string) error {
+	ctx, span := tracer.Start(ctx, "HTTP PUT udm/{supi}/am-data/sor-ack")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Info", ue.UdmId)
+
+	span.SetAttributes(
+		attribute.String("http.method", "PUT"),
+		attribute.String("nf.target", "udm"),
+		attribute.String("net.peer.name", ue.NudmSDMUri),
+		attribute.String("udm.supi", ue.GetSupi()),
+		attribute.String("plmn.id", ue.PlmnId.GetMcc()+ue.PlmnId.GetMnc()),
+		attribute.String("sor.mac.iue", sorMacIue),
+	)
+
+	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
+	serverConfig := &configuration.Servers[0]
+	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
+		apiRootVar.DefaultValue = ue.NudmSDMUri
+		serverConfig.Variables["apiRoot"] = apiRootVar
+	}
+	client := Nudm_SDM.NewAPIClient(configuration)
+
+	ackInfo := models.NewAcknowledgeInfoWithDefaults()
+	ackInfo.SetSorMacIue(sorMacIue)
+	ackInfo.SetProvisioningTime(time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	apiSorAckRequest := client.ProvidingAcknowledgementOfSteeringOfRoamingAPI.SorAckInfo(ctx, ue.GetSupi())
+	apiSorAckRequest = apiSorAckRequest.AcknowledgeInfo(*ackInfo)
+	_, err := client.ProvidingAcknowledgementOfSteeringOfRoamingAPI.SorAckInfoExecute(apiSorAckRequest)
+	return err
+}
+
 func SDMGetAmData(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *models.ProblemDetails, err error) {
 	ctx, span := tracer.Start(ctx, "HTTP GET udm/{supi}/am-data")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Get", ue.UdmId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -65,6 +108,7 @@ func SDMGetAmData(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *m
 	)
 
 	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmSDMUri
@@ -106,6 +150,7 @@ func SDMGetAmData(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *m
 func SDMGetSmfSelectData(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *models.ProblemDetails, err error) {
 	ctx, span := tracer.Start(ctx, "HTTP GET udm/{supi}/smf-select-data")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Get", ue.UdmId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -116,6 +161,7 @@ func SDMGetSmfSelectData(ctx context.Context, ue *amf_context.AmfUe) (problemDet
 	)
 
 	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmSDMUri
@@ -151,6 +197,7 @@ func SDMGetSmfSelectData(ctx context.Context, ue *amf_context.AmfUe) (problemDet
 func SDMGetUeContextInSmfData(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *models.ProblemDetails, err error) {
 	ctx, span := tracer.Start(ctx, "HTTP GET udm/{supi}/ue-context-in-smf-data")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Get", ue.UdmId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -161,6 +208,7 @@ func SDMGetUeContextInSmfData(ctx context.Context, ue *amf_context.AmfUe) (probl
 	)
 
 	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmSDMUri
@@ -191,9 +239,69 @@ func SDMGetUeContextInSmfData(ctx context.Context, ue *amf_context.AmfUe) (probl
 	return problemDetails, err
 }
 
+// SDMGetTraceData retrieves the subscriber and equipment trace data of the UE; it is nil when
+// no trace is active for the UE.
+func SDMGetTraceData(ctx context.Context, ue *amf_context.AmfUe) (
+	traceData *models.TraceData, problemDetails *models.ProblemDetails, err error,
+) {
+	ctx, span := tracer.Start(ctx, "HTTP GET udm/{supi}/trace-data")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Get", ue.UdmId)
+
+	span.SetAttributes(
+		attribute.String("http.method", "GET"),
+		attribute.String("nf.target", "udm"),
+		attribute.String("net.peer.name", ue.NudmSDMUri),
+		attribute.String("udm.supi", ue.GetSupi()),
+		attribute.String("plmn.id", ue.PlmnId.GetMcc()+ue.PlmnId.GetMnc()),
+	)
+
+	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
+	serverConfig := &configuration.Servers[0]
+	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
+		apiRootVar.DefaultValue = ue.NudmSDMUri
+		serverConfig.Variables["apiRoot"] = apiRootVar
+	}
+	client := Nudm_SDM.NewAPIClient(configuration)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	plmnId := models.NewPlmnId(ue.PlmnId.GetMcc(), ue.PlmnId.GetMnc())
+	apiGetTraceConfigDataRequest := client.TraceConfigurationDataRetrievalAPI.GetTraceConfigData(ctx, ue.GetSupi())
+	apiGetTraceConfigDataRequest = apiGetTraceConfigDataRequest.PlmnId(*plmnId)
+	data, httpResp, localErr := client.TraceConfigurationDataRetrievalAPI.GetTraceConfigDataExecute(apiGetTraceConfigDataRequest)
+	if localErr == nil {
+		if data.TraceData.IsSet() {
+			traceData = data.TraceData.Get()
+		}
+		return traceData, nil, nil
+	} else if httpResp != nil {
+		if httpResp.Status != localErr.Error() {
+			err = localErr
+			return nil, problemDetails, err
+		}
+		if problem, ok := openapi.ErrorModel[models.ProblemDetails](localErr); ok {
+			problemDetails = &problem
+		} else {
+			err = localErr
+		}
+	} else {
+		err = openapi.ReportError("server no response")
+	}
+	return nil, problemDetails, err
+}
+
+// sdmResourceUri returns the URI of the Nudm_SDM resource of ue, e.g. its am-data, which an
+// SDM subscription monitors (TS 29.503 6.1.3.5)
+func sdmResourceUri(ue *amf_context.AmfUe, resource string) string {
+	return ue.NudmSDMUri + "/nudm-sdm/v2/" + url.PathEscape(ue.GetSupi()) + "/" + resource
+}
+
 func SDMSubscribe(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *models.ProblemDetails, err error) {
 	ctx, span := tracer.Start(ctx, "HTTP POST udm/{supi}/sdm-subscriptions")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Subscribe", ue.UdmId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -204,6 +312,7 @@ func SDMSubscribe(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *m
 	)
 
 	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmSDMUri
@@ -215,14 +324,19 @@ func SDMSubscribe(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *m
 	sdmSubscription := models.SdmSubscription{
 		NfInstanceId: amfSelf.NfId,
 		PlmnId:       &ue.PlmnId,
+		// changes of the AM data carry new SoR and UPU information (TS 23.502 4.20.2) and
+		// changes of the trace data activate or deactivate trace (TS 32.422 4.2.2.9)
+		CallbackReference:     amfSelf.GetIPv4Uri() + "/namf-callback/v1/" + url.PathEscape(ue.GetSupi()) + "/sdm-change-notify",
+		MonitoredResourceUris: []string{sdmResourceUri(ue, "am-data"), sdmResourceUri(ue, "trace-data")},
 	}
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	apiSubscribeRequest := client.SubscriptionCreationAPI.Subscribe(ctx, ue.GetSupi())
 	apiSubscribeRequest = apiSubscribeRequest.SdmSubscription(sdmSubscription)
-	_, httpResp, localErr := client.SubscriptionCreationAPI.SubscribeExecute(apiSubscribeRequest)
+	subscription, httpResp, localErr := client.SubscriptionCreationAPI.SubscribeExecute(apiSubscribeRequest)
 	if localErr == nil {
+		ue.SdmSubscriptionId = subscription.GetSubscriptionId()
 		return nil, nil
 	} else if httpResp != nil {
 		if httpResp.Status != localErr.Error() {
@@ -243,6 +357,7 @@ func SDMSubscribe(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *m
 func SDMGetSliceSelectionSubscriptionData(ctx context.Context, ue *amf_context.AmfUe) (problemDetails *models.ProblemDetails, err error) {
 	ctx, span := tracer.Start(ctx, "HTTP GET udm/{supi}/nssai")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nudm_SDM_Get", ue.UdmId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "GET"),
@@ -253,6 +368,7 @@ func SDMGetSliceSelectionSubscriptionData(ctx context.Context, ue *amf_context.A
 	)
 
 	configuration := Nudm_SDM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmSDMUri
@@ -287,6 +403,17 @@ func SDMGetSliceSelectionSubscriptionData(ctx context.Context, ue *amf_context.A
 			subscribedSnssai.SetDefaultIndication(false)
 			ue.SubscribedNssai = append(ue.SubscribedNssai, subscribedSnssai)
 		}
+		ue.NssaiRequiringNssaa = nil
+		for key, additionalData := range nssai.GetAdditionalSnssaiData() {
+			if !additionalData.GetRequiredAuthnAuthz() {
+				continue
+			}
+			for _, subscribedSnssai := range ue.SubscribedNssai {
+				if strings.EqualFold(util.SnssaiModelsToHex(subscribedSnssai.SubscribedSnssai), key) {
+					ue.NssaiRequiringNssaa = append(ue.NssaiRequiringNssaa, subscribedSnssai.SubscribedSnssai)
+				}
+			}
+		}
 	} else if httpResp != nil {
 		if httpResp.Status != localErr.Error() {
 			err = localErr
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestSDMSubscribeMonitoredResourceUris(t *testing.T) {
+	var subscription models.SdmSubscription
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/nudm-sdm/v2/imsi-208930000000001/sdm-subscriptions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+			t.Errorf("decode SdmSubscription: %v", err)
+		}
+		subscription.SubscriptionId = openapi.PtrString("sub-1")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(subscription)
+	}))
+	defer server.Close()
+
+	ue := &amf_context.AmfUe{
+		Supi:       "imsi-208930000000001",
+		NudmSDMUri: server.URL,
+		PlmnId:     models.PlmnId{Mcc: "208", Mnc: "93"},
+	}
+	problemDetails, err := SDMSubscribe(context.Background(), ue)
+	if err != nil || problemDetails != nil {
+		t.Fatalf("subscribe failed: %v %+v", err, problemDetails)
+	}
+	expected := []string{
+		server.URL + "/nudm-sdm/v2/imsi-208930000000001/am-data",
+		server.URL + "/nudm-sdm/v2/imsi-208930000000001/trace-data",
+	}
+	if !slices.Equal(subscription.MonitoredResourceUris, expected) {
+		t.Errorf("expected monitored resources %v, got %v", expected, subscription.MonitoredResourceUris)
+	}
+	if ue.SdmSubscriptionId != "sub-1" {
+		t.Errorf("expected subscription ID sub-1, got %q", ue.SdmSubscriptionId)
+	}
+}
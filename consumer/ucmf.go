@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	amf_context "github.com/omec-project/amf/context"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// UeRadioCapabilityIdResolution is the UE radio capability of a UE radio capability ID
+// returned by the Nucmf_UECapabilityManagement Resolve service operation (TS 29.675), which
+// has no client in the openapi module. Capabilities are hex encoded.
+type UeRadioCapabilityIdResolution struct {
+	UeRadioCapability string `json:"ueRadioCapability"`
+	ForPagingNr       string `json:"ueRadioCapabilityForPagingNr,omitempty"`
+	ForPagingEutra    string `json:"ueRadioCapabilityForPagingEutra,omitempty"`
+}
+
+// ucmfClient resolves UE radio capability IDs with the UCMF
+type ucmfClient struct {
+	uri string
+}
+
+// NewUcmfClient returns a client of the Nucmf_UECapabilityManagement service at uri.
+func NewUcmfClient(uri string) amf_context.UcmfClient {
+	return &ucmfClient{uri: strings.TrimRight(uri, "/")}
+}
+
+func (c *ucmfClient) Resolve(ctx context.Context, ueRadioCapabilityId string) (*amf_context.UeRadioCapabilityEntry, error) {
+	ctx, span := tracer.Start(ctx, "HTTP GET ucmf/ue-radio-capability-ids/{ueRadioCapaId}")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Nucmf_UECapabilityManagement_Resolve", configuredPeer(c.uri))
+
+	span.SetAttributes(
+		attribute.String("http.method", "GET"),
+		attribute.String("nf.target", "ucmf"),
+		attribute.String("net.peer.name", c.uri),
+	)
+
+	requestURI := fmt.Sprintf("%s/nucmf-uecm/v1/ue-radio-capability-ids/%s", c.uri, url.PathEscape(ueRadioCapabilityId))
+	resolution := &UeRadioCapabilityIdResolution{}
+	_, problemDetails, err := sendJSONRequest(ctx, http.MethodGet, requestURI, nil, resolution)
+	if err != nil {
+		return nil, err
+	}
+	if problemDetails != nil {
+		if problemDetails.GetStatus() == http.StatusNotFound {
+			return nil, amf_context.ErrUeRadioCapabilityIdUnknown
+		}
+		return nil, fmt.Errorf("UCMF resolve failed: %s", problemDetails.GetCause())
+	}
+
+	entry := &amf_context.UeRadioCapabilityEntry{UeRadioCapability: resolution.UeRadioCapability}
+	if resolution.ForPagingNr != "" || resolution.ForPagingEutra != "" {
+		entry.UeRadioCapabilityForPaging = &amf_context.UERadioCapabilityForPaging{
+			NR:    resolution.ForPagingNr,
+			EUTRA: resolution.ForPagingEutra,
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestUcmfClientResolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/nucmf-uecm/v1/ue-radio-capability-ids/10f8390102030405" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(UeRadioCapabilityIdResolution{
+				UeRadioCapability: "0401020304",
+				ForPagingNr:       "0a0b",
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ProblemDetails{Status: openapi.PtrInt32(http.StatusNotFound)})
+	}))
+	defer server.Close()
+
+	client := NewUcmfClient(server.URL + "/")
+	entry, err := client.Resolve(context.Background(), "10f8390102030405")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.UeRadioCapability != "0401020304" || entry.UeRadioCapabilityForPaging == nil ||
+		entry.UeRadioCapabilityForPaging.NR != "0a0b" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	if _, err = client.Resolve(context.Background(), "10f8390000000000"); !errors.Is(err, amf_context.ErrUeRadioCapabilityIdUnknown) {
+		t.Errorf("expected an unknown ID error, got %v", err)
+	}
+}
@@ -37,10 +37,20 @@ func servingNetworkPlmnID(ue *amfContext.AmfUe, servedGuami models.Guami) *model
 	return &plmnId
 }
 
+// supiOrSuci identifies the UE towards the AUSF; a UE that registered with its 5G-GUTI,
+// e.g. when re-authenticated, has no SUCI
+func supiOrSuci(ue *amfContext.AmfUe) string {
+	if ue.Suci != "" {
+		return ue.Suci
+	}
+	return ue.GetSupi()
+}
+
 func SendUEAuthenticationAuthenticateRequest(ctx context.Context, ue *amfContext.AmfUe,
 	resynchronizationInfo *models.ResynchronizationInfo,
 ) (*models.UEAuthenticationCtx, *models.ProblemDetails, error) {
 	configuration := Nausf_UEAuthentication.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.AusfUri
@@ -57,7 +67,7 @@ func SendUEAuthenticationAuthenticateRequest(ctx context.Context, ue *amfContext
 	if err != nil {
 		return nil, nil, err
 	}
-	authInfo := models.NewAuthenticationInfo(ue.Suci, fmt.Sprintf("5G:mnc%03d.mcc%s.3gppnetwork.org", mnc, plmnId.GetMcc()))
+	authInfo := models.NewAuthenticationInfo(supiOrSuci(ue), fmt.Sprintf("5G:mnc%03d.mcc%s.3gppnetwork.org", mnc, plmnId.GetMcc()))
 	if resynchronizationInfo != nil {
 		authInfo.SetResynchronizationInfo(*resynchronizationInfo)
 	}
@@ -66,6 +76,7 @@ func SendUEAuthenticationAuthenticateRequest(ctx context.Context, ue *amfContext
 
 	ctx, span := tracer.Start(ctx, "HTTP POST ausf/ue-authentications")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nausf_UEAuthentication_Authenticate", ue.AusfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -102,6 +113,7 @@ func SendAuth5gAkaConfirmRequest(ctx context.Context, ue *amfContext.AmfUe, resS
 	}
 
 	configuration := Nausf_UEAuthentication.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ausfUri
@@ -116,6 +128,7 @@ func SendAuth5gAkaConfirmRequest(ctx context.Context, ue *amfContext.AmfUe, resS
 
 	ctx, span := tracer.Start(ctx, "HTTP PUT ausf/ue-authentications/{authCtxId}/5g-aka-confirmation")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nausf_UEAuthentication_Authenticate", ue.AusfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "PUT"),
@@ -125,7 +138,7 @@ func SendAuth5gAkaConfirmRequest(ctx context.Context, ue *amfContext.AmfUe, resS
 	)
 
 	apiUeAuthenticationsAuthCtxId5gAkaConfirmationPutRequest := client.DefaultAPI.UeAuthenticationsAuthCtxId5gAkaConfirmationPut(
-		ctx, ue.Suci)
+		ctx, supiOrSuci(ue))
 	apiUeAuthenticationsAuthCtxId5gAkaConfirmationPutRequest = apiUeAuthenticationsAuthCtxId5gAkaConfirmationPutRequest.ConfirmationData(*confirmData)
 	confirmResult, httpResponse, err := client.DefaultAPI.UeAuthenticationsAuthCtxId5gAkaConfirmationPutExecute(apiUeAuthenticationsAuthCtxId5gAkaConfirmationPutRequest)
 	if err == nil {
@@ -157,6 +170,7 @@ func SendEapAuthConfirmRequest(ctx context.Context, ue *amfContext.AmfUe, eapMsg
 	ausfUri := fmt.Sprintf("%s://%s", confirmUri.Scheme, confirmUri.Host)
 
 	configuration := Nausf_UEAuthentication.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ausfUri
@@ -171,6 +185,7 @@ func SendEapAuthConfirmRequest(ctx context.Context, ue *amfContext.AmfUe, eapMsg
 
 	ctx, span := tracer.Start(ctx, "HTTP POST ausf/ue-authentications/{authCtxId}/eap-session")
 	defer span.End()
+	ctx = withSbiRequest(ctx, "Nausf_UEAuthentication_Authenticate", ue.AusfId)
 
 	span.SetAttributes(
 		attribute.String("http.method", "POST"),
@@ -179,7 +194,7 @@ func SendEapAuthConfirmRequest(ctx context.Context, ue *amfContext.AmfUe, eapMsg
 		attribute.String("plmn.id", ue.PlmnId.GetMcc()+ue.PlmnId.GetMnc()),
 	)
 
-	apiEapAuthMethodRequest := client.DefaultAPI.EapAuthMethod(ctx, ue.Suci)
+	apiEapAuthMethodRequest := client.DefaultAPI.EapAuthMethod(ctx, supiOrSuci(ue))
 	apiEapAuthMethodRequest = apiEapAuthMethodRequest.EapSession(*eapSession)
 	eapSessionRsp, httpResponse, err := client.DefaultAPI.EapAuthMethodExecute(apiEapAuthMethodRequest)
 	if err == nil {
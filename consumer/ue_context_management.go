@@ -36,6 +36,7 @@ func UeCmRegistration(ctx context.Context, ue *amf_context.AmfUe, accessType mod
 	*models.ProblemDetails, error,
 ) {
 	configuration := Nudm_UECM.NewConfiguration()
+	configuration.HTTPClient = sbiHTTPClient
 	serverConfig := &configuration.Servers[0]
 	if apiRootVar, exists := serverConfig.Variables["apiRoot"]; exists {
 		apiRootVar.DefaultValue = ue.NudmUECMUri
@@ -60,6 +61,7 @@ func UeCmRegistration(ctx context.Context, ue *amf_context.AmfUe, accessType mod
 
 		gppAccessCtx, span := tracer.Start(gppAccessCtx, "HTTP PUT udm/{ueId}/registrations/amf-3gpp-access")
 		defer span.End()
+		gppAccessCtx = withSbiRequest(gppAccessCtx, "Nudm_UECM_Registration", ue.UdmId)
 
 		span.SetAttributes(
 			attribute.String("http.method", "PUT"),
@@ -99,6 +101,7 @@ func UeCmRegistration(ctx context.Context, ue *amf_context.AmfUe, accessType mod
 
 		non3gppAccessCtx, span := tracer.Start(non3gppAccessCtx, "HTTP PUT udm/{ueId}/registrations/amf-non-3gpp-access")
 		defer span.End()
+		non3gppAccessCtx = withSbiRequest(non3gppAccessCtx, "Nudm_UECM_Registration", ue.UdmId)
 
 		span.SetAttributes(
 			attribute.String("http.method", "PUT"),
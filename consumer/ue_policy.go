@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Npcf_UEPolicyControl data types (TS 29.525 5.6.2), which are not part of the openapi module
+
+// UePolicyAssociationRequest creates a UE policy association at the PCF.
+type UePolicyAssociationRequest struct {
+	NotificationUri string               `json:"notificationUri"`
+	Supi            string               `json:"supi"`
+	Gpsi            string               `json:"gpsi,omitempty"`
+	AccessType      models.AccessType    `json:"accessType,omitempty"`
+	Pei             string               `json:"pei,omitempty"`
+	UserLoc         *models.UserLocation `json:"userLoc,omitempty"`
+	TimeZone        string               `json:"timeZone,omitempty"`
+	ServingPlmn     *models.PlmnIdNid    `json:"servingPlmn,omitempty"`
+	RatType         models.RatType       `json:"ratType,omitempty"`
+	Guami           *models.Guami        `json:"guami,omitempty"`
+	SuppFeat        string               `json:"suppFeat"`
+}
+
+// UePolicyAssociation is the UE policy association created by the PCF. The UE policies
+// themselves are delivered with Namf_Communication_N1N2MessageTransfer.
+type UePolicyAssociation struct {
+	Triggers []models.RequestTrigger `json:"triggers,omitempty"`
+	SuppFeat string                  `json:"suppFeat"`
+}
+
+// UePolicyUpdate notifies the AMF of new policy control request triggers.
+type UePolicyUpdate struct {
+	ResourceUri string                  `json:"resourceUri"`
+	Triggers    []models.RequestTrigger `json:"triggers,omitempty"`
+}
+
+var uePolicyAssociationIdRegexp = regexp.MustCompile(`/policies/([^/?#]+)`)
+
+// UEPolicyControlCreate creates the UE policy association of the UE, over which the PCF
+// provides the UE policies (TS 23.502 4.16.11, TS 29.525 4.2.2).
+func UEPolicyControlCreate(ctx context.Context, ue *amf_context.AmfUe, anType models.AccessType) (
+	*models.ProblemDetails, error,
+) {
+	ctx, span := tracer.Start(ctx, "HTTP POST pcf/ue-policy/policies")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Npcf_UEPolicyControl_Create", ue.PcfId)
+
+	span.SetAttributes(
+		attribute.String("http.method", "POST"),
+		attribute.String("nf.target", "pcf"),
+		attribute.String("net.peer.name", ue.PcfUePolicyUri),
+		attribute.String("ue.supi", ue.GetSupi()),
+		attribute.String("ue.plmn.id", ue.PlmnId.GetMcc()+ue.PlmnId.GetMnc()),
+	)
+
+	amfSelf := amf_context.AMF_Self()
+	associationRequest := UePolicyAssociationRequest{
+		NotificationUri: amfSelf.GetIPv4Uri() + "/namf-callback/v1/ue-policy/",
+		Supi:            ue.GetSupi(),
+		Gpsi:            ue.GetGpsi(),
+		AccessType:      anType,
+		Pei:             ue.GetPei(),
+		TimeZone:        ue.TimeZone,
+		ServingPlmn:     models.NewPlmnIdNid(ue.PlmnId.GetMcc(), ue.PlmnId.GetMnc()),
+		RatType:         ue.RatType,
+		SuppFeat:        "0",
+	}
+	if len(amfSelf.ServedGuamiList) > 0 {
+		associationRequest.Guami = &amfSelf.ServedGuamiList[0]
+	}
+	if ue.Location.NrLocation != nil || ue.Location.EutraLocation != nil || ue.Location.N3gaLocation != nil {
+		associationRequest.UserLoc = &ue.Location
+	}
+
+	requestURI := strings.TrimRight(ue.PcfUePolicyUri, "/") + "/npcf-ue-policy-control/v1/policies"
+	association := &UePolicyAssociation{}
+	header, problemDetails, err := sendJSONRequest(ctx, http.MethodPost, requestURI, associationRequest, association)
+	if problemDetails != nil || err != nil {
+		return problemDetails, err
+	}
+
+	locationHeader := header.Get("Location")
+	match := uePolicyAssociationIdRegexp.FindStringSubmatch(locationHeader)
+	if len(match) < 2 {
+		return nil, fmt.Errorf("no PolicyAssociationId in UE policy Location header %q", locationHeader)
+	}
+	ue.UePolicyUri = locationHeader
+	ue.UePolicyAssociationId = match[1]
+	ue.UePolicyTriggers = association.Triggers
+	logger.ConsumerLog.Debugf("UE Policy Association ID: %s", ue.UePolicyAssociationId)
+	return nil, nil
+}
+
+// UEPolicyControlDelete deletes the UE policy association of the UE (TS 29.525 4.2.4).
+func UEPolicyControlDelete(ctx context.Context, ue *amf_context.AmfUe) (*models.ProblemDetails, error) {
+	ctx, span := tracer.Start(ctx, "HTTP DELETE pcf/ue-policy/policies/{polAssoId}")
+	defer span.End()
+	ctx = withSbiRequest(ctx, "Npcf_UEPolicyControl_Delete", ue.PcfId)
+
+	span.SetAttributes(
+		attribute.String("http.method", "DELETE"),
+		attribute.String("nf.target", "pcf"),
+		attribute.String("net.peer.name", ue.PcfUePolicyUri),
+		attribute.String("ue.supi", ue.GetSupi()),
+		attribute.String("ue.plmn.id", ue.PlmnId.GetMcc()+ue.PlmnId.GetMnc()),
+	)
+
+	requestURI := fmt.Sprintf("%s/npcf-ue-policy-control/v1/policies/%s",
+		strings.TrimRight(ue.PcfUePolicyUri, "/"), url.PathEscape(ue.UePolicyAssociationId))
+	_, problemDetails, err := sendJSONRequest(ctx, http.MethodDelete, requestURI, nil, nil)
+	if problemDetails != nil || err != nil {
+		return problemDetails, err
+	}
+	ue.RemoveUePolicyAssociation()
+	return nil, nil
+}
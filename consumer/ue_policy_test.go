@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestUEPolicyControlCreateAndDelete(t *testing.T) {
+	var associationRequest UePolicyAssociationRequest
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/npcf-ue-policy-control/v1/policies":
+			if err := json.NewDecoder(r.Body).Decode(&associationRequest); err != nil {
+				t.Errorf("decode UePolicyAssociationRequest: %v", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Location", "http://pcf/npcf-ue-policy-control/v1/policies/upa-1")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(UePolicyAssociation{
+				Triggers: []models.RequestTrigger{models.REQUESTTRIGGER_LOC_CH},
+				SuppFeat: "0",
+			})
+		case r.Method == http.MethodDelete && r.URL.Path == "/npcf-ue-policy-control/v1/policies/upa-1":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(models.ProblemDetails{Status: openapi.PtrInt32(http.StatusNotFound)})
+		}
+	}))
+	defer server.Close()
+
+	ue := &amf_context.AmfUe{Supi: "imsi-001010000000001", PcfUePolicyUri: server.URL}
+	problemDetails, err := UEPolicyControlCreate(context.Background(), ue, models.ACCESSTYPE__3_GPP_ACCESS)
+	if err != nil || problemDetails != nil {
+		t.Fatalf("create failed: %v %+v", err, problemDetails)
+	}
+	if ue.UePolicyAssociationId != "upa-1" || len(ue.UePolicyTriggers) != 1 {
+		t.Errorf("unexpected association: id %q, triggers %v", ue.UePolicyAssociationId, ue.UePolicyTriggers)
+	}
+	if associationRequest.Supi != ue.Supi || associationRequest.AccessType != models.ACCESSTYPE__3_GPP_ACCESS {
+		t.Errorf("unexpected request %+v", associationRequest)
+	}
+
+	problemDetails, err = UEPolicyControlDelete(context.Background(), ue)
+	if err != nil || problemDetails != nil {
+		t.Fatalf("delete failed: %v %+v", err, problemDetails)
+	}
+	if !deleted || ue.UePolicyAssociationId != "" {
+		t.Errorf("expected the association to be deleted, id %q", ue.UePolicyAssociationId)
+	}
+
+	ue.UePolicyAssociationId = "unknown"
+	if problemDetails, _ = UEPolicyControlDelete(context.Background(), ue); problemDetails == nil ||
+		problemDetails.GetStatus() != http.StatusNotFound {
+		t.Errorf("expected 404 problem details, got %+v", problemDetails)
+	}
+}
@@ -28,6 +28,7 @@ const (
 	TimeT3513 time.Duration = 6 * time.Second
 	TimeT3522 time.Duration = 6 * time.Second
 	TimeT3550 time.Duration = 6 * time.Second
+	TimeT3555 time.Duration = 6 * time.Second
 	TimeT3560 time.Duration = 6 * time.Second
 	TimeT3565 time.Duration = 6 * time.Second
 )
@@ -12,6 +12,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/omec-project/amf/factory"
 	"github.com/omec-project/amf/logger"
@@ -46,6 +47,8 @@ type AmfRan struct {
 
 	/* RAN UE List */
 	RanUeList map[int64]*RanUe `json:"-"`
+	/* time the NG connection was set up */
+	ConnectedAt time.Time
 
 	Amf2RanMsgChan chan *sdcoreAmfServer.AmfMessage `json:"-"`
 	/* logger */
@@ -74,7 +77,7 @@ func NewSupportedTAIList() []SupportedTAI {
 // or ID at creation time should use this function so that ran.Log is never
 // nil when ran itself is non-nil.
 func NewAmfRanDefault() *AmfRan {
-	return &AmfRan{Log: logger.NgapLog, RanUeList: make(map[int64]*RanUe)}
+	return &AmfRan{Log: logger.NgapLog, RanUeList: make(map[int64]*RanUe), ConnectedAt: time.Now()}
 }
 
 // RatInformationForTAC returns the RATInformation advertised by this RAN
@@ -135,11 +138,12 @@ func (ran *AmfRan) NewRanUe(ranUeNgapID int64) (*RanUe, error) {
 	ranUe.AmfUeNgapId = amfUeNgapID
 	ranUe.RanUeNgapId = ranUeNgapID
 	ranUe.Ran = ran
-	ranUe.Log = ran.Log.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ranUe.AmfUeNgapId))
+	ranUe.Log = newUeLogger(ran.Log, logger.FieldAmfUeNgapID, ranUe.AmfUeNgapId)
 	ran.ranStateMu.Lock()
 	ran.RanUeList[ranUeNgapID] = &ranUe
 	ran.ranStateMu.Unlock()
 	self.RanUePool.Store(ranUe.AmfUeNgapId, &ranUe)
+	ranUe.countOnRan(ran)
 	return &ranUe, nil
 }
 
@@ -211,6 +215,11 @@ func (ran *AmfRan) SetRanId(ranNodeId *ngapType.GlobalRANNodeID) error {
 			ran.GnbId += ranId.GNbId.GNBValue
 		}
 	}
+	if ran.GnbIp != "" {
+		ran.Log = logger.NgapLog.With(logger.FieldRanAddr, ran.GnbIp, logger.FieldRanId, ran.GnbId)
+	} else {
+		ran.Log = logger.NgapLog.With(logger.FieldRanId, ran.GnbId)
+	}
 	ran.Log.Debugf("set RanId: %+v, GnbId: %s, AnType: %s", ran.RanId, ran.GnbId, ran.AnType)
 	return nil
 }
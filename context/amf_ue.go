@@ -12,12 +12,12 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
-	"fmt"
 	"os"
 	"reflect"
 	"regexp"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -25,6 +25,7 @@ import (
 	"github.com/omec-project/amf/logger"
 	"github.com/omec-project/amf/metrics"
 	"github.com/omec-project/amf/protos/sdcoreAmfServer"
+	"github.com/omec-project/amf/tracerecording"
 	"github.com/omec-project/nas/v2/nasMessage"
 	"github.com/omec-project/nas/v2/nasType"
 	"github.com/omec-project/nas/v2/security"
@@ -34,6 +35,7 @@ import (
 	"github.com/omec-project/util/idgenerator"
 	mi "github.com/omec-project/util/metricinfo"
 	"github.com/omec-project/util/ueauth"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -45,6 +47,8 @@ const (
 	OnGoingProcedureN2Handover   OnGoingProcedure = "N2Handover"
 	OnGoingProcedureRegistration OnGoingProcedure = "Registration"
 	OnGoingProcedureAbort        OnGoingProcedure = "Abort"
+	// network initiated re-authentication of a registered UE, see OamProcedureReauthentication
+	OnGoingProcedureReauthentication OnGoingProcedure = "Reauthentication"
 )
 
 const (
@@ -70,7 +74,7 @@ const (
 type AmfUe struct {
 	// Mutex sync.Mutex `json:"mutex,omitempty" yaml:"mutex" bson:"mutex,omitempty"`
 	Mutex sync.Mutex `json:"-"`
-	// identityMu guards the UE identity fields (Supi/Pei/Gpsi/Tmsi/Guti and
+	// identityMu guards the UE identity fields (Supi/Pei/Gpsi/Tmsi/Guti/OldGuti and
 	// RegistrationType5GS) so they can be read safely from goroutines other than
 	// the one running the UE's NAS procedure (e.g. SBI handlers, logging). Use the
 	// Get*/Set* accessors below; do not touch those fields directly across
@@ -78,7 +82,7 @@ type AmfUe struct {
 	// state) so an accessor can never self-deadlock against a Mutex holder.
 	identityMu sync.RWMutex `json:"-"`
 	/* the AMF which serving this AmfUe now */
-	ServingAMF *AMFContext `json:"servingAMF,omitempty"` // never nil
+	ServingAMF *AMFContext `json:"-"` // never nil
 
 	/* Gmm State */
 	State map[models.AccessType]*fsm.State `json:"-"`
@@ -93,6 +97,7 @@ type AmfUe struct {
 	/* Used for AMF relocation */
 	TargetAmfProfile *models.NFProfileDiscovery `json:"targetAmfProfile,omitempty"`
 	TargetAmfUri     string                     `json:"targetAmfUri,omitempty"`
+	TargetAmfId      string                     `json:"targetAmfId,omitempty"`
 	/* Ue Identity*/
 	PlmnId              models.PlmnId `json:"plmnId,omitempty"`
 	Suci                string        `json:"suci,omitempty"`
@@ -102,6 +107,8 @@ type AmfUe struct {
 	Pei                 string        `json:"pei,omitempty"`
 	Tmsi                int32         `json:"tmsi,omitempty"` // 5G-Tmsi
 	Guti                string        `json:"guti,omitempty"`
+	OldGuti             string        `json:"oldGuti,omitempty"` // 5G-GUTI before a reallocation not acknowledged yet
+	OldTmsi             int32         `json:"oldTmsi,omitempty"`
 	GroupID             string        `json:"groupID,omitempty"`
 	EBI                 int32         `json:"ebi,omitempty"`
 	/* Ue Identity*/
@@ -126,6 +133,16 @@ type AmfUe struct {
 	UdmGroupId                        string                                    `json:"udmGroupId,omitempty"`
 	SubscribedNssai                   []models.SubscribedSnssai                 `json:"subscribeNssai,omitempty"`
 	AccessAndMobilitySubscriptionData *models.AccessAndMobilitySubscriptionData `json:"accessAndMobilitySubscriptionData,omitempty"`
+	SdmSubscriptionId                 string                                    `json:"sdmSubscriptionId,omitempty"`
+	/* Subscriber and equipment trace */
+	TraceRecordingSessionRef string                  `json:"traceRecordingSessionRef,omitempty"` // TRSR allocated by the AMF
+	traceMutex               sync.Mutex              `json:"-"`
+	traceSession             *tracerecording.Session `json:"-"`
+	/* Steering of Roaming and UE Parameters Update */
+	SorAckRequested bool `json:"sorAckRequested,omitempty"` // the UE owes an ack for the last SoR container
+	UpuAckRequested bool `json:"upuAckRequested,omitempty"` // the UE owes an ack for the last UPU container
+	// containers queued for a UE in CM-IDLE, sent once it is CM-CONNECTED
+	PendingTransparentContainers []TransparentContainer `json:"pendingTransparentContainers,omitempty"`
 	/* contex abut ausf */
 	AusfGroupId                       string                      `json:"ausfGroupId,omitempty"`
 	AusfId                            string                      `json:"ausfId,omitempty"`
@@ -144,14 +161,19 @@ type AmfUe struct {
 	AmPolicyAssociation          *models.PolicyAssociation `json:"amPolicyAssociation,omitempty"`
 	RequestTriggerLocationChange bool                      `json:"requestTriggerLocationChange,omitempty"` // true if AmPolicyAssociation.Trigger contains REQUESTTRIGGER_LOC_CH
 	ConfigurationUpdateMessage   []byte                    `json:"configurationUpdateMessage,omitempty"`
+	/* UE policy association (URSP delivery) */
+	PcfUePolicyUri        string                  `json:"pcfUePolicyUri,omitempty"`
+	UePolicyAssociationId string                  `json:"uePolicyAssociationId,omitempty"`
+	UePolicyUri           string                  `json:"uePolicyUri,omitempty"`
+	UePolicyTriggers      []models.RequestTrigger `json:"uePolicyTriggers,omitempty"`
 	/* UeContextForHandover*/
 	HandoverNotifyUri string `json:"handoverNotifyUri,omitempty"`
 	/* N1N2Message */
-	N1N2MessageIDGenerator          *idgenerator.IDGenerator `json:"n1n2MessageIDGenerator,omitempty"`
+	N1N2MessageIDGenerator          *idgenerator.IDGenerator `json:"-"`
 	N1N2Message                     *N1N2Message             `json:"-"`
-	N1N2MessageSubscribeIDGenerator *idgenerator.IDGenerator `json:"n1n2MessageSubscribeIDGenerator,omitempty"`
+	N1N2MessageSubscribeIDGenerator *idgenerator.IDGenerator `json:"-"`
 	// map[int64]models.UeN1N2InfoSubscriptionCreateData; use n1n2MessageSubscriptionID as key
-	N1N2MessageSubscription sync.Map `json:"n1n2MessageSubscription,omitempty"`
+	N1N2MessageSubscription sync.Map `json:"-"`
 	/* Pdu Sesseion context */
 	SmContextList sync.Map `json:"-"` // map[int32]*SmContext, pdu session id as key
 	/* Related Context*/
@@ -162,10 +184,20 @@ type AmfUe struct {
 	UeRadioCapability             string                                          `json:"ueRadioCapability,omitempty"` // OCTET string
 	Capability5GMM                nasType.Capability5GMM                          `json:"capability5GMM,omitempty"`
 	ConfigurationUpdateIndication nasType.ConfigurationUpdateIndication           `json:"configurationUpdateIndication,omitempty"`
+
+	/* UE radio capability signalling optimisation (TS 23.501 5.4.4.1a) */
+	UeRadioCapabilityId string `json:"ueRadioCapabilityId,omitempty"` // hex, TS 24.501 9.11.3.68
+
 	/* context related to Paging */
 	UeRadioCapabilityForPaging                 *UERadioCapabilityForPaging                 `json:"ueRadioCapabilityForPaging,omitempty"`
 	InfoOnRecommendedCellsAndRanNodesForPaging *InfoOnRecommendedCellsAndRanNodesForPaging `json:"infoOnRecommendedCellsAndRanNodesForPaging,omitempty"`
 	UESpecificDRX                              uint8                                       `json:"ueSpecificDRX,omitempty"`
+	PagingState                                *PagingState                                `json:"pagingState,omitempty"`
+	/* MICO mode and extended DRX */
+	MicoMode                       bool            `json:"micoMode,omitempty"`
+	MicoAllPlmnRegistrationArea    bool            `json:"micoAllPlmnRegistrationArea,omitempty"`
+	MicoStrictPeriodicRegistration bool            `json:"micoStrictPeriodicRegistration,omitempty"`
+	EdrxParameters                 *EdrxParameters `json:"edrxParameters,omitempty"` // nil if eDRX is not used
 	/* Security Context */
 	SecurityContextAvailable bool                         `json:"securityContextAvailable,omitempty"`
 	UESecurityCapability     nasType.UESecurityCapability `json:"ueSecurityCapability,omitempty"` // for security command
@@ -193,16 +225,63 @@ type AmfUe struct {
 	AllowedNssai                      map[models.AccessType][]models.AllowedSnssai `json:"allowedNssai,omitempty"`
 	ConfiguredNssai                   []models.ConfiguredSnssai                    `json:"configuredNssai,omitempty"`
 	NetworkSlicingSubscriptionChanged bool                                         `json:"networkSlicingSubscriptionChanged,omitempty"`
+	/* Network Slice-Specific Authentication and Authorization */
+	NssaafUri           string                   `json:"nssaafUri,omitempty"`
+	NssaafId            string                   `json:"nssaafId,omitempty"`
+	NssaiRequiringNssaa []models.Snssai          `json:"nssaiRequiringNssaa,omitempty"`
+	PendingNssai        []models.Snssai          `json:"pendingNssai,omitempty"`
+	NssaaSessions       map[string]*NssaaSession `json:"nssaaSessions,omitempty"`
+	// NSSAA messages for the UE in CM-IDLE, sent in order once it answers paging
+	PendingNssaaMessages []PendingNssaaMessage `json:"pendingNssaaMessages,omitempty"`
 	/* T3513(Paging) */
-	T3513 *Timer `json:"t3513Value,omitempty"` // for paging
+	T3513 *Timer `json:"-"` // for paging
 	/* T3565(Notification) */
-	T3565 *Timer `json:"t3565Value,omitempty"` // for NAS Notification
+	T3565 *Timer `json:"-"` // for NAS Notification
+	/* T3555 (for configuration update command retransmission) */
+	T3555 *Timer `json:"-"`
 	/* T3560 (for authentication request/security mode command retransmission) */
-	T3560 *Timer `json:"t3560Value,omitempty"`
+	T3560 *Timer `json:"-"`
 	/* T3550 (for registration accept retransmission) */
-	T3550 *Timer `json:"t3550Value,omitempty"`
+	T3550 *Timer `json:"-"`
 	/* T3522 (for deregistration request) */
-	T3522 *Timer `json:"t3522Value,omitempty"`
+	T3522 *Timer `json:"-"`
+	// timers read from the DB, re-armed by RearmTimers once the UE context is linked
+	restoredTimers map[string]TimerState
+
+	// procedures measured for the KPIs, see StartProcedure
+	kpiMu         sync.Mutex
+	kpiProcedures map[KpiProcedure]kpiProcedureRun
+
+	// labels the UE and its PDU sessions are counted with in the UE population gauges
+	populationMu         sync.Mutex
+	populationRegistered map[models.AccessType]bool
+	populationCounted    map[models.AccessType]registeredPopulation
+	populationSessions   map[int32]sessionPopulation
+
+	// UE-level span of the running registration, see StartRegistrationSpan
+	registrationSpanMu sync.Mutex
+	registrationSpan   trace.Span
+
+	// last uplink NAS message of the UE in unix milliseconds, see RecordActivity
+	lastActivity atomic.Int64
+
+	// OAM procedure awaiting its outcome, see StartOamProcedure
+	oamMu        sync.Mutex
+	oamProcedure *oamProcedureRun
+	// security context of the UE before its re-authentication, see SaveSecurityContext
+	reauthSecurityContext *savedSecurityContext
+	// NAS messages the UE sent during its re-authentication, see DeferNasMessage
+	deferredNasMessages []DeferredNasMessage
+
+	// procedure the log lines of the UE are correlated with, see UpdateLoggers
+	logMu       sync.Mutex
+	procedureId string
+
+	// procedure timeline of the UE, see RecordFlight
+	flightMu      sync.Mutex
+	flightRecords []FlightRecord
+	flightNext    int // oldest record once the ring buffer is full
+
 	/* Ue Context Release Cause */
 	ReleaseCause map[models.AccessType]*CauseAll `json:"releaseCause,omitempty"`
 	/* T3502 (Assigned by AMF, and used by UE to initialize registration procedure) */
@@ -231,12 +310,12 @@ func (ue *AmfUe) MarshalJSON() ([]byte, error) {
 	smCtxListVal := make(map[string]SmContext)
 	var ranUeNgapIDVal, amfUeNgapIDVal int64
 	var gnbId string
-	if ue.RanUe != nil && ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS] != nil {
-		gnbId = ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].Ran.GnbId
-		if ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS] != nil {
-			ranUeNgapIDVal = ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].RanUeNgapId
-			amfUeNgapIDVal = ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].AmfUeNgapId
+	if ranUe := ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS]; ranUe != nil {
+		if ranUe.Ran != nil {
+			gnbId = ranUe.Ran.GnbId
 		}
+		ranUeNgapIDVal = ranUe.RanUeNgapId
+		amfUeNgapIDVal = ranUe.AmfUeNgapId
 	}
 
 	for access, state := range ue.State {
@@ -245,42 +324,48 @@ func (ue *AmfUe) MarshalJSON() ([]byte, error) {
 	var n1n2MsgPtr *N1N2Message
 	if ue.N1N2Message != nil {
 		n1n2MsgVal := *ue.N1N2Message
-		n1n2MsgVal.Request = ue.N1N2Message.Request
-		n1n2MsgVal.Request.JsonData = models.NewN1N2MessageTransferReqData()
 		if ue.N1N2Message.Request.JsonData != nil {
-			n1n2MsgVal.Request.JsonData = ue.N1N2Message.Request.JsonData
-			n1n2MsgVal.Request.JsonData.N1MessageContainer = models.NewN1MessageContainerWithDefaults()
-			n1n2MsgVal.Request.JsonData.N2InfoContainer = models.NewN2InfoContainerWithDefaults()
-			if ue.N1N2Message.Request.JsonData.N1MessageContainer != nil {
-				*n1n2MsgVal.Request.JsonData.N1MessageContainer = *ue.N1N2Message.Request.JsonData.N1MessageContainer
-			}
-			if ue.N1N2Message.Request.JsonData.N2InfoContainer != nil {
-				*n1n2MsgVal.Request.JsonData.N2InfoContainer = *ue.N1N2Message.Request.JsonData.N2InfoContainer
-			}
+			// copy so that marshalling never touches the pending request itself
+			jsonData := *ue.N1N2Message.Request.JsonData
+			n1n2MsgVal.Request.JsonData = &jsonData
+		} else {
+			n1n2MsgVal.Request.JsonData = models.NewN1N2MessageTransferReqData()
 		}
 		n1n2MsgPtr = &n1n2MsgVal
 	}
 
 	ue.SmContextList.Range(func(key, val interface{}) bool {
 		smContext := val.(*SmContext)
-		pduSessId := smContext.PduSessionID()
-		newSmCtx := NewSmContext(pduSessId)
-		newSmCtx.SetPduSessionID(smContext.PduSessionID())
-		newSmCtx.SetSmContextRef(smContext.SmContextRef())
-		newSmCtx.SetSmfUri(smContext.SmfUri())
-		newSmCtx.SetSmfID(smContext.SmfID())
-		newSmCtx.SetSnssai(smContext.Snssai())
-		newSmCtx.SetDnn(smContext.Dnn())
-		newSmCtx.SetAccessType(smContext.AccessType())
-		newSmCtx.SetHSmfID(smContext.HSmfID())
-		newSmCtx.SetVSmfID(smContext.VSmfID())
-		newSmCtx.SetNsInstance(smContext.NsInstance())
-
-		pduSessIdStr := strconv.FormatInt(int64(pduSessId), 10)
-		smCtxListVal[pduSessIdStr] = *newSmCtx
+		smContext.Mu.RLock()
+		newSmCtx := *smContext
+		smContext.Mu.RUnlock()
+		newSmCtx.Mu = nil
+
+		pduSessIdStr := strconv.FormatInt(int64(newSmCtx.PduSessionIDVal), 10)
+		smCtxListVal[pduSessIdStr] = newSmCtx
 		return true
 	})
 
+	n1n2SubscriptionVal := make(map[string]models.UeN1N2InfoSubscriptionCreateData)
+	ue.N1N2MessageSubscription.Range(func(key, val interface{}) bool {
+		n1n2SubscriptionVal[strconv.FormatInt(key.(int64), 10)] = val.(models.UeN1N2InfoSubscriptionCreateData)
+		return true
+	})
+
+	timersVal := make(map[string]TimerState)
+	for name, timer := range map[string]*Timer{
+		TimerT3513: ue.T3513,
+		TimerT3522: ue.T3522,
+		TimerT3550: ue.T3550,
+		TimerT3555: ue.T3555,
+		TimerT3560: ue.T3560,
+		TimerT3565: ue.T3565,
+	} {
+		if timer != nil {
+			timersVal[name] = timer.State()
+		}
+	}
+
 	customAmfUe := CustomFieldsAmfUe{
 		State:       stateVal,
 		SmCtxList:   smCtxListVal,
@@ -290,6 +375,13 @@ func (ue *AmfUe) MarshalJSON() ([]byte, error) {
 		AmfUeNgapId: amfUeNgapIDVal,
 		N1N2Message: n1n2MsgPtr,
 		RanId:       gnbId,
+		N1N2Subs:    n1n2SubscriptionVal,
+		Timers:      timersVal,
+		Flight:      ue.persistedFlightRecords(),
+	}
+	customAmfUe.Snssais, customAmfUe.Dnns = ue.sessionSnssaisAndDnns()
+	if lastActivity := ue.LastActivity(); !lastActivity.IsZero() {
+		customAmfUe.LastActivity = lastActivity.UnixMilli()
 	}
 
 	return sonic.Marshal(&struct {
@@ -321,24 +413,51 @@ func (ue *AmfUe) UnmarshalJSON(data []byte) error {
 	}
 	for index, states := range aux.State {
 		ue.State[index] = fsm.NewState(fsm.StateType(states))
-		if ue.RanUe[index] == nil {
-			ue.RanUe[index] = &RanUe{}
+	}
+	// only the 3GPP access UE-associated NG connection is stored
+	if aux.AmfUeNgapId != 0 {
+		ranUe := &RanUe{
+			RanUeNgapId: aux.RanUeNgapId,
+			AmfUeNgapId: aux.AmfUeNgapId,
+			Ran:         ran,
+			Log:         newUeLogger(logger.NgapLog, logger.FieldAmfUeNgapID, aux.AmfUeNgapId),
 		}
-		ue.RanUe[index].RanUeNgapId = aux.RanUeNgapId
-		ue.RanUe[index].AmfUeNgapId = aux.AmfUeNgapId
-		ue.RanUe[index].Log = logger.NgapLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ue.RanUe[index].AmfUeNgapId))
-		if ran != nil {
-			// ran.RanUeList[ue.RanUe[index].RanUeNgapId] = ue.RanUe[index]
-			ue.RanUe[index].Ran = ran
+		if stored := ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS]; stored != nil {
+			stored.RanUeNgapId = ranUe.RanUeNgapId
+			stored.AmfUeNgapId = ranUe.AmfUeNgapId
+			updateUeLogger(&stored.Log, logger.NgapLog, logger.FieldAmfUeNgapID, aux.AmfUeNgapId)
+			if ran != nil {
+				stored.Ran = ran
+			}
+			ranUe = stored
 		}
+		ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS] = ranUe
 	}
 	for key, val := range aux.SmCtxList {
 		keyVal, err := strconv.ParseInt(key, 10, 32)
 		if err != nil {
 			logger.ContextLog.Errorf("Error parsing int from %s: %v", key, err)
+			continue
 		}
-		ue.StoreSmContext(int32(keyVal), &val)
+		smContext := val
+		smContext.Mu = new(sync.RWMutex)
+		// counted in the PDU session gauge by the AMF instance that stored it
+		ue.SmContextList.Store(int32(keyVal), &smContext)
+	}
+	subscriptionIDs := make([]int64, 0, len(aux.N1N2Subs))
+	for key, val := range aux.N1N2Subs {
+		subscriptionID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			logger.ContextLog.Errorf("Error parsing int from %s: %v", key, err)
+			continue
+		}
+		ue.N1N2MessageSubscription.Store(subscriptionID, val)
+		subscriptionIDs = append(subscriptionIDs, subscriptionID)
 	}
+	reserveIDs(ue.N1N2MessageSubscribeIDGenerator, subscriptionIDs)
+	ue.restoredTimers = aux.Timers
+	ue.restoreFlightRecords(aux.Flight)
+	ue.lastActivity.Store(aux.LastActivity)
 	sqn := uint8(aux.ULCount & 0x000000ff)
 	overflow := uint16((aux.ULCount & 0x00ffff00) >> 8)
 	ue.ULCount.Set(overflow, sqn)
@@ -380,6 +499,14 @@ type NgapMsg struct {
 	SctplbMsg *sdcoreAmfServer.SctplbMessage
 	NgapMsg   *ngapType.NGAPPDU
 	Ran       *AmfRan
+	Context   ctxt.Context
+}
+
+// TransparentContainer is an SoR or UE parameters update transparent container sent in DL NAS
+// Transport
+type TransparentContainer struct {
+	PayloadContainerType uint8  `json:"payloadContainerType"`
+	Contents             []byte `json:"contents"`
 }
 
 type SbiResponseMsg struct {
@@ -393,6 +520,8 @@ type SbiMsg struct {
 	Msg         interface{}
 	UeContextId string
 	ReqUri      string
+	// Context carries the trace context of the SBI request
+	Context ctxt.Context
 
 	Result chan SbiResponseMsg
 }
@@ -429,27 +558,27 @@ type UERadioCapabilityForPaging struct {
 
 // TS 38.413 9.3.1.100
 type InfoOnRecommendedCellsAndRanNodesForPaging struct {
-	RecommendedCells    []RecommendedCell  // RecommendedCellsForPaging
-	RecommendedRanNodes []RecommendRanNode // RecommendedRanNodesForPaging
+	RecommendedCells    []RecommendedCell  `json:"recommendedCells,omitempty"`    // RecommendedCellsForPaging
+	RecommendedRanNodes []RecommendRanNode `json:"recommendedRanNodes,omitempty"` // RecommendedRanNodesForPaging
 }
 
 // TS 38.413 9.3.1.71
 type RecommendedCell struct {
-	NgRanCGI         NGRANCGI
-	TimeStayedInCell *int64
+	NgRanCGI         NGRANCGI `json:"ngRanCgi"`
+	TimeStayedInCell *int64   `json:"timeStayedInCell,omitempty"`
 }
 
 // TS 38.413 9.3.1.101
 type RecommendRanNode struct {
-	Present         int32
-	GlobalRanNodeId *models.GlobalRanNodeId
-	Tai             *models.Tai
+	Present         int32                   `json:"present"`
+	GlobalRanNodeId *models.GlobalRanNodeId `json:"globalRanNodeId,omitempty"`
+	Tai             *models.Tai             `json:"tai,omitempty"`
 }
 
 type NGRANCGI struct {
-	Present  int32
-	NRCGI    *models.Ncgi
-	EUTRACGI *models.Ecgi
+	Present  int32        `json:"present"`
+	NRCGI    *models.Ncgi `json:"nrCgi,omitempty"`
+	EUTRACGI *models.Ecgi `json:"eutraCgi,omitempty"`
 }
 
 func (ue *AmfUe) init() {
@@ -473,10 +602,53 @@ func (ue *AmfUe) init() {
 	ue.AmfInstanceName = os.Getenv("HOSTNAME")
 	ue.AmfInstanceIp = os.Getenv("POD_IP")
 	// ue.TransientInfo = make(chan AmfUeTransientInfo, 10)
-	ue.GmmLog = logger.GmmLog
-	ue.NASLog = logger.NasLog
-	ue.TxLog = logger.GmmLog
-	ue.ProducerLog = logger.ProducerLog
+	ue.GmmLog = newUeLogger(logger.GmmLog)
+	ue.NASLog = newUeLogger(logger.NasLog)
+	ue.TxLog = newUeLogger(logger.GmmLog)
+	ue.ProducerLog = newUeLogger(logger.ProducerLog)
+}
+
+// RearmTimers restarts the retransmission timers that were running when the UE
+// context was stored, once the context restored from the DB is linked to its RanUe.
+func (ue *AmfUe) RearmTimers() {
+	timers := ue.restoredTimers
+	ue.restoredTimers = nil
+	for name, state := range timers {
+		if rearm, ok := timerRearmFuncs[name]; ok {
+			ue.TxLog.Infof("re-arm timer %s (expired %d times)", name, state.ExpireTimes)
+			rearm(ue, state)
+		}
+	}
+}
+
+// reserveIDs marks restored ids as used in generator. IDGenerator has no API for
+// that, so ids are allocated up to the largest restored one and the gaps freed.
+func reserveIDs(generator *idgenerator.IDGenerator, ids []int64) {
+	if generator == nil || len(ids) == 0 {
+		return
+	}
+	inUse := make(map[int64]bool, len(ids))
+	var maxID int64
+	for _, id := range ids {
+		inUse[id] = true
+		maxID = max(maxID, id)
+	}
+	var allocated []int64
+	for {
+		id, err := generator.Allocate()
+		if err != nil {
+			break
+		}
+		allocated = append(allocated, id)
+		if id >= maxID {
+			break
+		}
+	}
+	for _, id := range allocated {
+		if !inUse[id] {
+			generator.FreeID(id)
+		}
+	}
 }
 
 // UeIdentity is a consistent snapshot of a UE's identity fields, taken under
@@ -531,6 +703,12 @@ func (ue *AmfUe) GetGuti() string {
 	return ue.Guti
 }
 
+func (ue *AmfUe) GetOldGuti() string {
+	ue.identityMu.RLock()
+	defer ue.identityMu.RUnlock()
+	return ue.OldGuti
+}
+
 func (ue *AmfUe) GetTmsi() int32 {
 	ue.identityMu.RLock()
 	defer ue.identityMu.RUnlock()
@@ -594,13 +772,19 @@ func (ue *AmfUe) Remove() {
 		}
 	}
 
-	if AMF_Self().EnableDbStore {
+	if AMF_Self().Drsm != nil {
 		if err := AMF_Self().Drsm.ReleaseInt32ID(ue.Tmsi); err != nil {
 			logger.ContextLog.Errorf("error releasing RanUe: %v", err)
 		}
 	} else {
 		tmsiGenerator.FreeID(int64(ue.Tmsi))
 	}
+	AMF_Self().FreeOldGuti(ue)
+
+	ue.closeTraceSession()
+	ue.releasePopulation()
+	ue.EndRegistrationSpan("UE context removed")
+	ue.endOamProcedure(nil, OamOutcomeFailed, "UE context removed")
 
 	if len(ue.Supi) > 0 {
 		AMF_Self().UePool.Delete(ue.Supi)
@@ -612,9 +796,10 @@ func (ue *AmfUe) Remove() {
 
 func (ue *AmfUe) DetachRanUe(anType models.AccessType) {
 	ue.Mutex.Lock()
-	defer ue.Mutex.Unlock()
-
 	delete(ue.RanUe, anType)
+	ue.Mutex.Unlock()
+
+	ue.updateCmPopulation(anType)
 }
 
 func (ue *AmfUe) GetRanUe(anType models.AccessType) *RanUe {
@@ -632,12 +817,13 @@ func (ue *AmfUe) AttachRanUe(ranUe *RanUe) {
 	if oldRanUe == ranUe {
 		ranUe.AmfUe = ue
 		ue.Mutex.Unlock()
-		ue.updateAttachedRanUeLogs(ranUe)
+		ue.UpdateLoggers()
 		return
 	}
 	ue.RanUe[anType] = ranUe
 	ranUe.AmfUe = ue
 	ue.Mutex.Unlock()
+	ue.updateCmPopulation(anType)
 
 	if oldRanUe != nil {
 		go func(oldRanUe, newRanUe *RanUe, anType models.AccessType) {
@@ -653,14 +839,7 @@ func (ue *AmfUe) AttachRanUe(ranUe *RanUe) {
 		}(oldRanUe, ranUe, anType)
 	}
 
-	ue.updateAttachedRanUeLogs(ranUe)
-}
-
-func (ue *AmfUe) updateAttachedRanUeLogs(ranUe *RanUe) {
-	// set log information
-	ue.NASLog = logger.NasLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ranUe.AmfUeNgapId))
-	ue.GmmLog = logger.GmmLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ranUe.AmfUeNgapId))
-	ue.TxLog = logger.GmmLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ranUe.AmfUeNgapId))
+	ue.UpdateLoggers()
 }
 
 func (ue *AmfUe) GetAnType() models.AccessType {
@@ -938,10 +1117,14 @@ func (ue *AmfUe) ClearRegistrationData() {
 	// Allowed Nssai should be cleared first as it is a new Registration
 	ue.SubscribedNssai = nil
 	ue.AllowedNssai = make(map[models.AccessType][]models.AllowedSnssai)
+	ue.NssaiRequiringNssaa = nil
+	ue.PendingNssai = nil
+	ue.NssaaSessions = nil
+	ue.PendingNssaaMessages = nil
 	ue.SubscriptionDataValid = false
 	// Clearing SMContextList locally
 	ue.SmContextList.Range(func(key, _ interface{}) bool {
-		ue.SmContextList.Delete(key)
+		ue.DeleteSmContext(key.(int32))
 		return true
 	})
 }
@@ -962,6 +1145,12 @@ func (ue *AmfUe) RemoveAmPolicyAssociation() {
 	ue.PolicyAssociationId = ""
 }
 
+func (ue *AmfUe) RemoveUePolicyAssociation() {
+	ue.UePolicyAssociationId = ""
+	ue.UePolicyUri = ""
+	ue.UePolicyTriggers = nil
+}
+
 func (ue *AmfUe) CopyDataFromUeContextModel(ueContext models.UeContext) {
 	if ueContext.GetSupi() != "" {
 		ue.SetSupi(ueContext.GetSupi())
@@ -1151,7 +1340,8 @@ func (ue *AmfUe) CopyDataFromUeContextModel(ueContext models.UeContext) {
 		}
 	}
 	if ueContext.TraceData.IsSet() {
-		ue.TraceData = ueContext.TraceData.Get()
+		// the trace session continues with a TRSR of this AMF
+		ue.ActivateTrace(ueContext.TraceData.Get())
 	}
 }
 
@@ -1159,6 +1349,12 @@ func (ue *AmfUe) CopyDataFromUeContextModel(ueContext models.UeContext) {
 
 func (ue *AmfUe) StoreSmContext(pduSessionID int32, smContext *SmContext) {
 	ue.SmContextList.Store(pduSessionID, smContext)
+	ue.countSession(pduSessionID, smContext)
+}
+
+func (ue *AmfUe) DeleteSmContext(pduSessionID int32) {
+	ue.SmContextList.Delete(pduSessionID)
+	ue.uncountSession(pduSessionID)
 }
 
 func (ue *AmfUe) SmContextFindByPDUSessionID(pduSessionID int32) (*SmContext, bool) {
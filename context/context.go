@@ -16,11 +16,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/omec-project/amf/factory"
 	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/packetcapture"
 	"github.com/omec-project/openapi/v2/models"
 	"github.com/omec-project/openapi/v2/nfConfigApi"
 	"github.com/omec-project/util/drsm"
@@ -32,6 +34,8 @@ var (
 	tmsiGenerator                    *idgenerator.IDGenerator = nil
 	amfUeNGAPIDGenerator             *idgenerator.IDGenerator = nil
 	amfStatusSubscriptionIDGenerator *idgenerator.IDGenerator = nil
+	// IDs of the subscriptions restored after a restart, not allocated by the generator
+	reservedAMFStatusSubscriptionIDs sync.Map // map[int64]struct{}
 	amfContextMutex                  sync.Mutex
 )
 
@@ -92,12 +96,31 @@ type AMFContext struct {
 	T3513Cfg                 factory.TimerValue
 	T3522Cfg                 factory.TimerValue
 	T3550Cfg                 factory.TimerValue
+	T3555Cfg                 factory.TimerValue
 	T3560Cfg                 factory.TimerValue
 	T3565Cfg                 factory.TimerValue
 	EnableSctpLb             bool
 	EnableDbStore            bool
 	EnableNrfCaching         bool
 	NrfCacheEvictionInterval time.Duration
+	OverloadControl          *OverloadControl               // nil when overload control is disabled
+	PowerSaving              *factory.PowerSavingConfig     // nil when MICO mode and eDRX are disabled
+	SignallingTrace          *factory.SignallingTraceConfig // nil when trace recording is disabled
+	FlightRecorder           *factory.FlightRecorderConfig  // nil when the UE flight recorder is disabled
+	PacketCapture            *packetcapture.Manager         // nil when packet captures are disabled
+	Paging                   *factory.PagingConfig          // nil pages the registration area on every attempt
+	BackupAmfName            string
+	BackupAmfUri             string
+	DrainTimeout             time.Duration
+	drainMu                  sync.Mutex
+	draining                 bool
+	drainDone                chan struct{}
+	uePoolSnapshot           atomic.Pointer[uePoolSnapshot] // SUPIs the pages of the UE queries are read from
+
+	// RACS (TS 23.501 5.4.4.1a); the dictionary is nil when RACS is not configured
+	RacsSupportedByRan          bool
+	UeRadioCapabilityDictionary *UeRadioCapabilityDictionary
+	Ucmf                        UcmfClient
 }
 
 type AMFContextEventSubscription struct {
@@ -116,7 +139,7 @@ type SecurityAlgorithm struct {
 func (context *AMFContext) TmsiAllocate() int32 {
 	var val int32
 	var err error
-	if context.EnableDbStore {
+	if context.Drsm != nil {
 		val, err = context.Drsm.AllocateInt32ID()
 	} else {
 		var tmp int64
@@ -134,7 +157,7 @@ func (context *AMFContext) TmsiAllocate() int32 {
 func (context *AMFContext) AllocateAmfUeNgapID() (int64, error) {
 	var val int64
 	var err error
-	if context.EnableDbStore {
+	if context.Drsm != nil {
 		var tmp int32
 		tmp, err = context.Drsm.AllocateInt32ID()
 		val = int64(tmp)
@@ -161,23 +184,41 @@ func (context *AMFContext) AllocateGutiToUe(ue *AmfUe) {
 }
 
 func (context *AMFContext) ReAllocateGutiToUe(ue *AmfUe) {
-	var err error
-	servedGuami := context.ServedGuamiList[0]
-	oldTmsi := ue.GetTmsi()
-	if context.EnableDbStore {
-		err = context.Drsm.ReleaseInt32ID(oldTmsi)
-	} else {
-		tmsiGenerator.FreeID(int64(oldTmsi))
-	}
-	if err != nil {
-		logger.ContextLog.Errorf("Error releasing tmsi: %v", err)
+	context.FreeOldGuti(ue)
+	context.freeTmsi(ue.GetTmsi())
+	context.AllocateGutiToUe(ue)
+}
+
+// ReAllocateGutiToUeKeepingOld assigns a new 5G-GUTI to the UE and keeps the old one
+// allocated to it until FreeOldGuti, as the UE goes on using the old 5G-GUTI until it
+// acknowledges the new one (TS 24.501 5.4.4.2)
+func (context *AMFContext) ReAllocateGutiToUeKeepingOld(ue *AmfUe) {
+	context.FreeOldGuti(ue)
+	ue.identityMu.Lock()
+	ue.OldGuti, ue.OldTmsi = ue.Guti, ue.Tmsi
+	ue.identityMu.Unlock()
+	context.AllocateGutiToUe(ue)
+}
+
+// FreeOldGuti frees the 5G-GUTI the UE had before ReAllocateGutiToUeKeepingOld, if any
+func (context *AMFContext) FreeOldGuti(ue *AmfUe) {
+	ue.identityMu.Lock()
+	oldGuti, oldTmsi := ue.OldGuti, ue.OldTmsi
+	ue.OldGuti, ue.OldTmsi = "", 0
+	ue.identityMu.Unlock()
+	if oldGuti != "" {
+		context.freeTmsi(oldTmsi)
 	}
-	tmsi := context.TmsiAllocate()
-	ue.SetTmsi(tmsi)
+}
 
-	plmnID := servedGuami.PlmnId.GetMcc() + servedGuami.PlmnId.GetMnc()
-	tmsiStr := fmt.Sprintf("%08x", tmsi)
-	ue.SetGuti(plmnID + servedGuami.AmfId + tmsiStr)
+func (context *AMFContext) freeTmsi(tmsi int32) {
+	if context.Drsm != nil {
+		if err := context.Drsm.ReleaseInt32ID(tmsi); err != nil {
+			logger.ContextLog.Errorf("Error releasing tmsi: %v", err)
+		}
+	} else {
+		tmsiGenerator.FreeID(int64(tmsi))
+	}
 }
 
 func (context *AMFContext) AllocateRegistrationArea(ue *AmfUe, anType models.AccessType) {
@@ -210,11 +251,11 @@ func (context *AMFContext) AllocateRegistrationArea(ue *AmfUe, anType models.Acc
 func (context *AMFContext) NewAMFStatusSubscription(subscriptionData models.SubscriptionDataAmf) (subscriptionID string) {
 	var id int32
 	var err error
-	if context.EnableDbStore {
+	if context.Drsm != nil {
 		id, err = context.Drsm.AllocateInt32ID()
 	} else {
 		var tmp int64
-		tmp, err = amfStatusSubscriptionIDGenerator.Allocate()
+		tmp, err = allocateAMFStatusSubscriptionID()
 		id = int32(tmp)
 	}
 	if err != nil {
@@ -224,9 +265,52 @@ func (context *AMFContext) NewAMFStatusSubscription(subscriptionData models.Subs
 
 	subscriptionID = strconv.Itoa(int(id))
 	context.AMFStatusSubscriptions.Store(subscriptionID, subscriptionData)
+	if context.EnableDbStore {
+		storeAMFStatusSubscriptionInDB(subscriptionID, subscriptionData)
+	}
 	return
 }
 
+// allocateAMFStatusSubscriptionID allocates a subscription ID not held by a restored
+// subscription; the reserved IDs skipped stay allocated until their subscription is deleted
+func allocateAMFStatusSubscriptionID() (int64, error) {
+	for {
+		id, err := amfStatusSubscriptionIDGenerator.Allocate()
+		if err != nil {
+			return 0, err
+		}
+		if _, reserved := reservedAMFStatusSubscriptionIDs.LoadAndDelete(id); !reserved {
+			return id, nil
+		}
+	}
+}
+
+func reserveAMFStatusSubscriptionID(id int64) {
+	reservedAMFStatusSubscriptionIDs.Store(id, struct{}{})
+}
+
+// restoreAMFStatusSubscription restores a subscription stored before a restart and reserves
+// its ID, so that new subscriptions do not reuse it
+func (context *AMFContext) restoreAMFStatusSubscription(subscriptionID string, subscriptionData models.SubscriptionDataAmf) {
+	context.AMFStatusSubscriptions.Store(subscriptionID, subscriptionData)
+	if context.Drsm != nil {
+		// the IDs allocated by DRSM are reserved for the AMF instance already
+		return
+	}
+	if id, err := strconv.ParseInt(subscriptionID, 10, 64); err != nil {
+		logger.ContextLog.Error(err)
+	} else {
+		reserveAMFStatusSubscriptionID(id)
+	}
+}
+
+func (context *AMFContext) UpdateAMFStatusSubscription(subscriptionID string, subscriptionData models.SubscriptionDataAmf) {
+	context.AMFStatusSubscriptions.Store(subscriptionID, subscriptionData)
+	if context.EnableDbStore {
+		storeAMFStatusSubscriptionInDB(subscriptionID, subscriptionData)
+	}
+}
+
 // Return Value: (subscriptionData *models.SubScriptionData, ok bool)
 func (context *AMFContext) FindAMFStatusSubscription(subscriptionID string) (*models.SubscriptionDataAmf, bool) {
 	if value, ok := context.AMFStatusSubscriptions.Load(subscriptionID); ok {
@@ -239,12 +323,16 @@ func (context *AMFContext) FindAMFStatusSubscription(subscriptionID string) (*mo
 
 func (context *AMFContext) DeleteAMFStatusSubscription(subscriptionID string) {
 	context.AMFStatusSubscriptions.Delete(subscriptionID)
+	if context.EnableDbStore {
+		deleteAMFStatusSubscriptionFromDB(subscriptionID)
+	}
 	if id, err := strconv.ParseInt(subscriptionID, 10, 64); err != nil {
 		logger.ContextLog.Error(err)
 	} else {
-		if context.EnableDbStore {
+		if context.Drsm != nil {
 			err = context.Drsm.ReleaseInt32ID(int32(id))
 		} else {
+			reservedAMFStatusSubscriptionIDs.Delete(id)
 			amfStatusSubscriptionIDGenerator.FreeID(id)
 		}
 		if err != nil {
@@ -372,6 +460,7 @@ func (context *AMFContext) NewAmfRan(conn net.Conn) *AmfRan {
 	ran := AmfRan{}
 	ran.SupportedTAList = NewSupportedTAIList()
 	ran.RanUeList = make(map[int64]*RanUe)
+	ran.ConnectedAt = time.Now()
 	ran.Conn = conn
 	ran.GnbIp = conn.RemoteAddr().String()
 	ran.Log = logger.NgapLog.With(logger.FieldRanAddr, conn.RemoteAddr().String())
@@ -391,6 +480,7 @@ func (context *AMFContext) NewAmfRanAddr(remoteAddr string) *AmfRan {
 	ran := AmfRan{}
 	ran.SupportedTAList = NewSupportedTAIList()
 	ran.RanUeList = make(map[int64]*RanUe)
+	ran.ConnectedAt = time.Now()
 	ran.GnbIp = remoteAddr
 	ran.Log = logger.NgapLog.With(logger.FieldRanAddr, remoteAddr)
 	context.AmfRanPool.Store(remoteAddr, &ran)
@@ -401,6 +491,7 @@ func (context *AMFContext) NewAmfRanId(GnbId string) *AmfRan {
 	ran := AmfRan{}
 	ran.SupportedTAList = NewSupportedTAIList()
 	ran.RanUeList = make(map[int64]*RanUe)
+	ran.ConnectedAt = time.Now()
 	ran.GnbId = GnbId
 	ran.Log = logger.NgapLog.With(logger.FieldRanId, GnbId)
 	context.AmfRanPool.Store(GnbId, &ran)
@@ -475,7 +566,8 @@ func mapToByte(data map[string]interface{}) (ret []byte) {
 func (context *AMFContext) AmfUeFindByGutiLocal(guti string) (ue *AmfUe, ok bool) {
 	context.UePool.Range(func(key, value interface{}) bool {
 		candidate := value.(*AmfUe)
-		if ok = (candidate.Guti == guti); ok {
+		// the old 5G-GUTI of a reallocation still identifies the UE, see ReAllocateGutiToUeKeepingOld
+		if ok = (candidate.GetGuti() == guti || candidate.GetOldGuti() == guti); ok {
 			ue = candidate
 			return false
 		}
@@ -528,6 +620,18 @@ func (context *AMFContext) AmfUeFindByPolicyAssociationID(polAssoId string) (ue
 	return
 }
 
+func (context *AMFContext) AmfUeFindByUePolicyAssociationID(polAssoId string) (ue *AmfUe, ok bool) {
+	context.UePool.Range(func(key, value interface{}) bool {
+		candidate := value.(*AmfUe)
+		if ok = (candidate.UePolicyAssociationId == polAssoId); ok {
+			ue = candidate
+			return false
+		}
+		return true
+	})
+	return
+}
+
 func (context *AMFContext) RanUeFindByAmfUeNgapIDLocal(amfUeNgapID int64) *RanUe {
 	if value, ok := context.RanUePool.Load(amfUeNgapID); ok {
 		return value.(*RanUe)
@@ -674,6 +778,31 @@ func UpdateAmfContext(amfContext *AMFContext, newConfig []nfConfigApi.AccessAndM
 	return nil
 }
 
+// DiffGuamiList returns the GUAMIs that became available and unavailable when the
+// served GUAMI list changed from oldList to newList.
+func DiffGuamiList(oldList, newList []models.Guami) (added, removed []models.Guami) {
+	for _, guami := range newList {
+		if !containsGuami(oldList, guami) {
+			added = append(added, guami)
+		}
+	}
+	for _, guami := range oldList {
+		if !containsGuami(newList, guami) {
+			removed = append(removed, guami)
+		}
+	}
+	return added, removed
+}
+
+func containsGuami(guamiList []models.Guami, guami models.Guami) bool {
+	for _, item := range guamiList {
+		if reflect.DeepEqual(item, guami) {
+			return true
+		}
+	}
+	return false
+}
+
 func ConvertAccessAndMobilityList(newConfig []nfConfigApi.AccessAndMobility) ([]models.Tai, []models.PlmnSnssai, []models.Guami) {
 	newSupportedTais := []models.Tai{}
 	var newPlmnSnssaiList []models.PlmnSnssai
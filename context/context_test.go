@@ -7,6 +7,7 @@
 package context
 
 import (
+	"math"
 	"reflect"
 	"sync"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/omec-project/openapi/v2"
 	"github.com/omec-project/openapi/v2/models"
 	"github.com/omec-project/openapi/v2/nfConfigApi"
+	"github.com/omec-project/util/idgenerator"
 )
 
 var (
@@ -279,3 +281,71 @@ func TestConvertAccessAndMobilityListDeduplicatesSnssaiByValue(t *testing.T) {
 		t.Fatalf("expected one deduplicated SNSSAI, got %+v", plmnSnssaiList[0].SNssaiList)
 	}
 }
+
+func TestDiffGuamiList(t *testing.T) {
+	guami1 := models.Guami{PlmnId: models.PlmnIdNid{Mcc: "001", Mnc: "01"}, AmfId: "cafe00"}
+	guami2 := models.Guami{PlmnId: models.PlmnIdNid{Mcc: "001", Mnc: "02"}, AmfId: "cafe00"}
+	guami3 := models.Guami{PlmnId: models.PlmnIdNid{Mcc: "001", Mnc: "03"}, AmfId: "cafe00"}
+
+	added, removed := DiffGuamiList([]models.Guami{guami1, guami2}, []models.Guami{guami2, guami3})
+	if !reflect.DeepEqual(added, []models.Guami{guami3}) {
+		t.Errorf("unexpected added GUAMIs: %+v", added)
+	}
+	if !reflect.DeepEqual(removed, []models.Guami{guami1}) {
+		t.Errorf("unexpected removed GUAMIs: %+v", removed)
+	}
+
+	added, removed = DiffGuamiList([]models.Guami{guami1}, []models.Guami{guami1})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("expected no change, got added %+v removed %+v", added, removed)
+	}
+}
+
+func TestRestoredAMFStatusSubscriptionIDIsReserved(t *testing.T) {
+	self := AMF_Self()
+	savedGenerator := amfStatusSubscriptionIDGenerator
+	amfStatusSubscriptionIDGenerator = idgenerator.NewGenerator(1, math.MaxInt32)
+	defer func() { amfStatusSubscriptionIDGenerator = savedGenerator }()
+
+	restored := models.SubscriptionDataAmf{AmfStatusUri: "http://nf-a/status"}
+	self.restoreAMFStatusSubscription("1", restored)
+	defer self.DeleteAMFStatusSubscription("1")
+
+	subscriptionID := self.NewAMFStatusSubscription(models.SubscriptionDataAmf{AmfStatusUri: "http://nf-b/status"})
+	defer self.DeleteAMFStatusSubscription(subscriptionID)
+	if subscriptionID == "" || subscriptionID == "1" {
+		t.Fatalf("new subscription got ID %q of the restored subscription", subscriptionID)
+	}
+	if data, ok := self.FindAMFStatusSubscription("1"); !ok || data.AmfStatusUri != restored.AmfStatusUri {
+		t.Errorf("restored subscription overwritten: %+v", data)
+	}
+}
+
+func TestReAllocateGutiToUeKeepingOld(t *testing.T) {
+	self := &AMFContext{ServedGuamiList: []models.Guami{{
+		PlmnId: models.PlmnIdNid{Mcc: "208", Mnc: "93"},
+		AmfId:  "cafe00",
+	}}}
+	savedGenerator := tmsiGenerator
+	tmsiGenerator = idgenerator.NewGenerator(1, math.MaxInt32)
+	defer func() { tmsiGenerator = savedGenerator }()
+
+	ue := &AmfUe{}
+	self.AllocateGutiToUe(ue)
+	oldGuti, oldTmsi := ue.GetGuti(), ue.GetTmsi()
+	self.ReAllocateGutiToUeKeepingOld(ue)
+	if ue.GetGuti() == oldGuti || ue.GetOldGuti() != oldGuti || ue.OldTmsi != oldTmsi {
+		t.Fatalf("expected the old 5G-GUTI %s to be kept, got %s and %s", oldGuti, ue.GetGuti(), ue.GetOldGuti())
+	}
+
+	// the old 5G-TMSI is not allocated again until it is freed
+	other := &AmfUe{}
+	self.AllocateGutiToUe(other)
+	if other.GetTmsi() == oldTmsi {
+		t.Fatal("expected the old 5G-TMSI to stay allocated")
+	}
+	self.FreeOldGuti(ue)
+	if ue.GetOldGuti() != "" || ue.OldTmsi != 0 {
+		t.Errorf("expected no old 5G-GUTI, got %s", ue.GetOldGuti())
+	}
+}
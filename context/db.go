@@ -7,9 +7,13 @@ package context
 
 import (
 	"bytes"
+	ctxt "context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/omec-project/amf/factory"
@@ -23,33 +27,60 @@ import (
 
 var dbMutex sync.Mutex
 
-const (
-	dbWriteWorkers   = 4
-	dbWriteQueueSize = 256
-)
-
-type dbWriteOp struct {
-	filter bson.M
-	data   bson.M
+// ueContextPersistence is the UE context store in use and the queue of pending writes
+type ueContextPersistence struct {
+	store    UeContextStore
+	writeCh  chan *UeContextRecord
+	owner    string
+	leaseTTL time.Duration
 }
 
-var (
-	dbWriteCh   = make(chan dbWriteOp, dbWriteQueueSize)
-	dbWriteOnce sync.Once
-)
+var ueContextPersist atomic.Pointer[ueContextPersistence]
 
-func startDBWriteWorkers() {
-	dbWriteOnce.Do(func() {
-		for range dbWriteWorkers {
-			go func() {
-				for op := range dbWriteCh {
-					if _, postErr := mongoapi.CommonDBClient.RestfulAPIPost(AmfUeDataColl, op.filter, op.data); postErr != nil {
-						logger.DataRepoLog.Warnln(postErr)
-					}
+// SetUeContextStore sends all AmfUe persistence to store. Writes are queued and applied by
+// the worker goroutines; stored contexts are leased to this instance for leaseTTL.
+func SetUeContextStore(store UeContextStore, workers, queueSize int, leaseTTL time.Duration) {
+	p := &ueContextPersistence{
+		store:    store,
+		writeCh:  make(chan *UeContextRecord, queueSize),
+		owner:    os.Getenv("HOSTNAME"),
+		leaseTTL: leaseTTL,
+	}
+	for range workers {
+		go func() {
+			for rec := range p.writeCh {
+				if err := p.store.Put(ctxt.Background(), rec); err != nil {
+					logger.DataRepoLog.Warnln(err)
 				}
-			}()
+			}
+		}()
+	}
+	ueContextPersist.Store(p)
+}
+
+// GetUeContextStore returns the UE context store, or nil before it is set up.
+func GetUeContextStore() UeContextStore {
+	if p := ueContextPersist.Load(); p != nil {
+		return p.store
+	}
+	return nil
+}
+
+// CloseUeContextStore releases the UE context store, e.g. the lock on the embedded database file.
+func CloseUeContextStore() {
+	if p := ueContextPersist.Load(); p != nil {
+		if err := p.store.Close(); err != nil {
+			logger.DataRepoLog.Warnln(err)
 		}
-	})
+	}
+}
+
+// dbWriteQueueLen returns the length and capacity of the UE context write queue
+func dbWriteQueueLen() (length, capacity int) {
+	if p := ueContextPersist.Load(); p != nil {
+		return len(p.writeCh), cap(p.writeCh)
+	}
+	return 0, 0
 }
 
 type CustomFieldsAmfUe struct {
@@ -61,13 +92,42 @@ type CustomFieldsAmfUe struct {
 	RanUeNgapId int64                        `json:"ranUeNgapId"`
 	AmfUeNgapId int64                        `json:"amfUeNgapId"`
 	RanId       string                       `json:"ranId"`
+	// map[subscriptionID]; sync.Map does not marshal
+	N1N2Subs map[string]models.UeN1N2InfoSubscriptionCreateData `json:"n1n2Subs,omitempty"`
+	// running retransmission timers, by timer name
+	Timers map[string]TimerState `json:"timers,omitempty"`
+	// flight recorder of the UE, if persisted
+	Flight []FlightRecord `json:"flightRecords,omitempty"`
+	// fields the OAM UE queries filter on, see UeQuery
+	Snssais      []string `json:"snssais,omitempty"`
+	Dnns         []string `json:"dnns,omitempty"`
+	LastActivity int64    `json:"lastActivity,omitempty"` // unix milliseconds
 }
 
 var (
-	Namespace     = os.Getenv("POD_NAMESPACE")
-	AmfUeDataColl = "amf.data.amfState"
+	Namespace                 = os.Getenv("POD_NAMESPACE")
+	AmfUeDataColl             = "amf.data.amfState"
+	AmfStatusSubscriptionColl = "amf.data.amfStatusSubscriptions"
 )
 
+// amfStatusSubscriptionDoc is the DB document of an AMF status change subscription; the
+// collection is shared by the AMF instances, each restores only its own subscriptions
+type amfStatusSubscriptionDoc struct {
+	AmfInstanceId    string                     `json:"amfInstanceId"`
+	SubscriptionId   string                     `json:"subscriptionId"`
+	SubscriptionData models.SubscriptionDataAmf `json:"subscriptionData"`
+}
+
+// amfStatusSubscriptionFilter selects the subscriptions of this AMF instance, or one of them
+// if subscriptionID is not empty
+func amfStatusSubscriptionFilter(subscriptionID string) bson.M {
+	filter := bson.M{"amfInstanceId": AMF_Self().NfId}
+	if subscriptionID != "" {
+		filter["subscriptionId"] = subscriptionID
+	}
+	return filter
+}
+
 func AllocateUniqueID(generator **idgenerator.IDGenerator, idName string) (int64, error) {
 	// Use MongoDB increment field to generate new offset.
 	// generate ids between offset to 8192 above offset.
@@ -95,6 +155,27 @@ func AllocateUniqueID(generator **idgenerator.IDGenerator, idName string) (int64
 	return val, nil
 }
 
+// SetupUeContextStore opens the UE context store selected by cfg and starts its write workers.
+func SetupUeContextStore(cfg *factory.UeContextStoreConfig) error {
+	var store UeContextStore
+	switch cfg.Backend {
+	case factory.UeContextStoreMemory:
+		store = NewMemoryUeContextStore()
+	case factory.UeContextStoreEmbedded:
+		var err error
+		if store, err = NewBoltUeContextStore(cfg.Path); err != nil {
+			return fmt.Errorf("open UE context store %s: %w", cfg.Path, err)
+		}
+	default:
+		AmfUeDataColl = cfg.Collection
+		SetupAmfCollection()
+		store = NewMongoUeContextStore(mongoapi.CommonDBClient, AmfUeDataColl)
+	}
+	SetUeContextStore(store, cfg.WriteWorkers, cfg.WriteQueueSize, cfg.LeaseTtl)
+	logger.DataRepoLog.Infof("UE context store backend: %s", cfg.Backend)
+	return nil
+}
+
 func SetupAmfCollection() {
 	mongoDbUrl := "mongodb://mongodb:27017"
 	if factory.AmfConfig.Configuration.AmfDBName == "" {
@@ -110,6 +191,7 @@ func SetupAmfCollection() {
 
 	if Namespace != "" {
 		AmfUeDataColl = Namespace + "." + AmfUeDataColl
+		AmfStatusSubscriptionColl = Namespace + "." + AmfStatusSubscriptionColl
 	}
 	for {
 		mongoapi.ConnectMongo(mongoDbUrl, factory.AmfConfig.Configuration.AmfDBName)
@@ -135,6 +217,10 @@ func SetupAmfCollection() {
 		logger.DataRepoLog.Errorln("create index failed on Tmsi field")
 	}
 
+	if err = createMongoUeQueryIndexes(mongoapi.CommonDBClient, AmfUeDataColl); err != nil {
+		logger.DataRepoLog.Errorf("create UE query indexes failed: %v", err)
+	}
+
 	/*_, err = CommonDBClient.CreateIndex(AmfUeDataColl, "customFieldsAmfUe.amfUeNgapId")
 	if err != nil {
 		logger.DataRepoLog.Errorf("Create index failed on AmfUeNgapID field.")
@@ -146,7 +232,6 @@ func SetupAmfCollection() {
 	if err != nil {
 		logger.DataRepoLog.Errorf("Create index failed on RanUeNgapID field.")
 	}*/
-	startDBWriteWorkers()
 }
 
 // amfJSONBufInitialCap is the initial capacity for pooled JSON encoding buffers
@@ -158,93 +243,110 @@ var amfJSONBufPool = sync.Pool{
 	New: func() any { return bytes.NewBuffer(make([]byte, 0, amfJSONBufInitialCap)) },
 }
 
-func ToBsonM(data *AmfUe) (ret bson.M) {
-	buf := amfJSONBufPool.Get().(*bytes.Buffer)
-	buf.Reset()
-	enc := sonic.ConfigDefault.NewEncoder(buf)
-	if err := enc.Encode(data); err != nil {
-		amfJSONBufPool.Put(buf)
-		logger.DataRepoLog.Errorf("amfue marshal error: %v", err)
-		return
-	}
-	if err := sonic.Unmarshal(buf.Bytes(), &ret); err != nil {
-		logger.DataRepoLog.Errorf("amfue unmarshal error: %v", err)
-	}
-	amfJSONBufPool.Put(buf)
-	return
-}
-
 func StoreContextInDB(ue *AmfUe) {
 	self := AMF_Self()
-	if !self.EnableDbStore {
+	p := ueContextPersist.Load()
+	if !self.EnableDbStore || p == nil {
 		return
 	}
 	// Serialize synchronously (snapshot before next EventChannel message can modify ue).
-	amfUeBsonA := ToBsonM(ue)
-	if amfUeBsonA == nil {
+	rec, err := NewUeContextRecord(ue)
+	if err != nil {
+		logger.DataRepoLog.Errorf("amfue marshal error: %v", err)
+		return
+	}
+	if rec.Supi == "" {
 		return
 	}
-	filter := bson.M{"supi": ue.GetSupi()}
+	// a draining AMF keeps the contexts it stores released to the backup AMF, see HandOverContextInDB
+	if !self.IsDraining() {
+		rec.Owner, rec.LeaseExpiry = p.owner, time.Now().Add(p.leaseTTL)
+	}
 	select {
-	case dbWriteCh <- dbWriteOp{filter: filter, data: amfUeBsonA}:
+	case p.writeCh <- rec:
 	default:
 		metrics.IncrementDbWriteDropped()
 		logger.DataRepoLog.Warnf("DB write queue full, dropping store for supi=%s", ue.GetSupi())
 	}
 }
 
+// HandOverContextInDB stores ue with its ownership released, so that the AMF instance taking
+// over the UE, e.g. the backup AMF of a planned removal, restores it from the shared store
+// and leases it at its first lookup. It returns once the context is stored.
+func HandOverContextInDB(ctx ctxt.Context, ue *AmfUe) error {
+	p := ueContextPersist.Load()
+	if !AMF_Self().EnableDbStore || p == nil {
+		return ErrUeContextStoreDisabled
+	}
+	rec, err := NewUeContextRecord(ue)
+	if err != nil {
+		return err
+	}
+	return p.store.Put(ctx, rec)
+}
+
 func DeleteContextFromDB(ue *AmfUe) {
 	self := AMF_Self()
-	if self.EnableDbStore {
-		filter := bson.M{"supi": ue.GetSupi()}
-
-		delErr := mongoapi.CommonDBClient.RestfulAPIDeleteOne(AmfUeDataColl, filter)
-		if delErr != nil {
+	if p := ueContextPersist.Load(); self.EnableDbStore && p != nil {
+		if delErr := p.store.Delete(ctxt.Background(), ue.GetSupi()); delErr != nil {
 			logger.DataRepoLog.Warnln(delErr)
 		}
 	}
 }
 
-func DbFetch(collName string, filter bson.M) *AmfUe {
-	ue := &AmfUe{}
-	ue.init()
-	result, getOneErr := mongoapi.CommonDBClient.RestfulAPIGetOne(collName, filter)
-	if getOneErr != nil {
-		logger.DataRepoLog.Warnln(getOneErr)
+func DbFetch(key UeContextKey) *AmfUe {
+	p := ueContextPersist.Load()
+	if p == nil {
+		return nil
 	}
-
-	if len(result) == 0 {
+	rec, getErr := p.store.Get(ctxt.Background(), key)
+	if getErr != nil {
+		if !errors.Is(getErr, ErrUeContextNotFound) {
+			logger.DataRepoLog.Warnln(getErr)
+		}
 		return nil
 	}
-	err := sonic.Unmarshal(mapToByte(result), ue)
+	ue := &AmfUe{}
+	ue.init()
+	err := sonic.Unmarshal(rec.Data, ue)
 	if err != nil {
 		logger.DataRepoLog.Errorf("amfue unmarshal error: %v", err)
 		return nil
 	}
 
+	// the retransmission timers are only run by the instance holding the lease; another
+	// instance still owning the UE context keeps running them
+	leased, leaseErr := p.store.LeaseOwnership(ctxt.Background(), ue.Supi, p.owner, p.leaseTTL)
+	if leaseErr != nil {
+		logger.DataRepoLog.Warnln(leaseErr)
+	}
+
 	dbMutex.Lock()
 	defer dbMutex.Unlock()
 
-	ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].AmfUe = ue
-	AMF_Self().RanUePool.Store(ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].AmfUeNgapId, ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS])
+	// a UE in CM-IDLE has no UE-associated NG connection to restore
+	var amfUeNgapID int64
+	if ranUe := ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS]; ranUe != nil {
+		ranUe.AmfUe = ue
+		amfUeNgapID = ranUe.AmfUeNgapId
+		AMF_Self().RanUePool.Store(amfUeNgapID, ranUe)
+	}
 	AMF_Self().UePool.Store(ue.Supi, ue)
 	ue.EventChannel = nil
-	ue.NASLog = logger.NasLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].AmfUeNgapId))
-	ue.GmmLog = logger.GmmLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].AmfUeNgapId))
-	ue.TxLog = logger.GmmLog.With(logger.FieldAmfUeNgapID, fmt.Sprintf("AMF_UE_NGAP_ID:%d", ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS].AmfUeNgapId))
-	ue.ProducerLog = logger.ProducerLog.With(logger.FieldSupi, fmt.Sprintf("SUPI:%s", ue.Supi))
+	ue.UpdateLoggers()
 	ue.AmfInstanceName = os.Getenv("HOSTNAME")
 	ue.AmfInstanceIp = os.Getenv("POD_IP")
 	ue.TxLog.Debugln("amfue fetched")
+	if leased {
+		ue.RearmTimers()
+	} else {
+		ue.TxLog.Infof("UE context leased by %s, retransmission timers not re-armed", rec.Owner)
+	}
 	return ue
 }
 
 func DbFetchRanUeByRanUeNgapID(ranUeNgapID int64, ran *AmfRan) *RanUe {
-	filter := bson.M{}
-	filter["customFieldsAmfUe.ranUeNgapId"] = ranUeNgapID
-	filter["customFieldsAmfUe.ranId"] = ran.GnbId
-
-	ue := DbFetch(AmfUeDataColl, filter)
+	ue := DbFetch(UeContextKey{RanId: ran.GnbId, RanUeNgapId: ranUeNgapID})
 	if ue == nil {
 		logger.DataRepoLog.Debugln("DbFetchRanUeByRanUeNgapID: no document found for ranUeNgapID", ranUeNgapID)
 		return nil
@@ -264,9 +366,7 @@ func DbFetchRanUeByRanUeNgapID(ranUeNgapID int64, ran *AmfRan) *RanUe {
 
 func DbFetchRanUeByAmfUeNgapID(amfUeNgapID int64) *RanUe {
 	self := AMF_Self()
-	filter := bson.M{}
-	filter["customFieldsAmfUe.amfUeNgapId"] = amfUeNgapID
-	ue := DbFetch(AmfUeDataColl, filter)
+	ue := DbFetch(UeContextKey{AmfUeNgapId: amfUeNgapID})
 	if ue == nil {
 		logger.DataRepoLog.Errorln("DbFetchRanUeByAmfUeNgapID: no document found for amfUeNgapID ", amfUeNgapID)
 		return nil
@@ -286,10 +386,7 @@ func DbFetchRanUeByAmfUeNgapID(amfUeNgapID int64) *RanUe {
 
 func DbFetchUeByGuti(guti string) (ue *AmfUe, ok bool) {
 	self := AMF_Self()
-	filter := bson.M{}
-	filter["guti"] = guti
-
-	ue = DbFetch(AmfUeDataColl, filter)
+	ue = DbFetch(UeContextKey{Guti: guti})
 	if ue == nil {
 		logger.DataRepoLog.Warnln("FindByGuti: no document found for guti", guti)
 		return nil, false
@@ -311,10 +408,7 @@ func DbFetchUeByGuti(guti string) (ue *AmfUe, ok bool) {
 
 func DbFetchUeBySupi(supi string) (ue *AmfUe, ok bool) {
 	self := AMF_Self()
-	filter := bson.M{}
-	filter["supi"] = supi
-
-	ue = DbFetch(AmfUeDataColl, filter)
+	ue = DbFetch(UeContextKey{Supi: supi})
 	if ue == nil {
 		logger.DataRepoLog.Warnln("FindBySupi: no document found for supi", supi)
 		return nil, false
@@ -334,17 +428,19 @@ func DbFetchUeBySupi(supi string) (ue *AmfUe, ok bool) {
 }
 
 func DbFetchAllEntries() (ueList []*AmfUe) {
-	ue := &AmfUe{}
-	filter := bson.M{}
-	results, getManyErr := mongoapi.CommonDBClient.RestfulAPIGetMany(AmfUeDataColl, filter)
-	if getManyErr != nil {
-		logger.DataRepoLog.Warnln(getManyErr)
+	store := GetUeContextStore()
+	if store == nil {
+		return nil
+	}
+	records, listErr := store.ListBySupi(ctxt.Background(), "")
+	if listErr != nil {
+		logger.DataRepoLog.Warnln(listErr)
 	}
 
-	for _, val := range results {
-		ue = &AmfUe{}
+	for _, rec := range records {
+		ue := &AmfUe{}
 		ue.init()
-		err := sonic.Unmarshal(mapToByte(val), ue)
+		err := sonic.Unmarshal(rec.Data, ue)
 		if err != nil {
 			logger.DataRepoLog.Errorf("amfue unmarshal error: %v", err)
 			return nil
@@ -354,3 +450,64 @@ func DbFetchAllEntries() (ueList []*AmfUe) {
 
 	return ueList
 }
+
+// AMF status subscriptions are only persisted when the UE context store is MongoDB
+
+func storeAMFStatusSubscriptionInDB(subscriptionID string, subscriptionData models.SubscriptionDataAmf) {
+	if mongoapi.CommonDBClient == nil {
+		return
+	}
+	doc := amfStatusSubscriptionDoc{
+		AmfInstanceId:    AMF_Self().NfId,
+		SubscriptionId:   subscriptionID,
+		SubscriptionData: subscriptionData,
+	}
+	var data bson.M
+	buf, err := sonic.Marshal(doc)
+	if err == nil {
+		err = sonic.Unmarshal(buf, &data)
+	}
+	if err != nil {
+		logger.DataRepoLog.Errorf("AMF status subscription marshal error: %v", err)
+		return
+	}
+	filter := amfStatusSubscriptionFilter(subscriptionID)
+	if _, err = mongoapi.CommonDBClient.RestfulAPIPutOne(AmfStatusSubscriptionColl, filter, data); err != nil {
+		logger.DataRepoLog.Warnln(err)
+	}
+}
+
+func deleteAMFStatusSubscriptionFromDB(subscriptionID string) {
+	if mongoapi.CommonDBClient == nil {
+		return
+	}
+	filter := amfStatusSubscriptionFilter(subscriptionID)
+	if err := mongoapi.CommonDBClient.RestfulAPIDeleteOne(AmfStatusSubscriptionColl, filter); err != nil {
+		logger.DataRepoLog.Warnln(err)
+	}
+}
+
+// LoadAMFStatusSubscriptionsFromDB restores the AMF status change subscriptions this AMF
+// instance stored before a restart and returns how many were restored.
+func LoadAMFStatusSubscriptionsFromDB() int {
+	if mongoapi.CommonDBClient == nil {
+		return 0
+	}
+	results, err := mongoapi.CommonDBClient.RestfulAPIGetMany(AmfStatusSubscriptionColl, amfStatusSubscriptionFilter(""))
+	if err != nil {
+		logger.DataRepoLog.Warnln(err)
+		return 0
+	}
+	restored := 0
+	for _, result := range results {
+		doc := amfStatusSubscriptionDoc{}
+		if err = sonic.Unmarshal(mapToByte(result), &doc); err != nil {
+			logger.DataRepoLog.Errorf("AMF status subscription unmarshal error: %v", err)
+			continue
+		}
+		AMF_Self().restoreAMFStatusSubscription(doc.SubscriptionId, doc.SubscriptionData)
+		restored++
+	}
+	logger.DataRepoLog.Infof("restored %d AMF status subscriptions", restored)
+	return restored
+}
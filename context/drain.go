@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+// StartDrain puts the AMF in drain mode for planned removal (TS 23.501 5.21.2.2).
+// It returns false if a drain is already in progress or finished.
+func (context *AMFContext) StartDrain() bool {
+	context.drainMu.Lock()
+	defer context.drainMu.Unlock()
+	if context.draining {
+		return false
+	}
+	context.draining = true
+	if context.drainDone == nil {
+		context.drainDone = make(chan struct{})
+	}
+	return true
+}
+
+// IsDraining reports whether the AMF stopped accepting new UEs because of planned removal.
+func (context *AMFContext) IsDraining() bool {
+	context.drainMu.Lock()
+	defer context.drainMu.Unlock()
+	return context.draining
+}
+
+// FinishDrain signals that all UE contexts were handed over or released.
+func (context *AMFContext) FinishDrain() {
+	context.drainMu.Lock()
+	defer context.drainMu.Unlock()
+	if context.drainDone == nil {
+		context.drainDone = make(chan struct{})
+	}
+	select {
+	case <-context.drainDone:
+	default:
+		close(context.drainDone)
+	}
+}
+
+// DrainDone returns a channel that is closed once the drain procedure completes.
+func (context *AMFContext) DrainDone() <-chan struct{} {
+	context.drainMu.Lock()
+	defer context.drainMu.Unlock()
+	if context.drainDone == nil {
+		context.drainDone = make(chan struct{})
+	}
+	return context.drainDone
+}
+
+// RanUeCount returns the number of UE-associated NG connections still held by the AMF.
+func (context *AMFContext) RanUeCount() int {
+	count := 0
+	context.RanUePool.Range(func(key, value any) bool {
+		count++
+		return true
+	})
+	return count
+}
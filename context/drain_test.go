@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+)
+
+func TestDrainLifecycle(t *testing.T) {
+	amfContext := &AMFContext{}
+
+	done := amfContext.DrainDone()
+	if amfContext.IsDraining() {
+		t.Fatalf("expected AMF not to be draining initially")
+	}
+	if !amfContext.StartDrain() {
+		t.Fatalf("expected first StartDrain to succeed")
+	}
+	if amfContext.StartDrain() {
+		t.Errorf("expected second StartDrain to report a drain in progress")
+	}
+	if !amfContext.IsDraining() {
+		t.Errorf("expected AMF to be draining")
+	}
+
+	select {
+	case <-done:
+		t.Fatalf("expected drain channel to be open before FinishDrain")
+	default:
+	}
+
+	amfContext.FinishDrain()
+	amfContext.FinishDrain()
+	select {
+	case <-done:
+	default:
+		t.Errorf("expected drain channel to be closed after FinishDrain")
+	}
+}
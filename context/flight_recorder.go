@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/omec-project/amf/tracerecording"
+)
+
+// FlightRecordKind is what a flight record of a UE is about
+type FlightRecordKind string
+
+const (
+	FlightRecordNgap  FlightRecordKind = "ngap"
+	FlightRecordNas   FlightRecordKind = "nas"
+	FlightRecordGmm   FlightRecordKind = "gmm"
+	FlightRecordTimer FlightRecordKind = "timer"
+	FlightRecordSbi   FlightRecordKind = "sbi"
+)
+
+// directions of the NGAP, NAS and SBI flight records, seen from the AMF
+const (
+	FlightRecordUplink   = "uplink"
+	FlightRecordDownlink = "downlink"
+	FlightRecordOutgoing = "outgoing"
+	FlightRecordIncoming = "incoming"
+)
+
+// FlightRecord is an event in the procedure timeline of a UE: an NGAP or NAS message, a
+// GMM state transition, a timer event or an SBI call.
+type FlightRecord struct {
+	Time      time.Time        `json:"time"`
+	Kind      FlightRecordKind `json:"kind"`
+	Direction string           `json:"direction,omitempty"`
+	// message type, GMM event, timer or SBI service operation
+	Name string `json:"name"`
+	// GMM state transition, timer event or SBI response status
+	Result string `json:"result,omitempty"`
+}
+
+// RecordFlight adds record to the flight recorder of the UE, a ring buffer that keeps the
+// latest records up to the configured size. It does nothing if the flight recorder is
+// disabled.
+func (ue *AmfUe) RecordFlight(record FlightRecord) {
+	cfg := AMF_Self().FlightRecorder
+	if cfg == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+
+	ue.flightMu.Lock()
+	defer ue.flightMu.Unlock()
+	ue.appendFlightRecord(record, cfg.Size)
+}
+
+// appendFlightRecord must be called with flightMu held
+func (ue *AmfUe) appendFlightRecord(record FlightRecord, size int) {
+	if len(ue.flightRecords) < size {
+		ue.flightRecords = append(ue.flightRecords, record)
+		return
+	}
+	if size <= 0 {
+		return
+	}
+	// the buffer is full, overwrite the oldest record
+	ue.flightRecords[ue.flightNext] = record
+	ue.flightNext = (ue.flightNext + 1) % len(ue.flightRecords)
+}
+
+// FlightRecords returns the records of the flight recorder of the UE, oldest first.
+func (ue *AmfUe) FlightRecords() []FlightRecord {
+	ue.flightMu.Lock()
+	defer ue.flightMu.Unlock()
+
+	records := make([]FlightRecord, 0, len(ue.flightRecords))
+	records = append(records, ue.flightRecords[ue.flightNext:]...)
+	return append(records, ue.flightRecords[:ue.flightNext]...)
+}
+
+// restoreFlightRecords refills the flight recorder with the records stored with the UE
+// context in the DB.
+func (ue *AmfUe) restoreFlightRecords(records []FlightRecord) {
+	cfg := AMF_Self().FlightRecorder
+	if cfg == nil {
+		return
+	}
+
+	ue.flightMu.Lock()
+	defer ue.flightMu.Unlock()
+	ue.flightRecords, ue.flightNext = nil, 0
+	for _, record := range records {
+		ue.appendFlightRecord(record, cfg.Size)
+	}
+}
+
+// persistedFlightRecords returns the flight records stored with the UE context in the DB,
+// nil unless persistence is enabled.
+func (ue *AmfUe) persistedFlightRecords() []FlightRecord {
+	if cfg := AMF_Self().FlightRecorder; cfg == nil || !cfg.Persist {
+		return nil
+	}
+	return ue.FlightRecords()
+}
+
+// WriteFlightRecords writes records as JSON lines, one record per line.
+func WriteFlightRecords(w io.Writer, records []FlightRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flightRecordDirection(direction tracerecording.Direction) string {
+	switch direction {
+	case tracerecording.Uplink:
+		return FlightRecordUplink
+	case tracerecording.Downlink:
+		return FlightRecordDownlink
+	}
+	return ""
+}
+
+type flightRecorderUeKey struct{}
+
+// WithFlightRecorderUe annotates ctx with the UE whose flight recorder gets the SBI calls
+// made with ctx.
+func WithFlightRecorderUe(ctx ctxt.Context, ue *AmfUe) ctxt.Context {
+	if ctx == nil || ue == nil {
+		return ctx
+	}
+	return ctxt.WithValue(ctx, flightRecorderUeKey{}, ue)
+}
+
+// FlightRecorderUe returns the UE ctx was annotated with by WithFlightRecorderUe, if any.
+func FlightRecorderUe(ctx ctxt.Context) (*AmfUe, bool) {
+	ue, ok := ctx.Value(flightRecorderUeKey{}).(*AmfUe)
+	return ue, ok
+}
+
+// events of the UE timers in the flight records
+const (
+	FlightRecordTimerStarted = "started"
+	FlightRecordTimerExpired = "expired"
+	FlightRecordTimerAborted = "aborted"
+)
+
+// RecordTimerFlight adds an event of a UE timer such as TimerT3560 to the flight recorder.
+func (ue *AmfUe) RecordTimerFlight(timer, event string) {
+	ue.RecordFlight(FlightRecord{Kind: FlightRecordTimer, Name: timer, Result: event})
+}
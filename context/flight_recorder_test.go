@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"bytes"
+	ctxt "context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/amf/tracerecording"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	self := AMF_Self()
+	origFlightRecorder := self.FlightRecorder
+	t.Cleanup(func() { self.FlightRecorder = origFlightRecorder })
+
+	ue := &AmfUe{}
+	self.FlightRecorder = nil
+	ue.RecordTimerFlight(TimerT3560, FlightRecordTimerStarted)
+	if len(ue.FlightRecords()) != 0 {
+		t.Fatal("expected nothing to be recorded with the flight recorder disabled")
+	}
+
+	self.FlightRecorder = &factory.FlightRecorderConfig{Enabled: true, Size: 3}
+	ue.RecordNgapTrace(tracerecording.Uplink, []byte{0x00, 0x0f, 0x40})
+	for _, timer := range []string{TimerT3560, TimerT3550, TimerT3513} {
+		ue.RecordTimerFlight(timer, FlightRecordTimerStarted)
+	}
+	records := ue.FlightRecords()
+	if len(records) != 3 {
+		t.Fatalf("expected the ring buffer to keep 3 records, got %d", len(records))
+	}
+	for i, timer := range []string{TimerT3560, TimerT3550, TimerT3513} {
+		if records[i].Kind != FlightRecordTimer || records[i].Name != timer {
+			t.Errorf("record %d: expected timer %s, got %+v", i, timer, records[i])
+		}
+	}
+
+	var jsonLines bytes.Buffer
+	if err := WriteFlightRecords(&jsonLines, records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(jsonLines.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a JSON line per record, got:\n%s", jsonLines.String())
+	}
+	var record FlightRecord
+	if err := json.Unmarshal([]byte(lines[2]), &record); err != nil || record.Name != TimerT3513 {
+		t.Errorf("unexpected last JSON line %s: %v", lines[2], err)
+	}
+
+	restored := &AmfUe{}
+	restored.restoreFlightRecords(append(records, FlightRecord{Kind: FlightRecordSbi, Name: "Nudm_SDM_Get"}))
+	if records = restored.FlightRecords(); len(records) != 3 || records[2].Kind != FlightRecordSbi {
+		t.Errorf("unexpected restored records %+v", records)
+	}
+	if restored.persistedFlightRecords() != nil {
+		t.Error("expected the flight records not to be persisted unless configured")
+	}
+}
+
+func TestFlightRecorderUe(t *testing.T) {
+	ue := &AmfUe{}
+	if _, ok := FlightRecorderUe(ctxt.Background()); ok {
+		t.Fatal("expected no UE in a plain context")
+	}
+	if found, ok := FlightRecorderUe(WithFlightRecorderUe(ctxt.Background(), ue)); !ok || found != ue {
+		t.Error("expected the annotated UE")
+	}
+}
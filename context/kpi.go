@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/omec-project/amf/metrics"
+	"github.com/omec-project/nas/v2/nasConvert"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// KpiProcedure is a procedure whose attempts, successes, failures and latency are exported
+// as AMF KPIs (TS 28.552 5.1.1)
+type KpiProcedure string
+
+const (
+	KpiInitialRegistration   KpiProcedure = "initial_registration"
+	KpiMobilityRegistration  KpiProcedure = "mobility_registration"
+	KpiPeriodicRegistration  KpiProcedure = "periodic_registration"
+	KpiEmergencyRegistration KpiProcedure = "emergency_registration"
+	KpiServiceRequest        KpiProcedure = "service_request"
+	KpiN2Handover            KpiProcedure = "n2_handover"
+	KpiXnHandover            KpiProcedure = "xn_handover"
+	KpiPaging                KpiProcedure = "paging"
+	KpiAuthentication        KpiProcedure = "authentication"
+	KpiSecurityModeControl   KpiProcedure = "security_mode_control"
+)
+
+// failure cause types of the procedure KPIs
+const (
+	KpiCauseType5GMM = "5gmm"
+	KpiCauseTypeNgap = "ngap"
+	// failures without a cause value, e.g. a timer expiry
+	KpiCauseTypeAmf = "amf"
+)
+
+// kpiProcedureRun is a running procedure and the labels its KPIs are reported with
+type kpiProcedureRun struct {
+	start  time.Time
+	plmn   string
+	tac    string
+	snssai string
+}
+
+// KpiRegistrationProcedure returns the KPI procedure of a 5GS registration type.
+func KpiRegistrationProcedure(registrationType5GS uint8) KpiProcedure {
+	switch registrationType5GS {
+	case nasMessage.RegistrationType5GSMobilityRegistrationUpdating:
+		return KpiMobilityRegistration
+	case nasMessage.RegistrationType5GSPeriodicRegistrationUpdating:
+		return KpiPeriodicRegistration
+	case nasMessage.RegistrationType5GSEmergencyRegistration:
+		return KpiEmergencyRegistration
+	}
+	return KpiInitialRegistration
+}
+
+// Kpi5GMMCause formats a 5GMM cause (TS 24.501 9.11.3.2) as failure cause of a procedure KPI.
+func Kpi5GMMCause(cause5GMM uint8) string {
+	return strconv.Itoa(int(cause5GMM))
+}
+
+// KpiNgapCause formats an NGAP cause (TS 38.413 9.3.1.2) as failure cause of a procedure KPI.
+func KpiNgapCause(cause *ngapType.Cause) string {
+	if cause == nil {
+		return "unknown"
+	}
+	switch cause.Present {
+	case ngapType.CausePresentRadioNetwork:
+		if cause.RadioNetwork != nil {
+			return "radio_network:" + strconv.Itoa(int(cause.RadioNetwork.Value))
+		}
+	case ngapType.CausePresentTransport:
+		if cause.Transport != nil {
+			return "transport:" + strconv.Itoa(int(cause.Transport.Value))
+		}
+	case ngapType.CausePresentNas:
+		if cause.Nas != nil {
+			return "nas:" + strconv.Itoa(int(cause.Nas.Value))
+		}
+	case ngapType.CausePresentProtocol:
+		if cause.Protocol != nil {
+			return "protocol:" + strconv.Itoa(int(cause.Protocol.Value))
+		}
+	case ngapType.CausePresentMisc:
+		if cause.Misc != nil {
+			return "misc:" + strconv.Itoa(int(cause.Misc.Value))
+		}
+	}
+	return "unknown"
+}
+
+// StartProcedure counts an attempt of procedure, labelled with the current PLMN, TAC and
+// S-NSSAI of the UE, and starts measuring its latency. Before the UE has an allowed NSSAI,
+// e.g. at initial registration, the first S-NSSAI it requested is used. Starting a running procedure again,
+// e.g. for a retransmitted request, counts a new attempt and restarts the measurement.
+func (ue *AmfUe) StartProcedure(procedure KpiProcedure) {
+	run := kpiProcedureRun{
+		start:  time.Now(),
+		plmn:   ue.Tai.PlmnId.Mcc + ue.Tai.PlmnId.Mnc,
+		tac:    ue.Tai.Tac,
+		snssai: ue.kpiSnssaiLabel(),
+	}
+
+	ue.kpiMu.Lock()
+	if ue.kpiProcedures == nil {
+		ue.kpiProcedures = make(map[KpiProcedure]kpiProcedureRun)
+	}
+	ue.kpiProcedures[procedure] = run
+	ue.kpiMu.Unlock()
+
+	metrics.IncrementProcedureAttempts(string(procedure), run.plmn, run.tac, run.snssai)
+
+	// authentication and security mode control run within a registration or service request,
+	// and keep its procedure ID
+	if procedure != KpiAuthentication && procedure != KpiSecurityModeControl {
+		ue.setProcedureId(procedure)
+	}
+}
+
+// CompleteProcedure counts a success of a procedure started with StartProcedure and records
+// its latency. It does nothing if procedure is not running.
+func (ue *AmfUe) CompleteProcedure(procedure KpiProcedure) {
+	run, ok := ue.stopProcedure(procedure)
+	if !ok {
+		return
+	}
+	metrics.ObserveProcedureSuccess(string(procedure), run.plmn, run.tac, run.snssai, time.Since(run.start))
+	if procedure == KpiPaging {
+		ue.EndOamProcedure(OamProcedurePaging, OamOutcomeCompleted, "")
+	}
+}
+
+// FailProcedure counts a failure of a procedure started with StartProcedure with its cause.
+// It does nothing if procedure is not running.
+func (ue *AmfUe) FailProcedure(procedure KpiProcedure, causeType, cause string) {
+	run, ok := ue.stopProcedure(procedure)
+	if !ok {
+		return
+	}
+	metrics.IncrementProcedureFailures(string(procedure), causeType, cause, run.plmn, run.tac, run.snssai)
+	ue.failOamProcedures(procedure, causeType+":"+cause)
+}
+
+func (ue *AmfUe) stopProcedure(procedure KpiProcedure) (kpiProcedureRun, bool) {
+	ue.kpiMu.Lock()
+	defer ue.kpiMu.Unlock()
+
+	run, ok := ue.kpiProcedures[procedure]
+	delete(ue.kpiProcedures, procedure)
+	return run, ok
+}
+
+func (ue *AmfUe) kpiSnssaiLabel() string {
+	if allowedNssai := ue.AllowedNssai[models.ACCESSTYPE__3_GPP_ACCESS]; len(allowedNssai) > 0 {
+		return kpiSnssai(allowedNssai[0].AllowedSnssai)
+	}
+	if ue.RegistrationRequest == nil || ue.RegistrationRequest.RequestedNSSAI == nil {
+		return ""
+	}
+	requestedNssai, err := nasConvert.RequestedNssaiToModels(ue.RegistrationRequest.RequestedNSSAI)
+	if err != nil || len(requestedNssai) == 0 {
+		return ""
+	}
+	return kpiSnssai(requestedNssai[0].ServingSnssai)
+}
+
+func kpiSnssai(snssai models.Snssai) string {
+	if snssai.Sd == nil || *snssai.Sd == "" {
+		return strconv.Itoa(int(snssai.Sst))
+	}
+	return strconv.Itoa(int(snssai.Sst)) + "-" + *snssai.Sd
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/nas/v2/nasType"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestKpiRegistrationProcedure(t *testing.T) {
+	tests := map[uint8]KpiProcedure{
+		nasMessage.RegistrationType5GSInitialRegistration:          KpiInitialRegistration,
+		nasMessage.RegistrationType5GSMobilityRegistrationUpdating: KpiMobilityRegistration,
+		nasMessage.RegistrationType5GSPeriodicRegistrationUpdating: KpiPeriodicRegistration,
+		nasMessage.RegistrationType5GSEmergencyRegistration:        KpiEmergencyRegistration,
+		nasMessage.RegistrationType5GSReserved:                     KpiInitialRegistration,
+	}
+	for registrationType, expected := range tests {
+		if procedure := KpiRegistrationProcedure(registrationType); procedure != expected {
+			t.Errorf("registration type %d: expected %s, got %s", registrationType, expected, procedure)
+		}
+	}
+}
+
+func TestKpiNgapCause(t *testing.T) {
+	cause := &ngapType.Cause{
+		Present:      ngapType.CausePresentRadioNetwork,
+		RadioNetwork: &ngapType.CauseRadioNetwork{Value: ngapType.CauseRadioNetworkPresentHoTargetNotAllowed},
+	}
+	if got := KpiNgapCause(cause); got != "radio_network:8" {
+		t.Errorf("expected radio_network:8, got %s", got)
+	}
+	if got := KpiNgapCause(nil); got != "unknown" {
+		t.Errorf("expected unknown for a missing cause, got %s", got)
+	}
+}
+
+func TestProcedureBookkeeping(t *testing.T) {
+	sd := "010203"
+	ue := &AmfUe{
+		Tai: models.Tai{PlmnId: models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"},
+		AllowedNssai: map[models.AccessType][]models.AllowedSnssai{
+			models.ACCESSTYPE__3_GPP_ACCESS: {{AllowedSnssai: models.Snssai{Sst: 1, Sd: &sd}}},
+		},
+	}
+
+	ue.StartProcedure(KpiServiceRequest)
+	run, ok := ue.kpiProcedures[KpiServiceRequest]
+	if !ok {
+		t.Fatal("expected the service request to be running")
+	}
+	if run.plmn != "20893" || run.tac != "000001" || run.snssai != "1-010203" {
+		t.Errorf("unexpected KPI labels %+v", run)
+	}
+
+	ue.CompleteProcedure(KpiServiceRequest)
+	if _, ok = ue.kpiProcedures[KpiServiceRequest]; ok {
+		t.Error("expected a completed procedure to be stopped")
+	}
+	if _, ok = ue.stopProcedure(KpiServiceRequest); ok {
+		t.Error("expected a procedure to be completed only once")
+	}
+
+	ue.StartProcedure(KpiPaging)
+	ue.FailProcedure(KpiPaging, KpiCauseTypeAmf, "t3513_expiry")
+	if _, ok = ue.kpiProcedures[KpiPaging]; ok {
+		t.Error("expected a failed procedure to be stopped")
+	}
+}
+
+func TestStartProcedureRequestedNssaiLabel(t *testing.T) {
+	// S-NSSAI with SST 1 and SD 010203 (TS 24.501 9.11.2.8)
+	snssaiValue := []uint8{0x04, 0x01, 0x01, 0x02, 0x03}
+	requestedNssai := nasType.NewRequestedNSSAI(nasMessage.RegistrationRequestRequestedNSSAIType)
+	requestedNssai.SetLen(uint8(len(snssaiValue)))
+	requestedNssai.SetSNSSAIValue(snssaiValue)
+	ue := &AmfUe{
+		RegistrationRequest: &nasMessage.RegistrationRequest{RequestedNSSAI: requestedNssai},
+	}
+
+	ue.StartProcedure(KpiInitialRegistration)
+	if run := ue.kpiProcedures[KpiInitialRegistration]; run.snssai != "1-010203" {
+		t.Errorf("expected the requested S-NSSAI as label before an allowed NSSAI exists, got %q", run.snssai)
+	}
+}
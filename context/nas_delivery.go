@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+const (
+	// MaxPendingDlNas bounds the downlink NAS PDUs kept per RanUe for NAS non-delivery
+	MaxPendingDlNas = 8
+	// PendingDlNasLifetime is how long a downlink NAS PDU is kept after it was sent
+	PendingDlNasLifetime = 30 * time.Second
+)
+
+// DlNasOrigin identifies the NF service consumer a downlink NAS PDU was transferred for with
+// Namf_Communication N1N2MessageTransfer, so that it can be told when the PDU is not delivered
+type DlNasOrigin struct {
+	N1MessageClass models.N1MessageClass
+	PduSessionId   int32
+	// n1n2FailureTxfNotifURI of the N1N2 message transfer request
+	FailureNotifyUri string
+	N1n2MsgDataUri   string
+}
+
+// PendingDlNas is a downlink NAS PDU sent in a DOWNLINK NAS TRANSPORT that the NG-RAN may still
+// report as not delivered (TS 38.413 8.6.3). Origin is nil for NAS messages of the AMF.
+type PendingDlNas struct {
+	NasPdu []byte
+	Origin *DlNasOrigin
+	SentAt time.Time
+}
+
+// BufferDlNas keeps a downlink NAS PDU sent to the UE. The oldest PDUs are dropped once
+// MaxPendingDlNas are buffered or PendingDlNasLifetime passed.
+func (ranUe *RanUe) BufferDlNas(nasPdu []byte, origin *DlNasOrigin) {
+	ranUe.dlNasMu.Lock()
+	defer ranUe.dlNasMu.Unlock()
+
+	now := time.Now()
+	pending := ranUe.pendingDlNas[:0]
+	for _, dlNas := range ranUe.pendingDlNas {
+		if now.Sub(dlNas.SentAt) < PendingDlNasLifetime {
+			pending = append(pending, dlNas)
+		}
+	}
+	if len(pending) == MaxPendingDlNas {
+		pending = pending[1:]
+	}
+	ranUe.pendingDlNas = append(pending, PendingDlNas{NasPdu: nasPdu, Origin: origin, SentAt: now})
+}
+
+// TakeDlNas removes and returns the buffered downlink NAS PDU equal to nasPdu.
+func (ranUe *RanUe) TakeDlNas(nasPdu []byte) (PendingDlNas, bool) {
+	ranUe.dlNasMu.Lock()
+	defer ranUe.dlNasMu.Unlock()
+
+	for i, dlNas := range ranUe.pendingDlNas {
+		if bytes.Equal(dlNas.NasPdu, nasPdu) {
+			ranUe.pendingDlNas = append(ranUe.pendingDlNas[:i], ranUe.pendingDlNas[i+1:]...)
+			return dlNas, true
+		}
+	}
+	return PendingDlNas{}, false
+}
+
+// DeferDlNas queues a downlink NAS PDU that was not delivered because of a handover, to be
+// retransmitted once the handover or path switch completed (TS 23.502 4.9.1.2.1).
+func (ranUe *RanUe) DeferDlNas(dlNas PendingDlNas) {
+	ranUe.dlNasMu.Lock()
+	defer ranUe.dlNasMu.Unlock()
+
+	ranUe.deferredDlNas = append(ranUe.deferredDlNas, dlNas)
+}
+
+// TakeDeferredDlNas removes and returns the downlink NAS PDUs queued with DeferDlNas.
+func (ranUe *RanUe) TakeDeferredDlNas() []PendingDlNas {
+	ranUe.dlNasMu.Lock()
+	defer ranUe.dlNasMu.Unlock()
+
+	deferred := ranUe.deferredDlNas
+	ranUe.deferredDlNas = nil
+	return deferred
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBufferDlNas(t *testing.T) {
+	ranUe := &RanUe{}
+	for i := range MaxPendingDlNas + 1 {
+		ranUe.BufferDlNas([]byte{0x7e, byte(i)}, nil)
+	}
+	if len(ranUe.pendingDlNas) != MaxPendingDlNas {
+		t.Fatalf("expected %d pending NAS PDUs, got %d", MaxPendingDlNas, len(ranUe.pendingDlNas))
+	}
+	if _, ok := ranUe.TakeDlNas([]byte{0x7e, 0}); ok {
+		t.Error("expected the oldest NAS PDU to be dropped")
+	}
+
+	origin := &DlNasOrigin{PduSessionId: 5}
+	ranUe.BufferDlNas([]byte{0x7e, 0xff}, origin)
+	dlNas, ok := ranUe.TakeDlNas([]byte{0x7e, 0xff})
+	if !ok || dlNas.Origin != origin {
+		t.Fatalf("expected pending NAS PDU with origin, got %+v %t", dlNas, ok)
+	}
+	if _, ok = ranUe.TakeDlNas([]byte{0x7e, 0xff}); ok {
+		t.Error("expected a taken NAS PDU to be removed")
+	}
+
+	// the buffer now holds 2..8, the oldest one expires
+	ranUe.pendingDlNas[0].SentAt = time.Now().Add(-PendingDlNasLifetime)
+	ranUe.BufferDlNas([]byte{0x7e, 0xfe}, nil)
+	if _, ok = ranUe.TakeDlNas([]byte{0x7e, 2}); ok {
+		t.Error("expected an expired NAS PDU to be dropped")
+	}
+}
+
+func TestDeferDlNas(t *testing.T) {
+	ranUe := &RanUe{}
+	ranUe.DeferDlNas(PendingDlNas{NasPdu: []byte{0x7e, 1}})
+	ranUe.DeferDlNas(PendingDlNas{NasPdu: []byte{0x7e, 2}})
+	if deferred := ranUe.TakeDeferredDlNas(); len(deferred) != 2 || deferred[0].NasPdu[1] != 1 {
+		t.Fatalf("unexpected deferred NAS PDUs: %+v", deferred)
+	}
+	if deferred := ranUe.TakeDeferredDlNas(); len(deferred) != 0 {
+		t.Errorf("expected no deferred NAS PDUs, got %d", len(deferred))
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// NssaaSession is the state of the network slice-specific authentication and authorization
+// of one S-NSSAI of a UE (TS 23.502 4.2.9)
+type NssaaSession struct {
+	Snssai models.Snssai     `json:"snssai"`
+	Status models.AuthStatus `json:"status"`
+	// AuthCtxId identifies the authentication context at the NSSAAF; empty until the EAP
+	// identity response of the UE has been relayed.
+	AuthCtxId string `json:"authCtxId,omitempty"`
+	// EapId is the identifier of the last EAP packet exchanged with the UE
+	EapId uint8 `json:"eapId,omitempty"`
+}
+
+// PendingNssaaMessage is an NSSAA NAS message for one S-NSSAI of a UE in CM-IDLE
+type PendingNssaaMessage struct {
+	Snssai models.Snssai `json:"snssai"`
+	NasPdu []byte        `json:"nasPdu"`
+}
+
+func nssaaSessionKey(snssai models.Snssai) string {
+	return fmt.Sprintf("%02x%s", snssai.GetSst(), strings.ToLower(snssai.GetSd()))
+}
+
+func snssaiEqual(a, b models.Snssai) bool {
+	return a.GetSst() == b.GetSst() && strings.EqualFold(a.GetSd(), b.GetSd())
+}
+
+// RequiresNssaa reports whether the subscription requires NSSAA for snssai.
+func (ue *AmfUe) RequiresNssaa(snssai models.Snssai) bool {
+	return slices.ContainsFunc(ue.NssaiRequiringNssaa, func(s models.Snssai) bool {
+		return snssaiEqual(s, snssai)
+	})
+}
+
+// NssaaSession returns the NSSAA state of snssai, or nil if NSSAA was never started for it.
+func (ue *AmfUe) NssaaSession(snssai models.Snssai) *NssaaSession {
+	return ue.NssaaSessions[nssaaSessionKey(snssai)]
+}
+
+// StartNssaaSession (re)starts NSSAA for snssai; the previous result, if any, is discarded.
+func (ue *AmfUe) StartNssaaSession(snssai models.Snssai) *NssaaSession {
+	if ue.NssaaSessions == nil {
+		ue.NssaaSessions = make(map[string]*NssaaSession)
+	}
+	session := &NssaaSession{Snssai: snssai, Status: models.AUTHSTATUS_PENDING}
+	ue.NssaaSessions[nssaaSessionKey(snssai)] = session
+	return session
+}
+
+// InPendingNssai reports whether snssai is waiting for NSSAA to complete.
+func (ue *AmfUe) InPendingNssai(snssai models.Snssai) bool {
+	return slices.ContainsFunc(ue.PendingNssai, func(s models.Snssai) bool {
+		return snssaiEqual(s, snssai)
+	})
+}
+
+// RemovePendingSnssai removes snssai from the pending NSSAI.
+func (ue *AmfUe) RemovePendingSnssai(snssai models.Snssai) {
+	ue.PendingNssai = slices.DeleteFunc(ue.PendingNssai, func(s models.Snssai) bool {
+		return snssaiEqual(s, snssai)
+	})
+}
+
+// RemoveAllowedSnssai removes snssai from the allowed NSSAI of every access type.
+func (ue *AmfUe) RemoveAllowedSnssai(snssai models.Snssai) {
+	for anType, allowedNssai := range ue.AllowedNssai {
+		ue.AllowedNssai[anType] = slices.DeleteFunc(allowedNssai, func(s models.AllowedSnssai) bool {
+			return snssaiEqual(s.AllowedSnssai, snssai)
+		})
+	}
+}
+
+// NssaaFailedNssai returns the S-NSSAIs that are rejected because NSSAA failed or the
+// authorization was revoked.
+func (ue *AmfUe) NssaaFailedNssai() []models.Snssai {
+	var failed []models.Snssai
+	for _, session := range ue.NssaaSessions {
+		if session.Status == models.AUTHSTATUS_EAP_FAILURE {
+			failed = append(failed, session.Snssai)
+		}
+	}
+	slices.SortFunc(failed, func(a, b models.Snssai) int {
+		return strings.Compare(nssaaSessionKey(a), nssaaSessionKey(b))
+	})
+	return failed
+}
+
+// SelectPendingNssai moves the S-NSSAIs of the allowed NSSAI of anType that require NSSAA
+// to the pending NSSAI, unless NSSAA already succeeded for them (TS 23.502 4.2.2.2.2 step
+// 21). S-NSSAIs for which NSSAA failed are removed from the allowed NSSAI.
+func (ue *AmfUe) SelectPendingNssai(anType models.AccessType) {
+	ue.PendingNssai = nil
+	ue.AllowedNssai[anType] = slices.DeleteFunc(ue.AllowedNssai[anType], func(s models.AllowedSnssai) bool {
+		if !ue.RequiresNssaa(s.AllowedSnssai) {
+			return false
+		}
+		session := ue.NssaaSession(s.AllowedSnssai)
+		switch {
+		case session == nil || session.Status == models.AUTHSTATUS_PENDING:
+			ue.PendingNssai = append(ue.PendingNssai, s.AllowedSnssai)
+			return true
+		case session.Status == models.AUTHSTATUS_EAP_FAILURE:
+			return true
+		}
+		return false
+	})
+}
+
+// QueueNssaaMessage queues nasPdu, an NSSAA message for snssai, until the UE leaves CM-IDLE.
+// A message still queued for snssai is superseded by nasPdu and replaced in place. It
+// returns whether the queue was empty.
+func (ue *AmfUe) QueueNssaaMessage(snssai models.Snssai, nasPdu []byte) bool {
+	empty := len(ue.PendingNssaaMessages) == 0
+	message := PendingNssaaMessage{Snssai: snssai, NasPdu: nasPdu}
+	i := slices.IndexFunc(ue.PendingNssaaMessages, func(m PendingNssaaMessage) bool {
+		return snssaiEqual(m.Snssai, snssai)
+	})
+	if i < 0 {
+		ue.PendingNssaaMessages = append(ue.PendingNssaaMessages, message)
+	} else {
+		ue.PendingNssaaMessages[i] = message
+	}
+	return empty
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestSelectPendingNssai(t *testing.T) {
+	embb := models.Snssai{Sst: 1}
+	enterprise := models.Snssai{Sst: 1, Sd: openapi.PtrString("0000AA")}
+	authorized := models.Snssai{Sst: 1, Sd: openapi.PtrString("0000bb")}
+	failed := models.Snssai{Sst: 2, Sd: openapi.PtrString("0000cc")}
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+
+	ue := &AmfUe{
+		NssaiRequiringNssaa: []models.Snssai{enterprise, authorized, failed},
+		AllowedNssai: map[models.AccessType][]models.AllowedSnssai{anType: {
+			{AllowedSnssai: embb}, {AllowedSnssai: enterprise}, {AllowedSnssai: authorized}, {AllowedSnssai: failed},
+		}},
+	}
+	ue.StartNssaaSession(authorized).Status = models.AUTHSTATUS_EAP_SUCCESS
+	ue.StartNssaaSession(failed).Status = models.AUTHSTATUS_EAP_FAILURE
+
+	ue.SelectPendingNssai(anType)
+
+	if len(ue.PendingNssai) != 1 || !ue.InPendingNssai(models.Snssai{Sst: 1, Sd: openapi.PtrString("0000aa")}) {
+		t.Errorf("expected the enterprise S-NSSAI to be pending, got %+v", ue.PendingNssai)
+	}
+	if len(ue.AllowedNssai[anType]) != 2 || !ue.InAllowedNssai(embb, anType) || !ue.InAllowedNssai(authorized, anType) {
+		t.Errorf("expected eMBB and the authorized S-NSSAI to stay allowed, got %+v", ue.AllowedNssai[anType])
+	}
+	if rejected := ue.NssaaFailedNssai(); len(rejected) != 1 || rejected[0].GetSst() != 2 {
+		t.Errorf("expected the failed S-NSSAI to be rejected, got %+v", rejected)
+	}
+
+	ue.RemovePendingSnssai(enterprise)
+	if len(ue.PendingNssai) != 0 {
+		t.Errorf("expected no pending S-NSSAI, got %+v", ue.PendingNssai)
+	}
+
+	// a new registration authenticates the S-NSSAIs again
+	ue.ClearRegistrationData()
+	if ue.NssaaSession(failed) != nil || len(ue.NssaaFailedNssai()) != 0 {
+		t.Errorf("expected the NSSAA sessions to be cleared, got %+v", ue.NssaaSessions)
+	}
+}
+
+func TestQueueNssaaMessage(t *testing.T) {
+	first := models.Snssai{Sst: 1, Sd: openapi.PtrString("0000aa")}
+	second := models.Snssai{Sst: 1, Sd: openapi.PtrString("0000bb")}
+	ue := &AmfUe{}
+
+	if !ue.QueueNssaaMessage(first, []byte{0x01}) {
+		t.Error("expected the first message to find the queue empty")
+	}
+	if ue.QueueNssaaMessage(second, []byte{0x02}) {
+		t.Error("expected the second message to find the queue not empty")
+	}
+	ue.QueueNssaaMessage(models.Snssai{Sst: 1, Sd: openapi.PtrString("0000AA")}, []byte{0x03})
+
+	if len(ue.PendingNssaaMessages) != 2 {
+		t.Fatalf("expected one message per S-NSSAI, got %+v", ue.PendingNssaaMessages)
+	}
+	if got := ue.PendingNssaaMessages[0]; !snssaiEqual(got.Snssai, first) || got.NasPdu[0] != 0x03 {
+		t.Errorf("expected the newer message of the first S-NSSAI to keep its place, got %+v", got)
+	}
+	if got := ue.PendingNssaaMessages[1]; !snssaiEqual(got.Snssai, second) || got.NasPdu[0] != 0x02 {
+		t.Errorf("expected the message of the second S-NSSAI second, got %+v", got)
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"errors"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// OamProcedure is a network procedure run on a UE on request of OAM
+type OamProcedure string
+
+const (
+	// network initiated deregistration (TS 24.501 5.5.2.3)
+	OamProcedureDeregistration OamProcedure = "deregistration"
+	// primary re-authentication followed by a security mode control procedure (TS 33.501 6.1.3)
+	OamProcedureReauthentication OamProcedure = "reauthentication"
+	// 5G-GUTI reallocation with a Configuration Update Command (TS 24.501 5.4.4)
+	OamProcedureGutiReallocation OamProcedure = "gutiReallocation"
+	// paging of a UE in CM-IDLE (TS 23.502 4.2.3.3)
+	OamProcedurePaging OamProcedure = "paging"
+	// AN release of the UE-associated NG connection (TS 23.502 4.2.6)
+	OamProcedureUeContextRelease OamProcedure = "ueContextRelease"
+)
+
+// OamProcedureOutcome is how an OAM procedure ended
+type OamProcedureOutcome string
+
+const (
+	OamOutcomeCompleted OamProcedureOutcome = "completed"
+	OamOutcomeFailed    OamProcedureOutcome = "failed"
+	// the procedure did not end before the OAM request stopped waiting for it
+	OamOutcomeTimeout OamProcedureOutcome = "timeout"
+)
+
+// OamProcedureResult is the outcome of an OAM procedure and the state it left the UE in
+type OamProcedureResult struct {
+	Procedure OamProcedure        `json:"procedure"`
+	Supi      string              `json:"supi"`
+	Outcome   OamProcedureOutcome `json:"outcome"`
+	Detail    string              `json:"detail,omitempty"`
+	GmmState  string              `json:"gmmState,omitempty"`
+	CmState   models.CmState      `json:"cmState,omitempty"`
+	Guti      string              `json:"guti,omitempty"`
+}
+
+// oamProcedureRun is an OAM procedure awaiting its outcome
+type oamProcedureRun struct {
+	procedure OamProcedure
+	result    chan OamProcedureResult
+}
+
+var ErrOamProcedureRunning = errors.New("another OAM procedure is running on the UE")
+
+// oamProceduresFailedBy are the OAM procedures failed by the failure of a KPI procedure
+var oamProceduresFailedBy = map[KpiProcedure][]OamProcedure{
+	KpiAuthentication:      {OamProcedureReauthentication},
+	KpiSecurityModeControl: {OamProcedureReauthentication},
+	KpiPaging:              {OamProcedurePaging, OamProcedureGutiReallocation},
+}
+
+// StartOamProcedure makes procedure the OAM procedure of the UE awaiting its outcome, which
+// the returned channel receives once the procedure ends, see EndOamProcedure. A UE runs
+// one OAM procedure at a time.
+func (ue *AmfUe) StartOamProcedure(procedure OamProcedure) (<-chan OamProcedureResult, error) {
+	ue.oamMu.Lock()
+	defer ue.oamMu.Unlock()
+	if ue.oamProcedure != nil {
+		return nil, ErrOamProcedureRunning
+	}
+	ue.oamProcedure = &oamProcedureRun{procedure: procedure, result: make(chan OamProcedureResult, 1)}
+	return ue.oamProcedure.result, nil
+}
+
+// EndOamProcedure reports the outcome of procedure if it is the OAM procedure of the UE
+// awaiting its outcome, and does nothing otherwise.
+func (ue *AmfUe) EndOamProcedure(procedure OamProcedure, outcome OamProcedureOutcome, detail string) {
+	ue.endOamProcedure(&procedure, outcome, detail)
+}
+
+// CancelOamProcedure stops awaiting the outcome of procedure, e.g. after the OAM request
+// timed out.
+func (ue *AmfUe) CancelOamProcedure(procedure OamProcedure) {
+	ue.oamMu.Lock()
+	defer ue.oamMu.Unlock()
+	if ue.oamProcedure != nil && ue.oamProcedure.procedure == procedure {
+		ue.oamProcedure = nil
+	}
+}
+
+// endOamProcedure reports the outcome of the OAM procedure of the UE, if it is procedure or
+// procedure is nil
+func (ue *AmfUe) endOamProcedure(procedure *OamProcedure, outcome OamProcedureOutcome, detail string) {
+	ue.oamMu.Lock()
+	defer ue.oamMu.Unlock()
+	run := ue.oamProcedure
+	if run == nil || (procedure != nil && run.procedure != *procedure) {
+		return
+	}
+	ue.oamProcedure = nil
+	run.result <- OamProcedureResult{
+		Procedure: run.procedure,
+		Supi:      ue.GetSupi(),
+		Outcome:   outcome,
+		Detail:    detail,
+	}
+}
+
+// failOamProcedures fails the OAM procedure of the UE if the failure of procedure fails it
+func (ue *AmfUe) failOamProcedures(procedure KpiProcedure, cause string) {
+	for _, oamProcedure := range oamProceduresFailedBy[procedure] {
+		ue.EndOamProcedure(oamProcedure, OamOutcomeFailed, string(procedure)+" failed, "+cause)
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOamProcedure(t *testing.T) {
+	ue := &AmfUe{Supi: "imsi-208930000000001"}
+
+	result, err := ue.StartOamProcedure(OamProcedureGutiReallocation)
+	if err != nil {
+		t.Fatalf("start OAM procedure: %v", err)
+	}
+	if _, err = ue.StartOamProcedure(OamProcedurePaging); !errors.Is(err, ErrOamProcedureRunning) {
+		t.Errorf("expected ErrOamProcedureRunning, got %v", err)
+	}
+
+	// the outcome of another procedure is ignored
+	ue.EndOamProcedure(OamProcedureUeContextRelease, OamOutcomeCompleted, "")
+	select {
+	case r := <-result:
+		t.Fatalf("unexpected outcome %+v", r)
+	default:
+	}
+
+	ue.EndOamProcedure(OamProcedureGutiReallocation, OamOutcomeCompleted, "")
+	if r := <-result; r.Procedure != OamProcedureGutiReallocation || r.Outcome != OamOutcomeCompleted ||
+		r.Supi != "imsi-208930000000001" {
+		t.Errorf("unexpected outcome %+v", r)
+	}
+
+	// a failed paging fails the procedure awaiting the paging response
+	if result, err = ue.StartOamProcedure(OamProcedurePaging); err != nil {
+		t.Fatalf("start OAM procedure: %v", err)
+	}
+	ue.StartProcedure(KpiPaging)
+	ue.FailProcedure(KpiPaging, KpiCauseTypeAmf, "t3513_expiry")
+	if r := <-result; r.Outcome != OamOutcomeFailed || !strings.Contains(r.Detail, "t3513_expiry") {
+		t.Errorf("unexpected outcome %+v", r)
+	}
+
+	if result, err = ue.StartOamProcedure(OamProcedureReauthentication); err != nil {
+		t.Fatalf("start OAM procedure: %v", err)
+	}
+	ue.CancelOamProcedure(OamProcedureReauthentication)
+	ue.EndOamProcedure(OamProcedureReauthentication, OamOutcomeCompleted, "")
+	select {
+	case r := <-result:
+		t.Fatalf("unexpected outcome of a cancelled procedure %+v", r)
+	default:
+	}
+	if _, err = ue.StartOamProcedure(OamProcedurePaging); err != nil {
+		t.Errorf("expected a new OAM procedure after cancel, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/metrics"
+)
+
+const (
+	OverloadIndicatorEventQueue = "event_queue"
+	OverloadIndicatorDbQueue    = "db_queue"
+	OverloadIndicatorSbiLatency = "sbi_latency_ms"
+
+	// weight given to the newest sample in the SBI latency moving average
+	sbiLatencyEwmaWeight = 0.2
+)
+
+// OverloadControl watches the AMF internal queues and SBI latency and decides when
+// the AMF enters or leaves overload (TS 23.501 5.19.5). While overloaded, NG-RAN
+// nodes are asked to reduce signalling with NGAP Overload Start and new
+// registrations are rejected with 5GMM cause #22 and a T3346 back-off.
+type OverloadControl struct {
+	cfg        factory.OverloadControlConfig
+	overloaded atomic.Bool
+	sbiLatency atomic.Int64 // moving average of SBI round-trip time, in nanoseconds
+	mu         sync.Mutex   // serializes Evaluate
+}
+
+var (
+	// eventQueuePeak is the fill, in hundredths of a percent, of the fullest UE EventChannel
+	// a message was submitted to since the load was last sampled
+	eventQueuePeak atomic.Int64
+	// blockedEventSubmits counts the submitters waiting on a full UE EventChannel
+	blockedEventSubmits atomic.Int64
+)
+
+// OverloadLoad is a snapshot of the indicators used by overload control.
+type OverloadLoad struct {
+	EventQueuePercent float64
+	DbQueuePercent    float64
+	SbiLatency        time.Duration
+}
+
+func NewOverloadControl(cfg factory.OverloadControlConfig) *OverloadControl {
+	return &OverloadControl{cfg: cfg}
+}
+
+// IsOverloaded is safe to call on a nil receiver, which means overload control is disabled.
+func (oc *OverloadControl) IsOverloaded() bool {
+	return oc != nil && oc.overloaded.Load()
+}
+
+// T3346Value returns the back-off timer, in seconds, sent with 5GMM cause #22.
+func (oc *OverloadControl) T3346Value() int {
+	if oc == nil {
+		return 0
+	}
+	return oc.cfg.T3346Value
+}
+
+// TrafficLoadReduction returns the percentage of signalling NG-RAN is asked to shed.
+func (oc *OverloadControl) TrafficLoadReduction() int64 {
+	if oc == nil {
+		return 0
+	}
+	return oc.cfg.TrafficLoadReduction
+}
+
+// ObserveSbiLatency feeds one SBI round-trip time into the moving average.
+func (oc *OverloadControl) ObserveSbiLatency(d time.Duration) {
+	if oc == nil {
+		return
+	}
+	for {
+		old := oc.sbiLatency.Load()
+		next := int64(d)
+		if old != 0 {
+			next = int64(sbiLatencyEwmaWeight*float64(d) + (1-sbiLatencyEwmaWeight)*float64(old))
+		}
+		if oc.sbiLatency.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// CurrentLoad samples the fullest UE EventChannel since the last sample, the DB write
+// queue and the SBI latency. A UE EventChannel a submitter is still blocked on counts as full.
+func (oc *OverloadControl) CurrentLoad() OverloadLoad {
+	load := OverloadLoad{
+		EventQueuePercent: float64(eventQueuePeak.Swap(0)) / 100,
+		DbQueuePercent:    queueFillPercent(dbWriteQueueLen()),
+	}
+	if blockedEventSubmits.Load() > 0 {
+		load.EventQueuePercent = 100
+	}
+	if oc != nil {
+		load.SbiLatency = time.Duration(oc.sbiLatency.Load())
+	}
+	return load
+}
+
+// observeEventQueueFill records the fill of a UE EventChannel holding length of its
+// capacity messages
+func observeEventQueueFill(length, capacity int) {
+	fill := int64(math.Min(queueFillPercent(length, capacity), 100) * 100)
+	for {
+		peak := eventQueuePeak.Load()
+		if fill <= peak || eventQueuePeak.CompareAndSwap(peak, fill) {
+			return
+		}
+	}
+}
+
+// Evaluate compares the current load against the configured thresholds. The AMF
+// enters overload when any indicator crosses its high watermark and leaves it only
+// once every indicator has dropped below RecoveryPercent of its watermark.
+// It returns true when the overload state changed.
+func (oc *OverloadControl) Evaluate(load OverloadLoad) bool {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	metrics.SetOverloadLoad(OverloadIndicatorEventQueue, load.EventQueuePercent)
+	metrics.SetOverloadLoad(OverloadIndicatorDbQueue, load.DbQueuePercent)
+	metrics.SetOverloadLoad(OverloadIndicatorSbiLatency, float64(load.SbiLatency.Milliseconds()))
+
+	eventHigh := float64(oc.cfg.EventQueueHighWatermark)
+	dbHigh := float64(oc.cfg.DbQueueHighWatermark)
+	sbiHigh := float64(oc.cfg.SbiLatencyThreshold)
+	recovery := float64(oc.cfg.RecoveryPercent) / 100
+
+	if !oc.overloaded.Load() {
+		if load.EventQueuePercent < eventHigh && load.DbQueuePercent < dbHigh &&
+			float64(load.SbiLatency) < sbiHigh {
+			return false
+		}
+		logger.ContextLog.Warnf("AMF overload detected: event queue %.0f%%, db queue %.0f%%, sbi latency %v",
+			load.EventQueuePercent, load.DbQueuePercent, load.SbiLatency)
+		oc.overloaded.Store(true)
+		metrics.SetOverloadActive(true)
+		return true
+	}
+
+	if load.EventQueuePercent >= eventHigh*recovery || load.DbQueuePercent >= dbHigh*recovery ||
+		float64(load.SbiLatency) >= sbiHigh*recovery {
+		return false
+	}
+	logger.ContextLog.Infof("AMF overload cleared: event queue %.0f%%, db queue %.0f%%, sbi latency %v",
+		load.EventQueuePercent, load.DbQueuePercent, load.SbiLatency)
+	oc.overloaded.Store(false)
+	metrics.SetOverloadActive(false)
+	return true
+}
+
+// Run samples the load every CheckInterval until ctx is cancelled, calling onStart
+// when the AMF enters overload and onStop when it recovers.
+func (oc *OverloadControl) Run(ctx context.Context, onStart, onStop func()) {
+	if oc == nil {
+		return
+	}
+	ticker := time.NewTicker(oc.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !oc.Evaluate(oc.CurrentLoad()) {
+				continue
+			}
+			if oc.IsOverloaded() {
+				onStart()
+			} else {
+				onStop()
+			}
+		}
+	}
+}
+
+func queueFillPercent(length, capacity int) float64 {
+	if capacity == 0 {
+		return 0
+	}
+	return float64(length) * 100 / float64(capacity)
+}
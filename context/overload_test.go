@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/omec-project/amf/factory"
+)
+
+func newTestOverloadControl() *OverloadControl {
+	return NewOverloadControl(factory.OverloadControlConfig{
+		Enabled:                 true,
+		CheckInterval:           time.Second,
+		EventQueueHighWatermark: 80,
+		DbQueueHighWatermark:    80,
+		SbiLatencyThreshold:     time.Second,
+		RecoveryPercent:         50,
+		TrafficLoadReduction:    40,
+		T3346Value:              120,
+	})
+}
+
+func TestOverloadControlEvaluate(t *testing.T) {
+	oc := newTestOverloadControl()
+
+	steps := []struct {
+		name       string
+		load       OverloadLoad
+		changed    bool
+		overloaded bool
+	}{
+		{"idle", OverloadLoad{}, false, false},
+		{"below watermark", OverloadLoad{EventQueuePercent: 79, DbQueuePercent: 10}, false, false},
+		{"db queue full", OverloadLoad{DbQueuePercent: 90}, true, true},
+		{"still above recovery level", OverloadLoad{DbQueuePercent: 45}, false, true},
+		{"sbi latency above recovery level", OverloadLoad{SbiLatency: 600 * time.Millisecond}, false, true},
+		{"recovered", OverloadLoad{DbQueuePercent: 10, SbiLatency: 100 * time.Millisecond}, true, false},
+		{"sbi latency too high", OverloadLoad{SbiLatency: 2 * time.Second}, true, true},
+	}
+
+	for _, step := range steps {
+		changed := oc.Evaluate(step.load)
+		if changed != step.changed {
+			t.Errorf("%s: expected changed=%v, got %v", step.name, step.changed, changed)
+		}
+		if oc.IsOverloaded() != step.overloaded {
+			t.Errorf("%s: expected overloaded=%v, got %v", step.name, step.overloaded, oc.IsOverloaded())
+		}
+	}
+}
+
+func TestOverloadControlSbiLatencyAverage(t *testing.T) {
+	oc := newTestOverloadControl()
+
+	oc.ObserveSbiLatency(100 * time.Millisecond)
+	if got := oc.CurrentLoad().SbiLatency; got != 100*time.Millisecond {
+		t.Errorf("expected first sample to seed the average, got %v", got)
+	}
+	oc.ObserveSbiLatency(600 * time.Millisecond)
+	if got := oc.CurrentLoad().SbiLatency; got != 200*time.Millisecond {
+		t.Errorf("expected moving average of 200ms, got %v", got)
+	}
+}
+
+func TestOverloadControlDisabled(t *testing.T) {
+	var oc *OverloadControl
+	oc.ObserveSbiLatency(time.Second)
+	if oc.IsOverloaded() || oc.T3346Value() != 0 || oc.TrafficLoadReduction() != 0 {
+		t.Errorf("expected nil overload control to report no overload")
+	}
+}
+
+func TestOverloadControlEventQueuePeak(t *testing.T) {
+	oc := newTestOverloadControl()
+	oc.CurrentLoad()
+
+	tx := &EventChannel{Message: make(chan any, 10)}
+	for range 8 {
+		tx.SubmitMessage(NasMsg{})
+	}
+	<-tx.Message
+	if got := oc.CurrentLoad().EventQueuePercent; got != 80 {
+		t.Errorf("expected the fullest event queue since the last sample at 80%%, got %v", got)
+	}
+	if got := oc.CurrentLoad().EventQueuePercent; got != 0 {
+		t.Errorf("expected no event queue fill without new messages, got %v", got)
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"github.com/omec-project/amf/tracerecording"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// CaptureNgap adds an NGAP PDU exchanged with ran to the packet captures of the gNB and, for
+// a UE-associated PDU, of the UE of ranUe. ranUe is nil for non UE-associated PDUs.
+func (ran *AmfRan) CaptureNgap(ranUe *RanUe, direction tracerecording.Direction, pdu []byte) {
+	packetCapture := AMF_Self().PacketCapture
+	if packetCapture == nil || ran == nil {
+		return
+	}
+	var supi string
+	if ranUe != nil && ranUe.AmfUe != nil {
+		supi = ranUe.AmfUe.Supi
+	}
+	packetCapture.CaptureNgap(ran.GnbId, supi, direction, pdu)
+}
+
+// capturePlainNas adds a plain NAS message of the UE to the packet captures that decrypt NAS.
+func (ue *AmfUe) capturePlainNas(direction tracerecording.Direction, plainNas []byte) {
+	packetCapture := AMF_Self().PacketCapture
+	if packetCapture == nil {
+		return
+	}
+	var gnbId string
+	if ranUe := ue.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS); ranUe != nil && ranUe.Ran != nil {
+		gnbId = ranUe.Ran.GnbId
+	}
+	packetCapture.CaptureNas(gnbId, ue.Supi, direction, plainNas)
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/amf/metrics"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// PagingState is the progress of the paging procedure of the UE, kept with the UE context so
+// that paging resumes at the same stage on another AMF instance
+type PagingState struct {
+	Stage        int    `json:"stage"`
+	Scope        string `json:"scope"`
+	HighPriority bool   `json:"highPriority,omitempty"`
+}
+
+// PriorityLabel is the priority of the paging procedure in paging metrics
+func (s *PagingState) PriorityLabel() string {
+	if s.HighPriority {
+		return "high"
+	}
+	return "normal"
+}
+
+// PagingStages returns the stages of a paging procedure. The broadcast strategy has a single
+// stage paging the registration area for all T3513 attempts.
+func (context *AMFContext) PagingStages(highPriority bool) []factory.PagingStageConfig {
+	cfg := context.Paging
+	if cfg == nil || cfg.Strategy != factory.PagingStrategyEscalating {
+		return []factory.PagingStageConfig{{
+			Scope:    factory.PagingScopeRegistrationArea,
+			Timeout:  context.T3513Cfg.ExpireTime,
+			Attempts: context.T3513Cfg.MaxRetryTimes + 1,
+		}}
+	}
+	stages := cfg.Stages
+	if highPriority {
+		stages = cfg.HighPriorityStages
+	}
+	stages = append([]factory.PagingStageConfig(nil), stages...)
+	for i := range stages {
+		if stages[i].Timeout == 0 {
+			stages[i].Timeout = context.T3513Cfg.ExpireTime
+		}
+	}
+	return stages
+}
+
+// PagingHighPriority reports whether the UE is paged with high priority, for a paging policy
+// indicator or an ARP of the N1N2MessageTransfer at or above the configured thresholds
+func (context *AMFContext) PagingHighPriority(ue *AmfUe) bool {
+	cfg := context.Paging
+	if cfg == nil {
+		return false
+	}
+	if onGoing := ue.OnGoing[models.ACCESSTYPE__3_GPP_ACCESS]; cfg.HighPriorityPpi > 0 && onGoing != nil &&
+		onGoing.Ppi > 0 && onGoing.Ppi <= cfg.HighPriorityPpi {
+		return true
+	}
+	if cfg.HighPriorityArpLevel > 0 && ue.N1N2Message != nil && ue.N1N2Message.Request.JsonData != nil {
+		if arp := ue.N1N2Message.Request.JsonData.Arp; arp != nil {
+			if level := arp.PriorityLevel.Get(); level != nil && *level <= cfg.HighPriorityArpLevel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PagingTargets returns the NG-RAN nodes that page the UE at a paging scope. The last known
+// RAN nodes are the gNBs of the recommended cells and the recommended RAN nodes for paging
+// reported at the last UE context release, and the gNB of the last known location.
+func (context *AMFContext) PagingTargets(ue *AmfUe, scope string) []*AmfRan {
+	var targets []*AmfRan
+	add := func(ran *AmfRan) {
+		for _, target := range targets {
+			if target == ran {
+				return
+			}
+		}
+		targets = append(targets, ran)
+	}
+
+	switch scope {
+	case factory.PagingScopeLastKnownRanNode:
+		if info := ue.InfoOnRecommendedCellsAndRanNodesForPaging; info != nil {
+			for _, cell := range info.RecommendedCells {
+				if cell.NgRanCGI.Present != NgRanCgiPresentNRCGI || cell.NgRanCGI.NRCGI == nil {
+					continue
+				}
+				context.AmfRanPool.Range(func(key, value any) bool {
+					ran := value.(*AmfRan)
+					if ran.RanPresent == RanPresentGNbId && gnbServesNrCell(ran.RanId.GNbId, cell.NgRanCGI.NRCGI.NrCellId) {
+						add(ran)
+						return false
+					}
+					return true
+				})
+			}
+			for _, ranNode := range info.RecommendedRanNodes {
+				switch {
+				case ranNode.GlobalRanNodeId != nil:
+					if ran, ok := context.amfRanFindByGlobalRanNodeId(*ranNode.GlobalRanNodeId); ok {
+						add(ran)
+					}
+				case ranNode.Tai != nil:
+					context.AmfRanPool.Range(func(key, value any) bool {
+						ran := value.(*AmfRan)
+						for _, item := range ran.SupportedTAListSnapshot() {
+							if InTaiList(item.Tai, []models.Tai{*ranNode.Tai}) {
+								add(ran)
+								break
+							}
+						}
+						return true
+					})
+				}
+			}
+		}
+		if nrLocation := ue.Location.NrLocation; nrLocation != nil {
+			if gnbId := nrLocation.GlobalGnbId.Get(); gnbId != nil {
+				if ran, ok := context.amfRanFindByGlobalRanNodeId(*gnbId); ok {
+					add(ran)
+				}
+			}
+		}
+	case factory.PagingScopeTai, factory.PagingScopeRegistrationArea:
+		taiList := ue.RegistrationArea[models.ACCESSTYPE__3_GPP_ACCESS]
+		if scope == factory.PagingScopeTai {
+			taiList = []models.Tai{ue.Tai}
+		}
+		context.AmfRanPool.Range(func(key, value any) bool {
+			ran := value.(*AmfRan)
+			for _, item := range ran.SupportedTAListSnapshot() {
+				if InTaiList(item.Tai, taiList) {
+					add(ran)
+					break
+				}
+			}
+			return true
+		})
+	}
+	return targets
+}
+
+// PagingTargetsOfStages returns the NG-RAN nodes that page the UE at any of the stages, for
+// paging all stages at once when T3513 is disabled and the paging cannot escalate
+func (context *AMFContext) PagingTargetsOfStages(ue *AmfUe, stages []factory.PagingStageConfig) []*AmfRan {
+	var targets []*AmfRan
+	for _, stage := range stages {
+		for _, ran := range context.PagingTargets(ue, stage.Scope) {
+			if !slices.Contains(targets, ran) {
+				targets = append(targets, ran)
+			}
+		}
+	}
+	return targets
+}
+
+// gnbServesNrCell reports whether the NR cell identity, 36 bits in hex, starts with the gNB ID
+// (TS 38.300 8.2)
+func gnbServesNrCell(gnbId *models.GNbId, nrCellId string) bool {
+	if gnbId == nil || gnbId.BitLength < 22 || gnbId.BitLength > 32 || len(nrCellId) != 9 {
+		return false
+	}
+	nci, err := strconv.ParseUint(nrCellId, 16, 64)
+	if err != nil {
+		return false
+	}
+	value, err := strconv.ParseUint(gnbId.GNBValue, 16, 64)
+	if err != nil || len(gnbId.GNBValue)*4 < int(gnbId.BitLength) {
+		return false
+	}
+	// the hex gNB ID is padded to whole digits at the end
+	value >>= uint(len(gnbId.GNBValue)*4) - uint(gnbId.BitLength)
+	return nci>>(36-uint(gnbId.BitLength)) == value
+}
+
+// amfRanFindByGlobalRanNodeId is AmfRanFindByRanID for IDs that may lack the gNB ID
+func (context *AMFContext) amfRanFindByGlobalRanNodeId(ranNodeId models.GlobalRanNodeId) (*AmfRan, bool) {
+	if ranNodeId.GNbId == nil {
+		return nil, false
+	}
+	return context.AmfRanFindByRanID(ranNodeId)
+}
+
+// StopPaging ends the paging procedure of the UE when the UE answers, counting the answer for
+// the paging stage the UE was reached at.
+func (ue *AmfUe) StopPaging() {
+	if ue.T3513 != nil {
+		ue.T3513.Stop()
+		ue.T3513 = nil // clear the timer
+	}
+	if state := ue.PagingState; state != nil {
+		metrics.IncrementPagingSuccess(state.Scope, state.PriorityLabel())
+		ue.PagingState = nil
+	}
+	ue.CompleteProcedure(KpiPaging)
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func newPagingTestRan(self *AMFContext, gnbValue string, tacs ...string) *AmfRan {
+	ran := &AmfRan{
+		Name:       gnbValue,
+		RanPresent: RanPresentGNbId,
+		RanId:      &models.GlobalRanNodeId{GNbId: &models.GNbId{BitLength: 24, GNBValue: gnbValue}},
+	}
+	for _, tac := range tacs {
+		ran.SupportedTAList = append(ran.SupportedTAList, SupportedTAI{Tai: models.Tai{Tac: tac}})
+	}
+	self.AmfRanPool.Store(gnbValue, ran)
+	return ran
+}
+
+func TestPagingStages(t *testing.T) {
+	self := &AMFContext{T3513Cfg: factory.TimerValue{Enable: true, ExpireTime: 6 * time.Second, MaxRetryTimes: 4}}
+	stages := self.PagingStages(false)
+	if len(stages) != 1 || stages[0].Scope != factory.PagingScopeRegistrationArea || stages[0].Attempts != 5 ||
+		stages[0].Timeout != 6*time.Second {
+		t.Fatalf("expected a single broadcast stage, got %+v", stages)
+	}
+
+	self.Paging = &factory.PagingConfig{
+		Strategy: factory.PagingStrategyEscalating,
+		Stages: []factory.PagingStageConfig{
+			{Scope: factory.PagingScopeLastKnownRanNode, Timeout: 2 * time.Second, Attempts: 1},
+			{Scope: factory.PagingScopeRegistrationArea, Attempts: 2},
+		},
+		HighPriorityStages: []factory.PagingStageConfig{{Scope: factory.PagingScopeRegistrationArea, Attempts: 1}},
+	}
+	stages = self.PagingStages(false)
+	if len(stages) != 2 || stages[0].Timeout != 2*time.Second || stages[1].Timeout != 6*time.Second {
+		t.Errorf("unexpected escalating stages %+v", stages)
+	}
+	if self.Paging.Stages[1].Timeout != 0 {
+		t.Error("expected the configured stages to be left unchanged")
+	}
+	if stages = self.PagingStages(true); len(stages) != 1 || stages[0].Scope != factory.PagingScopeRegistrationArea {
+		t.Errorf("unexpected high priority stages %+v", stages)
+	}
+}
+
+func TestPagingHighPriority(t *testing.T) {
+	self := &AMFContext{Paging: &factory.PagingConfig{HighPriorityPpi: 2, HighPriorityArpLevel: 3}}
+	ue := &AmfUe{OnGoing: map[models.AccessType]*OnGoingProcedureWithPrio{
+		models.ACCESSTYPE__3_GPP_ACCESS: {Procedure: OnGoingProcedurePaging, Ppi: 5},
+	}}
+	if self.PagingHighPriority(ue) {
+		t.Error("expected PPI 5 to be normal priority")
+	}
+	ue.OnGoing[models.ACCESSTYPE__3_GPP_ACCESS].Ppi = 2
+	if !self.PagingHighPriority(ue) {
+		t.Error("expected PPI 2 to be high priority")
+	}
+
+	ue.OnGoing[models.ACCESSTYPE__3_GPP_ACCESS].Ppi = 0
+	arp := models.Arp{PriorityLevel: *openapi.NewNullableInt32(openapi.PtrInt32(3))}
+	ue.N1N2Message = &N1N2Message{Request: models.N1N2MessageTransferRequest{
+		JsonData: &models.N1N2MessageTransferReqData{Arp: &arp},
+	}}
+	if !self.PagingHighPriority(ue) {
+		t.Error("expected ARP priority level 3 to be high priority")
+	}
+	if (&AMFContext{}).PagingHighPriority(ue) {
+		t.Error("expected no high priority paging without a paging configuration")
+	}
+}
+
+func TestPagingTargets(t *testing.T) {
+	self := &AMFContext{}
+	lastRan := newPagingTestRan(self, "000001", "000001")
+	newPagingTestRan(self, "000002", "000001", "000002")
+	newPagingTestRan(self, "000003", "000003")
+
+	ue := &AmfUe{
+		Tai: models.Tai{Tac: "000001"},
+		RegistrationArea: map[models.AccessType][]models.Tai{
+			models.ACCESSTYPE__3_GPP_ACCESS: {{Tac: "000001"}, {Tac: "000002"}},
+		},
+	}
+	nrLocation := models.NewNrLocationWithDefaults()
+	nrLocation.GlobalGnbId = *models.NewNullableGlobalRanNodeId(lastRan.RanId)
+	ue.Location.NrLocation = nrLocation
+
+	if targets := self.PagingTargets(ue, factory.PagingScopeLastKnownRanNode); len(targets) != 1 || targets[0] != lastRan {
+		t.Errorf("expected the last known gNB, got %d targets", len(targets))
+	}
+	if targets := self.PagingTargets(ue, factory.PagingScopeTai); len(targets) != 2 {
+		t.Errorf("expected 2 gNBs of the TAI, got %d", len(targets))
+	}
+	ue.RegistrationArea[models.ACCESSTYPE__3_GPP_ACCESS] = append(ue.RegistrationArea[models.ACCESSTYPE__3_GPP_ACCESS],
+		models.Tai{Tac: "000003"})
+	if targets := self.PagingTargets(ue, factory.PagingScopeRegistrationArea); len(targets) != 3 {
+		t.Errorf("expected 3 gNBs of the registration area, got %d", len(targets))
+	}
+
+	ue.Location.NrLocation = nil
+	if targets := self.PagingTargets(ue, factory.PagingScopeLastKnownRanNode); len(targets) != 0 {
+		t.Errorf("expected no last known gNB, got %d", len(targets))
+	}
+}
+
+func TestStopPaging(t *testing.T) {
+	ue := &AmfUe{PagingState: &PagingState{Scope: factory.PagingScopeTai}}
+	ue.T3513 = NewTimer(time.Hour, 0, func(int32) {}, func() {})
+	ue.StopPaging()
+	if ue.T3513 != nil || ue.PagingState != nil {
+		t.Errorf("expected paging to be stopped, got %+v", ue.PagingState)
+	}
+}
+
+func TestPagingTargetsRecommendedForPaging(t *testing.T) {
+	self := &AMFContext{}
+	cellRan := newPagingTestRan(self, "000001", "000001")
+	taiRan := newPagingTestRan(self, "000002", "000002")
+	newPagingTestRan(self, "000003", "000003")
+
+	ue := &AmfUe{InfoOnRecommendedCellsAndRanNodesForPaging: &InfoOnRecommendedCellsAndRanNodesForPaging{
+		RecommendedCells: []RecommendedCell{{NgRanCGI: NGRANCGI{
+			Present: NgRanCgiPresentNRCGI,
+			NRCGI:   &models.Ncgi{NrCellId: "000001010"},
+		}}},
+		RecommendedRanNodes: []RecommendRanNode{
+			{Present: RecommendRanNodePresentTAI, Tai: &models.Tai{Tac: "000002"}},
+			{Present: RecommendRanNodePresentRanNode, GlobalRanNodeId: cellRan.RanId},
+		},
+	}}
+	targets := self.PagingTargets(ue, factory.PagingScopeLastKnownRanNode)
+	if len(targets) != 2 || targets[0] != cellRan || targets[1] != taiRan {
+		t.Errorf("expected the gNBs of the recommended cell and TAI, got %d targets", len(targets))
+	}
+}
+
+func TestGnbServesNrCell(t *testing.T) {
+	tests := []struct {
+		gnbId    models.GNbId
+		nrCellId string
+		want     bool
+	}{
+		{models.GNbId{BitLength: 24, GNBValue: "000001"}, "000001010", true},
+		{models.GNbId{BitLength: 24, GNBValue: "000002"}, "000001010", false},
+		{models.GNbId{BitLength: 22, GNBValue: "000004"}, "000004000", true},
+		{models.GNbId{BitLength: 32, GNBValue: "00000101"}, "000001010", true},
+		{models.GNbId{BitLength: 24, GNBValue: "000001"}, "00001", false},
+	}
+	for _, tc := range tests {
+		if got := gnbServesNrCell(&tc.gnbId, tc.nrCellId); got != tc.want {
+			t.Errorf("gnbServesNrCell(%+v, %s) = %v, want %v", tc.gnbId, tc.nrCellId, got, tc.want)
+		}
+	}
+}
+
+func TestPagingTargetsOfStages(t *testing.T) {
+	self := &AMFContext{}
+	lastRan := newPagingTestRan(self, "000001", "000001")
+	newPagingTestRan(self, "000002", "000001")
+	newPagingTestRan(self, "000003", "000002")
+	newPagingTestRan(self, "000004", "000004")
+
+	ue := &AmfUe{
+		Tai: models.Tai{Tac: "000001"},
+		RegistrationArea: map[models.AccessType][]models.Tai{
+			models.ACCESSTYPE__3_GPP_ACCESS: {{Tac: "000001"}, {Tac: "000002"}},
+		},
+	}
+	nrLocation := models.NewNrLocationWithDefaults()
+	nrLocation.GlobalGnbId = *models.NewNullableGlobalRanNodeId(lastRan.RanId)
+	ue.Location.NrLocation = nrLocation
+
+	stages := []factory.PagingStageConfig{
+		{Scope: factory.PagingScopeLastKnownRanNode},
+		{Scope: factory.PagingScopeTai},
+		{Scope: factory.PagingScopeRegistrationArea},
+	}
+	targets := self.PagingTargetsOfStages(ue, stages)
+	if len(targets) != 3 || targets[0] != lastRan {
+		t.Errorf("expected the 3 gNBs of the registration area once each, got %d targets", len(targets))
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"github.com/omec-project/amf/metrics"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// CM states of registered UEs in the UE population gauges
+const (
+	CmStateConnected = "connected"
+	CmStateIdle      = "idle"
+)
+
+// registeredPopulation is the labels a registered UE is counted with per access type
+type registeredPopulation struct {
+	tac     string
+	snssai  string
+	cmState string
+}
+
+// sessionPopulation is the labels a PDU session is counted with
+type sessionPopulation struct {
+	snssai string
+	dnn    string
+}
+
+// SetRegisteredPopulation counts ue as registered on anType, or stops counting it, in the
+// registered and CM state UE gauges. The gauges are updated incrementally from the labels
+// the UE was counted with before.
+func (ue *AmfUe) SetRegisteredPopulation(anType models.AccessType, registered bool) {
+	ue.populationMu.Lock()
+	defer ue.populationMu.Unlock()
+
+	if ue.populationRegistered == nil {
+		ue.populationRegistered = make(map[models.AccessType]bool)
+	}
+	ue.populationRegistered[anType] = registered
+	ue.updateRegisteredPopulation(anType)
+}
+
+// updateCmPopulation moves ue between the CM-CONNECTED and CM-IDLE gauges of anType after
+// a RanUe was attached or detached.
+func (ue *AmfUe) updateCmPopulation(anType models.AccessType) {
+	ue.populationMu.Lock()
+	defer ue.populationMu.Unlock()
+
+	ue.updateRegisteredPopulation(anType)
+}
+
+// updateRegisteredPopulation must be called with populationMu held
+func (ue *AmfUe) updateRegisteredPopulation(anType models.AccessType) {
+	counted, wasCounted := ue.populationCounted[anType]
+	if !ue.populationRegistered[anType] {
+		if wasCounted {
+			metrics.AddRegisteredUes(string(anType), counted.tac, counted.snssai, counted.cmState, -1)
+			delete(ue.populationCounted, anType)
+		}
+		return
+	}
+
+	current := registeredPopulation{tac: ue.Tai.Tac, cmState: CmStateIdle}
+	if allowedNssai := ue.AllowedNssai[anType]; len(allowedNssai) > 0 {
+		current.snssai = kpiSnssai(allowedNssai[0].AllowedSnssai)
+	}
+	if ue.GetRanUe(anType) != nil {
+		current.cmState = CmStateConnected
+	}
+	if wasCounted && counted == current {
+		return
+	}
+	if wasCounted {
+		metrics.AddRegisteredUes(string(anType), counted.tac, counted.snssai, counted.cmState, -1)
+	}
+	metrics.AddRegisteredUes(string(anType), current.tac, current.snssai, current.cmState, 1)
+	if ue.populationCounted == nil {
+		ue.populationCounted = make(map[models.AccessType]registeredPopulation)
+	}
+	ue.populationCounted[anType] = current
+}
+
+// countSession counts a PDU session stored with StoreSmContext in the PDU session gauge,
+// replacing a previous session with the same PDU session ID.
+func (ue *AmfUe) countSession(pduSessionID int32, smContext *SmContext) {
+	ue.populationMu.Lock()
+	defer ue.populationMu.Unlock()
+
+	if counted, ok := ue.populationSessions[pduSessionID]; ok {
+		metrics.AddPduSessions(counted.snssai, counted.dnn, -1)
+	}
+	counted := sessionPopulation{snssai: kpiSnssai(smContext.Snssai()), dnn: smContext.Dnn()}
+	metrics.AddPduSessions(counted.snssai, counted.dnn, 1)
+	if ue.populationSessions == nil {
+		ue.populationSessions = make(map[int32]sessionPopulation)
+	}
+	ue.populationSessions[pduSessionID] = counted
+}
+
+func (ue *AmfUe) uncountSession(pduSessionID int32) {
+	ue.populationMu.Lock()
+	defer ue.populationMu.Unlock()
+
+	if counted, ok := ue.populationSessions[pduSessionID]; ok {
+		metrics.AddPduSessions(counted.snssai, counted.dnn, -1)
+		delete(ue.populationSessions, pduSessionID)
+	}
+}
+
+// releasePopulation stops counting ue and its PDU sessions when the UE context is removed.
+func (ue *AmfUe) releasePopulation() {
+	ue.populationMu.Lock()
+	defer ue.populationMu.Unlock()
+
+	for anType := range ue.populationCounted {
+		ue.populationRegistered[anType] = false
+		ue.updateRegisteredPopulation(anType)
+	}
+	for pduSessionID, counted := range ue.populationSessions {
+		metrics.AddPduSessions(counted.snssai, counted.dnn, -1)
+		delete(ue.populationSessions, pduSessionID)
+	}
+}
+
+// countOnRan counts ranUe in the UE gauge of the NG-RAN node it is connected to.
+func (ranUe *RanUe) countOnRan(ran *AmfRan) {
+	ranUe.uncountOnRan()
+	ranUe.countedRan, ranUe.countedOnRan = ran.GnbId, true
+	metrics.AddGnbUes(ranUe.countedRan, 1)
+}
+
+func (ranUe *RanUe) uncountOnRan() {
+	if !ranUe.countedOnRan {
+		return
+	}
+	metrics.AddGnbUes(ranUe.countedRan, -1)
+	ranUe.countedRan, ranUe.countedOnRan = "", false
+}
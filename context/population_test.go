@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestRegisteredPopulation(t *testing.T) {
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	ue := &AmfUe{
+		Tai:   models.Tai{Tac: "000001"},
+		RanUe: make(map[models.AccessType]*RanUe),
+	}
+
+	ue.updateCmPopulation(anType)
+	if _, ok := ue.populationCounted[anType]; ok {
+		t.Fatal("expected a UE that is not registered not to be counted")
+	}
+
+	ue.SetRegisteredPopulation(anType, true)
+	if counted := ue.populationCounted[anType]; counted.cmState != CmStateIdle || counted.tac != "000001" {
+		t.Fatalf("expected a CM-IDLE UE in TAC 000001, got %+v", counted)
+	}
+
+	ue.RanUe[anType] = &RanUe{}
+	ue.updateCmPopulation(anType)
+	if counted := ue.populationCounted[anType]; counted.cmState != CmStateConnected {
+		t.Fatalf("expected a CM-CONNECTED UE, got %+v", counted)
+	}
+
+	ue.DetachRanUe(anType)
+	if counted := ue.populationCounted[anType]; counted.cmState != CmStateIdle {
+		t.Fatalf("expected a CM-IDLE UE after the RanUe was detached, got %+v", counted)
+	}
+
+	ue.SetRegisteredPopulation(anType, false)
+	if _, ok := ue.populationCounted[anType]; ok {
+		t.Error("expected a deregistered UE not to be counted")
+	}
+}
+
+func TestSessionPopulation(t *testing.T) {
+	ue := &AmfUe{}
+	smContext := NewSmContext(1)
+	smContext.SetSnssai(models.Snssai{Sst: 1})
+	smContext.SetDnn("internet")
+
+	ue.StoreSmContext(1, smContext)
+	if counted := ue.populationSessions[1]; counted.snssai != "1" || counted.dnn != "internet" {
+		t.Fatalf("unexpected PDU session labels %+v", counted)
+	}
+
+	ue.DeleteSmContext(1)
+	if _, ok := ue.populationSessions[1]; ok {
+		t.Error("expected a deleted PDU session not to be counted")
+	}
+	if _, ok := ue.SmContextFindByPDUSessionID(1); ok {
+		t.Error("expected the SM context to be deleted")
+	}
+
+	ue.StoreSmContext(2, smContext)
+	ue.releasePopulation()
+	if len(ue.populationSessions) != 0 {
+		t.Error("expected the PDU sessions of a removed UE not to be counted")
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// MicoIndicationSPRTI is the strict periodic registration timer indication bit of the
+// MICO indication IE (TS 24.501 9.11.3.31)
+const MicoIndicationSPRTI uint8 = 0x02
+
+// EdrxValueMaxNR is the longest NR eDRX cycle, 1024 hyperframes (TS 24.008 10.5.5.32)
+const EdrxValueMaxNR uint8 = 12
+
+// EdrxParameters are extended idle mode DRX parameters for NR (TS 24.008 10.5.5.32). The
+// eDRX value and paging time window match the NGAP NRPagingEDRXCycle and NRPagingTimeWindow
+// enumerations.
+type EdrxParameters struct {
+	EdrxValue        uint8 `json:"edrxValue"`
+	PagingTimeWindow uint8 `json:"pagingTimeWindow"`
+}
+
+// EdrxParametersFromOctet decodes octet 3 of the extended DRX parameters IE.
+func EdrxParametersFromOctet(octet uint8) EdrxParameters {
+	return EdrxParameters{EdrxValue: octet & 0x0f, PagingTimeWindow: octet >> 4}
+}
+
+// Octet encodes p as octet 3 of the extended DRX parameters IE.
+func (p EdrxParameters) Octet() uint8 {
+	return p.PagingTimeWindow<<4 | p.EdrxValue&0x0f
+}
+
+// Cycle returns the eDRX cycle length, from 2.56 s (a quarter hyperframe) up to 10485.76 s.
+func (p EdrxParameters) Cycle() time.Duration {
+	return 2560 * time.Millisecond << min(p.EdrxValue, EdrxValueMaxNR)
+}
+
+// MicoGrant is what the AMF grants in response to a MICO indication (TS 24.501 5.5.1.2.4).
+type MicoGrant struct {
+	Mico                       bool
+	AllPlmnRegistrationArea    bool
+	StrictPeriodicRegistration bool
+}
+
+// NegotiateMico decides whether MICO mode is granted for a UE that sent a MICO indication
+// with the given RAAI and SPRTI bits. MICO mode must be enabled in cfg and must not be
+// disallowed by the subscription.
+func NegotiateMico(raai, sprti bool, subscription *models.AccessAndMobilitySubscriptionData,
+	cfg *factory.PowerSavingConfig,
+) MicoGrant {
+	if cfg == nil || !cfg.MicoEnabled {
+		return MicoGrant{}
+	}
+	if allowed, ok := subscription.GetMicoAllowedOk(); ok && !*allowed {
+		return MicoGrant{}
+	}
+	return MicoGrant{
+		Mico:                       true,
+		AllPlmnRegistrationArea:    raai && cfg.MicoAllPlmnRegistrationArea,
+		StrictPeriodicRegistration: sprti && cfg.StrictPeriodicRegistration,
+	}
+}
+
+// NegotiateEdrx returns the eDRX parameters granted for the requested ones, or nil if eDRX
+// is not used (TS 23.501 5.31.7.2.1). The subscribed NR values take precedence over the
+// requested and configured ones, and the cycle is capped at the configured maximum.
+func NegotiateEdrx(requested EdrxParameters, subscription *models.AccessAndMobilitySubscriptionData,
+	cfg *factory.PowerSavingConfig,
+) *EdrxParameters {
+	if cfg == nil || !cfg.EdrxEnabled {
+		return nil
+	}
+	granted := requested
+	if cfg.PagingTimeWindow != nil {
+		granted.PagingTimeWindow = *cfg.PagingTimeWindow
+	}
+	if subscription != nil {
+		for _, edrx := range subscription.EdrxParametersList {
+			if edrx.RatType != models.RATTYPE_NR {
+				continue
+			}
+			if value, err := strconv.ParseUint(edrx.EdrxValue, 2, 4); err == nil {
+				granted.EdrxValue = uint8(value)
+			}
+		}
+		for _, ptw := range subscription.PtwParametersList {
+			if ptw.OperationMode != models.OPERATIONMODE_NR_N1 {
+				continue
+			}
+			if value, err := strconv.ParseUint(ptw.PtwValue, 2, 4); err == nil {
+				granted.PagingTimeWindow = uint8(value)
+			}
+		}
+	}
+	maxEdrxValue := EdrxValueMaxNR
+	if cfg.MaxEdrxValue != nil {
+		maxEdrxValue = *cfg.MaxEdrxValue
+	}
+	granted.EdrxValue = min(granted.EdrxValue, maxEdrxValue)
+	return &granted
+}
+
+// EstimatedMaxWaitTime returns how long a CM-IDLE UE may stay unreachable for paging, or 0 if
+// it can be paged right away: a UE in MICO mode is reachable again at the latest when its
+// periodic registration timer expires.
+func (ue *AmfUe) EstimatedMaxWaitTime() time.Duration {
+	if ue.MicoMode {
+		return time.Duration(ue.T3512Value) * time.Second
+	}
+	return 0
+}
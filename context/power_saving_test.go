@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestEdrxParametersOctet(t *testing.T) {
+	p := EdrxParametersFromOctet(0x35)
+	if p.EdrxValue != 5 || p.PagingTimeWindow != 3 {
+		t.Fatalf("expected eDRX value 5 and PTW 3, got %+v", p)
+	}
+	if p.Octet() != 0x35 {
+		t.Errorf("expected octet 0x35, got %#x", p.Octet())
+	}
+	if p.Cycle() != 81920*time.Millisecond {
+		t.Errorf("expected 81.92s cycle, got %v", p.Cycle())
+	}
+}
+
+func TestNegotiateMico(t *testing.T) {
+	cfg := &factory.PowerSavingConfig{MicoEnabled: true, StrictPeriodicRegistration: true}
+	if grant := NegotiateMico(true, true, nil, cfg); !grant.Mico || grant.AllPlmnRegistrationArea ||
+		!grant.StrictPeriodicRegistration {
+		t.Errorf("expected MICO with strict periodic registration only, got %+v", grant)
+	}
+	notAllowed := &models.AccessAndMobilitySubscriptionData{MicoAllowed: new(bool)}
+	if grant := NegotiateMico(false, false, notAllowed, cfg); grant.Mico {
+		t.Error("expected MICO to be rejected when not allowed by subscription")
+	}
+	if grant := NegotiateMico(false, false, nil, nil); grant.Mico {
+		t.Error("expected MICO to be rejected when not configured")
+	}
+}
+
+func TestNegotiateEdrx(t *testing.T) {
+	maxEdrxValue, ptw := uint8(9), uint8(2)
+	cfg := &factory.PowerSavingConfig{EdrxEnabled: true, MaxEdrxValue: &maxEdrxValue, PagingTimeWindow: &ptw}
+	requested := EdrxParameters{EdrxValue: 11, PagingTimeWindow: 7}
+
+	granted := NegotiateEdrx(requested, nil, cfg)
+	if granted == nil || granted.EdrxValue != 9 || granted.PagingTimeWindow != 2 {
+		t.Errorf("expected capped eDRX value 9 and configured PTW 2, got %+v", granted)
+	}
+
+	subscription := &models.AccessAndMobilitySubscriptionData{
+		EdrxParametersList: []models.EdrxParameters{{RatType: models.RATTYPE_NR, EdrxValue: "0101"}},
+		PtwParametersList:  []models.PtwParameters{{OperationMode: models.OPERATIONMODE_NR_N1, PtwValue: "0100"}},
+	}
+	granted = NegotiateEdrx(requested, subscription, cfg)
+	if granted == nil || granted.EdrxValue != 5 || granted.PagingTimeWindow != 4 {
+		t.Errorf("expected subscribed eDRX value 5 and PTW 4, got %+v", granted)
+	}
+
+	if NegotiateEdrx(requested, nil, &factory.PowerSavingConfig{}) != nil {
+		t.Error("expected no eDRX when disabled")
+	}
+}
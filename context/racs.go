@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"container/list"
+	ctxt "context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.yaml.in/yaml/v4"
+)
+
+var ErrUeRadioCapabilityIdUnknown = errors.New("UE radio capability ID unknown")
+
+// UE radio capability ID types, the first digit of the ID (TS 23.003 29.2)
+const (
+	UeRadioCapabilityIdManufacturerAssigned uint8 = 0
+	UeRadioCapabilityIdPlmnAssigned         uint8 = 1
+)
+
+// UeRadioCapabilityIdType returns the type of a hex encoded UE radio capability ID, whose
+// digits are BCD coded as in TS 24.501 9.11.3.68.
+func UeRadioCapabilityIdType(ueRadioCapabilityId string) (uint8, error) {
+	id, err := hex.DecodeString(ueRadioCapabilityId)
+	if err != nil || len(id) == 0 {
+		return 0, fmt.Errorf("invalid UE radio capability ID %q", ueRadioCapabilityId)
+	}
+	return id[0] & 0x0f, nil
+}
+
+// UeRadioCapabilityEntry is the UE radio capability a UE radio capability ID stands for.
+type UeRadioCapabilityEntry struct {
+	UeRadioCapability          string                      `yaml:"ueRadioCapability" json:"ueRadioCapability"` // OCTET string
+	UeRadioCapabilityForPaging *UERadioCapabilityForPaging `yaml:"ueRadioCapabilityForPaging,omitempty" json:"ueRadioCapabilityForPaging,omitempty"`
+}
+
+// UcmfClient resolves UE radio capability IDs (TS 23.502 5.2.18.2).
+type UcmfClient interface {
+	// Resolve returns ErrUeRadioCapabilityIdUnknown if the ID is not known to the UCMF
+	Resolve(ctx ctxt.Context, ueRadioCapabilityId string) (*UeRadioCapabilityEntry, error)
+}
+
+// UeRadioCapabilityDictionary caches the UE radio capabilities of manufacturer-assigned and
+// PLMN-assigned UE radio capability IDs, evicting the least recently used ID when full.
+type UeRadioCapabilityDictionary struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type dictionaryEntry struct {
+	id    string
+	entry UeRadioCapabilityEntry
+}
+
+func NewUeRadioCapabilityDictionary(maxEntries int) *UeRadioCapabilityDictionary {
+	return &UeRadioCapabilityDictionary{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (d *UeRadioCapabilityDictionary) Get(ueRadioCapabilityId string) (UeRadioCapabilityEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	element, ok := d.entries[ueRadioCapabilityId]
+	if !ok {
+		return UeRadioCapabilityEntry{}, false
+	}
+	d.lru.MoveToFront(element)
+	return element.Value.(*dictionaryEntry).entry, true
+}
+
+func (d *UeRadioCapabilityDictionary) Put(ueRadioCapabilityId string, entry UeRadioCapabilityEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if element, ok := d.entries[ueRadioCapabilityId]; ok {
+		element.Value.(*dictionaryEntry).entry = entry
+		d.lru.MoveToFront(element)
+		return
+	}
+	d.entries[ueRadioCapabilityId] = d.lru.PushFront(&dictionaryEntry{id: ueRadioCapabilityId, entry: entry})
+	if d.maxEntries > 0 && d.lru.Len() > d.maxEntries {
+		oldest := d.lru.Back()
+		d.lru.Remove(oldest)
+		delete(d.entries, oldest.Value.(*dictionaryEntry).id)
+	}
+}
+
+func (d *UeRadioCapabilityDictionary) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.lru.Len()
+}
+
+// ResolveUeRadioCapabilityId returns the UE radio capability of a UE radio capability ID from
+// the dictionary of the AMF, or from the UCMF, whose answer is added to the dictionary.
+func (context *AMFContext) ResolveUeRadioCapabilityId(ctx ctxt.Context, ueRadioCapabilityId string) (
+	*UeRadioCapabilityEntry, error,
+) {
+	entry, err := context.LookupUeRadioCapabilityId(ueRadioCapabilityId)
+	if !errors.Is(err, ErrUeRadioCapabilityIdUnknown) || context.Ucmf == nil {
+		return entry, err
+	}
+	entry, err = context.Ucmf.Resolve(ctx, ueRadioCapabilityId)
+	if err != nil {
+		return nil, err
+	}
+	context.UeRadioCapabilityDictionary.Put(ueRadioCapabilityId, *entry)
+	return entry, nil
+}
+
+// LookupUeRadioCapabilityId returns the UE radio capability of a UE radio capability ID from
+// the dictionary of the AMF only, ErrUeRadioCapabilityIdUnknown if it is not cached.
+func (context *AMFContext) LookupUeRadioCapabilityId(ueRadioCapabilityId string) (*UeRadioCapabilityEntry, error) {
+	if context.UeRadioCapabilityDictionary == nil {
+		return nil, fmt.Errorf("RACS is not enabled")
+	}
+	entry, ok := context.UeRadioCapabilityDictionary.Get(ueRadioCapabilityId)
+	if !ok {
+		return nil, ErrUeRadioCapabilityIdUnknown
+	}
+	return &entry, nil
+}
+
+// fileUcmfClient resolves UE radio capability IDs from a local YAML file, for deployments and
+// tests without a UCMF.
+type fileUcmfClient struct {
+	entries map[string]UeRadioCapabilityEntry
+}
+
+type ueRadioCapabilityIdFile struct {
+	UeRadioCapabilityIds []struct {
+		Id                     string `yaml:"id"`
+		UeRadioCapabilityEntry `yaml:",inline"`
+	} `yaml:"ueRadioCapabilityIds"`
+}
+
+// NewFileUcmfClient loads the UE radio capability IDs of a dictionary file.
+func NewFileUcmfClient(path string) (UcmfClient, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read UE radio capability ID file: %w", err)
+	}
+	file := ueRadioCapabilityIdFile{}
+	if err = yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("parse UE radio capability ID file: %w", err)
+	}
+	client := &fileUcmfClient{entries: make(map[string]UeRadioCapabilityEntry, len(file.UeRadioCapabilityIds))}
+	for _, id := range file.UeRadioCapabilityIds {
+		if _, err = UeRadioCapabilityIdType(id.Id); err != nil {
+			return nil, err
+		}
+		if _, err = hex.DecodeString(id.UeRadioCapability); err != nil {
+			return nil, fmt.Errorf("invalid UE radio capability of ID %s: %w", id.Id, err)
+		}
+		client.entries[id.Id] = id.UeRadioCapabilityEntry
+	}
+	return client, nil
+}
+
+func (c *fileUcmfClient) Resolve(_ ctxt.Context, ueRadioCapabilityId string) (*UeRadioCapabilityEntry, error) {
+	entry, ok := c.entries[ueRadioCapabilityId]
+	if !ok {
+		return nil, ErrUeRadioCapabilityIdUnknown
+	}
+	return &entry, nil
+}
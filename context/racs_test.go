@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"errors"
+	"testing"
+)
+
+type countingUcmfClient struct {
+	UcmfClient
+	resolved int
+}
+
+func (c *countingUcmfClient) Resolve(ctx ctxt.Context, ueRadioCapabilityId string) (*UeRadioCapabilityEntry, error) {
+	c.resolved++
+	return c.UcmfClient.Resolve(ctx, ueRadioCapabilityId)
+}
+
+func TestUeRadioCapabilityDictionaryEviction(t *testing.T) {
+	dictionary := NewUeRadioCapabilityDictionary(2)
+	dictionary.Put("10", UeRadioCapabilityEntry{UeRadioCapability: "01"})
+	dictionary.Put("11", UeRadioCapabilityEntry{UeRadioCapability: "02"})
+	if _, ok := dictionary.Get("10"); !ok {
+		t.Fatal("expected ID 10 to be cached")
+	}
+	dictionary.Put("12", UeRadioCapabilityEntry{UeRadioCapability: "03"})
+
+	if dictionary.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", dictionary.Len())
+	}
+	if _, ok := dictionary.Get("11"); ok {
+		t.Error("expected the least recently used ID 11 to be evicted")
+	}
+	if entry, ok := dictionary.Get("10"); !ok || entry.UeRadioCapability != "01" {
+		t.Errorf("unexpected entry of ID 10: %+v", entry)
+	}
+}
+
+func TestUeRadioCapabilityIdType(t *testing.T) {
+	if idType, err := UeRadioCapabilityIdType("10f8390102030405"); err != nil || idType != UeRadioCapabilityIdPlmnAssigned {
+		t.Errorf("expected a PLMN-assigned ID, got %d %v", idType, err)
+	}
+	if idType, err := UeRadioCapabilityIdType("00f1e20000000001"); err != nil || idType != UeRadioCapabilityIdManufacturerAssigned {
+		t.Errorf("expected a manufacturer-assigned ID, got %d %v", idType, err)
+	}
+	if _, err := UeRadioCapabilityIdType("zz"); err == nil {
+		t.Error("expected an invalid ID to be rejected")
+	}
+}
+
+func TestResolveUeRadioCapabilityId(t *testing.T) {
+	fileClient, err := NewFileUcmfClient("../util/testdata/ue_radio_capability_ids.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ucmf := &countingUcmfClient{UcmfClient: fileClient}
+	self := &AMFContext{UeRadioCapabilityDictionary: NewUeRadioCapabilityDictionary(10), Ucmf: ucmf}
+
+	for range 2 {
+		entry, err := self.ResolveUeRadioCapabilityId(ctxt.Background(), "10f8390102030405")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if entry.UeRadioCapability != "0401020304" || entry.UeRadioCapabilityForPaging == nil ||
+			entry.UeRadioCapabilityForPaging.NR != "0a0b" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+	}
+	if ucmf.resolved != 1 {
+		t.Errorf("expected the UCMF to be asked once, got %d", ucmf.resolved)
+	}
+
+	if _, err = self.ResolveUeRadioCapabilityId(ctxt.Background(), "10f8390000000000"); !errors.Is(err, ErrUeRadioCapabilityIdUnknown) {
+		t.Errorf("expected an unknown ID error, got %v", err)
+	}
+	if _, err = (&AMFContext{}).ResolveUeRadioCapabilityId(ctxt.Background(), "10f8390102030405"); err == nil {
+		t.Error("expected resolution to fail without RACS")
+	}
+}
+
+func TestLookupUeRadioCapabilityIdDoesNotAskUcmf(t *testing.T) {
+	fileClient, err := NewFileUcmfClient("../util/testdata/ue_radio_capability_ids.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ucmf := &countingUcmfClient{UcmfClient: fileClient}
+	self := &AMFContext{UeRadioCapabilityDictionary: NewUeRadioCapabilityDictionary(10), Ucmf: ucmf}
+
+	if _, err = self.LookupUeRadioCapabilityId("10f8390102030405"); !errors.Is(err, ErrUeRadioCapabilityIdUnknown) {
+		t.Fatalf("expected an unknown ID error before the ID is resolved, got %v", err)
+	}
+	if _, err = self.ResolveUeRadioCapabilityId(ctxt.Background(), "10f8390102030405"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry, err := self.LookupUeRadioCapabilityId("10f8390102030405"); err != nil || entry.UeRadioCapability != "0401020304" {
+		t.Errorf("expected the resolved ID to be cached, got %+v %v", entry, err)
+	}
+	if ucmf.resolved != 1 {
+		t.Errorf("expected the UCMF to be asked once, got %d", ucmf.resolved)
+	}
+}
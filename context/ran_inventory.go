@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ishidawataru/sctp"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// GnbInfo is the inventory entry of an NG-RAN node connected to the AMF
+type GnbInfo struct {
+	GnbId              string                  `json:"gnbId,omitempty"`
+	GlobalRanNodeId    *models.GlobalRanNodeId `json:"globalRanNodeId,omitempty"`
+	Name               string                  `json:"name,omitempty"`
+	AnType             models.AccessType       `json:"anType,omitempty"`
+	TransportAddresses []string                `json:"transportAddresses,omitempty"`
+	SupportedTaiList   []GnbSupportedTai       `json:"supportedTaiList,omitempty"`
+	UeCount            int                     `json:"ueCount"`
+	ConnectedAt        time.Time               `json:"connectedAt"`
+}
+
+// GnbSupportedTai is a TAI supported by an NG-RAN node and the slices it supports in the TAI
+type GnbSupportedTai struct {
+	Tai        models.Tai      `json:"tai"`
+	SNssaiList []models.Snssai `json:"sNssaiList,omitempty"`
+}
+
+// GnbDetail is the inventory entry of an NG-RAN node with its UE-associated NG connections
+type GnbDetail struct {
+	GnbInfo
+	Ues []GnbUeInfo `json:"ues"`
+}
+
+// GnbUeInfo is a UE-associated NG connection of an NG-RAN node
+type GnbUeInfo struct {
+	AmfUeNgapId int64  `json:"amfUeNgapId"`
+	RanUeNgapId int64  `json:"ranUeNgapId"`
+	Supi        string `json:"supi,omitempty"`
+}
+
+// Info returns the inventory entry of the RAN
+func (ran *AmfRan) Info() GnbInfo {
+	snapshot := ran.statsSnapshot()
+	info := GnbInfo{
+		GnbId:              ran.GnbId,
+		GlobalRanNodeId:    ran.RanId,
+		Name:               snapshot.name,
+		AnType:             ran.AnType,
+		TransportAddresses: ran.TransportAddresses(),
+		ConnectedAt:        ran.ConnectedAt,
+	}
+	for _, supportedTai := range snapshot.supportedTAList {
+		info.SupportedTaiList = append(info.SupportedTaiList, GnbSupportedTai{
+			Tai:        supportedTai.Tai,
+			SNssaiList: supportedTai.SNssaiList,
+		})
+	}
+	ran.RLockRanState()
+	info.UeCount = len(ran.RanUeList)
+	ran.RUnlockRanState()
+	return info
+}
+
+// Detail returns the inventory entry of the RAN with its UE-associated NG connections,
+// sorted by AMF UE NGAP ID
+func (ran *AmfRan) Detail() GnbDetail {
+	detail := GnbDetail{GnbInfo: ran.Info(), Ues: make([]GnbUeInfo, 0)}
+	ran.RLockRanState()
+	for _, ranUe := range ran.RanUeList {
+		ueInfo := GnbUeInfo{AmfUeNgapId: ranUe.AmfUeNgapId, RanUeNgapId: ranUe.RanUeNgapId}
+		if amfUe := ranUe.AmfUe; amfUe != nil {
+			ueInfo.Supi = amfUe.GetSupi()
+		}
+		detail.Ues = append(detail.Ues, ueInfo)
+	}
+	ran.RUnlockRanState()
+	sort.Slice(detail.Ues, func(i, j int) bool { return detail.Ues[i].AmfUeNgapId < detail.Ues[j].AmfUeNgapId })
+	return detail
+}
+
+// TransportAddresses returns the SCTP endpoints of the RAN, one per address of a multi-homed
+// association, or the address reported by the SCTP load balancer
+func (ran *AmfRan) TransportAddresses() []string {
+	if ran.Conn == nil {
+		if ran.GnbIp == "" {
+			return nil
+		}
+		return []string{ran.GnbIp}
+	}
+	remoteAddr := ran.Conn.RemoteAddr()
+	if sctpAddr, ok := remoteAddr.(*sctp.SCTPAddr); ok && sctpAddr != nil {
+		addresses := make([]string, 0, len(sctpAddr.IPAddrs))
+		for _, ipAddr := range sctpAddr.IPAddrs {
+			addresses = append(addresses, net.JoinHostPort(ipAddr.String(), strconv.Itoa(sctpAddr.Port)))
+		}
+		return addresses
+	}
+	if remoteAddr == nil {
+		return nil
+	}
+	return []string{remoteAddr.String()}
+}
+
+// AmfRanList returns the RANs connected to the AMF, sorted by gNB ID
+func (context *AMFContext) AmfRanList() []*AmfRan {
+	var rans []*AmfRan
+	context.AmfRanPool.Range(func(key, value any) bool {
+		rans = append(rans, value.(*AmfRan))
+		return true
+	})
+	sort.Slice(rans, func(i, j int) bool {
+		if rans[i].GnbId != rans[j].GnbId {
+			return rans[i].GnbId < rans[j].GnbId
+		}
+		return rans[i].GnbIp < rans[j].GnbIp
+	})
+	return rans
+}
+
+// AmfRanFindByInventoryId finds a RAN by the ID of its inventory entry: its gNB ID, or its
+// transport address before the NG Setup procedure set its gNB ID
+func (context *AMFContext) AmfRanFindByInventoryId(id string) (*AmfRan, bool) {
+	var ran *AmfRan
+	context.AmfRanPool.Range(func(key, value any) bool {
+		amfRan := value.(*AmfRan)
+		if amfRan.GnbId == id || (amfRan.GnbId == "" && amfRan.GnbIp == id) {
+			ran = amfRan
+			return false
+		}
+		return true
+	})
+	return ran, ran != nil
+}
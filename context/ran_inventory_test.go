@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestRanInventory(t *testing.T) {
+	amfContext := &AMFContext{}
+	ran := amfContext.NewAmfRanId("208:93:000102")
+	ran.Name = "gnb-1"
+	ran.SupportedTAList = append(ran.SupportedTAList, SupportedTAI{
+		Tai:        models.Tai{PlmnId: models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"},
+		SNssaiList: []models.Snssai{{Sst: 1}},
+	})
+	ran.RanUeList[2] = &RanUe{AmfUeNgapId: 20, RanUeNgapId: 2, AmfUe: &AmfUe{Supi: "imsi-208930000000001"}}
+	ran.RanUeList[1] = &RanUe{AmfUeNgapId: 10, RanUeNgapId: 1}
+	pending := amfContext.NewAmfRanAddr("192.0.2.1:38412")
+
+	info := ran.Info()
+	if info.GnbId != "208:93:000102" || info.Name != "gnb-1" || info.UeCount != 2 || info.ConnectedAt.IsZero() {
+		t.Errorf("unexpected gNB info: %+v", info)
+	}
+	if len(info.SupportedTaiList) != 1 || info.SupportedTaiList[0].Tai.Tac != "000001" ||
+		len(info.SupportedTaiList[0].SNssaiList) != 1 {
+		t.Errorf("unexpected supported TAIs: %+v", info.SupportedTaiList)
+	}
+	if addresses := pending.TransportAddresses(); len(addresses) != 1 || addresses[0] != "192.0.2.1:38412" {
+		t.Errorf("unexpected transport addresses: %v", addresses)
+	}
+
+	detail := ran.Detail()
+	if len(detail.Ues) != 2 || detail.Ues[0].AmfUeNgapId != 10 || detail.Ues[1].Supi != "imsi-208930000000001" {
+		t.Errorf("unexpected gNB UEs: %+v", detail.Ues)
+	}
+
+	if rans := amfContext.AmfRanList(); len(rans) != 2 || rans[0] != pending || rans[1] != ran {
+		t.Errorf("expected the RANs sorted by gNB ID, got %v", rans)
+	}
+	if found, ok := amfContext.AmfRanFindByInventoryId("208:93:000102"); !ok || found != ran {
+		t.Errorf("expected to find the RAN by gNB ID")
+	}
+	if found, ok := amfContext.AmfRanFindByInventoryId("192.0.2.1:38412"); !ok || found != pending {
+		t.Errorf("expected to find the RAN by transport address before NG Setup")
+	}
+	if _, ok := amfContext.AmfRanFindByInventoryId("208:93:000103"); ok {
+		t.Errorf("expected an unknown gNB not to be found")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/mohae/deepcopy"
@@ -76,6 +77,18 @@ type RanUe struct {
 	/*Received Initial context setup response or not */
 	RecvdInitialContextSetupResponse bool
 
+	/* RRC state reported by NG-RAN */
+	RrcState RrcState
+
+	/* downlink NAS PDUs for NAS non-delivery */
+	dlNasMu       sync.Mutex
+	pendingDlNas  []PendingDlNas
+	deferredDlNas []PendingDlNas
+
+	/* NG-RAN node the UE is counted on in the UE population gauges */
+	countedRan   string
+	countedOnRan bool
+
 	/* logger */
 	Log *zap.SugaredLogger `json:"-"`
 
@@ -102,13 +115,15 @@ func (ranUe *RanUe) Remove() error {
 			ranUe.AmfUe = nil
 		}
 		amfUe.Mutex.Unlock()
+		amfUe.updateCmPopulation(ran.AnType)
 	}
+	ranUe.uncountOnRan()
 
 	ran.ranStateMu.Lock()
 	delete(ran.RanUeList, ranUe.RanUeNgapId)
 	ran.ranStateMu.Unlock()
 	self := AMF_Self()
-	if self.EnableDbStore {
+	if self.Drsm != nil {
 		if err := self.Drsm.ReleaseInt32ID(int32(ranUe.AmfUeNgapId)); err != nil {
 			logger.ContextLog.Errorf("error releasing UE: %v", err)
 		}
@@ -147,6 +162,11 @@ func (ranUe *RanUe) SwitchToRan(newRan *AmfRan, ranUeNgapId int64) error {
 	// switch to newRan
 	ranUe.Ran = newRan
 	ranUe.RanUeNgapId = ranUeNgapId
+	ranUe.countOnRan(newRan)
+	updateUeLogger(&ranUe.Log, newRan.Log, logger.FieldAmfUeNgapID, ranUe.AmfUeNgapId)
+	if amfUe := ranUe.AmfUe; amfUe != nil {
+		amfUe.UpdateLoggers()
+	}
 
 	logger.ContextLog.Infof("RanUe[RanUeNgapID: %d] Switch to new Ran[Name: %s]", ranUe.RanUeNgapId, ranUe.Ran.Name)
 	return nil
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"slices"
+
+	"github.com/omec-project/nas/v2"
+	"github.com/omec-project/nas/v2/security"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// savedSecurityContext is the 5G NAS security context of a UE before its re-authentication.
+// Its NAS COUNTs stay live until a Security Mode Command takes the new security context into
+// use, see StartNewNasCounts.
+type savedSecurityContext struct {
+	abba                     []uint8
+	kseaf                    string
+	kamf                     string
+	securityContextAvailable bool
+	ngKsi                    models.NgKsi
+	macFailed                bool
+	knasInt                  [16]uint8
+	knasEnc                  [16]uint8
+	kgnb                     []uint8
+	kn3iwf                   []uint8
+	nh                       []uint8
+	ncc                      uint8
+	cipheringAlg             uint8
+	integrityAlg             uint8
+	// NAS COUNTs the security context was left with by StartNewNasCounts
+	countsSwitched bool
+	ulCount        security.Count
+	dlCount        security.Count
+}
+
+// SaveSecurityContext keeps the current security context of the UE, which it goes on using
+// until it takes the one of a re-authentication into use with a Security Mode Complete
+func (ue *AmfUe) SaveSecurityContext() {
+	ue.reauthSecurityContext = &savedSecurityContext{
+		abba:                     slices.Clone(ue.ABBA),
+		kseaf:                    ue.Kseaf,
+		kamf:                     ue.Kamf,
+		securityContextAvailable: ue.SecurityContextAvailable,
+		ngKsi:                    ue.NgKsi,
+		macFailed:                ue.MacFailed,
+		knasInt:                  ue.KnasInt,
+		knasEnc:                  ue.KnasEnc,
+		kgnb:                     slices.Clone(ue.Kgnb),
+		kn3iwf:                   slices.Clone(ue.Kn3iwf),
+		nh:                       slices.Clone(ue.NH),
+		ncc:                      ue.NCC,
+		cipheringAlg:             ue.CipheringAlg,
+		integrityAlg:             ue.IntegrityAlg,
+	}
+}
+
+// RestoreSecurityContext takes the security context kept by SaveSecurityContext back into
+// use after a re-authentication the UE was not rejected by failed. Its NAS COUNTs are only
+// taken back if a Security Mode Command switched them, see StartNewNasCounts.
+func (ue *AmfUe) RestoreSecurityContext() {
+	saved := ue.reauthSecurityContext
+	if saved == nil {
+		return
+	}
+	ue.reauthSecurityContext = nil
+	ue.ABBA = saved.abba
+	ue.Kseaf = saved.kseaf
+	ue.Kamf = saved.kamf
+	ue.SecurityContextAvailable = saved.securityContextAvailable
+	ue.NgKsi = saved.ngKsi
+	ue.MacFailed = saved.macFailed
+	ue.KnasInt = saved.knasInt
+	ue.KnasEnc = saved.knasEnc
+	ue.Kgnb = saved.kgnb
+	ue.Kn3iwf = saved.kn3iwf
+	ue.NH = saved.nh
+	ue.NCC = saved.ncc
+	ue.CipheringAlg = saved.cipheringAlg
+	ue.IntegrityAlg = saved.integrityAlg
+	if saved.countsSwitched {
+		ue.ULCount, ue.DLCount = saved.ulCount, saved.dlCount
+	}
+}
+
+// StartNewNasCounts resets the NAS COUNTs for a new security context taken into use by a
+// Security Mode Command. During a re-authentication the COUNTs the previous security context
+// was left with are kept with it, so that they go on where they stopped, and are never
+// rewound, if the UE does not take the new security context into use.
+func (ue *AmfUe) StartNewNasCounts() {
+	if saved := ue.reauthSecurityContext; saved != nil && !saved.countsSwitched {
+		saved.countsSwitched, saved.ulCount, saved.dlCount = true, ue.ULCount, ue.DLCount
+	}
+	ue.ULCount.Set(0, 0)
+	ue.DLCount.Set(0, 0)
+}
+
+// EndReauthentication ends the re-authentication of the UE over anType and reports its
+// outcome with OamProcedureReauthentication. A security context kept by SaveSecurityContext
+// and not restored is dropped.
+func (ue *AmfUe) EndReauthentication(anType models.AccessType, outcome OamProcedureOutcome, detail string) {
+	ue.reauthSecurityContext = nil
+	ue.SetOnGoing(anType, &OnGoingProcedureWithPrio{Procedure: OnGoingProcedureNothing})
+	ue.EndOamProcedure(OamProcedureReauthentication, outcome, detail)
+}
+
+// DeferredNasMessage is a NAS message of a UE handled once its re-authentication ended
+type DeferredNasMessage struct {
+	GmmMessage    *nas.GmmMessage
+	ProcedureCode int64
+}
+
+// DeferNasMessage keeps a NAS message the UE sent during its re-authentication that is not
+// part of it, e.g. an UL NAS Transport, to be handled once the UE is back in Registered
+func (ue *AmfUe) DeferNasMessage(gmmMessage *nas.GmmMessage, procedureCode int64) {
+	ue.deferredNasMessages = append(ue.deferredNasMessages,
+		DeferredNasMessage{GmmMessage: gmmMessage, ProcedureCode: procedureCode})
+}
+
+// TakeDeferredNasMessages returns the NAS messages kept by DeferNasMessage in the order the
+// UE sent them, and forgets them
+func (ue *AmfUe) TakeDeferredNasMessages() []DeferredNasMessage {
+	deferred := ue.deferredNasMessages
+	ue.deferredNasMessages = nil
+	return deferred
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestRestoreSecurityContext(t *testing.T) {
+	ue := &AmfUe{
+		Kamf:                     "old",
+		SecurityContextAvailable: true,
+		NgKsi:                    models.NgKsi{Tsc: models.SCTYPE_NATIVE, Ksi: 1},
+		KnasInt:                  [16]uint8{1},
+		NH:                       []uint8{1, 2},
+		CipheringAlg:             1,
+	}
+	ue.ULCount.Set(0, 5)
+	ue.SaveSecurityContext()
+
+	ue.Kamf = "new"
+	ue.NgKsi.Ksi = 2
+	ue.KnasInt = [16]uint8{2}
+	ue.NH[0] = 9
+	ue.CipheringAlg = 2
+	// the Authentication Request and Response are still protected with the old context
+	ue.ULCount.Set(0, 6)
+	ue.DLCount.Set(0, 3)
+	ue.RestoreSecurityContext()
+	if ue.Kamf != "old" || ue.NgKsi.Ksi != 1 || ue.KnasInt[0] != 1 || ue.NH[0] != 1 || ue.CipheringAlg != 1 {
+		t.Errorf("expected the saved security context to be restored, got ngKSI %d", ue.NgKsi.Ksi)
+	}
+	if ue.ULCount.Get() != 6 || ue.DLCount.Get() != 3 {
+		t.Errorf("expected the live NAS COUNTs not to be rewound, got UL %d DL %d", ue.ULCount.Get(), ue.DLCount.Get())
+	}
+
+	// nothing is saved anymore
+	ue.Kamf = "new"
+	ue.RestoreSecurityContext()
+	if ue.Kamf != "new" {
+		t.Error("expected no security context to be restored twice")
+	}
+}
+
+func TestRestoreSecurityContextAfterSecurityModeCommand(t *testing.T) {
+	ue := &AmfUe{Kamf: "old"}
+	ue.ULCount.Set(0, 7)
+	ue.DLCount.Set(0, 4)
+	ue.SaveSecurityContext()
+
+	ue.Kamf = "new"
+	ue.StartNewNasCounts()
+	if ue.ULCount.Get() != 0 || ue.DLCount.Get() != 0 {
+		t.Fatalf("expected the new security context to start with COUNT 0, got UL %d DL %d",
+			ue.ULCount.Get(), ue.DLCount.Get())
+	}
+	ue.DLCount.AddOne()
+	// a Security Mode Command encoded again keeps the COUNTs the old context was left with
+	ue.StartNewNasCounts()
+
+	ue.RestoreSecurityContext()
+	if ue.Kamf != "old" || ue.ULCount.Get() != 7 || ue.DLCount.Get() != 4 {
+		t.Errorf("expected the old context with the COUNTs it was left with, got UL %d DL %d",
+			ue.ULCount.Get(), ue.DLCount.Get())
+	}
+}
+
+func TestEndReauthentication(t *testing.T) {
+	ue := &AmfUe{OnGoing: map[models.AccessType]*OnGoingProcedureWithPrio{
+		models.ACCESSTYPE__3_GPP_ACCESS: {Procedure: OnGoingProcedureReauthentication},
+	}}
+	ue.GmmLog = logger.GmmLog
+	result, err := ue.StartOamProcedure(OamProcedureReauthentication)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ue.SaveSecurityContext()
+	ue.EndReauthentication(models.ACCESSTYPE__3_GPP_ACCESS, OamOutcomeFailed, "security mode rejected")
+	if procedure := ue.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS).Procedure; procedure != OnGoingProcedureNothing {
+		t.Errorf("expected no ongoing procedure, got %s", procedure)
+	}
+	if ue.reauthSecurityContext != nil {
+		t.Error("expected the saved security context to be dropped")
+	}
+	if r := <-result; r.Outcome != OamOutcomeFailed || r.Detail != "security mode rejected" {
+		t.Errorf("unexpected outcome %+v", r)
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// RrcState is the RRC state of a CM-CONNECTED UE as reported by the NG-RAN in the RRC
+// Inactive Transition Report (TS 38.413 9.3.1.92)
+type RrcState string
+
+const (
+	RrcStateConnected RrcState = "CONNECTED"
+	RrcStateInactive  RrcState = "INACTIVE"
+)
+
+// UpdateRrcState records state reported by the NG-RAN and returns whether it differs from
+// the previous one. A UE for which no report was received yet is RRC_CONNECTED.
+func (ranUe *RanUe) UpdateRrcState(state RrcState) bool {
+	previous := ranUe.RrcState
+	if previous == "" {
+		previous = RrcStateConnected
+	}
+	ranUe.RrcState = state
+	return previous != state
+}
+
+// RrcInactive reports whether ue is CM-CONNECTED with RRC Inactive on anType.
+func (ue *AmfUe) RrcInactive(anType models.AccessType) bool {
+	ue.Mutex.Lock()
+	defer ue.Mutex.Unlock()
+
+	ranUe, ok := ue.RanUe[anType]
+	return ok && ranUe.RrcState == RrcStateInactive
+}
+
+// RrcStateReportRequired reports whether an event exposure subscription of ue needs the
+// NG-RAN to report RRC state transitions (TS 23.502 4.8.3), i.e. the UE is subscribed to
+// connectivity state or reachability reports.
+func (ue *AmfUe) RrcStateReportRequired() bool {
+	for _, eventSub := range ue.EventSubscriptionsInfo {
+		if eventSub.EventSubscription == nil {
+			continue
+		}
+		for _, event := range eventSub.EventSubscription.EventList {
+			if event.Type == models.AMFEVENTTYPE_CONNECTIVITY_STATE_REPORT ||
+				event.Type == models.AMFEVENTTYPE_REACHABILITY_REPORT {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"testing"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestUpdateRrcState(t *testing.T) {
+	ranUe := &RanUe{}
+	if ranUe.UpdateRrcState(RrcStateConnected) {
+		t.Error("expected a new UE to be RRC_CONNECTED")
+	}
+	if !ranUe.UpdateRrcState(RrcStateInactive) {
+		t.Error("expected transition to RRC_INACTIVE")
+	}
+	if ranUe.UpdateRrcState(RrcStateInactive) {
+		t.Error("expected no transition for a repeated state")
+	}
+
+	ue := &AmfUe{RanUe: map[models.AccessType]*RanUe{models.ACCESSTYPE__3_GPP_ACCESS: ranUe}}
+	if !ue.RrcInactive(models.ACCESSTYPE__3_GPP_ACCESS) {
+		t.Error("expected UE to be RRC_INACTIVE on 3GPP access")
+	}
+	if ue.RrcInactive(models.ACCESSTYPE_NON_3_GPP_ACCESS) {
+		t.Error("expected UE without non-3GPP access not to be RRC_INACTIVE")
+	}
+}
+
+func TestRrcStateReportRequired(t *testing.T) {
+	ue := &AmfUe{EventSubscriptionsInfo: map[string]*AmfUeEventSubscription{
+		"1": {EventSubscription: models.NewExtAmfEventSubscription(
+			[]models.AmfEvent{{Type: models.AMFEVENTTYPE_LOCATION_REPORT}}, "http://nef/events", "1", "nf")},
+	}}
+	if ue.RrcStateReportRequired() {
+		t.Error("expected no RRC state report for a location report subscription")
+	}
+	ue.EventSubscriptionsInfo["2"] = &AmfUeEventSubscription{EventSubscription: models.NewExtAmfEventSubscription(
+		[]models.AmfEvent{{Type: models.AMFEVENTTYPE_REACHABILITY_REPORT}}, "http://nef/events", "2", "nf")}
+	if !ue.RrcStateReportRequired() {
+		t.Error("expected RRC state report for a reachability subscription")
+	}
+}
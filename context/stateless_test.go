@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"testing"
+	"time"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// TestUeContextSurvivesInstanceFailure stores a UE context in the middle of the
+// authentication procedure, drops every in-memory trace of it the way a crashed AMF
+// instance would, and checks that the first lookup on the surviving instance restores
+// the complete context and re-arms the retransmission timer.
+func TestUeContextSurvivesInstanceFailure(t *testing.T) {
+	self := AMF_Self()
+	savedDbStore, savedPersist := self.EnableDbStore, ueContextPersist.Load()
+	savedRearm := timerRearmFuncs[TimerT3560]
+	store := NewMemoryUeContextStore()
+	self.EnableDbStore = true
+	SetUeContextStore(store, 0, 1, time.Minute)
+	defer func() {
+		self.EnableDbStore = savedDbStore
+		ueContextPersist.Store(savedPersist)
+		timerRearmFuncs[TimerT3560] = savedRearm
+	}()
+
+	rearmed := make(chan TimerState, 1)
+	RegisterTimerRearmFunc(TimerT3560, func(ue *AmfUe, state TimerState) {
+		rearmed <- state
+	})
+
+	ran := self.NewAmfRanId("208:93:000001")
+	defer self.AmfRanPool.Delete(ran.GnbId)
+	ran.AnType = models.ACCESSTYPE__3_GPP_ACCESS
+
+	const (
+		supi        = "imsi-208930000000001"
+		guti        = "20893cafe0000000001"
+		amfUeNgapID = int64(4242)
+	)
+	ue := &AmfUe{}
+	ue.init()
+	ue.SetSupi(supi)
+	ue.SetGuti(guti)
+	ue.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(Authentication)
+	ue.SetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS, &OnGoingProcedureWithPrio{Procedure: OnGoingProcedureRegistration})
+	ue.ULCount.Set(1, 7)
+	ue.DLCount.Set(0, 3)
+
+	ranUe := &RanUe{RanUeNgapId: 7, AmfUeNgapId: amfUeNgapID, Ran: ran}
+	ue.AttachRanUe(ranUe)
+
+	smContext := NewSmContext(5)
+	smContext.SetDnn("internet")
+	smContext.SetSmfUri("http://smf:29502")
+	smContext.SetPduSessionInActive(true)
+	smContext.SetUserLocation(models.UserLocation{NrLocation: &models.NrLocation{Tai: models.Tai{Tac: "000001"}}})
+	ue.StoreSmContext(5, smContext)
+
+	subscription := models.UeN1N2InfoSubscriptionCreateData{}
+	subscription.SetN1NotifyCallbackUri("http://smsf/n1-notify")
+	ue.N1N2MessageSubscription.Store(int64(1), subscription)
+	ue.N1N2Message = &N1N2Message{
+		Status:      models.N1N2MESSAGETRANSFERCAUSE_ATTEMPTING_TO_REACH_UE,
+		ResourceUri: "/namf-comm/v1/ue-contexts/" + supi + "/n1-n2-messages/1",
+	}
+
+	ue.T3560 = NewTimerFromState(time.Hour, TimerState{ExpireTimes: 2, MaxRetryTimes: 4, Payload: []byte{0x7e, 0x00, 0x56}},
+		func(int32) {}, func() {})
+
+	rec, err := NewUeContextRecord(ue)
+	if err != nil {
+		t.Fatalf("encode UE context: %v", err)
+	}
+	// leased by the failed instance until just now
+	rec.Owner, rec.LeaseExpiry = "amf-failed", time.Now()
+	if err = store.Put(ctxt.Background(), rec); err != nil {
+		t.Fatalf("store UE context: %v", err)
+	}
+
+	// the instance dies: timers stop and the in-memory context is gone
+	ue.T3560.Stop()
+	self.UePool.Delete(supi)
+	self.RanUePool.Delete(amfUeNgapID)
+
+	restored, ok := self.AmfUeFindByGuti(guti)
+	if !ok || restored == nil {
+		t.Fatal("expected UE context to be restored on lookup miss")
+	}
+	defer self.UePool.Delete(supi)
+	defer self.RanUePool.Delete(amfUeNgapID)
+
+	if !restored.State[models.ACCESSTYPE__3_GPP_ACCESS].Is(Authentication) {
+		t.Errorf("expected Authentication state, got %s", restored.State[models.ACCESSTYPE__3_GPP_ACCESS].Current())
+	}
+	if restored.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS).Procedure != OnGoingProcedureRegistration {
+		t.Errorf("expected ongoing registration, got %+v", restored.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS))
+	}
+	if restored.ULCount.Get() != ue.ULCount.Get() || restored.DLCount.Get() != ue.DLCount.Get() {
+		t.Errorf("NAS COUNTs not restored: UL %d DL %d", restored.ULCount.Get(), restored.DLCount.Get())
+	}
+
+	restoredRanUe := self.RanUeFindByAmfUeNgapID(amfUeNgapID)
+	if restoredRanUe == nil || restoredRanUe.AmfUe != restored || restoredRanUe.Ran != ran {
+		t.Fatalf("expected RanUe to be linked to the restored UE and its gNB, got %+v", restoredRanUe)
+	}
+	if restored.RanUe[models.ACCESSTYPE_NON_3_GPP_ACCESS] != nil {
+		t.Error("expected no non-3GPP RanUe to be created")
+	}
+
+	restoredSmContext, ok := restored.SmContextFindByPDUSessionID(5)
+	if !ok {
+		t.Fatal("expected SM context to be restored")
+	}
+	if restoredSmContext.Dnn() != "internet" || restoredSmContext.SmfUri() != "http://smf:29502" ||
+		restoredSmContext.IsPduSessionActive() || restoredSmContext.UserLocation().NrLocation == nil {
+		t.Errorf("SM context not fully restored: %+v", restoredSmContext)
+	}
+
+	value, ok := restored.N1N2MessageSubscription.Load(int64(1))
+	if !ok {
+		t.Fatal("expected N1N2 message subscription to be restored")
+	}
+	if restoredSubscription := value.(models.UeN1N2InfoSubscriptionCreateData); restoredSubscription.GetN1NotifyCallbackUri() != "http://smsf/n1-notify" {
+		t.Errorf("unexpected restored N1N2 message subscription %+v", restoredSubscription)
+	}
+	if id, err := restored.N1N2MessageSubscribeIDGenerator.Allocate(); err != nil || id == 1 {
+		t.Errorf("expected new subscription ID not to collide with restored one, got %d (%v)", id, err)
+	}
+	if restored.N1N2Message == nil || restored.N1N2Message.ResourceUri != ue.N1N2Message.ResourceUri {
+		t.Error("expected pending N1N2 message to be restored")
+	}
+
+	select {
+	case state := <-rearmed:
+		if state.ExpireTimes != 2 || state.MaxRetryTimes != 4 || len(state.Payload) != 3 {
+			t.Errorf("unexpected re-armed T3560 state %+v", state)
+		}
+	default:
+		t.Error("expected T3560 to be re-armed")
+	}
+}
@@ -6,8 +6,21 @@
 package context
 
 import (
+	ctxt "context"
 	"sync/atomic"
 	"time"
+
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// names of the UE timers persisted with the UE context
+const (
+	TimerT3513 = "t3513"
+	TimerT3522 = "t3522"
+	TimerT3550 = "t3550"
+	TimerT3555 = "t3555"
+	TimerT3560 = "t3560"
+	TimerT3565 = "t3565"
 )
 
 // Timer can be used for retransmission, it will manage retry times automatically
@@ -15,9 +28,66 @@ type Timer struct {
 	ticker        *time.Ticker
 	expireTimes   int32 // accessed atomically
 	maxRetryTimes int32 // accessed atomically
+	payload       []byte
 	done          chan bool
 }
 
+// TimerState is the part of a running Timer stored with the UE context, so that the
+// timer can be re-armed on another AMF instance after the UE context is restored.
+type TimerState struct {
+	ExpireTimes   int32  `json:"expireTimes"`
+	MaxRetryTimes int32  `json:"maxRetryTimes"`
+	Payload       []byte `json:"payload,omitempty"` // message retransmitted on expiry
+}
+
+// TimerRearmFunc restarts a UE timer from its stored state
+type TimerRearmFunc func(ue *AmfUe, state TimerState)
+
+var timerRearmFuncs = make(map[string]TimerRearmFunc)
+
+// RegisterTimerRearmFunc registers how the timer called name is restarted after the
+// UE context is restored from the DB. It must be called from an init function.
+func RegisterTimerRearmFunc(name string, fn TimerRearmFunc) {
+	timerRearmFuncs[name] = fn
+}
+
+// TimerAbortFunc ends the procedure of a UE guarded by timer, which expired for the last time
+type TimerAbortFunc func(ctx ctxt.Context, ue *AmfUe, anType models.AccessType, timer *Timer)
+
+var timerAbortFuncs = make(map[string]TimerAbortFunc)
+
+// RegisterTimerAbortFunc registers how the procedure guarded by the timer called name is
+// ended when the timer expires for the last time, see SubmitTimerAbort. It must be called
+// from an init function.
+func RegisterTimerAbortFunc(name string, fn TimerAbortFunc) {
+	timerAbortFuncs[name] = fn
+}
+
+// TimerAbortMsg is the last expiry of a UE timer, handled on the EventChannel of the UE
+type TimerAbortMsg struct {
+	Name   string
+	Timer  *Timer
+	AnType models.AccessType
+}
+
+// SubmitTimerAbort queues the last expiry of timer, called name, on the EventChannel of the
+// UE, so that the procedure it guards is ended on the event loop of the UE rather than on
+// the goroutine of the timer.
+func (ue *AmfUe) SubmitTimerAbort(name string, timer *Timer, anType models.AccessType) {
+	msg := TimerAbortMsg{Name: name, Timer: timer, AnType: anType}
+	if ue.EventChannel == nil {
+		handleTimerAbort(ctxt.Background(), ue, msg)
+		return
+	}
+	ue.EventChannel.SubmitMessage(msg)
+}
+
+func handleTimerAbort(ctx ctxt.Context, ue *AmfUe, msg TimerAbortMsg) {
+	if abort, ok := timerAbortFuncs[msg.Name]; ok {
+		abort(ctx, ue, msg.AnType, msg.Timer)
+	}
+}
+
 // NewTimer will return a Timer struct and create a goroutine. Then it calls expiredFunc every time interval d until
 // the user call Stop(). the number of expire event is be recorded when the timer is active. When the number of expire
 // event is > maxRetryTimes, then the timer will call cancelFunc and turns off itself. Whether expiredFunc pass a
@@ -26,9 +96,18 @@ func NewTimer(d time.Duration, maxRetryTimes int,
 	expiredFunc func(expireTimes int32),
 	cancelFunc func(),
 ) *Timer {
-	t := &Timer{}
-	atomic.StoreInt32(&t.expireTimes, 0)
-	atomic.StoreInt32(&t.maxRetryTimes, int32(maxRetryTimes))
+	return NewTimerFromState(d, TimerState{MaxRetryTimes: int32(maxRetryTimes)}, expiredFunc, cancelFunc)
+}
+
+// NewTimerFromState works like NewTimer but starts counting from state.ExpireTimes and
+// remembers state.Payload, the message it retransmits, for persistence.
+func NewTimerFromState(d time.Duration, state TimerState,
+	expiredFunc func(expireTimes int32),
+	cancelFunc func(),
+) *Timer {
+	t := &Timer{payload: state.Payload}
+	atomic.StoreInt32(&t.expireTimes, state.ExpireTimes)
+	atomic.StoreInt32(&t.maxRetryTimes, state.MaxRetryTimes)
 	t.done = make(chan bool, 1)
 	t.ticker = time.NewTicker(d)
 
@@ -64,6 +143,15 @@ func (t *Timer) ExpireTimes() int32 {
 	return atomic.LoadInt32(&t.expireTimes)
 }
 
+// State returns the persistable state of the timer
+func (t *Timer) State() TimerState {
+	return TimerState{
+		ExpireTimes:   t.ExpireTimes(),
+		MaxRetryTimes: t.MaxRetryTimes(),
+		Payload:       t.payload,
+	}
+}
+
 // Stop turns off the timer, after Stop, no more timeout event will be triggered. User should call Stop() only once
 // otherwise it may hang on writing to done channel
 func (t *Timer) Stop() {
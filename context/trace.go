@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/tracerecording"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+var trsrCounter atomic.Uint32
+
+// allocateTrsr returns a Trace Recording Session Reference, unique among the trace
+// recording sessions of the AMF while fewer than 65536 are active (TS 32.422 5.7)
+func allocateTrsr() string {
+	return fmt.Sprintf("%04x", uint16(trsrCounter.Add(1)))
+}
+
+// ActivateTrace starts the trace session of the subscriber or equipment trace data provided by
+// the UDM (TS 32.422 4.2.2.9). A new Trace Recording Session Reference is allocated unless the
+// trace reference is unchanged, and the NAS and NGAP messages of the UE are recorded when
+// signalling trace is enabled. It reports whether a new trace recording session was started.
+func (ue *AmfUe) ActivateTrace(traceData *models.TraceData) bool {
+	if traceData == nil {
+		ue.DeactivateTrace()
+		return false
+	}
+	if ue.TraceData != nil && ue.TraceData.TraceRef == traceData.TraceRef && ue.TraceRecordingSessionRef != "" {
+		ue.TraceData = traceData
+		return false
+	}
+	ue.closeTraceSession()
+	ue.TraceData = traceData
+	ue.TraceRecordingSessionRef = allocateTrsr()
+
+	if cfg := AMF_Self().SignallingTrace; cfg != nil {
+		depth := traceData.GetTraceDepth()
+		session, err := tracerecording.NewSession(cfg.Directory, cfg.Format, tracerecording.SessionInfo{
+			TraceRef: traceData.TraceRef,
+			Trsr:     ue.TraceRecordingSessionRef,
+			Supi:     ue.Supi,
+			Pei:      ue.Pei,
+			MessageNamesOnly: depth == models.TRACEDEPTH_MINIMUM ||
+				depth == models.TRACEDEPTH_MINIMUM_WO_VENDOR_EXTENSION,
+		})
+		if err != nil {
+			logger.ContextLog.Errorf("start trace recording session of UE[%s] failed: %+v", ue.Supi, err)
+		} else {
+			ue.traceMutex.Lock()
+			ue.traceSession = session
+			ue.traceMutex.Unlock()
+			logger.ContextLog.Infof("trace recording session of UE[%s] started: %s", ue.Supi, session.Path)
+		}
+	}
+	return true
+}
+
+// DeactivateTrace ends the trace session of the UE.
+func (ue *AmfUe) DeactivateTrace() {
+	ue.closeTraceSession()
+	ue.TraceData = nil
+	ue.TraceRecordingSessionRef = ""
+}
+
+// RecordTrace adds a NAS or NGAP message of the UE to its trace recording session, if any,
+// and to its flight recorder.
+func (ue *AmfUe) RecordTrace(protocol tracerecording.Protocol, direction tracerecording.Direction,
+	name string, payload []byte,
+) {
+	kind := FlightRecordNas
+	if protocol == tracerecording.ProtocolNGAP {
+		kind = FlightRecordNgap
+	}
+	ue.RecordFlight(FlightRecord{Kind: kind, Direction: flightRecordDirection(direction), Name: name})
+
+	ue.traceMutex.Lock()
+	session := ue.traceSession
+	ue.traceMutex.Unlock()
+	if session == nil {
+		return
+	}
+	if err := session.Record(tracerecording.Record{
+		Protocol:  protocol,
+		Direction: direction,
+		Name:      name,
+		Payload:   payload,
+	}); err != nil {
+		logger.ContextLog.Warnf("record %s message of UE[%s] failed: %+v", name, ue.Supi, err)
+	}
+}
+
+// RecordNasTrace adds a plain 5GMM NAS message of the UE to its trace recording session and
+// to the packet captures that decrypt NAS.
+func (ue *AmfUe) RecordNasTrace(direction tracerecording.Direction, plainNas []byte) {
+	ue.capturePlainNas(direction, plainNas)
+	ue.RecordTrace(tracerecording.ProtocolNAS, direction, tracerecording.NasMessageName(plainNas), plainNas)
+}
+
+// RecordNgapTrace adds an APER encoded NGAP PDU of the UE to its trace recording session.
+func (ue *AmfUe) RecordNgapTrace(direction tracerecording.Direction, packet []byte) {
+	if len(packet) < 2 {
+		return
+	}
+	// the NGAP-PDU choice is followed by the procedure code
+	name := ngapType.ProcedureName(int64(packet[1]))
+	switch packet[0] >> 5 {
+	case 1:
+		name += "SuccessfulOutcome"
+	case 2:
+		name += "UnsuccessfulOutcome"
+	}
+	ue.RecordTrace(tracerecording.ProtocolNGAP, direction, name, packet)
+}
+
+// RecordCellTrafficTrace adds the identity of the UE and the cell and Trace Collection Entity
+// reported by the NG-RAN to the trace recording session of the UE (TS 32.422 4.2.2.10). An
+// NG-RAN trace without a session at the AMF gets its own trace collection file.
+func (ue *AmfUe) RecordCellTrafficTrace(traceRef, trsr string, cellTrafficTrace tracerecording.CellTrafficTrace) {
+	cellTrafficTrace.Supi, cellTrafficTrace.Pei = ue.Supi, ue.Pei
+
+	ue.traceMutex.Lock()
+	session := ue.traceSession
+	ue.traceMutex.Unlock()
+	if session == nil || session.Info.Trsr != trsr {
+		cfg := AMF_Self().SignallingTrace
+		if cfg == nil {
+			return
+		}
+		var err error
+		session, err = tracerecording.NewSession(cfg.Directory, cfg.Format, tracerecording.SessionInfo{
+			TraceRef: traceRef,
+			Trsr:     trsr,
+			Supi:     ue.Supi,
+			Pei:      ue.Pei,
+		})
+		if err != nil {
+			logger.ContextLog.Errorf("create trace collection file of UE[%s] failed: %+v", ue.Supi, err)
+			return
+		}
+		defer func() {
+			if err := session.Close(); err != nil {
+				logger.ContextLog.Warnf("close trace collection file of UE[%s] failed: %+v", ue.Supi, err)
+			}
+		}()
+	}
+	if err := session.RecordCellTrafficTrace(cellTrafficTrace); err != nil {
+		logger.ContextLog.Warnf("record cell traffic trace of UE[%s] failed: %+v", ue.Supi, err)
+	}
+}
+
+func (ue *AmfUe) closeTraceSession() {
+	ue.traceMutex.Lock()
+	session := ue.traceSession
+	ue.traceSession = nil
+	ue.traceMutex.Unlock()
+	if session == nil {
+		return
+	}
+	if err := session.Close(); err != nil {
+		logger.ContextLog.Warnf("close trace recording session of UE[%s] failed: %+v", ue.Supi, err)
+	}
+}
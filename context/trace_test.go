@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/amf/tracerecording"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+func TestActivateTrace(t *testing.T) {
+	self := AMF_Self()
+	origSignallingTrace := self.SignallingTrace
+	t.Cleanup(func() { self.SignallingTrace = origSignallingTrace })
+	self.SignallingTrace = &factory.SignallingTraceConfig{Enabled: true, Directory: t.TempDir(), Format: "xml"}
+
+	ue := &AmfUe{Supi: "imsi-001010000000001"}
+	if !ue.ActivateTrace(&models.TraceData{TraceRef: "00101-abcdef"}) {
+		t.Fatal("expected a new trace recording session")
+	}
+	trsr := ue.TraceRecordingSessionRef
+	if len(trsr) != 4 {
+		t.Fatalf("unexpected TRSR %q", trsr)
+	}
+	if ue.ActivateTrace(&models.TraceData{TraceRef: "00101-abcdef"}) || ue.TraceRecordingSessionRef != trsr {
+		t.Fatal("expected the trace recording session to continue for the same trace reference")
+	}
+
+	session := ue.traceSession
+	ue.RecordNasTrace(tracerecording.Uplink, []byte{0x7e, 0x00, 0x41})
+	ue.RecordNgapTrace(tracerecording.Downlink, []byte{0x00, 0x0e, 0x00})
+	ue.DeactivateTrace()
+	if ue.TraceData != nil || ue.TraceRecordingSessionRef != "" || ue.traceSession != nil {
+		t.Fatal("expected trace to be deactivated")
+	}
+
+	content, err := os.ReadFile(session.Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{`name="RegistrationRequest"`, `name="InitialContextSetup"`, "</traceCollecFile>"} {
+		if !strings.Contains(string(content), name) {
+			t.Errorf("expected %s in trace collection file:\n%s", name, content)
+		}
+	}
+}
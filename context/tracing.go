@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("amf/context")
+
+// StartRegistrationSpan starts the UE-level span covering a registration procedure, from the
+// Registration Request to the Registration Accept or Reject. The span is a child of the span
+// of the Registration Request in ctx; the NAS spans of the later messages of the procedure
+// link to it. A registration span that is still running is ended first.
+func (ue *AmfUe) StartRegistrationSpan(ctx ctxt.Context) {
+	procedure := KpiRegistrationProcedure(ue.GetRegistrationType5GS())
+	_, span := tracer.Start(ctx, "AMF UE Registration",
+		trace.WithAttributes(attribute.String("amf.registrationType", string(procedure))),
+	)
+
+	ue.registrationSpanMu.Lock()
+	previous := ue.registrationSpan
+	ue.registrationSpan = span
+	ue.registrationSpanMu.Unlock()
+
+	if previous != nil {
+		previous.SetStatus(codes.Error, "restarted")
+		previous.End()
+	}
+}
+
+// EndRegistrationSpan ends the span started with StartRegistrationSpan, as failed with cause
+// if it is not empty. It does nothing if no registration is running.
+func (ue *AmfUe) EndRegistrationSpan(cause string) {
+	ue.registrationSpanMu.Lock()
+	span := ue.registrationSpan
+	ue.registrationSpan = nil
+	ue.registrationSpanMu.Unlock()
+
+	if span == nil {
+		return
+	}
+	if cause != "" {
+		span.SetStatus(codes.Error, cause)
+	}
+	span.End()
+}
+
+// RegistrationSpanContext returns the span context of the running registration span, or an
+// invalid span context if no registration is running.
+func (ue *AmfUe) RegistrationSpanContext() trace.SpanContext {
+	ue.registrationSpanMu.Lock()
+	defer ue.registrationSpanMu.Unlock()
+
+	if ue.registrationSpan == nil {
+		return trace.SpanContext{}
+	}
+	return ue.registrationSpan.SpanContext()
+}
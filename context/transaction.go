@@ -46,11 +46,20 @@ func (tx *EventChannel) Start(ctx context.Context) {
 		case msg := <-tx.Message:
 			switch msg := msg.(type) {
 			case NasMsg:
+				msg.Context = WithFlightRecorderUe(msg.Context, tx.AmfUe)
 				tx.NasHandler(tx.AmfUe, msg)
 			case NgapMsg:
+				msg.Context = WithFlightRecorderUe(msg.Context, tx.AmfUe)
 				tx.NgapHandler(tx.AmfUe, msg)
 			case SbiMsg:
-				p_1, p_2, p_3, p_4 := tx.SbiHandler(ctx, msg.UeContextId, msg.ReqUri, msg.Msg)
+				sbiCtx := ctx
+				if msg.Context != nil {
+					// keep the trace context of the SBI request, procedures started by the
+					// handler may outlive the request
+					sbiCtx = context.WithoutCancel(msg.Context)
+				}
+				sbiCtx = WithFlightRecorderUe(sbiCtx, tx.AmfUe)
+				p_1, p_2, p_3, p_4 := tx.SbiHandler(sbiCtx, msg.UeContextId, msg.ReqUri, msg.Msg)
 				res := SbiResponseMsg{
 					RespData:       p_1,
 					LocationHeader: p_2,
@@ -59,7 +68,9 @@ func (tx *EventChannel) Start(ctx context.Context) {
 				}
 				msg.Result <- res
 			case ConfigMsg:
-				tx.ConfigHandler(ctx, msg.Supi, msg.Sst, msg.Sd, msg.Msg)
+				tx.ConfigHandler(WithFlightRecorderUe(ctx, tx.AmfUe), msg.Supi, msg.Sst, msg.Sd, msg.Msg)
+			case TimerAbortMsg:
+				handleTimerAbort(WithFlightRecorderUe(ctx, tx.AmfUe), tx.AmfUe, msg)
 			}
 		case event := <-tx.Event:
 			if event == "quit" {
@@ -71,5 +82,14 @@ func (tx *EventChannel) Start(ctx context.Context) {
 }
 
 func (tx *EventChannel) SubmitMessage(msg any) {
-	tx.Message <- msg
+	select {
+	case tx.Message <- msg:
+		observeEventQueueFill(len(tx.Message), cap(tx.Message))
+	default:
+		// the queue is full, overload control sees it as such until the message is queued
+		blockedEventSubmits.Add(1)
+		tx.Message <- msg
+		blockedEventSubmits.Add(-1)
+		observeEventQueueFill(cap(tx.Message), cap(tx.Message))
+	}
 }
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"bytes"
+	ctxt "context"
+	"errors"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+var (
+	ErrUeContextNotFound = errors.New("UE context not found")
+	// UE contexts are not persisted, there is no shared store to hand them over through
+	ErrUeContextStoreDisabled = errors.New("UE context store disabled")
+	errEmptyUeContextKey      = errors.New("empty UE context key")
+)
+
+// UeContextRecord is a persisted AmfUe together with the identities it can be looked up by
+// and the AMF instance that currently owns it.
+type UeContextRecord struct {
+	Supi        string
+	Guti        string
+	AmfUeNgapId int64
+	RanId       string
+	RanUeNgapId int64
+	Data        []byte // JSON encoded AmfUe
+
+	Owner       string
+	LeaseExpiry time.Time
+}
+
+// UeContextKey selects a stored UE context by the first identity that is set: SUPI, GUTI,
+// AMF UE NGAP ID, or RAN UE NGAP ID together with RanId.
+type UeContextKey struct {
+	Supi        string
+	Guti        string
+	AmfUeNgapId int64
+	RanId       string
+	RanUeNgapId int64
+}
+
+func (key UeContextKey) isEmpty() bool {
+	return key.Supi == "" && key.Guti == "" && key.AmfUeNgapId == 0 && key.RanId == ""
+}
+
+// UeContextStore persists AmfUe contexts so that any AMF instance can restore them.
+type UeContextStore interface {
+	// Get returns ErrUeContextNotFound if no context matches key
+	Get(ctx ctxt.Context, key UeContextKey) (*UeContextRecord, error)
+	// Put creates or replaces the context stored for rec.Supi
+	Put(ctx ctxt.Context, rec *UeContextRecord) error
+	Delete(ctx ctxt.Context, supi string) error
+	// ListBySupi returns the contexts whose SUPI starts with prefix, ordered by SUPI;
+	// an empty prefix lists every context
+	ListBySupi(ctx ctxt.Context, prefix string) ([]*UeContextRecord, error)
+	// Query returns at most query.Limit contexts selected by the filters of query whose SUPI
+	// follows query.Cursor, ordered by SUPI
+	Query(ctx ctxt.Context, query UeQuery) ([]*UeContextRecord, error)
+	// LeaseOwnership makes owner the owner of the context for ttl. It is not granted while
+	// another owner holds an unexpired lease; the current owner may always renew.
+	LeaseOwnership(ctx ctxt.Context, supi, owner string, ttl time.Duration) (bool, error)
+	Close() error
+}
+
+// ueContextRecordKeys are the parts of the JSON encoded AmfUe a record is indexed by
+type ueContextRecordKeys struct {
+	Supi   string `json:"supi"`
+	Guti   string `json:"guti"`
+	Custom struct {
+		AmfUeNgapId int64  `json:"amfUeNgapId"`
+		RanUeNgapId int64  `json:"ranUeNgapId"`
+		RanId       string `json:"ranId"`
+	} `json:"customFieldsAmfUe"`
+}
+
+// newUeContextRecordFromJSON builds a record from a JSON encoded AmfUe.
+func newUeContextRecordFromJSON(data []byte) (*UeContextRecord, error) {
+	keys := ueContextRecordKeys{}
+	if err := sonic.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	return &UeContextRecord{
+		Supi:        keys.Supi,
+		Guti:        keys.Guti,
+		AmfUeNgapId: keys.Custom.AmfUeNgapId,
+		RanId:       keys.Custom.RanId,
+		RanUeNgapId: keys.Custom.RanUeNgapId,
+		Data:        data,
+	}, nil
+}
+
+// NewUeContextRecord snapshots ue; it must be called from the UE EventChannel (or before
+// the context is shared) so that the snapshot is consistent.
+func NewUeContextRecord(ue *AmfUe) (*UeContextRecord, error) {
+	buf := amfJSONBufPool.Get().(*bytes.Buffer)
+	defer amfJSONBufPool.Put(buf)
+	buf.Reset()
+	if err := sonic.ConfigDefault.NewEncoder(buf).Encode(ue); err != nil {
+		return nil, err
+	}
+	return newUeContextRecordFromJSON(bytes.Clone(buf.Bytes()))
+}
+
+// leaseAvailable reports whether owner may take or renew the lease of rec at now.
+func (rec *UeContextRecord) leaseAvailable(owner string, now time.Time) bool {
+	return rec.Owner == "" || rec.Owner == owner || !now.Before(rec.LeaseExpiry)
+}
@@ -0,0 +1,293 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"bytes"
+	ctxt "context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bytedance/sonic"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltContextsBucket    = []byte("ueContexts")
+	boltGutiBucket        = []byte("guti")
+	boltAmfUeNgapIdBucket = []byte("amfUeNgapId")
+	boltRanUeBucket       = []byte("ranUe")
+	boltLeasesBucket      = []byte("leases")
+	// the summaries the UE queries filter on, so that a query only decodes the selected contexts
+	boltSummariesBucket = []byte("summaries")
+)
+
+type boltLease struct {
+	Owner  string    `json:"owner"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// boltUeContextStore keeps UE contexts in an embedded bbolt database file, for single-node
+// deployments without MongoDB. Secondary identities are kept in index buckets that map
+// to the SUPI.
+type boltUeContextStore struct {
+	db *bolt.DB
+}
+
+// NewBoltUeContextStore opens (or creates) the database file at path.
+func NewBoltUeContextStore(path string) (UeContextStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			boltContextsBucket, boltGutiBucket, boltAmfUeNgapIdBucket, boltRanUeBucket, boltLeasesBucket,
+			boltSummariesBucket,
+		} {
+			if _, bucketErr := tx.CreateBucketIfNotExists(name); bucketErr != nil {
+				return bucketErr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltUeContextStore{db: db}, nil
+}
+
+func boltAmfUeNgapIdKey(amfUeNgapId int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(amfUeNgapId))
+}
+
+func boltRanUeKey(ranId string, ranUeNgapId int64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(ranId), 0), uint64(ranUeNgapId))
+}
+
+// boltIndexKeys returns the index bucket and key pairs of rec
+func boltIndexKeys(rec *UeContextRecord) (buckets, keys [][]byte) {
+	if rec.Guti != "" {
+		buckets, keys = append(buckets, boltGutiBucket), append(keys, []byte(rec.Guti))
+	}
+	if rec.AmfUeNgapId != 0 {
+		buckets, keys = append(buckets, boltAmfUeNgapIdBucket), append(keys, boltAmfUeNgapIdKey(rec.AmfUeNgapId))
+	}
+	if rec.RanId != "" {
+		buckets, keys = append(buckets, boltRanUeBucket), append(keys, boltRanUeKey(rec.RanId, rec.RanUeNgapId))
+	}
+	return buckets, keys
+}
+
+func boltRecord(tx *bolt.Tx, supi []byte) (*UeContextRecord, error) {
+	data := tx.Bucket(boltContextsBucket).Get(supi)
+	if data == nil {
+		return nil, ErrUeContextNotFound
+	}
+	rec, err := newUeContextRecordFromJSON(bytes.Clone(data))
+	if err != nil {
+		return nil, err
+	}
+	if leaseData := tx.Bucket(boltLeasesBucket).Get(supi); leaseData != nil {
+		lease := boltLease{}
+		if err = sonic.Unmarshal(leaseData, &lease); err != nil {
+			return nil, err
+		}
+		rec.Owner, rec.LeaseExpiry = lease.Owner, lease.Expiry
+	}
+	return rec, nil
+}
+
+func boltPutSummary(tx *bolt.Tx, supi []byte, rec *UeContextRecord) error {
+	summary, err := rec.summary()
+	if err != nil {
+		return err
+	}
+	data, err := sonic.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(boltSummariesBucket).Put(supi, data)
+}
+
+// boltSummary returns the summary of the context stored for supi, decoded from the context
+// itself if it was stored without one
+func boltSummary(tx *bolt.Tx, supi []byte) (UeSummary, error) {
+	data := tx.Bucket(boltSummariesBucket).Get(supi)
+	if data == nil {
+		rec, err := boltRecord(tx, supi)
+		if err != nil {
+			return UeSummary{}, err
+		}
+		return rec.summary()
+	}
+	summary := UeSummary{}
+	err := sonic.Unmarshal(data, &summary)
+	return summary, err
+}
+
+func boltPutLease(tx *bolt.Tx, supi []byte, owner string, expiry time.Time) error {
+	data, err := sonic.Marshal(boltLease{Owner: owner, Expiry: expiry})
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(boltLeasesBucket).Put(supi, data)
+}
+
+// boltUnindex removes the context stored for supi from the index buckets
+func boltUnindex(tx *bolt.Tx, supi []byte) error {
+	old, err := boltRecord(tx, supi)
+	if err == ErrUeContextNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	buckets, keys := boltIndexKeys(old)
+	for i, bucket := range buckets {
+		b := tx.Bucket(bucket)
+		if bytes.Equal(b.Get(keys[i]), supi) {
+			if err = b.Delete(keys[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *boltUeContextStore) Get(ctx ctxt.Context, key UeContextKey) (rec *UeContextRecord, err error) {
+	if key.isEmpty() {
+		return nil, errEmptyUeContextKey
+	}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		var supi []byte
+		switch {
+		case key.Supi != "":
+			supi = []byte(key.Supi)
+		case key.Guti != "":
+			supi = tx.Bucket(boltGutiBucket).Get([]byte(key.Guti))
+		case key.AmfUeNgapId != 0:
+			supi = tx.Bucket(boltAmfUeNgapIdBucket).Get(boltAmfUeNgapIdKey(key.AmfUeNgapId))
+		default:
+			supi = tx.Bucket(boltRanUeBucket).Get(boltRanUeKey(key.RanId, key.RanUeNgapId))
+		}
+		if supi == nil {
+			return ErrUeContextNotFound
+		}
+		var recErr error
+		rec, recErr = boltRecord(tx, supi)
+		return recErr
+	})
+	return rec, err
+}
+
+func (s *boltUeContextStore) Put(ctx ctxt.Context, rec *UeContextRecord) error {
+	if rec.Supi == "" {
+		return errEmptyUeContextKey
+	}
+	supi := []byte(rec.Supi)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := boltUnindex(tx, supi); err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltContextsBucket).Put(supi, rec.Data); err != nil {
+			return err
+		}
+		if err := boltPutSummary(tx, supi, rec); err != nil {
+			return err
+		}
+		buckets, keys := boltIndexKeys(rec)
+		for i, bucket := range buckets {
+			if err := tx.Bucket(bucket).Put(keys[i], supi); err != nil {
+				return err
+			}
+		}
+		return boltPutLease(tx, supi, rec.Owner, rec.LeaseExpiry)
+	})
+}
+
+func (s *boltUeContextStore) Delete(ctx ctxt.Context, supi string) error {
+	key := []byte(supi)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := boltUnindex(tx, key); err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltLeasesBucket).Delete(key); err != nil {
+			return err
+		}
+		if err := tx.Bucket(boltSummariesBucket).Delete(key); err != nil {
+			return err
+		}
+		return tx.Bucket(boltContextsBucket).Delete(key)
+	})
+}
+
+func (s *boltUeContextStore) ListBySupi(ctx ctxt.Context, prefix string) (records []*UeContextRecord, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltContextsBucket).Cursor()
+		for supi, _ := c.Seek([]byte(prefix)); supi != nil && bytes.HasPrefix(supi, []byte(prefix)); supi, _ = c.Next() {
+			rec, recErr := boltRecord(tx, supi)
+			if recErr != nil {
+				return recErr
+			}
+			records = append(records, rec)
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (s *boltUeContextStore) Query(ctx ctxt.Context, query UeQuery) (records []*UeContextRecord, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltContextsBucket).Cursor()
+		supi, _ := c.Seek([]byte(query.Cursor))
+		if supi != nil && string(supi) == query.Cursor {
+			supi, _ = c.Next()
+		}
+		for ; supi != nil && len(records) < query.Limit; supi, _ = c.Next() {
+			summary, recErr := boltSummary(tx, supi)
+			if recErr != nil {
+				return recErr
+			}
+			if !query.matches(summary) {
+				continue
+			}
+			rec, recErr := boltRecord(tx, supi)
+			if recErr != nil {
+				return recErr
+			}
+			records = append(records, rec)
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (s *boltUeContextStore) LeaseOwnership(ctx ctxt.Context, supi, owner string, ttl time.Duration) (granted bool, err error) {
+	key := []byte(supi)
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		rec, recErr := boltRecord(tx, key)
+		if recErr != nil {
+			return recErr
+		}
+		now := time.Now()
+		if !rec.leaseAvailable(owner, now) {
+			return nil
+		}
+		granted = true
+		return boltPutLease(tx, key, owner, now.Add(ttl))
+	})
+	return granted, err
+}
+
+func (s *boltUeContextStore) Close() error {
+	return s.db.Close()
+}
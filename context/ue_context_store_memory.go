@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ranUeKey struct {
+	ranId       string
+	ranUeNgapId int64
+}
+
+// memoryUeContextStore keeps UE contexts in process memory. Contexts do not survive a
+// restart, so it is meant for tests and for deployments that only need a single instance.
+type memoryUeContextStore struct {
+	mu            sync.RWMutex
+	records       map[string]*UeContextRecord
+	byGuti        map[string]string
+	byAmfUeNgapId map[int64]string
+	byRanUe       map[ranUeKey]string
+	// the SUPIs of records in order, and the summaries the UE queries filter on, decoded
+	// once per Put instead of on every query
+	supis     []string
+	summaries map[string]UeSummary
+}
+
+func NewMemoryUeContextStore() UeContextStore {
+	return &memoryUeContextStore{
+		records:       make(map[string]*UeContextRecord),
+		byGuti:        make(map[string]string),
+		byAmfUeNgapId: make(map[int64]string),
+		byRanUe:       make(map[ranUeKey]string),
+		summaries:     make(map[string]UeSummary),
+	}
+}
+
+func (s *memoryUeContextStore) lookup(key UeContextKey) (string, bool) {
+	switch {
+	case key.Supi != "":
+		return key.Supi, true
+	case key.Guti != "":
+		supi, ok := s.byGuti[key.Guti]
+		return supi, ok
+	case key.AmfUeNgapId != 0:
+		supi, ok := s.byAmfUeNgapId[key.AmfUeNgapId]
+		return supi, ok
+	default:
+		supi, ok := s.byRanUe[ranUeKey{key.RanId, key.RanUeNgapId}]
+		return supi, ok
+	}
+}
+
+func (s *memoryUeContextStore) Get(ctx ctxt.Context, key UeContextKey) (*UeContextRecord, error) {
+	if key.isEmpty() {
+		return nil, errEmptyUeContextKey
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if supi, ok := s.lookup(key); ok {
+		if rec, ok := s.records[supi]; ok {
+			recCopy := *rec
+			return &recCopy, nil
+		}
+	}
+	return nil, ErrUeContextNotFound
+}
+
+func (s *memoryUeContextStore) Put(ctx ctxt.Context, rec *UeContextRecord) error {
+	if rec.Supi == "" {
+		return errEmptyUeContextKey
+	}
+	recCopy := *rec
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unindex(rec.Supi)
+	if i, found := slices.BinarySearch(s.supis, rec.Supi); !found {
+		s.supis = slices.Insert(s.supis, i, rec.Supi)
+	}
+	s.records[rec.Supi] = &recCopy
+	if summary, err := rec.summary(); err == nil {
+		s.summaries[rec.Supi] = summary
+	}
+	if rec.Guti != "" {
+		s.byGuti[rec.Guti] = rec.Supi
+	}
+	if rec.AmfUeNgapId != 0 {
+		s.byAmfUeNgapId[rec.AmfUeNgapId] = rec.Supi
+	}
+	if rec.RanId != "" {
+		s.byRanUe[ranUeKey{rec.RanId, rec.RanUeNgapId}] = rec.Supi
+	}
+	return nil
+}
+
+// unindex removes the secondary index entries of the context stored for supi; callers hold mu
+func (s *memoryUeContextStore) unindex(supi string) {
+	old, ok := s.records[supi]
+	if !ok {
+		return
+	}
+	delete(s.summaries, supi)
+	if s.byGuti[old.Guti] == supi {
+		delete(s.byGuti, old.Guti)
+	}
+	if s.byAmfUeNgapId[old.AmfUeNgapId] == supi {
+		delete(s.byAmfUeNgapId, old.AmfUeNgapId)
+	}
+	if key := (ranUeKey{old.RanId, old.RanUeNgapId}); s.byRanUe[key] == supi {
+		delete(s.byRanUe, key)
+	}
+}
+
+func (s *memoryUeContextStore) Delete(ctx ctxt.Context, supi string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unindex(supi)
+	if i, found := slices.BinarySearch(s.supis, supi); found {
+		s.supis = slices.Delete(s.supis, i, i+1)
+	}
+	delete(s.records, supi)
+	return nil
+}
+
+func (s *memoryUeContextStore) ListBySupi(ctx ctxt.Context, prefix string) ([]*UeContextRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var records []*UeContextRecord
+	for supi, rec := range s.records {
+		if strings.HasPrefix(supi, prefix) {
+			recCopy := *rec
+			records = append(records, &recCopy)
+		}
+	}
+	slices.SortFunc(records, func(a, b *UeContextRecord) int {
+		return strings.Compare(a.Supi, b.Supi)
+	})
+	return records, nil
+}
+
+func (s *memoryUeContextStore) Query(ctx ctxt.Context, query UeQuery) ([]*UeContextRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, found := slices.BinarySearch(s.supis, query.Cursor)
+	if found {
+		i++
+	}
+	var records []*UeContextRecord
+	for _, supi := range s.supis[i:] {
+		if len(records) == query.Limit {
+			break
+		}
+		summary, ok := s.summaries[supi]
+		if !ok {
+			var err error
+			if summary, err = s.records[supi].summary(); err != nil {
+				return nil, err
+			}
+		}
+		if query.matches(summary) {
+			recCopy := *s.records[supi]
+			records = append(records, &recCopy)
+		}
+	}
+	return records, nil
+}
+
+func (s *memoryUeContextStore) LeaseOwnership(ctx ctxt.Context, supi, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[supi]
+	if !ok {
+		return false, ErrUeContextNotFound
+	}
+	now := time.Now()
+	if !rec.leaseAvailable(owner, now) {
+		return false, nil
+	}
+	rec.Owner, rec.LeaseExpiry = owner, now.Add(ttl)
+	return true, nil
+}
+
+func (s *memoryUeContextStore) Close() error {
+	return nil
+}
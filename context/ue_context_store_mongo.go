@@ -0,0 +1,271 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/mongoapi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// lease fields added to the UE context documents
+const (
+	leaseOwnerField  = "leaseOwner"
+	leaseExpiryField = "leaseExpiry" // unix milliseconds
+)
+
+// mongoUeContextStore keeps one document per UE in a MongoDB collection, shared by all
+// AMF instances of the set.
+type mongoUeContextStore struct {
+	client     mongoapi.DBInterface
+	collection string
+}
+
+func NewMongoUeContextStore(client mongoapi.DBInterface, collection string) UeContextStore {
+	return &mongoUeContextStore{client: client, collection: collection}
+}
+
+func mongoUeContextFilter(key UeContextKey) bson.M {
+	switch {
+	case key.Supi != "":
+		return bson.M{"supi": key.Supi}
+	case key.Guti != "":
+		return bson.M{"guti": key.Guti}
+	case key.AmfUeNgapId != 0:
+		return bson.M{"customFieldsAmfUe.amfUeNgapId": key.AmfUeNgapId}
+	default:
+		return bson.M{
+			"customFieldsAmfUe.ranUeNgapId": key.RanUeNgapId,
+			"customFieldsAmfUe.ranId":       key.RanId,
+		}
+	}
+}
+
+func mongoUeContextRecord(doc map[string]any) (*UeContextRecord, error) {
+	owner, _ := doc[leaseOwnerField].(string)
+	var expiry int64
+	switch v := doc[leaseExpiryField].(type) {
+	case int64:
+		expiry = v
+	case int32:
+		expiry = int64(v)
+	case float64:
+		expiry = int64(v)
+	}
+	delete(doc, "_id")
+	delete(doc, leaseOwnerField)
+	delete(doc, leaseExpiryField)
+	rec, err := newUeContextRecordFromJSON(mapToByte(doc))
+	if err != nil {
+		return nil, err
+	}
+	rec.Owner = owner
+	if expiry != 0 {
+		rec.LeaseExpiry = time.UnixMilli(expiry)
+	}
+	return rec, nil
+}
+
+func (s *mongoUeContextStore) Get(ctx ctxt.Context, key UeContextKey) (*UeContextRecord, error) {
+	if key.isEmpty() {
+		return nil, errEmptyUeContextKey
+	}
+	doc, err := s.client.RestfulAPIGetOne(s.collection, mongoUeContextFilter(key))
+	if err != nil {
+		return nil, err
+	}
+	if len(doc) == 0 {
+		return nil, ErrUeContextNotFound
+	}
+	return mongoUeContextRecord(doc)
+}
+
+func (s *mongoUeContextStore) Put(ctx ctxt.Context, rec *UeContextRecord) error {
+	if rec.Supi == "" {
+		return errEmptyUeContextKey
+	}
+	var doc bson.M
+	if err := sonic.Unmarshal(rec.Data, &doc); err != nil {
+		return err
+	}
+	doc[leaseOwnerField] = rec.Owner
+	if !rec.LeaseExpiry.IsZero() {
+		doc[leaseExpiryField] = rec.LeaseExpiry.UnixMilli()
+	}
+	_, err := s.client.RestfulAPIPostWithContext(ctx, s.collection, bson.M{"supi": rec.Supi}, doc)
+	return err
+}
+
+func (s *mongoUeContextStore) Delete(ctx ctxt.Context, supi string) error {
+	return s.client.RestfulAPIDeleteOneWithContext(ctx, s.collection, bson.M{"supi": supi})
+}
+
+func (s *mongoUeContextStore) ListBySupi(ctx ctxt.Context, prefix string) ([]*UeContextRecord, error) {
+	filter := bson.M{}
+	if prefix != "" {
+		filter["supi"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	docs, err := s.client.RestfulAPIGetMany(s.collection, filter)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*UeContextRecord, 0, len(docs))
+	for _, doc := range docs {
+		rec, err := mongoUeContextRecord(doc)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	slices.SortFunc(records, func(a, b *UeContextRecord) int {
+		return strings.Compare(a.Supi, b.Supi)
+	})
+	return records, nil
+}
+
+// mongoUeQueryFilter returns the filter of the contexts selected by query
+func mongoUeQueryFilter(query UeQuery) bson.M {
+	filter := bson.M{"supi": bson.M{"$gt": query.Cursor}}
+	if query.GmmState != "" {
+		filter["customFieldsAmfUe.state."+string(models.ACCESSTYPE__3_GPP_ACCESS)] = query.GmmState
+	}
+	switch query.CmState {
+	case models.CMSTATE_CONNECTED:
+		filter["customFieldsAmfUe.amfUeNgapId"] = bson.M{"$ne": 0}
+	case models.CMSTATE_IDLE:
+		filter["customFieldsAmfUe.amfUeNgapId"] = 0
+	}
+	if query.Tac != "" {
+		filter["tai.tac"] = query.Tac
+	}
+	if query.GnbId != "" {
+		filter["customFieldsAmfUe.ranId"] = query.GnbId
+	}
+	if query.Snssai != "" {
+		filter["customFieldsAmfUe.snssais"] = query.Snssai
+	}
+	if query.Dnn != "" {
+		filter["customFieldsAmfUe.dnns"] = query.Dnn
+	}
+	lastActivity := bson.M{}
+	if !query.ActiveSince.IsZero() {
+		lastActivity["$gte"] = query.ActiveSince.UnixMilli()
+	}
+	if !query.ActiveUntil.IsZero() {
+		lastActivity["$lt"] = query.ActiveUntil.UnixMilli()
+	}
+	if len(lastActivity) > 0 {
+		filter["customFieldsAmfUe.lastActivity"] = lastActivity
+	}
+	return filter
+}
+
+// mongoUeQueryIndexFields are the fields the UE queries filter on; each is indexed together
+// with the SUPI the pages are ordered by
+var mongoUeQueryIndexFields = []string{
+	"customFieldsAmfUe.state." + string(models.ACCESSTYPE__3_GPP_ACCESS),
+	"customFieldsAmfUe.amfUeNgapId",
+	"tai.tac",
+	"customFieldsAmfUe.ranId",
+	"customFieldsAmfUe.snssais",
+	"customFieldsAmfUe.dnns",
+	"customFieldsAmfUe.lastActivity",
+}
+
+// createMongoUeQueryIndexes creates the indexes of the UE queries on collection; unlike the
+// indexes created by mongoapi they are not unique
+func createMongoUeQueryIndexes(client mongoapi.DBInterface, collection string) error {
+	mongoClient, ok := client.(*mongoapi.MongoClient)
+	if !ok {
+		return fmt.Errorf("UE query indexes require a MongoDB client, got %T", client)
+	}
+	indexes := make([]mongo.IndexModel, 0, len(mongoUeQueryIndexFields))
+	for _, field := range mongoUeQueryIndexFields {
+		indexes = append(indexes, mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}, {Key: "supi", Value: 1}}})
+	}
+	_, err := mongoClient.GetCollection(collection).Indexes().CreateMany(ctxt.Background(), indexes)
+	return err
+}
+
+func (s *mongoUeContextStore) Query(ctx ctxt.Context, query UeQuery) ([]*UeContextRecord, error) {
+	client, ok := s.client.(*mongoapi.MongoClient)
+	if !ok {
+		return nil, fmt.Errorf("UE context query requires a MongoDB client, got %T", s.client)
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "supi", Value: 1}}).SetLimit(int64(query.Limit))
+	cur, err := client.GetCollection(s.collection).Find(ctx, mongoUeQueryFilter(query), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var records []*UeContextRecord
+	for cur.Next(ctx) {
+		var doc map[string]any
+		if err = cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		rec, err := mongoUeContextRecord(doc)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, cur.Err()
+}
+
+// LeaseOwnership updates the lease with a single conditional update, so that two instances
+// racing for the same UE context cannot both be granted the lease.
+func (s *mongoUeContextStore) LeaseOwnership(ctx ctxt.Context, supi, owner string, ttl time.Duration) (bool, error) {
+	client, ok := s.client.(*mongoapi.MongoClient)
+	if !ok {
+		return false, fmt.Errorf("UE context lease requires a MongoDB client, got %T", s.client)
+	}
+	now := time.Now()
+	filter := bson.M{
+		"supi": supi,
+		"$or": bson.A{
+			bson.M{leaseOwnerField: bson.M{"$exists": false}},
+			bson.M{leaseOwnerField: ""},
+			bson.M{leaseOwnerField: owner},
+			bson.M{leaseExpiryField: bson.M{"$lte": now.UnixMilli()}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		leaseOwnerField:  owner,
+		leaseExpiryField: now.Add(ttl).UnixMilli(),
+	}}
+	result, err := client.GetCollection(s.collection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+	doc, err := s.client.RestfulAPIGetOne(s.collection, bson.M{"supi": supi})
+	if err != nil {
+		return false, err
+	}
+	if len(doc) == 0 {
+		return false, ErrUeContextNotFound
+	}
+	return false, nil
+}
+
+// Close leaves the shared MongoDB client connected; it is also used for AMF status
+// subscriptions.
+func (s *mongoUeContextStore) Close() error {
+	return nil
+}
@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testUeContextRecord(t *testing.T, supi, guti string, amfUeNgapId, ranUeNgapId int64) *UeContextRecord {
+	t.Helper()
+	data := fmt.Sprintf(`{"supi":%q,"guti":%q,"customFieldsAmfUe":{"amfUeNgapId":%d,"ranUeNgapId":%d,"ranId":"gnb-1"}}`,
+		supi, guti, amfUeNgapId, ranUeNgapId)
+	rec, err := newUeContextRecordFromJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("decode UE context record: %v", err)
+	}
+	return rec
+}
+
+func TestUeContextStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) UeContextStore{
+		"memory": func(t *testing.T) UeContextStore {
+			return NewMemoryUeContextStore()
+		},
+		"embedded": func(t *testing.T) UeContextStore {
+			store, err := NewBoltUeContextStore(filepath.Join(t.TempDir(), "uecontext.db"))
+			if err != nil {
+				t.Fatalf("open embedded store: %v", err)
+			}
+			return store
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+			testUeContextStore(t, store)
+		})
+		t.Run(name+"/query", func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+			testUeContextStoreQuery(t, store)
+		})
+	}
+}
+
+func testUeContextStore(t *testing.T, store UeContextStore) {
+	ctx := ctxt.Background()
+	for _, rec := range []*UeContextRecord{
+		testUeContextRecord(t, "imsi-208930000000002", "20893cafe0000000002", 2, 20),
+		testUeContextRecord(t, "imsi-208930000000001", "20893cafe0000000001", 1, 10),
+		testUeContextRecord(t, "imsi-001010000000001", "00101cafe0000000001", 3, 30),
+	} {
+		if err := store.Put(ctx, rec); err != nil {
+			t.Fatalf("put %s: %v", rec.Supi, err)
+		}
+	}
+
+	for _, key := range []UeContextKey{
+		{Supi: "imsi-208930000000001"},
+		{Guti: "20893cafe0000000001"},
+		{AmfUeNgapId: 1},
+		{RanId: "gnb-1", RanUeNgapId: 10},
+	} {
+		rec, err := store.Get(ctx, key)
+		if err != nil || rec.Supi != "imsi-208930000000001" {
+			t.Errorf("get %+v: expected imsi-208930000000001, got %+v (%v)", key, rec, err)
+		}
+	}
+	if _, err := store.Get(ctx, UeContextKey{Guti: "20893cafe0000000009"}); !errors.Is(err, ErrUeContextNotFound) {
+		t.Errorf("expected ErrUeContextNotFound for unknown GUTI, got %v", err)
+	}
+
+	// a new GUTI and NG connection replace the old index entries
+	if err := store.Put(ctx, testUeContextRecord(t, "imsi-208930000000001", "20893cafe0000000005", 5, 50)); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := store.Get(ctx, UeContextKey{Guti: "20893cafe0000000001"}); !errors.Is(err, ErrUeContextNotFound) {
+		t.Errorf("expected old GUTI to be unindexed, got %v", err)
+	}
+	if rec, err := store.Get(ctx, UeContextKey{AmfUeNgapId: 5}); err != nil || rec.Guti != "20893cafe0000000005" {
+		t.Errorf("expected updated context by new AMF UE NGAP ID, got %+v (%v)", rec, err)
+	}
+
+	records, err := store.ListBySupi(ctx, "imsi-20893")
+	if err != nil || len(records) != 2 ||
+		records[0].Supi != "imsi-208930000000001" || records[1].Supi != "imsi-208930000000002" {
+		t.Errorf("expected two sorted contexts for prefix, got %+v (%v)", records, err)
+	}
+	if records, err = store.ListBySupi(ctx, ""); err != nil || len(records) != 3 {
+		t.Errorf("expected all three contexts, got %d (%v)", len(records), err)
+	}
+
+	if granted, err := store.LeaseOwnership(ctx, "imsi-208930000000002", "amf-1", time.Minute); !granted || err != nil {
+		t.Errorf("expected lease of an unowned context to be granted, got %v (%v)", granted, err)
+	}
+	if granted, _ := store.LeaseOwnership(ctx, "imsi-208930000000002", "amf-2", time.Minute); granted {
+		t.Error("expected lease held by another owner to be refused")
+	}
+	if granted, _ := store.LeaseOwnership(ctx, "imsi-208930000000002", "amf-1", time.Minute); !granted {
+		t.Error("expected owner to renew its lease")
+	}
+	if granted, _ := store.LeaseOwnership(ctx, "imsi-208930000000001", "amf-1", 0); !granted {
+		t.Fatal("expected lease to be granted")
+	}
+	if granted, _ := store.LeaseOwnership(ctx, "imsi-208930000000001", "amf-2", time.Minute); !granted {
+		t.Error("expected expired lease to be taken over")
+	}
+	if rec, _ := store.Get(ctx, UeContextKey{Supi: "imsi-208930000000001"}); rec == nil || rec.Owner != "amf-2" {
+		t.Errorf("expected amf-2 to own the context, got %+v", rec)
+	}
+	if _, err = store.LeaseOwnership(ctx, "imsi-999990000000001", "amf-1", time.Minute); !errors.Is(err, ErrUeContextNotFound) {
+		t.Errorf("expected ErrUeContextNotFound leasing an unknown context, got %v", err)
+	}
+
+	if err = store.Delete(ctx, "imsi-208930000000001"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err = store.Get(ctx, UeContextKey{RanId: "gnb-1", RanUeNgapId: 50}); !errors.Is(err, ErrUeContextNotFound) {
+		t.Errorf("expected deleted context to be gone from the indexes, got %v", err)
+	}
+}
+
+func testUeContextStoreQuery(t *testing.T, store UeContextStore) {
+	ctx := ctxt.Background()
+	activity := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, doc := range []string{
+		`{"supi":"imsi-208930000000001","tai":{"tac":"000001"},"customFieldsAmfUe":{"state":{"3GPP_ACCESS":"Registered"},"amfUeNgapId":1,"ranId":"gnb-1","snssais":["1-010203"],"dnns":["internet"],"lastActivity":%d}}`,
+		`{"supi":"imsi-208930000000002","tai":{"tac":"000001"},"customFieldsAmfUe":{"state":{"3GPP_ACCESS":"Registered"},"lastActivity":%d}}`,
+		`{"supi":"imsi-208930000000003","tai":{"tac":"000002"},"customFieldsAmfUe":{"state":{"3GPP_ACCESS":"Deregistered"},"lastActivity":%d}}`,
+		`{"supi":"imsi-208930000000004","tai":{"tac":"000001"},"customFieldsAmfUe":{"state":{"3GPP_ACCESS":"Registered"},"amfUeNgapId":4,"ranId":"gnb-2","snssais":["1-010203","2"],"dnns":["ims"],"lastActivity":%d}}`,
+	} {
+		rec, err := newUeContextRecordFromJSON(fmt.Appendf(nil, doc, activity.Add(time.Duration(i)*time.Hour).UnixMilli()))
+		if err != nil {
+			t.Fatalf("decode UE context record: %v", err)
+		}
+		if err = store.Put(ctx, rec); err != nil {
+			t.Fatalf("put %s: %v", rec.Supi, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query UeQuery
+		supis []string
+	}{
+		{"page", UeQuery{Limit: 2}, []string{"imsi-208930000000001", "imsi-208930000000002"}},
+		{"cursor", UeQuery{Cursor: "imsi-208930000000002", Limit: 10}, []string{"imsi-208930000000003", "imsi-208930000000004"}},
+		{"gmm state", UeQuery{GmmState: "Registered", Tac: "000001", Limit: 10},
+			[]string{"imsi-208930000000001", "imsi-208930000000002", "imsi-208930000000004"}},
+		{"cm state", UeQuery{CmState: "IDLE", Limit: 10}, []string{"imsi-208930000000002", "imsi-208930000000003"}},
+		{"gnb", UeQuery{GnbId: "gnb-2", Limit: 10}, []string{"imsi-208930000000004"}},
+		{"slice and dnn", UeQuery{Snssai: "1-010203", Dnn: "internet", Limit: 10}, []string{"imsi-208930000000001"}},
+		{"activity", UeQuery{ActiveSince: activity.Add(time.Hour), ActiveUntil: activity.Add(3 * time.Hour), Limit: 10},
+			[]string{"imsi-208930000000002", "imsi-208930000000003"}},
+	} {
+		records, err := store.Query(ctx, tc.query)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		var supis []string
+		for _, rec := range records {
+			supis = append(supis, rec.Supi)
+		}
+		if fmt.Sprint(supis) != fmt.Sprint(tc.supis) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.supis, supis)
+		}
+	}
+
+	records, err := store.Query(ctx, UeQuery{Cursor: "imsi-208930000000003", Limit: 10})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one context after the cursor, got %d (%v)", len(records), err)
+	}
+	summary, err := records[0].summary()
+	if err != nil || summary.CmState != "CONNECTED" || summary.GnbId != "gnb-2" || summary.AmfUeNgapId != 4 ||
+		!summary.LastActivity.Equal(activity.Add(3*time.Hour)) || len(summary.Snssais) != 2 {
+		t.Errorf("unexpected summary %+v (%v)", summary, err)
+	}
+}
+
+func TestUeContextStoreQueryAfterUpdate(t *testing.T) {
+	ctx := ctxt.Background()
+	for name, store := range map[string]UeContextStore{
+		"memory":   NewMemoryUeContextStore(),
+		"embedded": mustOpenBoltUeContextStore(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer store.Close()
+			put := func(supi, state string) {
+				t.Helper()
+				rec, err := newUeContextRecordFromJSON(fmt.Appendf(nil,
+					`{"supi":%q,"customFieldsAmfUe":{"state":{"3GPP_ACCESS":%q}}}`, supi, state))
+				if err != nil {
+					t.Fatalf("decode UE context record: %v", err)
+				}
+				if err = store.Put(ctx, rec); err != nil {
+					t.Fatalf("put %s: %v", supi, err)
+				}
+			}
+			put("imsi-208930000000001", "Registered")
+			put("imsi-208930000000002", "Registered")
+			put("imsi-208930000000001", "Deregistered")
+			if err := store.Delete(ctx, "imsi-208930000000002"); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+
+			query := UeQuery{GmmState: "Registered", Limit: 10}
+			if records, err := store.Query(ctx, query); err != nil || len(records) != 0 {
+				t.Errorf("expected no registered context, got %d (%v)", len(records), err)
+			}
+			query.GmmState = "Deregistered"
+			if records, err := store.Query(ctx, query); err != nil || len(records) != 1 {
+				t.Errorf("expected the updated context, got %d (%v)", len(records), err)
+			}
+		})
+	}
+}
+
+func mustOpenBoltUeContextStore(t *testing.T) UeContextStore {
+	t.Helper()
+	store, err := NewBoltUeContextStore(filepath.Join(t.TempDir(), "uecontext.db"))
+	if err != nil {
+		t.Fatalf("open embedded store: %v", err)
+	}
+	return store
+}
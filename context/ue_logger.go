@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// procedureIdCounter numbers the procedures started by all UEs, see setProcedureId
+var procedureIdCounter atomic.Uint64
+
+// setProcedureId assigns a new procedure ID to the UE, carried by the log lines of the UE
+// until its next procedure starts
+func (ue *AmfUe) setProcedureId(procedure KpiProcedure) {
+	procedureId := string(procedure) + "-" + strconv.FormatUint(procedureIdCounter.Add(1), 10)
+	ue.logMu.Lock()
+	ue.procedureId = procedureId
+	ue.logMu.Unlock()
+	ue.UpdateLoggers()
+}
+
+// ProcedureId returns the ID of the latest procedure of the UE, empty before its first one
+func (ue *AmfUe) ProcedureId() string {
+	ue.logMu.Lock()
+	defer ue.logMu.Unlock()
+	return ue.procedureId
+}
+
+// UpdateLoggers rebuilds the NAS, GMM and producer loggers of the UE, and the loggers of its
+// RanUes, with the fields correlating every log line about the UE: its SUPI (its SUCI before
+// it is identified), AMF UE NGAP ID, RAN ID and procedure ID. It is called whenever one of
+// them changes, and must not be called with ue.Mutex held. The loggers are updated in place,
+// see ueLogCore, as the goroutines of the UE use them concurrently.
+func (ue *AmfUe) UpdateLoggers() {
+	ue.Mutex.Lock()
+	ranUes := make([]*RanUe, 0, 2)
+	for _, anType := range []models.AccessType{models.ACCESSTYPE__3_GPP_ACCESS, models.ACCESSTYPE_NON_3_GPP_ACCESS} {
+		if ranUe := ue.RanUe[anType]; ranUe != nil {
+			ranUes = append(ranUes, ranUe)
+		}
+	}
+	ue.Mutex.Unlock()
+
+	ueFields := make([]any, 0, 4)
+	if supi := ue.GetSupi(); supi != "" {
+		ueFields = append(ueFields, logger.FieldSupi, supi)
+	} else if ue.Suci != "" {
+		ueFields = append(ueFields, logger.FieldSuci, ue.Suci)
+	}
+	if procedureId := ue.ProcedureId(); procedureId != "" {
+		ueFields = append(ueFields, logger.FieldProcedureId, procedureId)
+	}
+
+	// the loggers of the UE carry the IDs of its 3GPP access NG connection, if any
+	fields := ueFields
+	if len(ranUes) > 0 {
+		fields = append(ranUes[0].logFields(), ueFields...)
+	}
+	updateUeLogger(&ue.NASLog, logger.NasLog, fields...)
+	updateUeLogger(&ue.GmmLog, logger.GmmLog, fields...)
+	updateUeLogger(&ue.TxLog, logger.GmmLog, fields...)
+	updateUeLogger(&ue.ProducerLog, logger.ProducerLog, fields...)
+
+	for _, ranUe := range ranUes {
+		if ranUe.Ran != nil {
+			updateUeLogger(&ranUe.Log, ranUe.Ran.Log, append([]any{logger.FieldAmfUeNgapID, ranUe.AmfUeNgapId}, ueFields...)...)
+		}
+	}
+}
+
+// ueLogCore is the core of a logger of a UE. Its fields change with the procedures of the
+// UE while the logger is in use on other goroutines, so UpdateLoggers swaps the core the
+// logger writes to instead of replacing the logger.
+type ueLogCore struct {
+	core atomic.Pointer[zapcore.Core]
+}
+
+// newUeLogger returns a logger of base with fields, whose fields updateUeLogger replaces
+func newUeLogger(base *zap.SugaredLogger, fields ...any) *zap.SugaredLogger {
+	c := &ueLogCore{}
+	c.store(base, fields)
+	return base.Desugar().WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core { return c })).Sugar()
+}
+
+// updateUeLogger replaces the fields of *log by those of base with fields. Only a logger not
+// created by newUeLogger yet is replaced, before it is shared.
+func updateUeLogger(log **zap.SugaredLogger, base *zap.SugaredLogger, fields ...any) {
+	if *log != nil {
+		if c, ok := (*log).Desugar().Core().(*ueLogCore); ok {
+			c.store(base, fields)
+			return
+		}
+	}
+	*log = newUeLogger(base, fields...)
+}
+
+func (c *ueLogCore) store(base *zap.SugaredLogger, fields []any) {
+	core := base.With(fields...).Desugar().Core()
+	c.core.Store(&core)
+}
+
+func (c *ueLogCore) load() zapcore.Core {
+	return *c.core.Load()
+}
+
+func (c *ueLogCore) Enabled(level zapcore.Level) bool {
+	return c.load().Enabled(level)
+}
+
+func (c *ueLogCore) With(fields []zapcore.Field) zapcore.Core {
+	return c.load().With(fields)
+}
+
+func (c *ueLogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.load().Check(entry, checked)
+}
+
+func (c *ueLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.load().Write(entry, fields)
+}
+
+func (c *ueLogCore) Sync() error {
+	return c.load().Sync()
+}
+
+// logFields returns the AMF UE NGAP ID and the RAN ID of the NG connection of the UE
+func (ranUe *RanUe) logFields() []any {
+	fields := []any{logger.FieldAmfUeNgapID, ranUe.AmfUeNgapId}
+	if ranUe.Ran != nil && ranUe.Ran.GnbId != "" {
+		fields = append(fields, logger.FieldRanId, ranUe.Ran.GnbId)
+	}
+	return fields
+}
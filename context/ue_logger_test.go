@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestUpdateLoggers(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	origGmmLog, origNgapLog := logger.GmmLog, logger.NgapLog
+	logger.GmmLog, logger.NgapLog = zap.New(core).Sugar(), zap.New(core).Sugar()
+	t.Cleanup(func() { logger.GmmLog, logger.NgapLog = origGmmLog, origNgapLog })
+
+	ran := &AmfRan{GnbId: "208:93:000102", Log: logger.NgapLog.With(logger.FieldRanId, "208:93:000102")}
+	ranUe := &RanUe{AmfUeNgapId: 7, Ran: ran}
+	ue := &AmfUe{Suci: "suci-0-208-93-0000-0-0-0000000001"}
+	ue.RanUe = map[models.AccessType]*RanUe{models.ACCESSTYPE__3_GPP_ACCESS: ranUe}
+
+	ue.StartProcedure(KpiInitialRegistration)
+	procedureId := ue.ProcedureId()
+	if !strings.HasPrefix(procedureId, "initial_registration-") {
+		t.Fatalf("unexpected procedure ID %q", procedureId)
+	}
+	ue.GmmLog.Info("gmm line")
+	fields := logs.TakeAll()[0].ContextMap()
+	if fields[logger.FieldSuci] != ue.Suci || fields[logger.FieldAmfUeNgapID] != int64(7) ||
+		fields[logger.FieldRanId] != "208:93:000102" || fields[logger.FieldProcedureId] != procedureId {
+		t.Errorf("unexpected GMM log fields: %v", fields)
+	}
+
+	// authentication runs within the registration and keeps its procedure ID
+	ue.StartProcedure(KpiAuthentication)
+	if ue.ProcedureId() != procedureId {
+		t.Errorf("expected procedure ID %q to be kept, got %q", procedureId, ue.ProcedureId())
+	}
+
+	ue.SetSupi("imsi-208930000000001")
+	ue.UpdateLoggers()
+	ranUe.Log.Info("ngap line")
+	fields = logs.TakeAll()[0].ContextMap()
+	if fields[logger.FieldSupi] != "imsi-208930000000001" || fields[logger.FieldAmfUeNgapID] != int64(7) ||
+		fields[logger.FieldProcedureId] != procedureId {
+		t.Errorf("unexpected NGAP log fields: %v", fields)
+	}
+	if _, ok := fields[logger.FieldSuci]; ok {
+		t.Errorf("expected the SUCI to be replaced by the SUPI: %v", fields)
+	}
+
+	ue.StartProcedure(KpiServiceRequest)
+	if next := ue.ProcedureId(); next == procedureId || !strings.HasPrefix(next, "service_request-") {
+		t.Errorf("expected a new service request procedure ID, got %q", next)
+	}
+}
+
+func TestUpdateLoggersInPlace(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	origGmmLog := logger.GmmLog
+	logger.GmmLog = zap.New(core).Sugar()
+	t.Cleanup(func() { logger.GmmLog = origGmmLog })
+
+	ue := &AmfUe{Suci: "suci-0-208-93-0000-0-0-0000000001"}
+	ue.UpdateLoggers()
+	gmmLog := ue.GmmLog
+
+	// the logger in use keeps logging while the procedure ID changes
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			gmmLog.Debug("concurrent line")
+		}
+	}()
+	ue.StartProcedure(KpiServiceRequest)
+	<-done
+	procedureId := ue.ProcedureId()
+
+	if ue.GmmLog != gmmLog {
+		t.Fatal("expected the GMM logger to be updated in place")
+	}
+	logs.TakeAll()
+	gmmLog.Info("gmm line")
+	if fields := logs.TakeAll()[0].ContextMap(); fields[logger.FieldProcedureId] != procedureId {
+		t.Errorf("expected procedure ID %q, got fields %v", procedureId, fields)
+	}
+}
@@ -0,0 +1,342 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	ctxt "context"
+	"slices"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// page sizes of the UE queries
+const (
+	DefaultUeQueryLimit = 100
+	MaxUeQueryLimit     = 1000
+)
+
+// uePoolSnapshotTTL bounds how long a UE context created during a paged UE query can be
+// missing from the following pages
+const uePoolSnapshotTTL = 30 * time.Second
+
+// UeQuery selects a page of UE contexts, ordered by SUPI. Fields left empty do not filter.
+type UeQuery struct {
+	GmmState    string         // GMM state of the 3GPP access, e.g. Registered
+	CmState     models.CmState // CM state of the 3GPP access
+	Tac         string
+	GnbId       string // gNB of the NG connection of the UE
+	Snssai      string // S-NSSAI of a PDU session of the UE, formatted as sst or sst-sd
+	Dnn         string // DNN of a PDU session of the UE
+	ActiveSince time.Time
+	ActiveUntil time.Time
+	Cursor      string // SUPI of the last UE of the previous page
+	Limit       int
+}
+
+// UeSummary is a UE context selected by a UeQuery
+type UeSummary struct {
+	Supi            string         `json:"supi"`
+	Guti            string         `json:"guti,omitempty"`
+	GmmState        string         `json:"gmmState,omitempty"`
+	CmState         models.CmState `json:"cmState"`
+	Mcc             string         `json:"mcc,omitempty"`
+	Mnc             string         `json:"mnc,omitempty"`
+	Tac             string         `json:"tac,omitempty"`
+	GnbId           string         `json:"gnbId,omitempty"`
+	AmfUeNgapId     int64          `json:"amfUeNgapId,omitempty"`
+	RanUeNgapId     int64          `json:"ranUeNgapId,omitempty"`
+	Snssais         []string       `json:"snssais,omitempty"`
+	Dnns            []string       `json:"dnns,omitempty"`
+	LastActivity    time.Time      `json:"lastActivity,omitzero"`
+	AmfInstanceName string         `json:"amfInstanceName,omitempty"`
+}
+
+// UePage is a page of UE contexts; NextCursor is empty on the last page
+type UePage struct {
+	Ues        []UeSummary `json:"ues"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+// ueSummaryFields are the parts of the JSON encoded AmfUe a UeSummary is built from, so that
+// a query does not restore complete UE contexts
+type ueSummaryFields struct {
+	Supi            string     `json:"supi"`
+	Guti            string     `json:"guti"`
+	Tai             models.Tai `json:"tai"`
+	AmfInstanceName string     `json:"amfInstanceName"`
+	Custom          struct {
+		State        map[models.AccessType]string `json:"state"`
+		AmfUeNgapId  int64                        `json:"amfUeNgapId"`
+		RanUeNgapId  int64                        `json:"ranUeNgapId"`
+		RanId        string                       `json:"ranId"`
+		Snssais      []string                     `json:"snssais"`
+		Dnns         []string                     `json:"dnns"`
+		LastActivity int64                        `json:"lastActivity"`
+	} `json:"customFieldsAmfUe"`
+}
+
+// RecordActivity records an uplink NAS message of the UE as its last activity.
+func (ue *AmfUe) RecordActivity() {
+	ue.lastActivity.Store(time.Now().UnixMilli())
+}
+
+// LastActivity returns the time of the last uplink NAS message of the UE, zero if none.
+func (ue *AmfUe) LastActivity() time.Time {
+	if lastActivity := ue.lastActivity.Load(); lastActivity != 0 {
+		return time.UnixMilli(lastActivity)
+	}
+	return time.Time{}
+}
+
+// sessionSnssaisAndDnns returns the sorted distinct S-NSSAIs and DNNs of the PDU sessions of the UE
+func (ue *AmfUe) sessionSnssaisAndDnns() (snssais, dnns []string) {
+	ue.SmContextList.Range(func(key, value any) bool {
+		smContext := value.(*SmContext)
+		if snssai := kpiSnssai(smContext.Snssai()); !slices.Contains(snssais, snssai) {
+			snssais = append(snssais, snssai)
+		}
+		if dnn := smContext.Dnn(); dnn != "" && !slices.Contains(dnns, dnn) {
+			dnns = append(dnns, dnn)
+		}
+		return true
+	})
+	slices.Sort(snssais)
+	slices.Sort(dnns)
+	return snssais, dnns
+}
+
+// Summary returns the summary of the UE context listed by the UE queries.
+func (ue *AmfUe) Summary() UeSummary {
+	summary := UeSummary{
+		Supi:            ue.GetSupi(),
+		Guti:            ue.GetGuti(),
+		CmState:         models.CMSTATE_IDLE,
+		Mcc:             ue.Tai.PlmnId.GetMcc(),
+		Mnc:             ue.Tai.PlmnId.GetMnc(),
+		Tac:             ue.Tai.Tac,
+		LastActivity:    ue.LastActivity(),
+		AmfInstanceName: ue.AmfInstanceName,
+	}
+	if state := ue.State[models.ACCESSTYPE__3_GPP_ACCESS]; state != nil {
+		summary.GmmState = string(state.Current())
+	}
+	if ranUe := ue.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS); ranUe != nil {
+		summary.CmState = models.CMSTATE_CONNECTED
+		summary.AmfUeNgapId = ranUe.AmfUeNgapId
+		summary.RanUeNgapId = ranUe.RanUeNgapId
+		if ranUe.Ran != nil {
+			summary.GnbId = ranUe.Ran.GnbId
+		}
+	}
+	summary.Snssais, summary.Dnns = ue.sessionSnssaisAndDnns()
+	return summary
+}
+
+// summary returns the summary of the UE context stored in rec.
+func (rec *UeContextRecord) summary() (UeSummary, error) {
+	fields := ueSummaryFields{}
+	if err := sonic.Unmarshal(rec.Data, &fields); err != nil {
+		return UeSummary{}, err
+	}
+	summary := UeSummary{
+		Supi:            fields.Supi,
+		Guti:            fields.Guti,
+		GmmState:        fields.Custom.State[models.ACCESSTYPE__3_GPP_ACCESS],
+		CmState:         models.CMSTATE_IDLE,
+		Mcc:             fields.Tai.PlmnId.GetMcc(),
+		Mnc:             fields.Tai.PlmnId.GetMnc(),
+		Tac:             fields.Tai.Tac,
+		GnbId:           fields.Custom.RanId,
+		Snssais:         fields.Custom.Snssais,
+		Dnns:            fields.Custom.Dnns,
+		AmfInstanceName: fields.AmfInstanceName,
+	}
+	// only the 3GPP access UE-associated NG connection is stored
+	if fields.Custom.AmfUeNgapId != 0 {
+		summary.CmState = models.CMSTATE_CONNECTED
+		summary.AmfUeNgapId = fields.Custom.AmfUeNgapId
+		summary.RanUeNgapId = fields.Custom.RanUeNgapId
+	}
+	if fields.Custom.LastActivity != 0 {
+		summary.LastActivity = time.UnixMilli(fields.Custom.LastActivity)
+	}
+	return summary, nil
+}
+
+// matches reports whether summary is selected by the filters of query; the cursor is not checked
+func (query UeQuery) matches(summary UeSummary) bool {
+	switch {
+	case summary.Supi == "":
+		return false
+	case query.GmmState != "" && summary.GmmState != query.GmmState:
+		return false
+	case query.CmState != "" && summary.CmState != query.CmState:
+		return false
+	case query.Tac != "" && summary.Tac != query.Tac:
+		return false
+	case query.GnbId != "" && summary.GnbId != query.GnbId:
+		return false
+	case query.Snssai != "" && !slices.Contains(summary.Snssais, query.Snssai):
+		return false
+	case query.Dnn != "" && !slices.Contains(summary.Dnns, query.Dnn):
+		return false
+	case !query.ActiveSince.IsZero() && (summary.LastActivity.IsZero() || summary.LastActivity.Before(query.ActiveSince)):
+		return false
+	case !query.ActiveUntil.IsZero() && (summary.LastActivity.IsZero() || !summary.LastActivity.Before(query.ActiveUntil)):
+		return false
+	}
+	return true
+}
+
+// limit returns the page size of query
+func (query UeQuery) limit() int {
+	switch {
+	case query.Limit <= 0:
+		return DefaultUeQueryLimit
+	case query.Limit > MaxUeQueryLimit:
+		return MaxUeQueryLimit
+	}
+	return query.Limit
+}
+
+// QueryUeContexts returns a page of the UE contexts selected by query. With the UE context
+// store enabled, the contexts stored by all AMF instances are queried from the store,
+// otherwise the contexts of this instance are.
+func QueryUeContexts(ctx ctxt.Context, query UeQuery) (UePage, error) {
+	limit := query.limit()
+	// one more context than the page size tells whether there is a next page
+	query.Limit = limit + 1
+
+	var summaries []UeSummary
+	if store := GetUeContextStore(); AMF_Self().EnableDbStore && store != nil {
+		records, err := store.Query(ctx, query)
+		if err != nil {
+			return UePage{}, err
+		}
+		for _, rec := range records {
+			summary, err := rec.summary()
+			if err != nil {
+				return UePage{}, err
+			}
+			summaries = append(summaries, summary)
+		}
+	} else {
+		summaries = AMF_Self().queryUePool(query)
+	}
+
+	page := UePage{Ues: summaries}
+	if len(summaries) > limit {
+		page.Ues = summaries[:limit]
+		page.NextCursor = page.Ues[limit-1].Supi
+	}
+	if page.Ues == nil {
+		page.Ues = []UeSummary{}
+	}
+	return page, nil
+}
+
+// ExportUeContexts calls yield with every UE context selected by query in SUPI order, and
+// stops at the first error of yield. The contexts of this instance are exported from a single
+// sorted snapshot of their SUPIs, the stored ones page by page.
+func ExportUeContexts(ctx ctxt.Context, query UeQuery, yield func(UeSummary) error) error {
+	if store := GetUeContextStore(); AMF_Self().EnableDbStore && store != nil {
+		query.Limit = MaxUeQueryLimit
+		for {
+			page, err := QueryUeContexts(ctx, query)
+			if err != nil {
+				return err
+			}
+			for _, summary := range page.Ues {
+				if err = yield(summary); err != nil {
+					return err
+				}
+			}
+			if page.NextCursor == "" {
+				return nil
+			}
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			query.Cursor = page.NextCursor
+		}
+	}
+	self := AMF_Self()
+	for _, supi := range self.sortedUePoolSupis(query.Cursor) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		value, ok := self.UePool.Load(supi)
+		if !ok {
+			// removed since the snapshot
+			continue
+		}
+		if summary := value.(*AmfUe).Summary(); query.matches(summary) {
+			if err := yield(summary); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortedUePoolSupis returns the sorted SUPIs of the UE contexts of this instance that follow
+// cursor
+func (context *AMFContext) sortedUePoolSupis(cursor string) []string {
+	var supis []string
+	context.UePool.Range(func(key, value any) bool {
+		if supi := value.(*AmfUe).GetSupi(); supi != "" && supi > cursor {
+			supis = append(supis, supi)
+		}
+		return true
+	})
+	slices.Sort(supis)
+	return supis
+}
+
+// uePoolSnapshot is a sorted snapshot of the SUPIs of the UE contexts of this instance
+type uePoolSnapshot struct {
+	taken time.Time
+	supis []string
+}
+
+// uePoolSupisAfter returns the SUPIs of the UE contexts of this instance that follow cursor.
+// The first page of a query takes a sorted snapshot of the SUPIs, which the following pages
+// are read from while it is younger than uePoolSnapshotTTL, so that paging through N UE
+// contexts sorts them once rather than once per page.
+func (context *AMFContext) uePoolSupisAfter(cursor string) []string {
+	snapshot := context.uePoolSnapshot.Load()
+	if cursor == "" || snapshot == nil || time.Since(snapshot.taken) > uePoolSnapshotTTL {
+		snapshot = &uePoolSnapshot{taken: time.Now(), supis: context.sortedUePoolSupis("")}
+		context.uePoolSnapshot.Store(snapshot)
+	}
+	i, found := slices.BinarySearch(snapshot.supis, cursor)
+	if found {
+		i++
+	}
+	return snapshot.supis[i:]
+}
+
+// queryUePool returns up to query.Limit UE contexts of this instance selected by query; only
+// the UEs up to the last one of the page are summarized
+func (context *AMFContext) queryUePool(query UeQuery) []UeSummary {
+	var summaries []UeSummary
+	for _, supi := range context.uePoolSupisAfter(query.Cursor) {
+		if len(summaries) == query.Limit {
+			break
+		}
+		value, ok := context.UePool.Load(supi)
+		if !ok {
+			// removed since the snapshot
+			continue
+		}
+		if summary := value.(*AmfUe).Summary(); query.matches(summary) {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package context
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUePoolSupisAfterPagesSnapshot(t *testing.T) {
+	self := &AMFContext{}
+	for _, supi := range []string{"imsi-208930000000003", "imsi-208930000000001", "imsi-208930000000002"} {
+		self.UePool.Store(supi, &AmfUe{Supi: supi})
+	}
+
+	first := self.uePoolSupisAfter("")
+	if !slices.Equal(first, []string{"imsi-208930000000001", "imsi-208930000000002", "imsi-208930000000003"}) {
+		t.Fatalf("expected all SUPIs in order on the first page, got %v", first)
+	}
+
+	// created after the first page: not in the snapshot the following pages are read from
+	self.UePool.Store("imsi-208930000000004", &AmfUe{Supi: "imsi-208930000000004"})
+	next := self.uePoolSupisAfter("imsi-208930000000001")
+	if !slices.Equal(next, []string{"imsi-208930000000002", "imsi-208930000000003"}) {
+		t.Errorf("expected the SUPIs after the cursor from the snapshot, got %v", next)
+	}
+
+	if supis := self.uePoolSupisAfter(""); len(supis) != 4 {
+		t.Errorf("expected a new query to take a new snapshot, got %v", supis)
+	}
+}
@@ -8,6 +8,7 @@ package factory
 
 import (
 	"testing"
+	"time"
 )
 
 func TestWebuiUrl(t *testing.T) {
@@ -280,3 +281,229 @@ func TestValidateAmfId(t *testing.T) {
 		})
 	}
 }
+
+func TestOverloadControlConfigDefaults(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/overload_control.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	oc := AmfConfig.Configuration.OverloadControl
+	if oc == nil || !oc.Enabled {
+		t.Fatalf("expected overload control to be enabled, got: %v", oc)
+	}
+	if oc.SbiLatencyThreshold != 500*time.Millisecond {
+		t.Errorf("expected SBI latency threshold 500ms, got: %v", oc.SbiLatencyThreshold)
+	}
+	if oc.TrafficLoadReduction != 30 {
+		t.Errorf("expected traffic load reduction 30, got: %d", oc.TrafficLoadReduction)
+	}
+	if oc.CheckInterval != time.Second || oc.EventQueueHighWatermark != 80 || oc.DbQueueHighWatermark != 80 ||
+		oc.RecoveryPercent != 50 || oc.T3346Value != 60 {
+		t.Errorf("expected defaults to be applied, got: %+v", oc)
+	}
+}
+
+func TestOverloadControlConfigInvalidReduction(t *testing.T) {
+	oc := &OverloadControlConfig{Enabled: true, TrafficLoadReduction: 100}
+	if err := setOverloadControlDefaults(oc); err == nil {
+		t.Errorf("expected trafficLoadReduction 100 to be rejected")
+	}
+}
+
+func TestUeContextStoreConfigDefaults(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/amfcfg.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	cfg := AmfConfig.Configuration.UeContextStore
+	if cfg == nil {
+		t.Fatal("expected UE context store configuration to be defaulted")
+	}
+	if cfg.Backend != UeContextStoreMongoDB || cfg.Collection != "amf.data.amfState" || cfg.WriteWorkers != 4 ||
+		cfg.WriteQueueSize != 256 || cfg.LeaseTtl != 10*time.Second {
+		t.Errorf("expected defaults to be applied, got: %+v", cfg)
+	}
+}
+
+func TestUeContextStoreConfigEmbedded(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/ue_context_store.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	cfg := AmfConfig.Configuration.UeContextStore
+	if cfg.Backend != UeContextStoreEmbedded || cfg.Path != "/tmp/amf-uecontext.db" || cfg.WriteWorkers != 2 {
+		t.Errorf("unexpected UE context store configuration: %+v", cfg)
+	}
+	if cfg.WriteQueueSize != 256 {
+		t.Errorf("expected default write queue size 256, got: %d", cfg.WriteQueueSize)
+	}
+}
+
+func TestUeContextStoreConfigUnknownBackend(t *testing.T) {
+	if err := setUeContextStoreDefaults(&UeContextStoreConfig{Backend: "redis"}); err == nil {
+		t.Errorf("expected unknown backend to be rejected")
+	}
+}
+
+func TestPowerSavingConfigDefaults(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/power_saving.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	ps := AmfConfig.Configuration.PowerSaving
+	if ps == nil || !ps.MicoEnabled || !ps.StrictPeriodicRegistration || ps.MicoAllPlmnRegistrationArea || !ps.EdrxEnabled {
+		t.Fatalf("unexpected power saving configuration: %+v", ps)
+	}
+	if ps.MicoT3512Value != 86400 {
+		t.Errorf("expected MICO T3512 value 86400, got: %d", ps.MicoT3512Value)
+	}
+	if ps.MaxEdrxValue == nil || *ps.MaxEdrxValue != 12 {
+		t.Errorf("expected default max eDRX value 12, got: %v", ps.MaxEdrxValue)
+	}
+	if ps.PagingTimeWindow == nil || *ps.PagingTimeWindow != 3 {
+		t.Errorf("expected paging time window 3, got: %v", ps.PagingTimeWindow)
+	}
+}
+
+func TestPowerSavingConfigInvalidEdrxValue(t *testing.T) {
+	maxEdrxValue := uint8(13)
+	if err := setPowerSavingDefaults(&PowerSavingConfig{EdrxEnabled: true, MaxEdrxValue: &maxEdrxValue}); err == nil {
+		t.Errorf("expected maxEdrxValue 13 to be rejected")
+	}
+}
+
+func TestSignallingTraceConfig(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/signalling_trace.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	st := AmfConfig.Configuration.SignallingTrace
+	if st == nil || !st.Enabled || st.Directory != "/tmp/amf-trace" || st.Format != SignallingTraceFormatBinary {
+		t.Fatalf("unexpected signalling trace configuration: %+v", st)
+	}
+}
+
+func TestSignallingTraceConfigDefaults(t *testing.T) {
+	st := &SignallingTraceConfig{Enabled: true}
+	if err := setSignallingTraceDefaults(st); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Directory != "/var/log/amf/trace" || st.Format != SignallingTraceFormatXML {
+		t.Errorf("expected defaults to be applied, got: %+v", st)
+	}
+	if err := setSignallingTraceDefaults(&SignallingTraceConfig{Format: "pcap"}); err == nil {
+		t.Errorf("expected unknown format to be rejected")
+	}
+}
+
+func TestFlightRecorderConfigDefaults(t *testing.T) {
+	fr := &FlightRecorderConfig{Enabled: true}
+	if err := setFlightRecorderDefaults(fr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fr.Size != 256 {
+		t.Errorf("expected default size 256, got: %d", fr.Size)
+	}
+	if err := setFlightRecorderDefaults(&FlightRecorderConfig{Size: -1}); err == nil {
+		t.Errorf("expected negative size to be rejected")
+	}
+}
+
+func TestPacketCaptureConfigDefaults(t *testing.T) {
+	pc := &PacketCaptureConfig{Enabled: true}
+	if err := setPacketCaptureDefaults(pc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pc.Directory != "/var/log/amf/pcap" || pc.MaxFileSize != 100<<20 || pc.AllowNasDecryption {
+		t.Errorf("expected defaults to be applied, got: %+v", pc)
+	}
+	if err := setPacketCaptureDefaults(&PacketCaptureConfig{MaxFileSize: -1}); err == nil {
+		t.Errorf("expected negative maxFileSize to be rejected")
+	}
+}
+
+func TestLoggingConfigDefaults(t *testing.T) {
+	logging := &LoggingConfig{Levels: map[string]string{"NGAP": "debug", "consumer": "warn"}}
+	if err := setLoggingDefaults(logging); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logging.Encoding != "console" {
+		t.Errorf("expected default encoding console, got: %s", logging.Encoding)
+	}
+	if err := setLoggingDefaults(&LoggingConfig{Encoding: "xml"}); err == nil {
+		t.Errorf("expected unsupported encoding to be rejected")
+	}
+	if err := setLoggingDefaults(&LoggingConfig{Levels: map[string]string{"SMF": "debug"}}); err == nil {
+		t.Errorf("expected unknown subsystem to be rejected")
+	}
+	if err := setLoggingDefaults(&LoggingConfig{Levels: map[string]string{"GMM": "verbose"}}); err == nil {
+		t.Errorf("expected invalid level to be rejected")
+	}
+}
+
+func TestRacsConfig(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/racs.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	racs := AmfConfig.Configuration.Racs
+	if racs == nil || !racs.Enabled || racs.DictionaryFile != "../util/testdata/ue_radio_capability_ids.yaml" {
+		t.Fatalf("unexpected RACS configuration: %+v", racs)
+	}
+	if racs.MaxDictionaryEntries != 10000 {
+		t.Errorf("expected default max dictionary entries 10000, got: %d", racs.MaxDictionaryEntries)
+	}
+}
+
+func TestRacsConfigInvalid(t *testing.T) {
+	if err := setRacsDefaults(&RacsConfig{MaxDictionaryEntries: -1}); err == nil {
+		t.Errorf("expected negative maxDictionaryEntries to be rejected")
+	}
+	if err := setRacsDefaults(&RacsConfig{UcmfUri: "ucmf/nucmf-uecm"}); err == nil {
+		t.Errorf("expected invalid ucmfUri to be rejected")
+	}
+}
+
+func TestPagingConfig(t *testing.T) {
+	origAmfConfig := AmfConfig
+	t.Cleanup(func() { AmfConfig = origAmfConfig })
+	if err := InitConfigFactory("../util/testdata/paging.yaml"); err != nil {
+		t.Fatalf("Error in InitConfigFactory: %v", err)
+	}
+
+	paging := AmfConfig.Configuration.Paging
+	if paging == nil || paging.Strategy != PagingStrategyEscalating || len(paging.Stages) != 3 {
+		t.Fatalf("unexpected paging configuration: %+v", paging)
+	}
+	if paging.Stages[0].Scope != PagingScopeLastKnownRanNode || paging.Stages[0].Timeout != 2*time.Second ||
+		paging.Stages[0].Attempts != 1 || paging.Stages[2].Attempts != 2 {
+		t.Errorf("unexpected paging stages: %+v", paging.Stages)
+	}
+	if len(paging.HighPriorityStages) != 1 || paging.HighPriorityStages[0].Scope != PagingScopeRegistrationArea {
+		t.Errorf("expected default high priority stages, got: %+v", paging.HighPriorityStages)
+	}
+	if paging.HighPriorityPpi != 2 || paging.HighPriorityArpLevel != 3 {
+		t.Errorf("unexpected high priority thresholds: %d %d", paging.HighPriorityPpi, paging.HighPriorityArpLevel)
+	}
+}
+
+func TestPagingConfigInvalid(t *testing.T) {
+	if err := setPagingDefaults(&PagingConfig{Strategy: "random"}); err == nil {
+		t.Errorf("expected unknown strategy to be rejected")
+	}
+	if err := setPagingDefaults(&PagingConfig{Stages: []PagingStageConfig{{Scope: "cell"}}}); err == nil {
+		t.Errorf("expected unknown scope to be rejected")
+	}
+}
@@ -56,6 +56,136 @@ type TelemetryConfig struct {
 	Ratio        *float64 `yaml:"ratio,omitempty"`         // Optional; defaults to 1.0
 }
 
+// OverloadControlConfig holds the thresholds used to detect AMF overload (TS 23.501 5.19.5).
+// Queue watermarks are percentages of the queue capacity.
+type OverloadControlConfig struct {
+	Enabled                 bool          `yaml:"enabled,omitempty"`                 // Optional; defaults to false
+	CheckInterval           time.Duration `yaml:"checkInterval,omitempty"`           // Optional; defaults to 1s
+	EventQueueHighWatermark int           `yaml:"eventQueueHighWatermark,omitempty"` // Optional; defaults to 80
+	DbQueueHighWatermark    int           `yaml:"dbQueueHighWatermark,omitempty"`    // Optional; defaults to 80
+	SbiLatencyThreshold     time.Duration `yaml:"sbiLatencyThreshold,omitempty"`     // Optional; defaults to 2s
+	RecoveryPercent         int           `yaml:"recoveryPercent,omitempty"`         // Optional; defaults to 50
+	TrafficLoadReduction    int64         `yaml:"trafficLoadReduction,omitempty"`    // Optional; 1-99, defaults to 50
+	T3346Value              int           `yaml:"t3346Value,omitempty"`              // Optional; seconds, defaults to 60
+}
+
+// PlannedRemovalConfig controls the AMF planned removal (drain) procedure (TS 23.501 5.21.2.2).
+type PlannedRemovalConfig struct {
+	BackupAmfName string        `yaml:"backupAmfName,omitempty"` // Optional; sent to gNBs in AMF Status Indication
+	BackupAmfUri  string        `yaml:"backupAmfUri,omitempty"`  // Optional; Namf_Communication apiRoot of the backup AMF
+	DrainTimeout  time.Duration `yaml:"drainTimeout,omitempty"`  // Optional; defaults to 30s
+}
+
+// PowerSavingConfig controls MICO mode (TS 23.501 5.4.1.3) and extended idle mode DRX
+// (TS 23.501 5.31.7.2). eDRX and paging time window values use the 4-bit NR encoding of
+// TS 24.008 10.5.5.32.
+type PowerSavingConfig struct {
+	MicoEnabled                 bool   `yaml:"micoEnabled,omitempty"`                 // Optional; defaults to false
+	MicoAllPlmnRegistrationArea bool   `yaml:"micoAllPlmnRegistrationArea,omitempty"` // Optional; grant requested all PLMN registration area (RAAI)
+	StrictPeriodicRegistration  bool   `yaml:"strictPeriodicRegistration,omitempty"`  // Optional; grant requested strict periodic registration timer (SPRTI)
+	MicoT3512Value              int    `yaml:"micoT3512Value,omitempty"`              // Optional; seconds, defaults to t3512Value
+	EdrxEnabled                 bool   `yaml:"edrxEnabled,omitempty"`                 // Optional; defaults to false
+	MaxEdrxValue                *uint8 `yaml:"maxEdrxValue,omitempty"`                // Optional; 0-12, defaults to 12 (10485.76 s)
+	PagingTimeWindow            *uint8 `yaml:"pagingTimeWindow,omitempty"`            // Optional; 0-15, defaults to the value requested by the UE
+}
+
+// UE context store backends
+const (
+	UeContextStoreMongoDB  = "mongodb"
+	UeContextStoreMemory   = "memory"
+	UeContextStoreEmbedded = "embedded"
+)
+
+// UeContextStoreConfig selects where UE contexts are persisted when enableDBStore is set.
+// The embedded backend keeps them in a local file for single-node deployments without MongoDB.
+type UeContextStoreConfig struct {
+	Backend        string        `yaml:"backend,omitempty"`        // Optional; mongodb, memory or embedded, defaults to mongodb
+	Collection     string        `yaml:"collection,omitempty"`     // Optional; MongoDB collection, defaults to amf.data.amfState
+	Path           string        `yaml:"path,omitempty"`           // Optional; embedded database file, defaults to /var/lib/amf/uecontext.db
+	WriteWorkers   int           `yaml:"writeWorkers,omitempty"`   // Optional; defaults to 4
+	WriteQueueSize int           `yaml:"writeQueueSize,omitempty"` // Optional; defaults to 256
+	LeaseTtl       time.Duration `yaml:"leaseTtl,omitempty"`       // Optional; UE context ownership lease, defaults to 10s
+}
+
+// Trace collection file formats
+const (
+	SignallingTraceFormatXML    = "xml"
+	SignallingTraceFormatBinary = "binary"
+)
+
+// SignallingTraceConfig controls the recording of the NAS and NGAP messages of UEs with
+// subscriber or equipment trace activated by the UDM (TS 32.422 4.1.2).
+type SignallingTraceConfig struct {
+	Enabled   bool   `yaml:"enabled,omitempty"`   // Optional; defaults to false, trace is still propagated to the NG-RAN
+	Directory string `yaml:"directory,omitempty"` // Optional; trace collection files, defaults to /var/log/amf/trace
+	Format    string `yaml:"format,omitempty"`    // Optional; xml (TS 32.423) or binary, defaults to xml
+}
+
+// FlightRecorderConfig controls the per-UE flight recorder, a timeline of the latest NGAP and
+// NAS messages, GMM state transitions, timer events and SBI calls of each UE that is
+// retrieved over OAM for debugging.
+type FlightRecorderConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"` // Optional; defaults to false
+	Size    int  `yaml:"size,omitempty"`    // Optional; records kept per UE, defaults to 256
+	Persist bool `yaml:"persist,omitempty"` // Optional; store the records with the UE context in the DB
+}
+
+// PacketCaptureConfig allows captures of the NGAP PDUs of selected gNBs or UEs, and of the
+// plain NAS messages of the UEs, to be started over OAM for field debugging. Captures are
+// written as pcapng files Wireshark dissects directly.
+type PacketCaptureConfig struct {
+	Enabled            bool   `yaml:"enabled,omitempty"`            // Optional; defaults to false, no capture can be started
+	Directory          string `yaml:"directory,omitempty"`          // Optional; capture files, defaults to /var/log/amf/pcap
+	MaxFileSize        int64  `yaml:"maxFileSize,omitempty"`        // Optional; bytes per capture file, defaults to 100 MiB
+	AllowNasDecryption bool   `yaml:"allowNasDecryption,omitempty"` // Optional; allow captures of plain NAS messages, defaults to false
+}
+
+// LoggingConfig selects the encoding of the log lines and the log level of each subsystem.
+// Levels set here override the AMF level of the logger section, and can be changed at
+// runtime over OAM.
+type LoggingConfig struct {
+	Encoding string            `yaml:"encoding,omitempty"` // Optional; console or json, defaults to console
+	Levels   map[string]string `yaml:"levels,omitempty"`   // Optional; level per subsystem, e.g. NGAP: debug
+}
+
+// RacsConfig controls Radio Capability Signalling optimisation (TS 23.501 5.4.4.1a). UE radio
+// capability IDs are resolved with the UCMF, or with a local dictionary file when no UCMF is
+// deployed.
+type RacsConfig struct {
+	Enabled              bool   `yaml:"enabled,omitempty"`              // Optional; NG-RAN supports RACS, send IDs instead of capabilities
+	UcmfUri              string `yaml:"ucmfUri,omitempty"`              // Optional; Nucmf_UECapabilityManagement apiRoot
+	DictionaryFile       string `yaml:"dictionaryFile,omitempty"`       // Optional; YAML file of UE radio capability IDs, used without UCMF
+	MaxDictionaryEntries int    `yaml:"maxDictionaryEntries,omitempty"` // Optional; cached IDs, defaults to 10000
+}
+
+const (
+	PagingStrategyBroadcast  = "broadcast"
+	PagingStrategyEscalating = "escalating"
+)
+
+const (
+	PagingScopeLastKnownRanNode = "lastKnownRanNode"
+	PagingScopeTai              = "tai"
+	PagingScopeRegistrationArea = "registrationArea"
+)
+
+// PagingConfig selects the NG-RAN nodes a CM-IDLE UE is paged at. The broadcast strategy pages
+// the registration area on every T3513 attempt; the escalating strategy pages the stages in
+// order, each one for its own attempts, before giving up.
+type PagingConfig struct {
+	Strategy             string              `yaml:"strategy,omitempty"`             // Optional; broadcast (default) or escalating
+	Stages               []PagingStageConfig `yaml:"stages,omitempty"`               // Optional; defaults to lastKnownRanNode, tai, registrationArea
+	HighPriorityStages   []PagingStageConfig `yaml:"highPriorityStages,omitempty"`   // Optional; defaults to registrationArea
+	HighPriorityPpi      int32               `yaml:"highPriorityPpi,omitempty"`      // Optional; paging policy indicators 1..n are high priority
+	HighPriorityArpLevel int32               `yaml:"highPriorityArpLevel,omitempty"` // Optional; ARP priority levels 1..n are high priority
+}
+
+type PagingStageConfig struct {
+	Scope    string        `yaml:"scope"`              // lastKnownRanNode, tai or registrationArea
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // Optional; defaults to the T3513 expireTime
+	Attempts int           `yaml:"attempts,omitempty"` // Optional; defaults to 1
+}
+
 type Configuration struct {
 	AmfName                         string                    `yaml:"amfName,omitempty"`
 	AmfId                           string                    `yaml:"amfId,omitempty"`
@@ -78,9 +208,20 @@ type Configuration struct {
 	T3513                           TimerValue                `yaml:"t3513"`
 	T3522                           TimerValue                `yaml:"t3522"`
 	T3550                           TimerValue                `yaml:"t3550"`
+	T3555                           TimerValue                `yaml:"t3555"`
 	T3560                           TimerValue                `yaml:"t3560"`
 	T3565                           TimerValue                `yaml:"t3565"`
 	Telemetry                       *TelemetryConfig          `yaml:"telemetry,omitempty"`
+	OverloadControl                 *OverloadControlConfig    `yaml:"overloadControl,omitempty"`
+	PlannedRemoval                  *PlannedRemovalConfig     `yaml:"plannedRemoval,omitempty"`
+	UeContextStore                  *UeContextStoreConfig     `yaml:"ueContextStore,omitempty"`
+	PowerSaving                     *PowerSavingConfig        `yaml:"powerSaving,omitempty"`
+	SignallingTrace                 *SignallingTraceConfig    `yaml:"signallingTrace,omitempty"`
+	FlightRecorder                  *FlightRecorderConfig     `yaml:"flightRecorder,omitempty"`
+	PacketCapture                   *PacketCaptureConfig      `yaml:"packetCapture,omitempty"`
+	Logging                         *LoggingConfig            `yaml:"logging,omitempty"`
+	Racs                            *RacsConfig               `yaml:"racs,omitempty"`
+	Paging                          *PagingConfig             `yaml:"paging,omitempty"`
 
 	EnableSctpLb             bool      `yaml:"enableSctpLb"`
 	EnableDbStore            bool      `yaml:"enableDBStore"`
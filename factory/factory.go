@@ -15,8 +15,10 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"time"
 
 	"github.com/omec-project/amf/logger"
+	"go.uber.org/zap/zapcore"
 	"go.yaml.in/yaml/v4"
 )
 
@@ -55,6 +57,52 @@ func InitConfigFactory(f string) error {
 			return fmt.Errorf("OTLP endpoint is not set in the configuration")
 		}
 	}
+	if oc := AmfConfig.Configuration.OverloadControl; oc != nil && oc.Enabled {
+		if err = setOverloadControlDefaults(oc); err != nil {
+			return err
+		}
+	}
+	if ps := AmfConfig.Configuration.PowerSaving; ps != nil {
+		if err = setPowerSavingDefaults(ps); err != nil {
+			return err
+		}
+	}
+	if st := AmfConfig.Configuration.SignallingTrace; st != nil {
+		if err = setSignallingTraceDefaults(st); err != nil {
+			return err
+		}
+	}
+	if fr := AmfConfig.Configuration.FlightRecorder; fr != nil {
+		if err = setFlightRecorderDefaults(fr); err != nil {
+			return err
+		}
+	}
+	if pc := AmfConfig.Configuration.PacketCapture; pc != nil {
+		if err = setPacketCaptureDefaults(pc); err != nil {
+			return err
+		}
+	}
+	if logging := AmfConfig.Configuration.Logging; logging != nil {
+		if err = setLoggingDefaults(logging); err != nil {
+			return err
+		}
+	}
+	if racs := AmfConfig.Configuration.Racs; racs != nil {
+		if err = setRacsDefaults(racs); err != nil {
+			return err
+		}
+	}
+	if paging := AmfConfig.Configuration.Paging; paging != nil {
+		if err = setPagingDefaults(paging); err != nil {
+			return err
+		}
+	}
+	if AmfConfig.Configuration.UeContextStore == nil {
+		AmfConfig.Configuration.UeContextStore = &UeContextStoreConfig{}
+	}
+	if err = setUeContextStoreDefaults(AmfConfig.Configuration.UeContextStore); err != nil {
+		return err
+	}
 	if err = validateWebuiUri(AmfConfig.Configuration.WebuiUri); err != nil {
 		return err
 	}
@@ -62,6 +110,189 @@ func InitConfigFactory(f string) error {
 	return err
 }
 
+func setOverloadControlDefaults(oc *OverloadControlConfig) error {
+	if oc.CheckInterval <= 0 {
+		oc.CheckInterval = time.Second
+	}
+	if oc.EventQueueHighWatermark <= 0 {
+		oc.EventQueueHighWatermark = 80
+	}
+	if oc.DbQueueHighWatermark <= 0 {
+		oc.DbQueueHighWatermark = 80
+	}
+	if oc.SbiLatencyThreshold <= 0 {
+		oc.SbiLatencyThreshold = 2 * time.Second
+	}
+	if oc.RecoveryPercent <= 0 {
+		oc.RecoveryPercent = 50
+	}
+	if oc.TrafficLoadReduction == 0 {
+		oc.TrafficLoadReduction = 50
+	}
+	if oc.T3346Value <= 0 {
+		oc.T3346Value = 60
+	}
+	if oc.EventQueueHighWatermark > 100 || oc.DbQueueHighWatermark > 100 || oc.RecoveryPercent >= 100 {
+		return fmt.Errorf("overload control watermarks must be percentages")
+	}
+	if oc.TrafficLoadReduction < 1 || oc.TrafficLoadReduction > 99 {
+		return fmt.Errorf("overload control trafficLoadReduction must be in range 1-99, got %d", oc.TrafficLoadReduction)
+	}
+	return nil
+}
+
+func setPowerSavingDefaults(ps *PowerSavingConfig) error {
+	if ps.MaxEdrxValue == nil {
+		maxEdrxValue := uint8(12)
+		ps.MaxEdrxValue = &maxEdrxValue
+	}
+	if *ps.MaxEdrxValue > 12 {
+		return fmt.Errorf("power saving maxEdrxValue must be in range 0-12, got %d", *ps.MaxEdrxValue)
+	}
+	if ps.PagingTimeWindow != nil && *ps.PagingTimeWindow > 15 {
+		return fmt.Errorf("power saving pagingTimeWindow must be in range 0-15, got %d", *ps.PagingTimeWindow)
+	}
+	if ps.MicoT3512Value < 0 {
+		return fmt.Errorf("power saving micoT3512Value must not be negative")
+	}
+	return nil
+}
+
+func setSignallingTraceDefaults(st *SignallingTraceConfig) error {
+	if st.Directory == "" {
+		st.Directory = "/var/log/amf/trace"
+	}
+	switch st.Format {
+	case "":
+		st.Format = SignallingTraceFormatXML
+	case SignallingTraceFormatXML, SignallingTraceFormatBinary:
+	default:
+		return fmt.Errorf("unknown signalling trace format %q", st.Format)
+	}
+	return nil
+}
+
+func setFlightRecorderDefaults(fr *FlightRecorderConfig) error {
+	if fr.Size == 0 {
+		fr.Size = 256
+	}
+	if fr.Size < 0 {
+		return fmt.Errorf("flight recorder size must not be negative")
+	}
+	return nil
+}
+
+func setPacketCaptureDefaults(pc *PacketCaptureConfig) error {
+	if pc.Directory == "" {
+		pc.Directory = "/var/log/amf/pcap"
+	}
+	if pc.MaxFileSize == 0 {
+		pc.MaxFileSize = 100 << 20
+	}
+	if pc.MaxFileSize < 0 {
+		return fmt.Errorf("packet capture maxFileSize must not be negative")
+	}
+	return nil
+}
+
+func setLoggingDefaults(logging *LoggingConfig) error {
+	if logging.Encoding == "" {
+		logging.Encoding = logger.EncodingConsole
+	}
+	if logging.Encoding != logger.EncodingConsole && logging.Encoding != logger.EncodingJson {
+		return fmt.Errorf("unsupported logging encoding %q", logging.Encoding)
+	}
+	for subsystem, level := range logging.Levels {
+		if !logger.IsSubsystem(subsystem) {
+			return fmt.Errorf("unknown logging subsystem %q", subsystem)
+		}
+		if _, err := zapcore.ParseLevel(level); err != nil {
+			return fmt.Errorf("invalid log level of %s: %w", subsystem, err)
+		}
+	}
+	return nil
+}
+
+func setRacsDefaults(racs *RacsConfig) error {
+	if racs.MaxDictionaryEntries == 0 {
+		racs.MaxDictionaryEntries = 10000
+	}
+	if racs.MaxDictionaryEntries < 0 {
+		return fmt.Errorf("racs maxDictionaryEntries must not be negative")
+	}
+	if racs.UcmfUri != "" {
+		if _, err := url.ParseRequestURI(racs.UcmfUri); err != nil {
+			return fmt.Errorf("invalid racs ucmfUri %q: %w", racs.UcmfUri, err)
+		}
+	}
+	return nil
+}
+
+func setPagingDefaults(paging *PagingConfig) error {
+	switch paging.Strategy {
+	case "":
+		paging.Strategy = PagingStrategyBroadcast
+	case PagingStrategyBroadcast, PagingStrategyEscalating:
+	default:
+		return fmt.Errorf("unknown paging strategy %q", paging.Strategy)
+	}
+	if len(paging.Stages) == 0 {
+		paging.Stages = []PagingStageConfig{
+			{Scope: PagingScopeLastKnownRanNode},
+			{Scope: PagingScopeTai},
+			{Scope: PagingScopeRegistrationArea},
+		}
+	}
+	if len(paging.HighPriorityStages) == 0 {
+		paging.HighPriorityStages = []PagingStageConfig{{Scope: PagingScopeRegistrationArea}}
+	}
+	for _, stages := range [][]PagingStageConfig{paging.Stages, paging.HighPriorityStages} {
+		for i := range stages {
+			switch stages[i].Scope {
+			case PagingScopeLastKnownRanNode, PagingScopeTai, PagingScopeRegistrationArea:
+			default:
+				return fmt.Errorf("unknown paging scope %q", stages[i].Scope)
+			}
+			if stages[i].Timeout < 0 || stages[i].Attempts < 0 {
+				return fmt.Errorf("paging stage %s must not have a negative timeout or attempts", stages[i].Scope)
+			}
+			if stages[i].Attempts == 0 {
+				stages[i].Attempts = 1
+			}
+		}
+	}
+	if paging.HighPriorityPpi < 0 || paging.HighPriorityArpLevel < 0 {
+		return fmt.Errorf("paging highPriorityPpi and highPriorityArpLevel must not be negative")
+	}
+	return nil
+}
+
+func setUeContextStoreDefaults(cfg *UeContextStoreConfig) error {
+	switch cfg.Backend {
+	case "":
+		cfg.Backend = UeContextStoreMongoDB
+	case UeContextStoreMongoDB, UeContextStoreMemory, UeContextStoreEmbedded:
+	default:
+		return fmt.Errorf("unknown UE context store backend %q", cfg.Backend)
+	}
+	if cfg.Collection == "" {
+		cfg.Collection = "amf.data.amfState"
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/var/lib/amf/uecontext.db"
+	}
+	if cfg.WriteWorkers <= 0 {
+		cfg.WriteWorkers = 4
+	}
+	if cfg.WriteQueueSize <= 0 {
+		cfg.WriteQueueSize = 256
+	}
+	if cfg.LeaseTtl <= 0 {
+		cfg.LeaseTtl = 10 * time.Second
+	}
+	return nil
+}
+
 func CheckConfigVersion() error {
 	currentVersion := AmfConfig.GetVersion()
 
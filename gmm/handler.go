@@ -24,6 +24,7 @@ import (
 	"github.com/omec-project/amf/context"
 	gmm_message "github.com/omec-project/amf/gmm/message"
 	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/metrics"
 	"github.com/omec-project/amf/nas/nas_security"
 	ngap_message "github.com/omec-project/amf/ngap/message"
 	"github.com/omec-project/amf/producer/callback"
@@ -49,15 +50,19 @@ const (
 )
 
 var (
-	sendDLNASTransport                    = gmm_message.SendDLNASTransport
-	sendReleaseSmContextRequest           = consumer.SendReleaseSmContextRequest
-	getSubscribedNssaiForRegistration     = getSubscribedNssai
-	communicateWithUDMForRegistration     = communicateWithUDM
-	handleRequestedNssaiForRegistration   = handleRequestedNssai
-	assignLadnInfoForRegistration         = assignLadnInfo
-	sendSearchNFInstancesForRegistration  = consumer.SendSearchNFInstances
-	amPolicyControlCreateForRegistration  = consumer.AMPolicyControlCreate
-	sendRegistrationAcceptForRegistration = gmm_message.SendRegistrationAccept
+	sendDLNASTransport                                       = gmm_message.SendDLNASTransport
+	sendReleaseSmContextRequest                              = consumer.SendReleaseSmContextRequest
+	getSubscribedNssaiForRegistration                        = getSubscribedNssai
+	communicateWithUDMForRegistration                        = communicateWithUDM
+	handleRequestedNssaiForRegistration                      = handleRequestedNssai
+	assignLadnInfoForRegistration                            = assignLadnInfo
+	sendSearchNFInstancesForRegistration                     = consumer.SendSearchNFInstances
+	amPolicyControlCreateForRegistration                     = consumer.AMPolicyControlCreate
+	uePolicyControlCreateForRegistration                     = consumer.UEPolicyControlCreate
+	sendRegistrationAcceptForRegistration                    = gmm_message.SendRegistrationAccept
+	sendSearchNFInstancesForAuthentication                   = consumer.SendSearchNFInstances
+	sendUEAuthenticationAuthenticateRequestForAuthentication = consumer.SendUEAuthenticationAuthenticateRequest
+	sendAuth5gAkaConfirmRequestForAuthentication             = consumer.SendAuth5gAkaConfirmRequest
 )
 
 func readBinaryResponseFile(file *os.File) ([]byte, error) {
@@ -94,22 +99,20 @@ func HandleULNASTransport(ctx ctxt.Context, ue *context.AmfUe, anType models.Acc
 	case nasMessage.PayloadContainerTypeLPP:
 		return fmt.Errorf("PayloadContainerTypeLPP has not been implemented yet in UL NAS TRANSPORT")
 	case nasMessage.PayloadContainerTypeSOR:
-		return fmt.Errorf("PayloadContainerTypeSOR has not been implemented yet in UL NAS TRANSPORT")
+		ue.GmmLog.Infoln("AMF Transfer SOR Ack To UDM")
+		return handleSorAck(ctx, ue, ulNasTransport.GetPayloadContainerContents())
 	case nasMessage.PayloadContainerTypeUEPolicy:
+		// TS 23.502 4.2.4.3 step 4: the PCF subscribed to UE policy container notifications
+		// when it started the UE configuration update
 		ue.GmmLog.Infoln("AMF Transfer UEPolicy To PCF")
+		if ue.UePolicyAssociationId == "" {
+			ue.GmmLog.Warnln("UE policy container received without UE policy association")
+		}
 		callback.SendN1MessageNotify(ue, models.N1MESSAGECLASS_UPDP,
 			ulNasTransport.GetPayloadContainerContents(), nil)
 	case nasMessage.PayloadContainerTypeUEParameterUpdate:
 		ue.GmmLog.Infoln("AMF Transfer UEParameterUpdate To UDM")
-		upuMac, err := nasConvert.UpuAckToModels(ulNasTransport.GetPayloadContainerContents())
-		if err != nil {
-			return err
-		}
-		err = consumer.PutUpuAck(ctx, ue, upuMac)
-		if err != nil {
-			return err
-		}
-		ue.GmmLog.Debugf("UpuMac[%s] in UPU ACK NAS Msg", upuMac)
+		return handleUpuAck(ctx, ue, ulNasTransport.GetPayloadContainerContents())
 	case nasMessage.PayloadContainerTypeMultiplePayload:
 		return fmt.Errorf("PayloadContainerTypeMultiplePayload has not been implemented yet in UL NAS TRANSPORT")
 	}
@@ -151,7 +154,7 @@ func transport5GSMMessage(
 	}
 
 	if has && isInitialRequest(requestType) {
-		ue.SmContextList.Delete(pduID)
+		ue.DeleteSmContext(pduID)
 		has, smCtx = false, nil
 	}
 
@@ -545,10 +548,7 @@ func HandleRegistrationRequest(ctx ctxt.Context, ue *context.AmfUe, anType model
 		Procedure: context.OnGoingProcedureRegistration,
 	})
 
-	if ue.T3513 != nil {
-		ue.T3513.Stop()
-		ue.T3513 = nil // clear the timer
-	}
+	ue.StopPaging()
 	if ue.T3565 != nil {
 		ue.T3565.Stop()
 		ue.T3565 = nil // clear the timer
@@ -588,6 +588,8 @@ func HandleRegistrationRequest(ctx ctxt.Context, ue *context.AmfUe, anType model
 
 	ue.RegistrationRequest = registrationRequest
 	ue.SetRegistrationType5GS(registrationRequest.GetRegistrationType5GS())
+	ue.StartProcedure(context.KpiRegistrationProcedure(ue.GetRegistrationType5GS()))
+	ue.StartRegistrationSpan(ctx)
 	switch ue.GetRegistrationType5GS() {
 	case nasMessage.RegistrationType5GSInitialRegistration:
 		ue.GmmLog.Debugf("RegistrationType: Initial Registration")
@@ -596,6 +598,8 @@ func HandleRegistrationRequest(ctx ctxt.Context, ue *context.AmfUe, anType model
 	case nasMessage.RegistrationType5GSPeriodicRegistrationUpdating:
 		ue.GmmLog.Debugf("RegistrationType: Periodic Registration Updating")
 	case nasMessage.RegistrationType5GSEmergencyRegistration:
+		ue.FailProcedure(context.KpiEmergencyRegistration, context.KpiCauseTypeAmf, "not_supported")
+		ue.EndRegistrationSpan("emergency registration not supported")
 		return fmt.Errorf("not Supportted RegistrationType: Emergency Registration")
 	case nasMessage.RegistrationType5GSReserved:
 		ue.SetRegistrationType5GS(nasMessage.RegistrationType5GSInitialRegistration)
@@ -720,6 +724,27 @@ func HandleRegistrationRequest(ctx ctxt.Context, ue *context.AmfUe, anType model
 		return fmt.Errorf("registration reject[Tracking area not allowed]")
 	}
 
+	// TS 23.501 5.21.2.2: an AMF under planned removal does not accept new UEs; the Initial
+	// UE Message is rerouted to the AMF set, where NG-RAN selects the backup AMF as the
+	// GUAMIs of this AMF were reported unavailable (TS 23.502 4.2.2.2.3)
+	if ue.GetRegistrationType5GS() == nasMessage.RegistrationType5GSInitialRegistration && amfSelf.IsDraining() {
+		if len(ranUe.InitialUEMessage) == 0 {
+			gmm_message.SendRegistrationReject(ranUe, nasMessage.Cause5GMMCongestion, "")
+			return fmt.Errorf("registration reject[AMF draining, no Initial UE Message to reroute]")
+		}
+		ngap_message.SendRerouteNasRequest(ue, anType, nil, ranUe.InitialUEMessage, nil)
+		return fmt.Errorf("registration rerouted[AMF draining]")
+	}
+
+	// TS 23.501 5.19.7.2: while the AMF is overloaded, new registrations are rejected with a
+	// back-off timer; periodic and mobility registration updates are still served
+	if ue.GetRegistrationType5GS() == nasMessage.RegistrationType5GSInitialRegistration &&
+		amfSelf.OverloadControl.IsOverloaded() {
+		metrics.IncrementOverloadRejected()
+		gmm_message.SendRegistrationReject(ranUe, nasMessage.Cause5GMMCongestion, "")
+		return fmt.Errorf("registration reject[Congestion]")
+	}
+
 	if registrationRequest.UESecurityCapability != nil {
 		ue.UESecurityCapability = *registrationRequest.UESecurityCapability
 	} else {
@@ -805,7 +830,7 @@ func HandleInitialRegistration(ctx ctxt.Context, ue *context.AmfUe, anType model
 		ue.Capability5GMM = *registrationRequest.Capability5GMM
 	}
 
-	if len(ue.AllowedNssai[anType]) == 0 {
+	if len(ue.AllowedNssai[anType]) == 0 && len(ue.PendingNssai) == 0 {
 		gmm_message.SendRegistrationReject(ranUe, nasMessage.Cause5GMM5GSServicesNotAllowed, "")
 		ngap_message.SendUEContextReleaseCommand(ranUe, context.UeContextN2NormalRelease,
 			ngapType.CausePresentNas, ngapType.CauseNasPresentNormalRelease)
@@ -821,14 +846,11 @@ func HandleInitialRegistration(ctx ctxt.Context, ue *context.AmfUe, anType model
 
 	storeLastVisitedRegisteredTAI(ue, registrationRequest.LastVisitedRegisteredTAI)
 
-	if registrationRequest.MICOIndication != nil {
-		ue.GmmLog.Warnf("Receive MICO Indication[RAAI: %d], Not Supported",
-			registrationRequest.GetRAAI())
-	}
-
 	// TODO: Negotiate DRX value if need (TS 23.501 5.4.5)
 	negotiateDRXParameters(ue, registrationRequest.RequestedDRXParameters)
 
+	handleUeRadioCapabilityId(ctx, ue, registrationRequest)
+
 	// TODO (step 10 optional): send Namf_Communication_RegistrationCompleteNotify to old AMF if need
 	if ue.ServingAmfChanged {
 		// If the AMF has changed the new AMF notifies the old AMF that the registration of the UE in the new AMF is completed
@@ -879,6 +901,8 @@ func HandleInitialRegistration(ctx ctxt.Context, ue *context.AmfUe, anType model
 					models.NFSERVICESTATUS_REGISTERED)
 				if pcfUri != "" {
 					ue.PcfId = nfProfile.NfInstanceId
+					ue.PcfUePolicyUri = util.SearchNFServiceUri(nfProfile, models.SERVICENAME_NPCF_UE_POLICY_CONTROL,
+						models.NFSERVICESTATUS_REGISTERED)
 					break
 				}
 			}
@@ -902,6 +926,16 @@ func HandleInitialRegistration(ctx ctxt.Context, ue *context.AmfUe, anType model
 		return err
 	}
 
+	// the registration goes on without UE policies if the PCF does not provide them
+	if ue.PcfUePolicyUri != "" && ue.UePolicyAssociationId == "" {
+		problemDetails, err = uePolicyControlCreateForRegistration(ctx, ue, anType)
+		if problemDetails != nil {
+			ue.GmmLog.Errorf("UE Policy Control Create Failed Problem[%+v]", problemDetails)
+		} else if err != nil {
+			ue.GmmLog.Errorf("UE Policy Control Create Error[%+v]", err)
+		}
+	}
+
 	// Service Area Restriction are applicable only to 3GPP access
 	if anType == models.ACCESSTYPE__3_GPP_ACCESS {
 		if ue.AmPolicyAssociation != nil && ue.AmPolicyAssociation.ServAreaRes != nil {
@@ -938,6 +972,7 @@ func HandleInitialRegistration(ctx ctxt.Context, ue *context.AmfUe, anType model
 	} else {
 		ue.Non3gppDeregistrationTimerValue = amfSelf.Non3gppDeregistrationTimerValue
 	}
+	negotiatePowerSaving(ue, anType, registrationRequest)
 
 	if anType == models.ACCESSTYPE__3_GPP_ACCESS {
 		sendRegistrationAcceptForRegistration(ue, anType, nil, nil, nil, nil, nil)
@@ -976,8 +1011,10 @@ func HandleMobilityAndPeriodicRegistrationUpdating(ctx ctxt.Context, ue *context
 		if registrationRequest.GetNGRanRcu() == nasMessage.NGRanRadioCapabilityUpdateNeeded {
 			ue.UeRadioCapability = ""
 			ue.UeRadioCapabilityForPaging = nil
+			ue.UeRadioCapabilityId = ""
 		}
 	}
+	handleUeRadioCapabilityId(ctx, ue, registrationRequest)
 
 	// Registration with AMF re-allocation (TS 23.502 4.2.2.2.3)
 	if len(ue.SubscribedNssai) == 0 {
@@ -999,11 +1036,6 @@ func HandleMobilityAndPeriodicRegistrationUpdating(ctx ctxt.Context, ue *context
 
 	storeLastVisitedRegisteredTAI(ue, registrationRequest.LastVisitedRegisteredTAI)
 
-	if registrationRequest.MICOIndication != nil {
-		ue.GmmLog.Warnf("Receive MICO Indication[RAAI: %d], Not Supported",
-			registrationRequest.GetRAAI())
-	}
-
 	// TODO: Negotiate DRX value if need (TS 23.501 5.4.5)
 	negotiateDRXParameters(ue, registrationRequest.RequestedDRXParameters)
 
@@ -1283,6 +1315,7 @@ func HandleMobilityAndPeriodicRegistrationUpdating(ctx ctxt.Context, ue *context
 
 	amfSelf.AllocateRegistrationArea(ue, anType)
 	assignLadnInfo(ue, registrationRequest, anType)
+	negotiatePowerSaving(ue, anType, registrationRequest)
 
 	// TODO: GUTI reassignment if need (based on operator poilcy)
 	// TODO: T3512/Non3GPP de-registration timer reassignment if need (based on operator policy)
@@ -1364,6 +1397,47 @@ func negotiateDRXParameters(ue *context.AmfUe, requestedDRXParameters *nasType.R
 	}
 }
 
+// negotiatePowerSaving negotiates MICO mode and eDRX, which the UE requests again in every
+// registration (TS 24.501 5.5.1.2.4, 5.5.1.3.4); the subscription data is known at this point.
+func negotiatePowerSaving(ue *context.AmfUe, anType models.AccessType, registrationRequest *nasMessage.RegistrationRequest) {
+	if anType != models.ACCESSTYPE__3_GPP_ACCESS {
+		return
+	}
+	amfSelf := context.AMF_Self()
+	ue.MicoMode, ue.MicoAllPlmnRegistrationArea, ue.MicoStrictPeriodicRegistration = false, false, false
+	ue.EdrxParameters = nil
+	ue.T3512Value = amfSelf.T3512Value
+
+	if mico := registrationRequest.MICOIndication; mico != nil {
+		raai := mico.GetRAAI() == 1
+		sprti := mico.Octet&context.MicoIndicationSPRTI != 0
+		grant := context.NegotiateMico(raai, sprti, ue.AccessAndMobilitySubscriptionData, amfSelf.PowerSaving)
+		if grant.Mico {
+			ue.MicoMode = true
+			ue.MicoAllPlmnRegistrationArea = grant.AllPlmnRegistrationArea
+			ue.MicoStrictPeriodicRegistration = grant.StrictPeriodicRegistration
+			if amfSelf.PowerSaving.MicoT3512Value > 0 {
+				ue.T3512Value = amfSelf.PowerSaving.MicoT3512Value
+			}
+			ue.GmmLog.Infof("MICO mode granted [RAAI: %t, SPRTI: %t]", grant.AllPlmnRegistrationArea,
+				grant.StrictPeriodicRegistration)
+		} else {
+			ue.GmmLog.Infof("MICO mode requested [RAAI: %t, SPRTI: %t], not granted", raai, sprti)
+		}
+	}
+
+	if edrx := registrationRequest.RequestedExtendedDRXParameters; edrx != nil {
+		requested := context.EdrxParametersFromOctet(edrx.Octet)
+		ue.EdrxParameters = context.NegotiateEdrx(requested, ue.AccessAndMobilitySubscriptionData, amfSelf.PowerSaving)
+		if ue.EdrxParameters != nil {
+			ue.GmmLog.Infof("eDRX granted [cycle: %v, PTW: %d]", ue.EdrxParameters.Cycle(),
+				ue.EdrxParameters.PagingTimeWindow)
+		} else {
+			ue.GmmLog.Infof("eDRX requested [cycle: %v], not granted", requested.Cycle())
+		}
+	}
+}
+
 func communicateWithUDM(ctx ctxt.Context, ue *context.AmfUe, accessType models.AccessType) error {
 	ue.GmmLog.Debugln("communicateWithUDM")
 	amfSelf := context.AMF_Self()
@@ -1421,6 +1495,11 @@ func communicateWithUDM(ctx ctxt.Context, ue *context.AmfUe, accessType models.A
 		return fmt.Errorf("SDM_Get UeContextInSmfData Error[%+v]", err)
 	}
 
+	// trace is not required for the registration to proceed
+	if err = RefreshTraceData(ctx, ue); err != nil {
+		ue.GmmLog.Errorln(err)
+	}
+
 	problemDetails, err = consumer.SDMSubscribe(ctx, ue)
 	if problemDetails != nil {
 		ue.GmmLog.Errorf("SDM Subscribe Failed Problem[%+v]", problemDetails)
@@ -1601,6 +1680,9 @@ func handleRequestedNssai(ctx ctxt.Context, ue *context.AmfUe, registrationReque
 			}
 		}
 	}
+
+	// S-NSSAIs subject to NSSAA are only allowed once the authentication succeeded
+	ue.SelectPendingNssai(anType)
 	return nil
 }
 
@@ -1792,9 +1874,18 @@ func HandleConfigurationUpdateComplete(ue *context.AmfUe,
 		return fmt.Errorf("NAS message integrity check failed")
 	}
 
-	// TODO: Stop timer T3555 in TS 24.501 Figure 5.4.4.1.1 in handler
+	if ue.T3555 != nil {
+		ue.T3555.Stop()
+		ue.T3555 = nil // clear the timer
+	}
 	// TODO: Send acknowledgment by Nudm_SMD_Info_Service to UDM in handler
 
+	// the acknowledgement was requested for a 5G-GUTI reallocation, see ReallocateGuti, the UE
+	// uses the new 5G-GUTI from now on
+	ue.ConfigurationUpdateIndication = nasType.ConfigurationUpdateIndication{}
+	context.AMF_Self().FreeOldGuti(ue)
+	context.StoreContextInDB(ue)
+	ue.EndOamProcedure(context.OamProcedureGutiReallocation, context.OamOutcomeCompleted, "")
 	return nil
 }
 
@@ -1804,7 +1895,7 @@ func AuthenticationProcedure(ctx ctxt.Context, ue *context.AmfUe, accessType mod
 	// Check whether UE has SUCI and SUPI
 	if IdentityVerification(ue) {
 		ue.GmmLog.Debugln("UE has SUCI / SUPI")
-		if ue.SecurityContextIsValid() {
+		if ue.SecurityContextIsValid() && ue.GetOnGoing(accessType).Procedure != context.OnGoingProcedureReauthentication {
 			ue.GmmLog.Debugln("UE has a valid security context - skip the authentication procedure")
 			return true, nil
 		}
@@ -1817,7 +1908,7 @@ func AuthenticationProcedure(ctx ctxt.Context, ue *context.AmfUe, accessType mod
 	amfSelf := context.AMF_Self()
 
 	// TODO: consider ausf group id, Routing ID part of SUCI
-	resp, err := consumer.SendSearchNFInstances(ctx, amfSelf.NrfUri, models.NFTYPE_AUSF, models.NFTYPE_AMF, nil)
+	resp, err := sendSearchNFInstancesForAuthentication(ctx, amfSelf.NrfUri, models.NFTYPE_AUSF, models.NFTYPE_AMF, nil)
 	if err != nil {
 		ue.GmmLog.Error("AMF can not select an AUSF by NRF")
 		return false, err
@@ -1839,7 +1930,7 @@ func AuthenticationProcedure(ctx ctxt.Context, ue *context.AmfUe, accessType mod
 	}
 	ue.AusfUri = ausfUri
 
-	response, problemDetails, err := consumer.SendUEAuthenticationAuthenticateRequest(ctx, ue, nil)
+	response, problemDetails, err := sendUEAuthenticationAuthenticateRequestForAuthentication(ctx, ue, nil)
 	if err != nil {
 		ue.GmmLog.Errorf("Nausf_UEAU Authenticate Request Error: %+v", err)
 		return false, errors.New("Authentication procedure failed")
@@ -1856,15 +1947,29 @@ func AuthenticationProcedure(ctx ctxt.Context, ue *context.AmfUe, accessType mod
 	}
 	ue.GmmLog.Infoln("ngKSI after 5G-AKA:", ue.NgKsi.Ksi)
 
+	ue.StartProcedure(context.KpiAuthentication)
 	gmm_message.SendAuthenticationRequest(ue.GetRanUe(accessType))
 	return false, nil
 }
 
-func NetworkInitiatedDeregistrationProcedure(ctx ctxt.Context, ue *context.AmfUe, accessType models.AccessType) (err error) {
+// nwInitiatedDeregistrationArgs returns the re-registration required flag and the 5GMM cause
+// of a network initiated deregistration event; re-registration is required unless the event
+// says otherwise
+func nwInitiatedDeregistrationArgs(args fsm.ArgsType) (reRegistrationRequired bool, cause5GMM uint8) {
+	reRegistrationRequired, ok := args[ArgReRegistrationRequired].(bool)
+	if !ok {
+		reRegistrationRequired = true
+	}
+	cause5GMM, _ = args[ArgCause5GMM].(uint8)
+	return reRegistrationRequired, cause5GMM
+}
+
+func NetworkInitiatedDeregistrationProcedure(ctx ctxt.Context, ue *context.AmfUe, accessType models.AccessType,
+	reRegistrationRequired bool, cause5GMM uint8,
+) (err error) {
 	anType := util.AnTypeToNas(accessType)
 	if ue.CmConnect(accessType) && ue.State[accessType].Is(context.Registered) {
-		// setting reregistration required flag to true
-		gmm_message.SendDeregistrationRequest(ue.GetRanUe(accessType), anType, true, 0)
+		gmm_message.SendDeregistrationRequest(ue.GetRanUe(accessType), anType, reRegistrationRequired, cause5GMM)
 	} else {
 		SetDeregisteredState(ue, anType)
 	}
@@ -1906,6 +2011,7 @@ func NetworkInitiatedDeregistrationProcedure(ctx ctxt.Context, ue *context.AmfUe
 				err = fmt.Errorf("AM Policy Control Delete Error[%v]", err.Error())
 				ue.GmmLog.Errorln(err)
 			}
+			deleteUePolicyAssociation(ctx, ue)
 		}
 	}
 	// if ue is not connected mode, removing UE Context
@@ -1935,11 +2041,9 @@ func HandleServiceRequest(ctx ctxt.Context, ue *context.AmfUe, anType models.Acc
 	}
 
 	ue.GmmLog.Infoln("handle Service Request")
+	ue.StartProcedure(context.KpiServiceRequest)
 
-	if ue.T3513 != nil {
-		ue.T3513.Stop()
-		ue.T3513 = nil // clear the timer
-	}
+	ue.StopPaging()
 	if ue.T3565 != nil {
 		ue.T3565.Stop()
 		ue.T3565 = nil // clear the timer
@@ -2022,6 +2126,10 @@ func HandleServiceRequest(ctx ctxt.Context, ue *context.AmfUe, anType models.Acc
 	ranUe.UeContextRequest = true
 	if serviceType == nasMessage.ServiceTypeSignalling {
 		err := sendServiceAccept(ue, anType, ctxList, suList, nil, nil, nil, nil)
+		if err == nil {
+			deliverPendingNssaaMessages(ue, anType)
+			deliverPendingTransparentContainers(ue, anType)
+		}
 		return err
 	}
 	n1n2Message := ue.N1N2Message
@@ -2161,6 +2269,8 @@ func HandleServiceRequest(ctx ctxt.Context, ue *context.AmfUe, anType models.Acc
 					sendDLNASTransport(ranUe, anType, nasMessage.PayloadContainerTypeUEPolicy, n1Msg, 0, 0, nil, 0)
 				}
 				ue.N1N2Message = nil
+				deliverPendingNssaaMessages(ue, anType)
+				deliverPendingTransparentContainers(ue, anType)
 				return nil
 			}
 			// TODO: Area of validity for the N2 SM information
@@ -2248,13 +2358,18 @@ func HandleServiceRequest(ctx ctxt.Context, ue *context.AmfUe, anType models.Acc
 				return err
 			}
 		}
-		// downlink signaling
-		if ue.ConfigurationUpdateMessage != nil {
+		// downlink signaling, the queued NSSAA messages and transparent containers are sent below
+		if n1n2Message == nil && (ue.ConfigurationUpdateMessage != nil || len(ue.PendingNssaaMessages) > 0 ||
+			len(ue.PendingTransparentContainers) > 0) {
 			err := sendServiceAccept(ue, anType, ctxList, suList,
 				acceptPduSessionPsi, reactivationResult, errPduSessionId, errCause)
 			if err != nil {
 				return err
 			}
+		}
+		// the Configuration Update Command after NSSAA follows the NSSAA messages
+		deliverPendingNssaaMessages(ue, anType)
+		if ue.ConfigurationUpdateMessage != nil {
 			mobilityRestrictionList := ngap_message.BuildIEMobilityRestrictionList(ue)
 			ngap_message.SendDownlinkNasTransport(ue.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS),
 				ue.ConfigurationUpdateMessage, &mobilityRestrictionList)
@@ -2307,6 +2422,8 @@ func HandleServiceRequest(ctx ctxt.Context, ue *context.AmfUe, anType models.Acc
 		ue.GmmLog.Info(errPduSessionId, errCause)
 	}
 	ue.N1N2Message = nil
+	deliverPendingNssaaMessages(ue, anType)
+	deliverPendingTransparentContainers(ue, anType)
 	return nil
 }
 
@@ -2396,12 +2513,11 @@ func HandleAuthenticationResponse(ctx ctxt.Context, ue *context.AmfUe, accessTyp
 			}
 		}
 
-		response, problemDetails, err := consumer.SendAuth5gAkaConfirmRequest(ctx, ue, hex.EncodeToString(resStar[:]))
+		response, problemDetails, err := sendAuth5gAkaConfirmRequestForAuthentication(ctx, ue, hex.EncodeToString(resStar[:]))
 		if err != nil {
 			return err
 		} else if problemDetails != nil {
-			ue.GmmLog.Debugf("Auth5gAkaConfirm Error[Problem Detail: %+v]", problemDetails)
-			return nil
+			return fmt.Errorf("Auth5gAkaConfirm Error[Problem Detail: %+v]", problemDetails)
 		}
 		switch response.AuthResult {
 		case models.AUTHRESULT_AUTHENTICATION_SUCCESS:
@@ -2410,6 +2526,7 @@ func HandleAuthenticationResponse(ctx ctxt.Context, ue *context.AmfUe, accessTyp
 			ue.SetSupi(response.GetSupi())
 			ue.DerivateKamf()
 			ue.GmmLog.Debugln("ue.DerivateKamf()", ue.Kamf)
+			ue.CompleteProcedure(context.KpiAuthentication)
 			return GmmFSM.SendEvent(ctx, ue.State[accessType], AuthSuccessEvent, fsm.ArgsType{
 				ArgAmfUe:      ue,
 				ArgAccessType: accessType,
@@ -2433,8 +2550,7 @@ func HandleAuthenticationResponse(ctx ctxt.Context, ue *context.AmfUe, accessTyp
 		if err != nil {
 			return err
 		} else if problemDetails != nil {
-			ue.GmmLog.Debugf("EapAuthConfirm Error[Problem Detail: %+v]", problemDetails)
-			return nil
+			return fmt.Errorf("EapAuthConfirm Error[Problem Detail: %+v]", problemDetails)
 		}
 
 		switch response.GetAuthResult() {
@@ -2443,6 +2559,7 @@ func HandleAuthenticationResponse(ctx ctxt.Context, ue *context.AmfUe, accessTyp
 			ue.Kseaf = response.GetKSeaf()
 			ue.SetSupi(response.GetSupi())
 			ue.DerivateKamf()
+			ue.CompleteProcedure(context.KpiAuthentication)
 			// TODO: select enc/int algorithm based on ue security capability & amf's policy,
 			// then generate KnasEnc, KnasInt
 			return GmmFSM.SendEvent(ctx, ue.State[accessType], SecurityModeSuccessEvent, fsm.ArgsType{
@@ -2489,16 +2606,22 @@ func HandleAuthenticationFailure(ctx ctxt.Context, ue *context.AmfUe, anType mod
 	}
 
 	cause5GMM := authenticationFailure.GetCauseValue()
+	// a rejected authentication failed with the cause reported by the UE
+	failAuthentication := func() {
+		ue.FailProcedure(context.KpiAuthentication, context.KpiCauseType5GMM, context.Kpi5GMMCause(cause5GMM))
+	}
 
 	switch ue.AuthenticationCtx.AuthType {
 	case models.AUTHTYPE__5_G_AKA:
 		switch cause5GMM {
 		case nasMessage.Cause5GMMMACFailure:
 			ue.GmmLog.Warnln("Authentication Failure Cause: Mac Failure")
+			failAuthentication()
 			gmm_message.SendAuthenticationReject(ranUe, "")
 			return GmmFSM.SendEvent(ctx, ue.State[anType], AuthFailEvent, fsm.ArgsType{ArgAmfUe: ue, ArgAccessType: anType})
 		case nasMessage.Cause5GMMNon5GAuthenticationUnacceptable:
 			ue.GmmLog.Warnln("Authentication Failure Cause: Non-5G Authentication Unacceptable")
+			failAuthentication()
 			gmm_message.SendAuthenticationReject(ranUe, "")
 			return GmmFSM.SendEvent(ctx, ue.State[anType], AuthFailEvent, fsm.ArgsType{ArgAmfUe: ue, ArgAccessType: anType})
 		case nasMessage.Cause5GMMngKSIAlreadyInUse:
@@ -2518,6 +2641,7 @@ func HandleAuthenticationFailure(ctx ctxt.Context, ue *context.AmfUe, anType mod
 			ue.AuthFailureCauseSynchFailureTimes++
 			if ue.AuthFailureCauseSynchFailureTimes >= 2 {
 				ue.GmmLog.Warnf("2 consecutive Synch Failure, terminate authentication procedure")
+				failAuthentication()
 				gmm_message.SendAuthenticationReject(ranUe, "")
 				return GmmFSM.SendEvent(ctx, ue.State[anType], AuthFailEvent, fsm.ArgsType{ArgAmfUe: ue, ArgAccessType: anType})
 			}
@@ -2526,12 +2650,11 @@ func HandleAuthenticationFailure(ctx ctxt.Context, ue *context.AmfUe, anType mod
 			resynchronizationInfo := models.NewResynchronizationInfoWithDefaults()
 			resynchronizationInfo.SetAuts(hex.EncodeToString(auts[:]))
 
-			response, problemDetails, err := consumer.SendUEAuthenticationAuthenticateRequest(ctx, ue, resynchronizationInfo)
+			response, problemDetails, err := sendUEAuthenticationAuthenticateRequestForAuthentication(ctx, ue, resynchronizationInfo)
 			if err != nil {
 				return err
 			} else if problemDetails != nil {
-				ue.GmmLog.Errorf("Nausf_UEAU Authenticate Request Error[Problem Detail: %+v]", problemDetails)
-				return nil
+				return fmt.Errorf("Nausf_UEAU Authenticate Request Error[Problem Detail: %+v]", problemDetails)
 			}
 			ue.AuthenticationCtx = response
 			ue.ABBA = []uint8{0x00, 0x00}
@@ -2564,26 +2687,35 @@ func HandleRegistrationComplete(ctx ctxt.Context, ue *context.AmfUe, accessType
 		ue.T3550 = nil // clear the timer
 	}
 
-	// if registrationComplete.SORTransparentContainer != nil {
-	// 	TODO: if at regsitration procedure 14b, udm provide amf Steering of Roaming info & request an ack,
-	// 	AMF provides the UE's ack with Nudm_SDM_Info (SOR not supportted in this stage)
-	// }
+	// the UE acknowledges the SoR information of the Registration Accept, the AMF provides the
+	// ack to the UDM with Nudm_SDM_Info (TS 23.122 C.2)
+	if registrationComplete.SORTransparentContainer != nil {
+		if err := handleSorAck(ctx, ue, registrationComplete.SORTransparentContainer.Buffer); err != nil {
+			ue.GmmLog.Errorf("SoR ack failed: %+v", err)
+		}
+	}
 
 	// TODO: if
 	//	1. AMF has evaluated the support of IMS Voice over PS Sessions (TS 23.501 5.16.3.2)
 	//	2. AMF determines that it needs to update the Homogeneous Support of IMS Voice over PS Sessions (TS 23.501 5.16.3.3)
 	// Then invoke Nudm_UECM_Update to send "Homogeneous Support of IMS Voice over PS Sessions" indication to udm
 
+	// the NAS signalling connection is kept for NSSAA of the pending NSSAI
 	if ue.RegistrationRequest.UplinkDataStatus == nil &&
-		ue.RegistrationRequest.GetFOR() == nasMessage.FollowOnRequestNoPending {
+		ue.RegistrationRequest.GetFOR() == nasMessage.FollowOnRequestNoPending && len(ue.PendingNssai) == 0 {
 		ngap_message.SendUEContextReleaseCommand(ue.GetRanUe(accessType), context.UeContextN2NormalRelease,
 			ngapType.CausePresentNas, ngapType.CauseNasPresentNormalRelease)
 	}
 
-	return GmmFSM.SendEvent(ctx, ue.State[accessType], ContextSetupSuccessEvent, fsm.ArgsType{
+	if err := GmmFSM.SendEvent(ctx, ue.State[accessType], ContextSetupSuccessEvent, fsm.ArgsType{
 		ArgAmfUe:      ue,
 		ArgAccessType: accessType,
-	})
+	}); err != nil {
+		return err
+	}
+	startNssaa(ue, accessType)
+	deliverUpuAfterRegistration(ue, accessType)
+	return nil
 }
 
 // TS 33.501 6.7.2
@@ -2605,6 +2737,7 @@ func HandleSecurityModeComplete(ctx ctxt.Context, ue *context.AmfUe, anType mode
 		// update Kgnb/Kn3iwf
 		ue.UpdateSecurityContext(anType)
 	}
+	ue.CompleteProcedure(context.KpiSecurityModeControl)
 
 	if securityModeComplete.IMEISV != nil {
 		ue.GmmLog.Debugln("receieve IMEISV")
@@ -2653,7 +2786,12 @@ func HandleSecurityModeReject(ue *context.AmfUe, anType models.AccessType,
 	cause := securityModeReject.GetCauseValue()
 	ue.GmmLog.Warnf("Reject Cause: %s", nasMessage.Cause5GMMToString(cause))
 	ue.GmmLog.Error("UE reject the security mode command, abort the ongoing procedure")
+	ue.FailProcedure(context.KpiSecurityModeControl, context.KpiCauseType5GMM, context.Kpi5GMMCause(cause))
 
+	// a re-authenticated UE stays registered with its previous security context
+	if ue.GetOnGoing(anType).Procedure == context.OnGoingProcedureReauthentication {
+		return nil
+	}
 	ue.SecurityContextAvailable = false
 
 	ngap_message.SendUEContextReleaseCommand(ue.GetRanUe(anType), context.UeContextReleaseUeContext,
@@ -2700,6 +2838,7 @@ func HandleDeregistrationRequest(ctx ctxt.Context, ue *context.AmfUe, anType mod
 			} else if err != nil {
 				ue.GmmLog.Errorf("AM Policy Control Delete Error[%v]", err.Error())
 			}
+			deleteUePolicyAssociation(ctx, ue)
 		}
 	}
 
@@ -2790,6 +2929,7 @@ func HandleDeregistrationAccept(ctx ctxt.Context, ue *context.AmfUe, anType mode
 	}
 
 	ue.DeregistrationTargetAccessType = 0
+	ue.EndOamProcedure(context.OamProcedureDeregistration, context.OamOutcomeCompleted, "")
 
 	return GmmFSM.SendEvent(ctx, ue.State[models.ACCESSTYPE__3_GPP_ACCESS], DeregistrationAcceptEvent, fsm.ArgsType{
 		ArgAmfUe:      ue,
@@ -2815,3 +2955,17 @@ func HandleAuthenticationError(ue *context.AmfUe, anType models.AccessType) erro
 	}
 	return nil
 }
+
+// deleteUePolicyAssociation deletes the UE policy association together with the AM policy
+// association, once the UE is deregistered over both accesses
+func deleteUePolicyAssociation(ctx ctxt.Context, ue *context.AmfUe) {
+	if ue.UePolicyAssociationId == "" {
+		return
+	}
+	problemDetails, err := consumer.UEPolicyControlDelete(ctx, ue)
+	if problemDetails != nil {
+		ue.GmmLog.Errorf("UE Policy Control Delete Failed Problem[%+v]", problemDetails)
+	} else if err != nil {
+		ue.GmmLog.Errorf("UE Policy Control Delete Error[%v]", err.Error())
+	}
+}
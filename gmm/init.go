@@ -19,6 +19,7 @@ const (
 	AuthRestartEvent               fsm.EventType = "Authentication Restart"
 	AuthFailEvent                  fsm.EventType = "Authentication Fail"
 	AuthErrorEvent                 fsm.EventType = "Authentication Error"
+	ReauthenticationFailEvent      fsm.EventType = "Reauthentication Fail"
 	SecurityModeSuccessEvent       fsm.EventType = "SecurityMode Success"
 	SecurityModeFailEvent          fsm.EventType = "SecurityMode Fail"
 	SecuritySkipEvent              fsm.EventType = "Security Skip"
@@ -42,6 +43,9 @@ const (
 	Arg3GPPDeregistered    string = "3GPP Deregistered"
 	ArgNon3GPPDeregistered string = "Non3GPP Deregistered"
 	ArgNssai               string = "Nssai"
+	// re-registration required flag and 5GMM cause of a network initiated deregistration
+	ArgReRegistrationRequired string = "Re-registration Required"
+	ArgCause5GMM              string = "5GMM Cause"
 )
 
 var transitions = fsm.Transitions{
@@ -57,10 +61,12 @@ var transitions = fsm.Transitions{
 	{Event: AuthSuccessEvent, From: context.Authentication, To: context.SecurityMode},
 	{Event: AuthFailEvent, From: context.Authentication, To: context.Deregistered},
 	{Event: AuthErrorEvent, From: context.Authentication, To: context.Deregistered},
+	{Event: ReauthenticationFailEvent, From: context.Authentication, To: context.Registered},
 	{Event: SecurityModeSuccessEvent, From: context.SecurityMode, To: context.ContextSetup},
 	{Event: SecuritySkipEvent, From: context.SecurityMode, To: context.ContextSetup},
 	{Event: SecurityModeFailEvent, From: context.SecurityMode, To: context.Deregistered},
 	{Event: SecurityModeAbortEvent, From: context.SecurityMode, To: context.Deregistered},
+	{Event: ReauthenticationFailEvent, From: context.SecurityMode, To: context.Registered},
 	{Event: ContextSetupSuccessEvent, From: context.ContextSetup, To: context.Registered},
 	{Event: ContextSetupFailEvent, From: context.ContextSetup, To: context.Deregistered},
 	{Event: InitDeregistrationEvent, From: context.Registered, To: context.DeregistrationInitiated},
@@ -80,7 +86,7 @@ var callbacks = fsm.Callbacks{
 var GmmFSM *fsm.FSM
 
 func init() {
-	if f, err := fsm.NewFSM(transitions, callbacks); err != nil {
+	if f, err := fsm.NewFSM(transitions, traceTransitions(transitions, callbacks)); err != nil {
 		logger.GmmLog.Errorf("initialize Gmm FSM error: %+v", err)
 	} else {
 		GmmFSM = f
@@ -315,7 +315,7 @@ func BuildServiceReject(pDUSessionStatus *[16]bool, cause uint8) ([]byte, error)
 	return m.PlainNasEncode()
 }
 
-// T3346 timer are not supported
+// T3346: included with 5GMM cause #22 while the AMF is overloaded (TS 24.501 5.5.1.2.5)
 func BuildRegistrationReject(ue *context.AmfUe, cause5GMM uint8, eapMessage string) ([]byte, error) {
 	m := nas.NewMessage()
 	m.GmmMessage = nas.NewGmmMessage()
@@ -335,6 +335,14 @@ func BuildRegistrationReject(ue *context.AmfUe, cause5GMM uint8, eapMessage stri
 		registrationReject.T3502Value.SetGPRSTimer2Value(t3502)
 	}
 
+	if cause5GMM == nasMessage.Cause5GMMCongestion {
+		if t3346 := context.AMF_Self().OverloadControl.T3346Value(); t3346 != 0 {
+			registrationReject.T3346Value = nasType.NewT3346Value(nasMessage.RegistrationRejectT3346ValueType)
+			registrationReject.T3346Value.SetLen(1)
+			registrationReject.T3346Value.SetGPRSTimer2Value(nasConvert.GPRSTimer2ToNas(t3346))
+		}
+	}
+
 	if eapMessage != "" {
 		registrationReject.EAPMessage = nasType.NewEAPMessage(nasMessage.RegistrationRejectEAPMessageType)
 		rawEapMsg, err := base64.StdEncoding.DecodeString(eapMessage)
@@ -524,6 +532,9 @@ func BuildRegistrationAccept(
 			registrationResult |= nasMessage.AccessType3GPP
 		}
 	}
+	if len(ue.PendingNssai) > 0 {
+		registrationResult |= registrationResultNssaaToBePerformed
+	}
 	registrationAccept.SetRegistrationResultValue5GS(registrationResult)
 	// TODO: set smsAllowed value of RegistrationResult5GS if need
 
@@ -560,6 +571,15 @@ func BuildRegistrationAccept(
 		registrationAccept.AllowedNSSAI.SetLen(uint8(len(buf)))
 		registrationAccept.AllowedNSSAI.SetSNSSAIValue(buf)
 	}
+	if len(ue.PendingNssai) > 0 {
+		registrationAccept.PendingNSSAI = nasType.NewPendingNSSAI(nasMessage.RegistrationAcceptPendingNSSAIType)
+		var buf []uint8
+		for _, snssai := range ue.PendingNssai {
+			buf = append(buf, nasConvert.SnssaiToNas(snssai)...)
+		}
+		registrationAccept.PendingNSSAI.SetLen(uint8(len(buf)))
+		registrationAccept.PendingNSSAI.Buffer = buf
+	}
 	/* TODO: DT-Trial: Commented below code because UE is not allowing rejected Nssais */
 	/*
 		if ue.NetworkSliceInfo != nil {
@@ -667,17 +687,55 @@ func BuildRegistrationAccept(
 		registrationAccept.T3502Value.SetGPRSTimer2Value(t3502)
 	}*/
 
+	if anType == models.ACCESSTYPE__3_GPP_ACCESS && ue.MicoMode {
+		registrationAccept.MICOIndication = nasType.NewMICOIndication(nasMessage.RegistrationAcceptMICOIndicationType)
+		if ue.MicoAllPlmnRegistrationArea {
+			registrationAccept.MICOIndication.SetRAAI(1)
+		}
+		if ue.MicoStrictPeriodicRegistration {
+			registrationAccept.MICOIndication.Octet |= context.MicoIndicationSPRTI
+		}
+		// a UE in MICO mode is only reachable again when its periodic registration timer expires
+		registrationAccept.T3512Value = nasType.NewT3512Value(nasMessage.RegistrationAcceptT3512ValueType)
+		registrationAccept.T3512Value.SetLen(1)
+		registrationAccept.T3512Value.Octet = nasConvert.GPRSTimer3ToNas(ue.T3512Value)
+	}
+
+	if anType == models.ACCESSTYPE__3_GPP_ACCESS && ue.EdrxParameters != nil {
+		registrationAccept.NegotiatedExtendedDRXParameters = nasType.NewNegotiatedExtendedDRXParameters(
+			nasMessage.RegistrationAcceptNegotiatedExtendedDRXParametersType)
+		registrationAccept.NegotiatedExtendedDRXParameters.SetLen(1)
+		registrationAccept.NegotiatedExtendedDRXParameters.Octet = ue.EdrxParameters.Octet()
+	}
+
 	if ue.UESpecificDRX != nasMessage.DRXValueNotSpecified {
 		registrationAccept.NegotiatedDRXParameters = nasType.NewNegotiatedDRXParameters(nasMessage.RegistrationAcceptNegotiatedDRXParametersType)
 		registrationAccept.NegotiatedDRXParameters.SetLen(1)
 		registrationAccept.SetDRXValue(ue.UESpecificDRX)
 	}
 
+	// the UE acknowledges the SoR information in Registration Complete if requested (TS 23.122 C.2)
+	if sorInfo, ok := ue.AccessAndMobilitySubscriptionData.GetSorInfoOk(); ok {
+		sorContainer, err := BuildSorTransparentContainer(sorInfo)
+		if err != nil {
+			return nil, err
+		}
+		registrationAccept.SORTransparentContainer = nasType.NewSORTransparentContainer(
+			nasMessage.RegistrationAcceptSORTransparentContainerType)
+		registrationAccept.SORTransparentContainer.SetLen(uint16(len(sorContainer)))
+		registrationAccept.SORTransparentContainer.Buffer = sorContainer
+		ue.SorAckRequested = SorContainerAckRequested(sorContainer)
+	}
+
 	m.RegistrationAccept = registrationAccept
 
 	return nas_security.Encode(ue, m, anType)
 }
 
+// ConfigurationUpdateIndicationAck requests the UE to acknowledge a Configuration Update
+// Command (TS 24.501 9.11.3.18)
+const ConfigurationUpdateIndicationAck uint8 = 0x01
+
 func BuildConfigurationUpdateCommand(ue *context.AmfUe, anType models.AccessType,
 	networkSlicingIndication *nasType.NetworkSlicingIndication,
 ) ([]byte, error) {
@@ -734,13 +792,31 @@ func BuildConfigurationUpdateCommand(ue *context.AmfUe, anType models.AccessType
 		configurationUpdateCommand.ConfiguredNSSAI.SetSNSSAIValue(buf)
 	}
 
+	var rejectedNssaiInPlmn, rejectedNssaiInTa []models.Snssai
 	if ue.NetworkSliceInfo != nil {
-		if len(ue.NetworkSliceInfo.RejectedNssaiInPlmn) != 0 || len(ue.NetworkSliceInfo.RejectedNssaiInTa) != 0 {
-			rejectedNssaiNas := nasConvert.RejectedNssaiToNas(
-				ue.NetworkSliceInfo.RejectedNssaiInPlmn, ue.NetworkSliceInfo.RejectedNssaiInTa)
-			configurationUpdateCommand.RejectedNSSAI = &rejectedNssaiNas
-			configurationUpdateCommand.RejectedNSSAI.SetIei(nasMessage.ConfigurationUpdateCommandRejectedNSSAIType)
+		rejectedNssaiInPlmn = ue.NetworkSliceInfo.RejectedNssaiInPlmn
+		rejectedNssaiInTa = ue.NetworkSliceInfo.RejectedNssaiInTa
+	}
+	nssaaFailedNssai := ue.NssaaFailedNssai()
+	if len(rejectedNssaiInPlmn) != 0 || len(rejectedNssaiInTa) != 0 || len(nssaaFailedNssai) != 0 {
+		rejectedNssaiNas := nasConvert.RejectedNssaiToNas(rejectedNssaiInPlmn, rejectedNssaiInTa)
+		for _, snssai := range nssaaFailedNssai {
+			rejectedNssaiNas.Buffer = appendRejectedSnssai(rejectedNssaiNas.Buffer, snssai,
+				rejectedSnssaiCauseNssaaFailed)
 		}
+		rejectedNssaiNas.SetLen(uint8(len(rejectedNssaiNas.Buffer)))
+		configurationUpdateCommand.RejectedNSSAI = &rejectedNssaiNas
+		configurationUpdateCommand.RejectedNSSAI.SetIei(nasMessage.ConfigurationUpdateCommandRejectedNSSAIType)
+	}
+
+	if len(ue.PendingNssai) > 0 {
+		configurationUpdateCommand.PendingNSSAI = nasType.NewPendingNSSAI(nasMessage.ConfigurationUpdateCommandPendingNSSAIType)
+		var buf []uint8
+		for _, snssai := range ue.PendingNssai {
+			buf = append(buf, nasConvert.SnssaiToNas(snssai)...)
+		}
+		configurationUpdateCommand.PendingNSSAI.SetLen(uint8(len(buf)))
+		configurationUpdateCommand.PendingNSSAI.Buffer = buf
 	}
 
 	// TODO: UniversalTimeAndLocalTimeZone
@@ -794,3 +870,77 @@ func BuildConfigurationUpdateCommand(ue *context.AmfUe, anType models.AccessType
 
 	return m.PlainNasEncode()
 }
+
+// NSSAA to be performed bit of the 5GS registration result (TS 24.501 9.11.3.6)
+const registrationResultNssaaToBePerformed uint8 = 0x10
+
+// rejected S-NSSAI cause for a failed or revoked NSSAA (TS 24.501 9.11.3.46)
+const rejectedSnssaiCauseNssaaFailed uint8 = 0x02
+
+// appendRejectedSnssai appends a rejected S-NSSAI to the contents of the rejected NSSAI IE
+func appendRejectedSnssai(buf []uint8, snssai models.Snssai, cause uint8) []uint8 {
+	snssaiNas := nasConvert.SnssaiToNas(snssai)
+	contents := snssaiNas[1:]
+	// the rejected S-NSSAI carries no mapped HPLMN S-NSSAI
+	if len(contents) > 4 {
+		contents = contents[:4]
+	}
+	buf = append(buf, uint8(len(contents))<<4|cause&0x0f)
+	return append(buf, contents...)
+}
+
+func BuildNetworkSliceSpecificAuthenticationCommand(ue *context.AmfUe, anType models.AccessType,
+	snssai models.Snssai, eapMsg []byte,
+) ([]byte, error) {
+	m := nas.NewMessage()
+	m.GmmMessage = nas.NewGmmMessage()
+	m.GmmHeader.SetMessageType(nas.MsgTypeNetworkSliceSpecificAuthenticationCommand)
+
+	m.SecurityHeader = nas.SecurityHeader{
+		ProtocolDiscriminator: nasMessage.Epd5GSMobilityManagementMessage,
+		SecurityHeaderType:    nas.SecurityHeaderTypeIntegrityProtectedAndCiphered,
+	}
+
+	command := nasMessage.NewNetworkSliceSpecificAuthenticationCommand(0)
+	command.SetExtendedProtocolDiscriminator(nasMessage.Epd5GSMobilityManagementMessage)
+	command.SetSecurityHeaderType(nas.SecurityHeaderTypePlainNas)
+	command.SetSpareHalfOctet(0)
+	command.SetMessageType(nas.MsgTypeNetworkSliceSpecificAuthenticationCommand)
+	snssaiNas := nasConvert.SnssaiToNas(snssai)
+	command.SNSSAI.SetLen(snssaiNas[0])
+	copy(command.SNSSAI.Octet[:], snssaiNas[1:])
+	command.EAPMessage.SetLen(uint16(len(eapMsg)))
+	command.SetEAPMessage(eapMsg)
+
+	m.NetworkSliceSpecificAuthenticationCommand = command
+
+	return nas_security.Encode(ue, m, anType)
+}
+
+func BuildNetworkSliceSpecificAuthenticationResult(ue *context.AmfUe, anType models.AccessType,
+	snssai models.Snssai, eapMsg []byte,
+) ([]byte, error) {
+	m := nas.NewMessage()
+	m.GmmMessage = nas.NewGmmMessage()
+	m.GmmHeader.SetMessageType(nas.MsgTypeNetworkSliceSpecificAuthenticationResult)
+
+	m.SecurityHeader = nas.SecurityHeader{
+		ProtocolDiscriminator: nasMessage.Epd5GSMobilityManagementMessage,
+		SecurityHeaderType:    nas.SecurityHeaderTypeIntegrityProtectedAndCiphered,
+	}
+
+	result := nasMessage.NewNetworkSliceSpecificAuthenticationResult(0)
+	result.SetExtendedProtocolDiscriminator(nasMessage.Epd5GSMobilityManagementMessage)
+	result.SetSecurityHeaderType(nas.SecurityHeaderTypePlainNas)
+	result.SetSpareHalfOctet(0)
+	result.SetMessageType(nas.MsgTypeNetworkSliceSpecificAuthenticationResult)
+	snssaiNas := nasConvert.SnssaiToNas(snssai)
+	result.SNSSAI.SetLen(snssaiNas[0])
+	copy(result.SNSSAI.Octet[:], snssaiNas[1:])
+	result.EAPMessage.SetLen(uint16(len(eapMsg)))
+	result.SetEAPMessage(eapMsg)
+
+	m.NetworkSliceSpecificAuthenticationResult = result
+
+	return nas_security.Encode(ue, m, anType)
+}
@@ -7,6 +7,8 @@
 package message
 
 import (
+	ctxt "context"
+
 	"github.com/omec-project/amf/context"
 	"github.com/omec-project/amf/logger"
 	ngap_message "github.com/omec-project/amf/ngap/message"
@@ -57,20 +59,30 @@ func SendNotification(ue *context.RanUe, nasMsg []byte) {
 	amfUe.GmmLog.Infoln("send Notification")
 
 	if context.AMF_Self().T3565Cfg.Enable {
-		cfg := context.AMF_Self().T3565Cfg
-		amfUe.T3565 = context.NewTimer(cfg.ExpireTime, cfg.MaxRetryTimes, func(expireTimes int32) {
-			amfUe.GmmLog.Warnf("T3565 expires, retransmit Notification (retry: %d)", expireTimes)
-			ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
-		}, func() {
-			amfUe.GmmLog.Warnf("T3565 Expires %d times, abort notification procedure", cfg.MaxRetryTimes)
-			if amfUe.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS).Procedure != context.OnGoingProcedureN2Handover {
-				callback.SendN1N2TransferFailureNotification(amfUe, models.N1N2MESSAGETRANSFERCAUSE_UE_NOT_RESPONDING)
-			}
-			amfUe.T3565 = nil // clear the timer
-		})
+		startT3565(ue, nasMsg, context.TimerState{})
 	}
 }
 
+func startT3565(ue *context.RanUe, nasMsg []byte, state context.TimerState) {
+	amfUe := ue.AmfUe
+	cfg := context.AMF_Self().T3565Cfg
+	state.MaxRetryTimes = int32(cfg.MaxRetryTimes)
+	state.Payload = nasMsg
+	amfUe.RecordTimerFlight(context.TimerT3565, context.FlightRecordTimerStarted)
+	amfUe.T3565 = context.NewTimerFromState(cfg.ExpireTime, state, func(expireTimes int32) {
+		amfUe.RecordTimerFlight(context.TimerT3565, context.FlightRecordTimerExpired)
+		amfUe.GmmLog.Warnf("T3565 expires, retransmit Notification (retry: %d)", expireTimes)
+		ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	}, func() {
+		amfUe.RecordTimerFlight(context.TimerT3565, context.FlightRecordTimerAborted)
+		amfUe.GmmLog.Warnf("T3565 Expires %d times, abort notification procedure", cfg.MaxRetryTimes)
+		if amfUe.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS).Procedure != context.OnGoingProcedureN2Handover {
+			callback.SendN1N2TransferFailureNotification(amfUe, models.N1N2MESSAGETRANSFERCAUSE_UE_NOT_RESPONDING)
+		}
+		amfUe.T3565 = nil // clear the timer
+	})
+}
+
 func SendIdentityRequest(ue *context.RanUe, typeOfIdentity uint8) {
 	if ue == nil {
 		logger.GmmLog.Error("RanUe is nil")
@@ -117,18 +129,39 @@ func SendAuthenticationRequest(ue *context.RanUe) {
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
 
 	if context.AMF_Self().T3560Cfg.Enable {
-		cfg := context.AMF_Self().T3560Cfg
-		amfUe.T3560 = context.NewTimer(cfg.ExpireTime, cfg.MaxRetryTimes, func(expireTimes int32) {
-			amfUe.GmmLog.Warnf("T3560 expires, retransmit Authentication Request (retry: %d)", expireTimes)
-			ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
-		}, func() {
-			amfUe.GmmLog.Warnf("T3560 Expires %d times, abort authentication procedure & ongoing 5GMM procedure",
-				cfg.MaxRetryTimes)
-			amfUe.Remove()
-		})
+		startT3560(ue, nasMsg, "Authentication Request", "authentication procedure & ongoing 5GMM procedure",
+			context.TimerState{})
 	}
 }
 
+// startT3560 guards the retransmission of an Authentication Request or a Security
+// Mode Command; procedure names what is aborted when T3560 expires for the last time.
+func startT3560(ue *context.RanUe, nasMsg []byte, msgName, procedure string, state context.TimerState) {
+	amfUe := ue.AmfUe
+	cfg := context.AMF_Self().T3560Cfg
+	state.MaxRetryTimes = int32(cfg.MaxRetryTimes)
+	state.Payload = nasMsg
+	amfUe.RecordTimerFlight(context.TimerT3560, context.FlightRecordTimerStarted)
+	var t3560 *context.Timer
+	t3560 = context.NewTimerFromState(cfg.ExpireTime, state, func(expireTimes int32) {
+		amfUe.RecordTimerFlight(context.TimerT3560, context.FlightRecordTimerExpired)
+		amfUe.GmmLog.Warnf("T3560 expires, retransmit %s (retry: %d)", msgName, expireTimes)
+		ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	}, func() {
+		amfUe.RecordTimerFlight(context.TimerT3560, context.FlightRecordTimerAborted)
+		amfUe.GmmLog.Warnf("T3560 Expires %d times, abort %s", cfg.MaxRetryTimes, procedure)
+		if anType := ue.Ran.AnType; amfUe.GetOnGoing(anType).Procedure == context.OnGoingProcedureReauthentication {
+			// the UE stays registered with the security context it had before the re-authentication
+			amfUe.SubmitTimerAbort(context.TimerT3560, t3560, anType)
+			return
+		}
+		amfUe.FailProcedure(context.KpiAuthentication, context.KpiCauseTypeAmf, "t3560_expiry")
+		amfUe.FailProcedure(context.KpiSecurityModeControl, context.KpiCauseTypeAmf, "t3560_expiry")
+		amfUe.Remove()
+	})
+	amfUe.T3560 = t3560
+}
+
 func SendServiceAccept(ue *context.RanUe, anType models.AccessType, pDUSessionStatus *[16]bool, reactivationResult *[16]bool,
 	errPduSessionId, errCause []uint8,
 ) {
@@ -149,6 +182,7 @@ func SendServiceAccept(ue *context.RanUe, anType models.AccessType, pDUSessionSt
 		return
 	}
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	amfUe.CompleteProcedure(context.KpiServiceRequest)
 }
 
 func SendConfigurationUpdateCommand(amfUe *context.AmfUe, accessType models.AccessType,
@@ -163,6 +197,51 @@ func SendConfigurationUpdateCommand(amfUe *context.AmfUe, accessType models.Acce
 	}
 	mobilityRestrictionList := ngap_message.BuildIEMobilityRestrictionList(amfUe)
 	ngap_message.SendDownlinkNasTransport(amfUe.RanUe[accessType], nasMsg, &mobilityRestrictionList)
+
+	if amfUe.ConfigurationUpdateIndication.Octet&ConfigurationUpdateIndicationAck != 0 &&
+		context.AMF_Self().T3555Cfg.Enable {
+		// a new command replaces the one awaiting its acknowledgement
+		if amfUe.T3555 != nil {
+			amfUe.T3555.Stop()
+		}
+		startT3555(amfUe.RanUe[accessType], nasMsg, context.TimerState{})
+	}
+}
+
+// startT3555 guards the retransmission of a Configuration Update Command the UE is requested
+// to acknowledge (TS 24.501 5.4.4.2)
+func startT3555(ue *context.RanUe, nasMsg []byte, state context.TimerState) {
+	amfUe := ue.AmfUe
+	cfg := context.AMF_Self().T3555Cfg
+	state.MaxRetryTimes = int32(cfg.MaxRetryTimes)
+	state.Payload = nasMsg
+	amfUe.RecordTimerFlight(context.TimerT3555, context.FlightRecordTimerStarted)
+	var t3555 *context.Timer
+	t3555 = context.NewTimerFromState(cfg.ExpireTime, state, func(expireTimes int32) {
+		amfUe.RecordTimerFlight(context.TimerT3555, context.FlightRecordTimerExpired)
+		amfUe.GmmLog.Warnf("T3555 expires, retransmit Configuration Update Command (retry: %d)", expireTimes)
+		ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	}, func() {
+		amfUe.RecordTimerFlight(context.TimerT3555, context.FlightRecordTimerAborted)
+		amfUe.GmmLog.Warnf("T3555 Expires %d times, abort configuration update procedure", cfg.MaxRetryTimes)
+		amfUe.SubmitTimerAbort(context.TimerT3555, t3555, ue.Ran.AnType)
+	})
+	amfUe.T3555 = t3555
+}
+
+// abortConfigurationUpdate ends the configuration update procedure of a UE that did not
+// acknowledge the Configuration Update Command before T3555 expired for the last time
+func abortConfigurationUpdate(_ ctxt.Context, amfUe *context.AmfUe, anType models.AccessType, timer *context.Timer) {
+	if amfUe.T3555 != timer {
+		// acknowledged, or replaced by another command, while the expiry was queued
+		return
+	}
+	amfUe.T3555 = nil // clear the timer
+	// TS 24.501 5.4.4.5 case c: the old and the new 5G-GUTI both stay valid, the old one
+	// until the UE acknowledges or is assigned another 5G-GUTI
+	amfUe.ConfigurationUpdateIndication = nasType.ConfigurationUpdateIndication{}
+	amfUe.EndOamProcedure(context.OamProcedureGutiReallocation, context.OamOutcomeFailed,
+		"configuration update not acknowledged, T3555 expired")
 }
 
 func SendAuthenticationReject(ue *context.RanUe, eapMsg string) {
@@ -183,6 +262,7 @@ func SendAuthenticationReject(ue *context.RanUe, eapMsg string) {
 		return
 	}
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	amfUe.FailProcedure(context.KpiAuthentication, context.KpiCauseTypeAmf, "authentication_reject")
 }
 
 func SendAuthenticationResult(ue *context.RanUe, eapSuccess bool, eapMsg string) {
@@ -205,6 +285,50 @@ func SendAuthenticationResult(ue *context.RanUe, eapSuccess bool, eapMsg string)
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
 }
 
+func SendNetworkSliceSpecificAuthenticationCommand(ue *context.RanUe, anType models.AccessType,
+	snssai models.Snssai, eapMsg []byte,
+) {
+	if ue == nil {
+		logger.GmmLog.Error("RanUe is nil")
+		return
+	}
+	if ue.AmfUe == nil {
+		logger.GmmLog.Errorln("AmfUe is nil")
+		return
+	}
+
+	ue.AmfUe.GmmLog.Infof("send Network Slice-Specific Authentication Command [S-NSSAI: %+v]", snssai)
+
+	nasMsg, err := BuildNetworkSliceSpecificAuthenticationCommand(ue.AmfUe, anType, snssai, eapMsg)
+	if err != nil {
+		ue.AmfUe.GmmLog.Errorln(err.Error())
+		return
+	}
+	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+}
+
+func SendNetworkSliceSpecificAuthenticationResult(ue *context.RanUe, anType models.AccessType,
+	snssai models.Snssai, eapMsg []byte,
+) {
+	if ue == nil {
+		logger.GmmLog.Error("RanUe is nil")
+		return
+	}
+	if ue.AmfUe == nil {
+		logger.GmmLog.Errorln("AmfUe is nil")
+		return
+	}
+
+	ue.AmfUe.GmmLog.Infof("send Network Slice-Specific Authentication Result [S-NSSAI: %+v]", snssai)
+
+	nasMsg, err := BuildNetworkSliceSpecificAuthenticationResult(ue.AmfUe, anType, snssai, eapMsg)
+	if err != nil {
+		ue.AmfUe.GmmLog.Errorln(err.Error())
+		return
+	}
+	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+}
+
 func SendServiceReject(ue *context.RanUe, pDUSessionStatus *[16]bool, cause uint8) {
 	if ue == nil {
 		logger.GmmLog.Error("RanUe is nil")
@@ -223,6 +347,7 @@ func SendServiceReject(ue *context.RanUe, pDUSessionStatus *[16]bool, cause uint
 		return
 	}
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	amfUe.FailProcedure(context.KpiServiceRequest, context.KpiCauseType5GMM, context.Kpi5GMMCause(cause))
 }
 
 // T3502: This IE may be included to indicate a value for timer T3502 during the initial registration
@@ -245,6 +370,9 @@ func SendRegistrationReject(ue *context.RanUe, cause5GMM uint8, eapMessage strin
 		return
 	}
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	amfUe.FailProcedure(context.KpiRegistrationProcedure(amfUe.GetRegistrationType5GS()), context.KpiCauseType5GMM,
+		context.Kpi5GMMCause(cause5GMM))
+	amfUe.EndRegistrationSpan("registration reject, 5GMM cause " + context.Kpi5GMMCause(cause5GMM))
 }
 
 // eapSuccess: only used when authType is EAP-AKA', set the value to false if authType is not EAP-AKA'
@@ -266,17 +394,11 @@ func SendSecurityModeCommand(ue *context.RanUe, anType models.AccessType, eapSuc
 		amfUe.GmmLog.Errorln(err.Error())
 		return
 	}
+	amfUe.StartProcedure(context.KpiSecurityModeControl)
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
 
 	if context.AMF_Self().T3560Cfg.Enable {
-		cfg := context.AMF_Self().T3560Cfg
-		amfUe.T3560 = context.NewTimer(cfg.ExpireTime, cfg.MaxRetryTimes, func(expireTimes int32) {
-			amfUe.GmmLog.Warnf("T3560 expires, retransmit Security Mode Command (retry: %d)", expireTimes)
-			ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
-		}, func() {
-			amfUe.GmmLog.Warnf("T3560 Expires %d times, abort security mode control procedure", cfg.MaxRetryTimes)
-			amfUe.Remove()
-		})
+		startT3560(ue, nasMsg, "Security Mode Command", "security mode control procedure", context.TimerState{})
 	}
 }
 
@@ -303,33 +425,45 @@ func SendDeregistrationRequest(ue *context.RanUe, accessType uint8, reRegistrati
 	ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
 
 	if context.AMF_Self().T3522Cfg.Enable {
-		cfg := context.AMF_Self().T3522Cfg
-		amfUe.T3522 = context.NewTimer(cfg.ExpireTime, cfg.MaxRetryTimes, func(expireTimes int32) {
-			amfUe.GmmLog.Warnf("T3522 expires, retransmit Deregistration Request (retry: %d)", expireTimes)
-			ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
-		}, func() {
-			amfUe.GmmLog.Warnf("T3522 Expires %d times, abort deregistration procedure", cfg.MaxRetryTimes)
-			amfUe.T3522 = nil // clear the timer
-			switch accessType {
-			case nasMessage.AccessType3GPP:
-				amfUe.GmmLog.Warnln("UE accessType[3GPP] transfer to Deregistered state")
-				amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(context.Deregistered)
-				amfUe.Remove()
-			case nasMessage.AccessTypeNon3GPP:
-				amfUe.GmmLog.Warnln("UE accessType[Non3GPP] transfer to Deregistered state")
-				amfUe.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Set(context.Deregistered)
-				amfUe.Remove()
-			default:
-				amfUe.GmmLog.Warnln("UE accessType[3GPP] transfer to Deregistered state")
-				amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(context.Deregistered)
-				amfUe.GmmLog.Warnln("UE accessType[Non3GPP] transfer to Deregistered state")
-				amfUe.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Set(context.Deregistered)
-				amfUe.Remove()
-			}
-		})
+		startT3522(ue, nasMsg, accessType, context.TimerState{})
 	}
 }
 
+func startT3522(ue *context.RanUe, nasMsg []byte, accessType uint8, state context.TimerState) {
+	amfUe := ue.AmfUe
+	cfg := context.AMF_Self().T3522Cfg
+	state.MaxRetryTimes = int32(cfg.MaxRetryTimes)
+	state.Payload = nasMsg
+	amfUe.RecordTimerFlight(context.TimerT3522, context.FlightRecordTimerStarted)
+	amfUe.T3522 = context.NewTimerFromState(cfg.ExpireTime, state, func(expireTimes int32) {
+		amfUe.RecordTimerFlight(context.TimerT3522, context.FlightRecordTimerExpired)
+		amfUe.GmmLog.Warnf("T3522 expires, retransmit Deregistration Request (retry: %d)", expireTimes)
+		ngap_message.SendDownlinkNasTransport(ue, nasMsg, nil)
+	}, func() {
+		amfUe.RecordTimerFlight(context.TimerT3522, context.FlightRecordTimerAborted)
+		amfUe.GmmLog.Warnf("T3522 Expires %d times, abort deregistration procedure", cfg.MaxRetryTimes)
+		amfUe.T3522 = nil // clear the timer
+		amfUe.EndOamProcedure(context.OamProcedureDeregistration, context.OamOutcomeCompleted,
+			"deregistered locally after T3522 expiry")
+		switch accessType {
+		case nasMessage.AccessType3GPP:
+			amfUe.GmmLog.Warnln("UE accessType[3GPP] transfer to Deregistered state")
+			amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(context.Deregistered)
+			amfUe.Remove()
+		case nasMessage.AccessTypeNon3GPP:
+			amfUe.GmmLog.Warnln("UE accessType[Non3GPP] transfer to Deregistered state")
+			amfUe.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Set(context.Deregistered)
+			amfUe.Remove()
+		default:
+			amfUe.GmmLog.Warnln("UE accessType[3GPP] transfer to Deregistered state")
+			amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(context.Deregistered)
+			amfUe.GmmLog.Warnln("UE accessType[Non3GPP] transfer to Deregistered state")
+			amfUe.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Set(context.Deregistered)
+			amfUe.Remove()
+		}
+	})
+}
+
 func SendDeregistrationAccept(ue *context.RanUe) {
 	if ue == nil {
 		logger.GmmLog.Error("RanUe is nil")
@@ -377,27 +511,79 @@ func SendRegistrationAccept(
 	} else {
 		ngap_message.SendDownlinkNasTransport(ue.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS), nasMsg, nil)
 	}
+	ue.CompleteProcedure(context.KpiRegistrationProcedure(ue.GetRegistrationType5GS()))
+	ue.EndRegistrationSpan("")
 
 	if context.AMF_Self().T3550Cfg.Enable {
-		cfg := context.AMF_Self().T3550Cfg
-		ue.T3550 = context.NewTimer(cfg.ExpireTime, cfg.MaxRetryTimes, func(expireTimes int32) {
-			if ue.RanUe[anType] == nil {
-				ue.GmmLog.Warnln("[NAS] UE Context released, abort retransmission of Registration Accept")
-				ue.T3550 = nil
+		startT3550(ue, anType, nasMsg, pduSessionResourceSetupList, context.TimerState{})
+	}
+}
+
+func startT3550(ue *context.AmfUe, anType models.AccessType, nasMsg []byte,
+	pduSessionResourceSetupList *ngapType.PDUSessionResourceSetupListCxtReq, state context.TimerState,
+) {
+	cfg := context.AMF_Self().T3550Cfg
+	state.MaxRetryTimes = int32(cfg.MaxRetryTimes)
+	state.Payload = nasMsg
+	ue.RecordTimerFlight(context.TimerT3550, context.FlightRecordTimerStarted)
+	ue.T3550 = context.NewTimerFromState(cfg.ExpireTime, state, func(expireTimes int32) {
+		ue.RecordTimerFlight(context.TimerT3550, context.FlightRecordTimerExpired)
+		if ue.RanUe[anType] == nil {
+			ue.GmmLog.Warnln("[NAS] UE Context released, abort retransmission of Registration Accept")
+			ue.T3550 = nil
+		} else {
+			if ue.RanUe[anType].UeContextRequest && !ue.RanUe[anType].RecvdInitialContextSetupResponse {
+				ngap_message.SendInitialContextSetupRequest(ue, anType, nasMsg, pduSessionResourceSetupList, nil, nil, nil)
 			} else {
-				if ue.RanUe[anType].UeContextRequest && !ue.RanUe[anType].RecvdInitialContextSetupResponse {
-					ngap_message.SendInitialContextSetupRequest(ue, anType, nasMsg, pduSessionResourceSetupList, nil, nil, nil)
-				} else {
-					ue.GmmLog.Warnf("T3550 expires, retransmit Registration Accept (retry: %d)", expireTimes)
-					ngap_message.SendDownlinkNasTransport(ue.RanUe[anType], nasMsg, nil)
-				}
+				ue.GmmLog.Warnf("T3550 expires, retransmit Registration Accept (retry: %d)", expireTimes)
+				ngap_message.SendDownlinkNasTransport(ue.RanUe[anType], nasMsg, nil)
 			}
-		}, func() {
-			ue.GmmLog.Warnf("T3550 Expires %d times, abort retransmission of Registration Accept", cfg.MaxRetryTimes)
-			ue.T3550 = nil // clear the timer
-			// TS 24.501 5.5.1.2.8 case c, 5.5.1.3.8 case c
-			ue.State[anType].Set(context.Registered)
-			ue.ClearRegistrationRequestData(anType)
-		})
-	}
+		}
+	}, func() {
+		ue.RecordTimerFlight(context.TimerT3550, context.FlightRecordTimerAborted)
+		ue.GmmLog.Warnf("T3550 Expires %d times, abort retransmission of Registration Accept", cfg.MaxRetryTimes)
+		ue.T3550 = nil // clear the timer
+		// TS 24.501 5.5.1.2.8 case c, 5.5.1.3.8 case c
+		ue.State[anType].Set(context.Registered)
+		ue.SetRegisteredPopulation(anType, true)
+		ue.ClearRegistrationRequestData(anType)
+	})
+}
+
+// re-arm the NAS retransmission timers of UE contexts restored from the DB, so that a
+// procedure started on another AMF instance continues where it was interrupted
+func init() {
+	context.RegisterTimerRearmFunc(context.TimerT3560, func(amfUe *context.AmfUe, state context.TimerState) {
+		ue := amfUe.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS)
+		if ue == nil || !context.AMF_Self().T3560Cfg.Enable {
+			return
+		}
+		if amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Is(context.SecurityMode) {
+			startT3560(ue, state.Payload, "Security Mode Command", "security mode control procedure", state)
+		} else {
+			startT3560(ue, state.Payload, "Authentication Request",
+				"authentication procedure & ongoing 5GMM procedure", state)
+		}
+	})
+	context.RegisterTimerRearmFunc(context.TimerT3565, func(amfUe *context.AmfUe, state context.TimerState) {
+		if ue := amfUe.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS); ue != nil && context.AMF_Self().T3565Cfg.Enable {
+			startT3565(ue, state.Payload, state)
+		}
+	})
+	context.RegisterTimerRearmFunc(context.TimerT3522, func(amfUe *context.AmfUe, state context.TimerState) {
+		if ue := amfUe.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS); ue != nil && context.AMF_Self().T3522Cfg.Enable {
+			startT3522(ue, state.Payload, amfUe.DeregistrationTargetAccessType, state)
+		}
+	})
+	context.RegisterTimerAbortFunc(context.TimerT3555, abortConfigurationUpdate)
+	context.RegisterTimerRearmFunc(context.TimerT3555, func(amfUe *context.AmfUe, state context.TimerState) {
+		if ue := amfUe.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS); ue != nil && context.AMF_Self().T3555Cfg.Enable {
+			startT3555(ue, state.Payload, state)
+		}
+	})
+	context.RegisterTimerRearmFunc(context.TimerT3550, func(amfUe *context.AmfUe, state context.TimerState) {
+		if amfUe.GetRanUe(models.ACCESSTYPE__3_GPP_ACCESS) != nil && context.AMF_Self().T3550Cfg.Enable {
+			startT3550(amfUe, models.ACCESSTYPE__3_GPP_ACCESS, state.Payload, nil, state)
+		}
+	})
 }
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package message
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/omec-project/nas/v2/nasConvert"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// SOR transparent container header (TS 24.501 9.11.3.51)
+const (
+	SorDataTypeAck      uint8 = 0x01
+	sorListIndication   uint8 = 0x02
+	sorListTypePlmnList uint8 = 0x04
+	sorAckRequested     uint8 = 0x08
+)
+
+// UE parameters update transparent container header (TS 24.501 9.11.3.53A)
+const (
+	UpuDataTypeAck         uint8 = 0x01
+	upuAckRequested        uint8 = 0x02
+	upuReRegistrationReq   uint8 = 0x04
+	upuDataSetRoutingInd   uint8 = 0x01
+	upuDataSetDefConfNssai uint8 = 0x02
+)
+
+// length of the MAC-I in the SoR and UPU containers and of their acknowledgements
+const transparentContainerMacLen = 16
+
+// BuildSorTransparentContainer returns the SOR transparent container carried in Registration
+// Accept or DL NAS Transport, starting from the SOR header (octet 4). A container already
+// built by the UDM is relayed as is, otherwise it is assembled from the SoR-MAC-IAUSF,
+// CounterSoR and steering container of sorInfo.
+func BuildSorTransparentContainer(sorInfo *models.SorInfo) ([]byte, error) {
+	if container := sorInfo.GetSorTransparentContainer(); container != "" {
+		return base64.StdEncoding.DecodeString(container)
+	}
+
+	header := uint8(0)
+	if sorInfo.AckInd {
+		header |= sorAckRequested
+	}
+	var list []byte
+	if steering := sorInfo.SteeringContainer; steering != nil {
+		switch {
+		case steering.String != nil:
+			securedPacket, err := base64.StdEncoding.DecodeString(*steering.String)
+			if err != nil {
+				return nil, fmt.Errorf("invalid secured packet in steering container: %w", err)
+			}
+			header |= sorListIndication
+			list = securedPacket
+		case steering.ArrayOfSteeringInfo != nil:
+			header |= sorListIndication | sorListTypePlmnList
+			for _, info := range *steering.ArrayOfSteeringInfo {
+				list = append(list, nasConvert.PlmnIDToNas(info.PlmnId)...)
+				list = binary.BigEndian.AppendUint16(list, accessTechBitmap(info.AccessTechList))
+			}
+		}
+	}
+
+	buf, err := appendMacAndCounter([]byte{header}, sorInfo.GetSorMacIausf(), sorInfo.GetCountersor())
+	if err != nil {
+		return nil, fmt.Errorf("invalid SorInfo: %w", err)
+	}
+	return append(buf, list...), nil
+}
+
+// BuildUpuTransparentContainer returns the UE parameters update transparent container carried
+// in DL NAS Transport, starting from the UPU header (octet 4). A container already built by
+// the UDM is relayed as is, otherwise it is assembled from the UPU data of upuInfo.
+func BuildUpuTransparentContainer(upuInfo *models.UpuInfo) ([]byte, error) {
+	if container := upuInfo.GetUpuTransparentContainer(); container != "" {
+		return base64.StdEncoding.DecodeString(container)
+	}
+
+	header := uint8(0)
+	if upuInfo.GetUpuAckInd() {
+		header |= upuAckRequested
+	}
+	if upuInfo.GetUpuRegInd() {
+		header |= upuReRegistrationReq
+	}
+	buf, err := appendMacAndCounter([]byte{header}, upuInfo.GetUpuMacIausf(), upuInfo.GetCounterUpu())
+	if err != nil {
+		return nil, fmt.Errorf("invalid UpuInfo: %w", err)
+	}
+
+	for _, upuData := range upuInfo.UpuDataList {
+		if securedPacket := upuData.GetSecPacket(); securedPacket != "" {
+			contents, err := base64.StdEncoding.DecodeString(securedPacket)
+			if err != nil {
+				return nil, fmt.Errorf("invalid secured packet in UpuData: %w", err)
+			}
+			buf = appendUpuDataSet(buf, upuDataSetRoutingInd, contents)
+		}
+		if len(upuData.DefaultConfNssai) > 0 {
+			var contents []byte
+			for _, snssai := range upuData.DefaultConfNssai {
+				contents = append(contents, nasConvert.SnssaiToNas(snssai)...)
+			}
+			buf = appendUpuDataSet(buf, upuDataSetDefConfNssai, contents)
+		}
+	}
+	return buf, nil
+}
+
+// SorContainerAckRequested reports whether the SOR transparent container asks the UE for an ack.
+func SorContainerAckRequested(container []byte) bool {
+	return len(container) > 0 && container[0]&SorDataTypeAck == 0 && container[0]&sorAckRequested != 0
+}
+
+// UpuContainerAckRequested reports whether the UPU transparent container asks the UE for an ack.
+func UpuContainerAckRequested(container []byte) bool {
+	return len(container) > 0 && container[0]&UpuDataTypeAck == 0 && container[0]&upuAckRequested != 0
+}
+
+// ParseSorAck returns the SoR-MAC-IUE of the acknowledgement sent by the UE in a SOR transparent
+// container, as the hex string expected by Nudm_SDM_Info.
+func ParseSorAck(container []byte) (string, error) {
+	return parseContainerAck(container, SorDataTypeAck, "SOR")
+}
+
+// ParseUpuAck returns the UPU-MAC-IUE of the acknowledgement sent by the UE in a UE parameters
+// update transparent container.
+func ParseUpuAck(container []byte) (string, error) {
+	return parseContainerAck(container, UpuDataTypeAck, "UPU")
+}
+
+func parseContainerAck(container []byte, ackType uint8, name string) (string, error) {
+	if len(container) < 1+transparentContainerMacLen {
+		return "", fmt.Errorf("%s ack container too short: %d octets", name, len(container))
+	}
+	if container[0]&ackType == 0 {
+		return "", fmt.Errorf("%s container is not an acknowledgement", name)
+	}
+	return hex.EncodeToString(container[1 : 1+transparentContainerMacLen]), nil
+}
+
+func appendMacAndCounter(buf []byte, macHex, counterHex string) ([]byte, error) {
+	mac, err := hex.DecodeString(macHex)
+	if err != nil || len(mac) != transparentContainerMacLen {
+		return nil, fmt.Errorf("MAC-IAUSF [%s] is not %d octets", macHex, transparentContainerMacLen)
+	}
+	counter, err := hex.DecodeString(counterHex)
+	if err != nil || len(counter) != 2 {
+		return nil, fmt.Errorf("counter [%s] is not 2 octets", counterHex)
+	}
+	buf = append(buf, mac...)
+	return append(buf, counter...), nil
+}
+
+func appendUpuDataSet(buf []byte, dataSetType uint8, contents []byte) []byte {
+	buf = append(buf, dataSetType)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(contents)))
+	return append(buf, contents...)
+}
+
+// accessTechBitmap encodes the access technology identifier of TS 31.102 4.2.5
+func accessTechBitmap(accessTechs []models.AccessTech) uint16 {
+	var bitmap uint16
+	for _, accessTech := range accessTechs {
+		switch accessTech {
+		case models.ACCESSTECH_UTRAN:
+			bitmap |= 0x8000
+		case models.ACCESSTECH_EUTRAN_IN_WBS1_MODE_AND_NBS1_MODE:
+			bitmap |= 0x4000
+		case models.ACCESSTECH_EUTRAN_IN_WBS1_MODE_ONLY:
+			bitmap |= 0x2000
+		case models.ACCESSTECH_EUTRAN_IN_NBS1_MODE_ONLY:
+			bitmap |= 0x1000
+		case models.ACCESSTECH_NR:
+			bitmap |= 0x0800
+		case models.ACCESSTECH_GSM_AND_ECGSM_IO_T:
+			bitmap |= 0x0080
+		case models.ACCESSTECH_GSM_COMPACT:
+			bitmap |= 0x0040
+		case models.ACCESSTECH_CDMA_HRPD:
+			bitmap |= 0x0020
+		case models.ACCESSTECH_CDMA_1X_RTT:
+			bitmap |= 0x0010
+		case models.ACCESSTECH_ECGSM_IO_T_ONLY:
+			bitmap |= 0x0080 | 0x0008
+		case models.ACCESSTECH_GSM_WITHOUT_ECGSM_IO_T:
+			bitmap |= 0x0080 | 0x0004
+		}
+	}
+	return bitmap
+}
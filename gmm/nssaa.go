@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	gmm_message "github.com/omec-project/amf/gmm/message"
+	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/nas/v2/nasConvert"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+)
+
+// EAP packets built by the AMF itself (RFC 3748 4)
+const (
+	eapCodeRequest  uint8 = 1
+	eapCodeSuccess  uint8 = 3
+	eapCodeFailure  uint8 = 4
+	eapTypeIdentity uint8 = 1
+)
+
+func eapIdentityRequest(id uint8) []byte {
+	return []byte{eapCodeRequest, id, 0, 5, eapTypeIdentity}
+}
+
+func eapResult(success bool, id uint8) []byte {
+	if success {
+		return []byte{eapCodeSuccess, id, 0, 4}
+	}
+	return []byte{eapCodeFailure, id, 0, 4}
+}
+
+var (
+	searchNssaafInstance         = consumer.SearchNssaafInstance
+	sendNssaaAuthenticateRequest = consumer.SendNssaaAuthenticateRequest
+	sendNssaaConfirmRequest      = consumer.SendNssaaConfirmRequest
+)
+
+// startNssaa starts NSSAA for every S-NSSAI of the pending NSSAI, once the registration
+// procedure has completed (TS 23.502 4.2.9.2 step 1).
+func startNssaa(ue *context.AmfUe, anType models.AccessType) {
+	for _, snssai := range ue.PendingNssai {
+		startNssaaForSnssai(ue, anType, snssai)
+	}
+}
+
+func startNssaaForSnssai(ue *context.AmfUe, anType models.AccessType, snssai models.Snssai) {
+	session := ue.StartNssaaSession(snssai)
+	session.EapId = 1
+	ue.GmmLog.Infof("start NSSAA for S-NSSAI %+v", snssai)
+	sendNssaaCommand(ue, anType, snssai, eapIdentityRequest(session.EapId))
+}
+
+// StartNssaaReauthentication re-runs NSSAA for snssai when the AAA server requests it
+// (TS 23.502 4.2.9.3). The S-NSSAI stays allowed until the new result is known.
+func StartNssaaReauthentication(ue *context.AmfUe, anType models.AccessType, snssai models.Snssai) error {
+	if !ue.RequiresNssaa(snssai) {
+		return fmt.Errorf("S-NSSAI %+v of UE[%s] is not subject to NSSAA", snssai, ue.GetSupi())
+	}
+	startNssaaForSnssai(ue, anType, snssai)
+	return nil
+}
+
+// RevokeNssaa withdraws snssai from the UE when the AAA server revokes its authorization
+// (TS 23.502 4.2.9.4): the PDU sessions of the S-NSSAI are released and the UE gets a new
+// allowed NSSAI, or is deregistered if no S-NSSAI is left.
+func RevokeNssaa(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType, snssai models.Snssai) error {
+	session := ue.NssaaSession(snssai)
+	if session == nil {
+		session = ue.StartNssaaSession(snssai)
+	}
+	session.Status = models.AUTHSTATUS_EAP_FAILURE
+	session.AuthCtxId = ""
+	ue.RemovePendingSnssai(snssai)
+	ue.RemoveAllowedSnssai(snssai)
+
+	ue.SmContextList.Range(func(key, value any) bool {
+		smContext := value.(*context.SmContext)
+		smSnssai := smContext.Snssai()
+		if smSnssai.GetSst() != snssai.GetSst() || smSnssai.GetSd() != snssai.GetSd() {
+			return true
+		}
+		ue.GmmLog.Infof("release PDU session %d of revoked S-NSSAI %+v", key, snssai)
+		problemDetails, err := sendReleaseSmContextRequest(ue, smContext, nil, "", nil)
+		if problemDetails != nil {
+			ue.GmmLog.Errorf("Release SmContext Failed Problem[%+v]", problemDetails)
+		} else if err != nil {
+			ue.GmmLog.Errorf("Release SmContext Error[%v]", err.Error())
+		}
+		return true
+	})
+
+	return updateNssaiAfterNssaa(ctx, ue, anType)
+}
+
+// TS 24.501 5.4.7.2.2
+func HandleNetworkSliceSpecificAuthenticationComplete(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType,
+	nssaaComplete *nasMessage.NetworkSliceSpecificAuthenticationComplete,
+) error {
+	ue.GmmLog.Info("Handle Network Slice-Specific Authentication Complete")
+
+	if ue.MacFailed {
+		return fmt.Errorf("NAS message integrity check failed")
+	}
+
+	snssai := nasConvert.SnssaiToModels(&nssaaComplete.SNSSAI)
+	session := ue.NssaaSession(snssai)
+	if session == nil || session.Status != models.AUTHSTATUS_PENDING {
+		return fmt.Errorf("no NSSAA ongoing for S-NSSAI %+v", snssai)
+	}
+	eapMsg := nssaaComplete.GetEAPMessage()
+	if len(eapMsg) < 4 {
+		return fmt.Errorf("invalid EAP message for S-NSSAI %+v", snssai)
+	}
+	session.EapId = eapMsg[1]
+
+	if ue.NssaafUri == "" {
+		amfSelf := context.AMF_Self()
+		if err := searchNssaafInstance(ctx, ue, amfSelf.NrfUri, models.NFTYPE_NSSAAF, models.NFTYPE_AMF, nil); err != nil {
+			ue.GmmLog.Errorf("AMF can not select an NSSAAF Instance by NRF[Error: %+v]", err)
+			return finishNssaa(ctx, ue, anType, session, models.AUTHSTATUS_EAP_FAILURE, nil)
+		}
+	}
+
+	var nextEapMsg string
+	var authResult models.AuthStatus
+	eapMsgB64 := base64.StdEncoding.EncodeToString(eapMsg)
+	if session.AuthCtxId == "" {
+		authCtx, problemDetails, err := sendNssaaAuthenticateRequest(ctx, ue, snssai, eapMsgB64)
+		if problemDetails != nil || err != nil {
+			ue.GmmLog.Errorf("Nnssaaf_NSSAA_Authenticate failed [Problem: %+v, Error: %v]", problemDetails, err)
+			return finishNssaa(ctx, ue, anType, session, models.AUTHSTATUS_EAP_FAILURE, nil)
+		}
+		session.AuthCtxId = authCtx.AuthCtxId
+		nextEapMsg = authCtx.EapMessage
+	} else {
+		confirmRsp, problemDetails, err := sendNssaaConfirmRequest(ctx, ue, session.AuthCtxId, snssai, eapMsgB64)
+		if problemDetails != nil || err != nil {
+			ue.GmmLog.Errorf("Nnssaaf_NSSAA_Authenticate confirmation failed [Problem: %+v, Error: %v]", problemDetails, err)
+			return finishNssaa(ctx, ue, anType, session, models.AUTHSTATUS_EAP_FAILURE, nil)
+		}
+		nextEapMsg = confirmRsp.EapMessage
+		if confirmRsp.AuthResult != nil {
+			authResult = *confirmRsp.AuthResult
+		}
+	}
+
+	rawEapMsg, err := base64.StdEncoding.DecodeString(nextEapMsg)
+	if err != nil {
+		ue.GmmLog.Errorf("decode EAP message from NSSAAF failed: %+v", err)
+		return finishNssaa(ctx, ue, anType, session, models.AUTHSTATUS_EAP_FAILURE, nil)
+	}
+	switch authResult {
+	case models.AUTHSTATUS_EAP_SUCCESS, models.AUTHSTATUS_EAP_FAILURE:
+		return finishNssaa(ctx, ue, anType, session, authResult, rawEapMsg)
+	default:
+		if len(rawEapMsg) > 1 {
+			session.EapId = rawEapMsg[1]
+		}
+		sendNssaaCommand(ue, anType, snssai, rawEapMsg)
+		return nil
+	}
+}
+
+// finishNssaa sends the NSSAA result to the UE and applies it to the allowed NSSAI
+func finishNssaa(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType, session *context.NssaaSession,
+	result models.AuthStatus, eapMsg []byte,
+) error {
+	success := result == models.AUTHSTATUS_EAP_SUCCESS
+	ue.GmmLog.Infof("NSSAA for S-NSSAI %+v finished: %s", session.Snssai, result)
+	session.Status = result
+	session.AuthCtxId = ""
+	if len(eapMsg) == 0 {
+		eapMsg = eapResult(success, session.EapId)
+	}
+	if ue.CmConnect(anType) {
+		gmm_message.SendNetworkSliceSpecificAuthenticationResult(ue.GetRanUe(anType), anType, session.Snssai, eapMsg)
+	} else if nasMsg, err := gmm_message.BuildNetworkSliceSpecificAuthenticationResult(ue, anType,
+		session.Snssai, eapMsg); err != nil {
+		ue.GmmLog.Errorf("build Network Slice-Specific Authentication Result failed: %+v", err)
+	} else if err = queueNssaaMessage(ue, anType, session.Snssai, nasMsg); err != nil {
+		ue.GmmLog.Errorln(err)
+	}
+
+	ue.RemovePendingSnssai(session.Snssai)
+	if !success {
+		ue.RemoveAllowedSnssai(session.Snssai)
+	} else if !ue.InAllowedNssai(session.Snssai, anType) {
+		ue.AllowedNssai[anType] = append(ue.AllowedNssai[anType], models.AllowedSnssai{AllowedSnssai: session.Snssai})
+	}
+	return updateNssaiAfterNssaa(ctx, ue, anType)
+}
+
+// updateNssaiAfterNssaa updates the UE with the new allowed and rejected NSSAI once no
+// S-NSSAI is pending anymore (TS 23.502 4.2.9.2 step 20-21)
+func updateNssaiAfterNssaa(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType) error {
+	if len(ue.PendingNssai) > 0 {
+		return nil
+	}
+	context.StoreContextInDB(ue)
+	if len(ue.AllowedNssai[anType]) == 0 {
+		ue.GmmLog.Warnln("no S-NSSAI left after NSSAA, deregister UE")
+		return GmmFSM.SendEvent(ctx, ue.State[anType], NwInitiatedDeregistrationEvent, fsm.ArgsType{
+			ArgAmfUe:      ue,
+			ArgAccessType: anType,
+		})
+	}
+	if ue.CmConnect(anType) {
+		gmm_message.SendConfigurationUpdateCommand(ue, anType, nil)
+		return nil
+	}
+	nasMsg, err := gmm_message.BuildConfigurationUpdateCommand(ue, anType, nil)
+	if err != nil {
+		return fmt.Errorf("build Configuration Update Command failed: %w", err)
+	}
+	return pageWithNasMessage(ue, anType, nasMsg)
+}
+
+func sendNssaaCommand(ue *context.AmfUe, anType models.AccessType, snssai models.Snssai, eapMsg []byte) {
+	if ue.CmConnect(anType) {
+		gmm_message.SendNetworkSliceSpecificAuthenticationCommand(ue.GetRanUe(anType), anType, snssai, eapMsg)
+		return
+	}
+	nasMsg, err := gmm_message.BuildNetworkSliceSpecificAuthenticationCommand(ue, anType, snssai, eapMsg)
+	if err != nil {
+		ue.GmmLog.Errorf("build Network Slice-Specific Authentication Command failed: %+v", err)
+		return
+	}
+	if err = queueNssaaMessage(ue, anType, snssai, nasMsg); err != nil {
+		ue.GmmLog.Errorln(err)
+	}
+}
+
+// queueNssaaMessage queues nasMsg, an NSSAA message for snssai, for a CM-IDLE UE and pages
+// the UE unless a message is already waiting for it to answer paging
+func queueNssaaMessage(ue *context.AmfUe, anType models.AccessType, snssai models.Snssai, nasMsg []byte) error {
+	if !ue.QueueNssaaMessage(snssai, nasMsg) {
+		return nil
+	}
+	return pageUe(ue, anType)
+}
+
+// deliverPendingNssaaMessages sends the NSSAA messages queued while the UE was CM-IDLE, in
+// the order they were queued.
+func deliverPendingNssaaMessages(ue *context.AmfUe, anType models.AccessType) {
+	for len(ue.PendingNssaaMessages) > 0 && ue.CmConnect(anType) {
+		ngap_message.SendDownlinkNasTransport(ue.GetRanUe(anType), ue.PendingNssaaMessages[0].NasPdu, nil)
+		ue.PendingNssaaMessages = ue.PendingNssaaMessages[1:]
+	}
+	if len(ue.PendingNssaaMessages) == 0 {
+		ue.PendingNssaaMessages = nil
+	}
+}
+
+// pageWithNasMessage pages a CM-IDLE UE; nasMsg is sent once the UE answers with a
+// Service Request
+func pageWithNasMessage(ue *context.AmfUe, anType models.AccessType, nasMsg []byte) error {
+	// a UE with NSSAA messages queued was paged already
+	if len(ue.PendingNssaaMessages) == 0 {
+		if err := pageUe(ue, anType); err != nil {
+			return err
+		}
+	}
+	ue.ConfigurationUpdateMessage = nasMsg
+	return nil
+}
+
+// pageUe pages a CM-IDLE UE over 3GPP access
+func pageUe(ue *context.AmfUe, anType models.AccessType) error {
+	if anType != models.ACCESSTYPE__3_GPP_ACCESS {
+		return fmt.Errorf("UE is CM-IDLE over %s, cannot page it", anType)
+	}
+	pkg, err := ngap_message.BuildPaging(ue, nil, false)
+	if err != nil {
+		return fmt.Errorf("build Paging failed: %w", err)
+	}
+	ue.SetOnGoing(anType, &context.OnGoingProcedureWithPrio{
+		Procedure: context.OnGoingProcedurePaging,
+	})
+	ngap_message.SendPaging(ue, pkg)
+	return nil
+}
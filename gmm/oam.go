@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"fmt"
+
+	"github.com/omec-project/amf/context"
+	gmm_message "github.com/omec-project/amf/gmm/message"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/nas/v2"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/nas/v2/nasType"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+)
+
+// StartReauthentication runs a primary authentication and a security mode control procedure
+// on a registered UE in CM-CONNECTED, which stays registered with the new security context
+// (TS 33.501 6.1.3). The outcome is reported with OamProcedureReauthentication. A UE the
+// re-authentication fails for stays registered with its previous security context, unless
+// it was sent an Authentication Reject which deregisters it.
+func StartReauthentication(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType) error {
+	if !ue.State[anType].Is(context.Registered) {
+		return fmt.Errorf("UE is not registered over %s", anType)
+	}
+	ue.RetransmissionOfInitialNASMsg = false
+	ue.SetOnGoing(anType, &context.OnGoingProcedureWithPrio{
+		Procedure: context.OnGoingProcedureReauthentication,
+	})
+	ue.SaveSecurityContext()
+	if err := GmmFSM.SendEvent(ctx, ue.State[anType], StartAuthEvent, fsm.ArgsType{
+		ArgAmfUe:      ue,
+		ArgAccessType: anType,
+	}); err != nil {
+		ue.RestoreSecurityContext()
+		ue.SetOnGoing(anType, &context.OnGoingProcedureWithPrio{Procedure: context.OnGoingProcedureNothing})
+		return err
+	}
+	return nil
+}
+
+func init() {
+	context.RegisterTimerAbortFunc(context.TimerT3560, abortReauthentication)
+}
+
+// abortReauthentication fails the re-authentication of a UE that did not answer the
+// Authentication Request or Security Mode Command before T3560 expired for the last time
+func abortReauthentication(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType, timer *context.Timer) {
+	state := ue.State[anType]
+	if ue.T3560 != timer || !reauthenticating(ue, anType) ||
+		!state.Is(context.Authentication) && !state.Is(context.SecurityMode) {
+		// answered, or ended otherwise, while the expiry was queued
+		return
+	}
+	ue.FailProcedure(context.KpiAuthentication, context.KpiCauseTypeAmf, "t3560_expiry")
+	ue.FailProcedure(context.KpiSecurityModeControl, context.KpiCauseTypeAmf, "t3560_expiry")
+	failReauthentication(ctx, state, ue, anType, "T3560 expired")
+}
+
+// failReauthentication returns a UE whose re-authentication failed without rejecting it to
+// Registered with its previous security context, which the UE keeps using as long as it has
+// not completed the security mode control procedure (TS 24.501 5.4.2.5)
+func failReauthentication(ctx ctxt.Context, state *fsm.State, ue *context.AmfUe, anType models.AccessType,
+	detail string,
+) {
+	ue.GmmLog.Warnf("re-authentication failed: %s", detail)
+	if err := GmmFSM.SendEvent(ctx, state, ReauthenticationFailEvent, fsm.ArgsType{
+		ArgAmfUe:      ue,
+		ArgAccessType: anType,
+	}); err != nil {
+		logger.GmmLog.Errorln(err)
+	}
+	ue.EndReauthentication(anType, context.OamOutcomeFailed, detail)
+	handleDeferredNasMessages(ctx, ue, anType)
+}
+
+// rejectReauthentication deregisters a UE sent an Authentication Reject during its
+// re-authentication, as the UE deletes its security context and enters 5GMM-DEREGISTERED
+// (TS 24.501 5.4.1.3.5)
+func rejectReauthentication(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType) {
+	ue.GmmLog.Warnln("re-authentication rejected, deregister the UE")
+	ue.TakeDeferredNasMessages()
+	ue.EndReauthentication(anType, context.OamOutcomeFailed, "authentication rejected, UE deregistered")
+	if err := NetworkInitiatedDeregistrationProcedure(ctx, ue, anType, false, 0); err != nil {
+		logger.GmmLog.Errorln(err)
+	}
+}
+
+// reauthenticating reports whether the UE runs a re-authentication over anType
+func reauthenticating(ue *context.AmfUe, anType models.AccessType) bool {
+	return ue.GetOnGoing(anType).Procedure == context.OnGoingProcedureReauthentication
+}
+
+// handleNasMessageDuringReauthentication handles a NAS message of a UE in re-authentication
+// that is not part of it, and reports whether it did. The messages of the signalling the UE
+// goes on with are deferred until the re-authentication ends; a Registration or Deregistration
+// Request the UE starts ends the re-authentication and is handled in Registered
+// (TS 24.501 5.4.1.3.7 case e).
+func handleNasMessageDuringReauthentication(ctx ctxt.Context, state *fsm.State, ue *context.AmfUe,
+	anType models.AccessType, gmmMessage *nas.GmmMessage, procedureCode int64,
+) bool {
+	messageType := gmmMessage.GetMessageType()
+	switch messageType {
+	case nas.MsgTypeULNASTransport, nas.MsgTypeServiceRequest, nas.MsgTypeConfigurationUpdateComplete,
+		nas.MsgTypeNetworkSliceSpecificAuthenticationComplete, nas.MsgTypeNotificationResponse:
+		ue.GmmLog.Infof("defer %s until the re-authentication ends", nas.MessageName(messageType))
+		ue.DeferNasMessage(gmmMessage, procedureCode)
+		return true
+	case nas.MsgTypeRegistrationRequest, nas.MsgTypeDeregistrationRequestUEOriginatingDeregistration:
+		failReauthentication(ctx, state, ue, anType, "aborted by a "+nas.MessageName(messageType))
+		if err := GmmFSM.SendEvent(ctx, ue.State[anType], GmmMessageEvent, fsm.ArgsType{
+			ArgAmfUe:         ue,
+			ArgAccessType:    anType,
+			ArgNASMessage:    gmmMessage,
+			ArgProcedureCode: procedureCode,
+		}); err != nil {
+			logger.GmmLog.Errorln(err)
+		}
+		return true
+	}
+	return false
+}
+
+// handleDeferredNasMessages handles the NAS messages deferred during the re-authentication of
+// the UE, which ended, in the order the UE sent them
+func handleDeferredNasMessages(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType) {
+	for _, deferred := range ue.TakeDeferredNasMessages() {
+		if err := GmmFSM.SendEvent(ctx, ue.State[anType], GmmMessageEvent, fsm.ArgsType{
+			ArgAmfUe:         ue,
+			ArgAccessType:    anType,
+			ArgNASMessage:    deferred.GmmMessage,
+			ArgProcedureCode: deferred.ProcedureCode,
+		}); err != nil {
+			logger.GmmLog.Errorln(err)
+		}
+	}
+}
+
+// ReallocateGuti assigns a new 5G-GUTI to a registered UE in CM-CONNECTED and sends it in a
+// Configuration Update Command requesting an acknowledgement (TS 24.501 5.4.4.2). The old
+// 5G-GUTI identifies the UE until it completes the procedure, whose outcome is reported
+// with OamProcedureGutiReallocation.
+func ReallocateGuti(ue *context.AmfUe, anType models.AccessType) error {
+	if !ue.State[anType].Is(context.Registered) {
+		return fmt.Errorf("UE is not registered over %s", anType)
+	}
+	// a CM-IDLE UE answers paging with its current 5G-S-TMSI, the new one is unknown to it
+	if !ue.CmConnect(anType) {
+		return fmt.Errorf("UE is CM-IDLE over %s", anType)
+	}
+	context.AMF_Self().ReAllocateGutiToUeKeepingOld(ue)
+	ue.ConfigurationUpdateIndication = *nasType.NewConfigurationUpdateIndication(
+		nasMessage.ConfigurationUpdateCommandConfigurationUpdateIndicationType)
+	ue.ConfigurationUpdateIndication.Octet |= gmm_message.ConfigurationUpdateIndicationAck
+	gmm_message.SendConfigurationUpdateCommand(ue, anType, nil)
+	ue.PublishUeCtxtInfo()
+	context.StoreContextInDB(ue)
+	return nil
+}
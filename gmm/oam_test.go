@@ -0,0 +1,530 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	"bytes"
+	ctxt "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/factory"
+	ngaputil "github.com/omec-project/amf/ngap/util"
+	"github.com/omec-project/nas/v2"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/nas/v2/nasType"
+	"github.com/omec-project/nas/v2/security"
+	"github.com/omec-project/ngap/v2"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+)
+
+const (
+	oamTestSupi  = "imsi-208930000000001"
+	oamTestKamf  = "0101010101010101010101010101010101010101010101010101010101010101"
+	oamTestRand  = "02020202020202020202020202020202"
+	oamTestAutn  = "03030303030303030303030303030303"
+	oamTestKseaf = "0404040404040404040404040404040404040404040404040404040404040404"
+)
+
+var oamTestResStar = [16]uint8{0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05, 0x05}
+
+func disableKafkaForTest(t *testing.T) {
+	t.Helper()
+
+	originalConfig := factory.AmfConfig.Configuration
+	if originalConfig == nil {
+		factory.AmfConfig.Configuration = &factory.Configuration{}
+		t.Cleanup(func() { factory.AmfConfig.Configuration = nil })
+	}
+	originalEnableKafka := factory.AmfConfig.Configuration.KafkaInfo.EnableKafka
+	disabled := false
+	factory.AmfConfig.Configuration.KafkaInfo.EnableKafka = &disabled
+	t.Cleanup(func() {
+		if factory.AmfConfig.Configuration != nil {
+			factory.AmfConfig.Configuration.KafkaInfo.EnableKafka = originalEnableKafka
+		}
+	})
+}
+
+// newOamTestUe returns a UE registered over 3GPP access and in CM-CONNECTED with a native
+// security context, and the connection to the gNB its NAS messages are sent on
+func newOamTestUe(t *testing.T) (*context.AmfUe, *ngaputil.TestConn) {
+	t.Helper()
+	disableKafkaForTest(t)
+
+	amfSelf := context.AMF_Self()
+	originalServedGuamiList := amfSelf.ServedGuamiList
+	originalSecurityAlgorithm := amfSelf.SecurityAlgorithm
+	t.Cleanup(func() {
+		amfSelf.ServedGuamiList = originalServedGuamiList
+		amfSelf.SecurityAlgorithm = originalSecurityAlgorithm
+	})
+	amfSelf.ServedGuamiList = []models.Guami{{PlmnId: models.PlmnIdNid{Mcc: "208", Mnc: "93"}, AmfId: "cafe00"}}
+	amfSelf.SecurityAlgorithm = context.SecurityAlgorithm{
+		IntegrityOrder: []uint8{security.AlgIntegrity128NIA2},
+		CipheringOrder: []uint8{security.AlgCiphering128NEA0},
+	}
+
+	conn := &ngaputil.TestConn{}
+	ran := context.NewAmfRanDefault()
+	ran.AnType = models.ACCESSTYPE__3_GPP_ACCESS
+	ran.Conn = conn
+	ranUe, err := ran.NewRanUe(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ue := amfSelf.NewAmfUe(oamTestSupi)
+	t.Cleanup(ue.Remove)
+	ue.AttachRanUe(ranUe)
+	ue.State[models.ACCESSTYPE__3_GPP_ACCESS] = fsm.NewState(context.Registered)
+	ue.PlmnId = models.PlmnId{Mcc: "208", Mnc: "93"}
+	ue.UnauthenticatedSupi = false
+	ue.SecurityContextAvailable = true
+	ue.NgKsi = models.NgKsi{Tsc: models.SCTYPE_NATIVE, Ksi: 1}
+	ue.ABBA = []uint8{0x00, 0x00}
+	ue.Kamf = oamTestKamf
+	ue.IntegrityAlg = security.AlgIntegrity128NIA2
+	ue.CipheringAlg = security.AlgCiphering128NEA0
+	ue.DerivateAlgKey()
+	capability := nasType.NewUESecurityCapability(nasMessage.RegistrationRequestUESecurityCapabilityType)
+	capability.SetLen(2)
+	capability.SetEA0_5G(1)
+	capability.SetIA2_128_5G(1)
+	ue.UESecurityCapability = *capability
+	return ue, conn
+}
+
+// stubAusf makes the AUSF authenticate the UE with a 5G AKA vector expecting oamTestResStar,
+// and confirm the authentication with oamTestKseaf
+func stubAusf(t *testing.T) {
+	t.Helper()
+	originalSendSearchNFInstances := sendSearchNFInstancesForAuthentication
+	originalSendUEAuthenticationAuthenticateRequest := sendUEAuthenticationAuthenticateRequestForAuthentication
+	originalSendAuth5gAkaConfirmRequest := sendAuth5gAkaConfirmRequestForAuthentication
+	t.Cleanup(func() {
+		sendSearchNFInstancesForAuthentication = originalSendSearchNFInstances
+		sendUEAuthenticationAuthenticateRequestForAuthentication = originalSendUEAuthenticationAuthenticateRequest
+		sendAuth5gAkaConfirmRequestForAuthentication = originalSendAuth5gAkaConfirmRequest
+	})
+
+	sendSearchNFInstancesForAuthentication = func(
+		ctx ctxt.Context,
+		nrfUri string,
+		targetNfType, requestNfType models.NFType,
+		configure consumer.SearchNFInstancesRequestConfigurer,
+	) (*models.SearchResult, error) {
+		services := []models.NFService{{
+			ServiceName:     models.SERVICENAME_NAUSF_AUTH,
+			NfServiceStatus: models.NFSERVICESTATUS_REGISTERED,
+			ApiPrefix:       openapi.PtrString("http://ausf.example.com"),
+		}}
+		return models.NewSearchResult(300, []models.NFProfileDiscovery{{
+			NfInstanceId: "ausf-instance",
+			NfServices:   services,
+		}}), nil
+	}
+	sendUEAuthenticationAuthenticateRequestForAuthentication = func(ctx ctxt.Context, ue *context.AmfUe,
+		resynchronizationInfo *models.ResynchronizationInfo,
+	) (*models.UEAuthenticationCtx, *models.ProblemDetails, error) {
+		rand, err := hex.DecodeString(oamTestRand)
+		if err != nil {
+			return nil, nil, err
+		}
+		// HXRES* (TS 33.501 Annex A.5)
+		hxresStar := sha256.Sum256(append(rand, oamTestResStar[:]...))
+		av5gAka := models.NewAv5gAka(oamTestRand, hex.EncodeToString(hxresStar[16:]), oamTestAutn)
+		return models.NewUEAuthenticationCtx(models.AUTHTYPE__5_G_AKA,
+			models.Av5gAkaAsUEAuthenticationCtx5gAuthData(av5gAka), nil), nil, nil
+	}
+	sendAuth5gAkaConfirmRequestForAuthentication = func(ctx ctxt.Context, ue *context.AmfUe, resStar string) (
+		*models.ConfirmationDataResponse, *models.ProblemDetails, error,
+	) {
+		response := models.NewConfirmationDataResponse(models.AUTHRESULT_AUTHENTICATION_SUCCESS)
+		response.SetSupi(ue.GetSupi())
+		response.SetKseaf(oamTestKseaf)
+		return response, nil, nil
+	}
+}
+
+// sendGmmMessage hands message to the GMM FSM as received from ue over 3GPP access
+func sendGmmMessage(t *testing.T, ue *context.AmfUe, message *nas.GmmMessage) {
+	t.Helper()
+	if err := GmmFSM.SendEvent(ctxt.Background(), ue.State[models.ACCESSTYPE__3_GPP_ACCESS], GmmMessageEvent,
+		fsm.ArgsType{
+			ArgAmfUe:         ue,
+			ArgAccessType:    models.ACCESSTYPE__3_GPP_ACCESS,
+			ArgNASMessage:    message,
+			ArgProcedureCode: ngapType.ProcedureCodeUplinkNASTransport,
+		}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newAuthenticationResponse(resStar [16]uint8) *nas.GmmMessage {
+	message := nas.NewGmmMessage()
+	message.GmmHeader.SetMessageType(nas.MsgTypeAuthenticationResponse)
+	message.AuthenticationResponse = nasMessage.NewAuthenticationResponse(0)
+	message.AuthenticationResponse.AuthenticationResponseParameter = nasType.NewAuthenticationResponseParameter(
+		nasMessage.AuthenticationResponseAuthenticationResponseParameterType)
+	message.AuthenticationResponse.AuthenticationResponseParameter.SetLen(uint8(len(resStar)))
+	message.AuthenticationResponse.SetRES(resStar)
+	return message
+}
+
+func newSecurityModeComplete() *nas.GmmMessage {
+	message := nas.NewGmmMessage()
+	message.GmmHeader.SetMessageType(nas.MsgTypeSecurityModeComplete)
+	message.SecurityModeComplete = nasMessage.NewSecurityModeComplete(0)
+	return message
+}
+
+func newSecurityModeReject() *nas.GmmMessage {
+	message := nas.NewGmmMessage()
+	message.GmmHeader.SetMessageType(nas.MsgTypeSecurityModeReject)
+	message.SecurityModeReject = nasMessage.NewSecurityModeReject(0)
+	message.SecurityModeReject.SetCauseValue(nasMessage.Cause5GMMUESecurityCapabilitiesMismatch)
+	return message
+}
+
+func newConfigurationUpdateComplete() *nas.GmmMessage {
+	message := nas.NewGmmMessage()
+	message.GmmHeader.SetMessageType(nas.MsgTypeConfigurationUpdateComplete)
+	message.ConfigurationUpdateComplete = nasMessage.NewConfigurationUpdateComplete(0)
+	return message
+}
+
+// sentGmmMessageType returns the type of the not ciphered 5GMM message of the Downlink NAS
+// Transport last sent on conn
+func sentGmmMessageType(t *testing.T, conn *ngaputil.TestConn) uint8 {
+	t.Helper()
+	pdu, err := ngap.Decoder(conn.Snapshot())
+	if err != nil {
+		t.Fatalf("decode NGAP message: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.Value.DownlinkNASTransport == nil {
+		t.Fatal("expected a Downlink NAS Transport to be sent")
+	}
+	for _, ie := range pdu.InitiatingMessage.Value.DownlinkNASTransport.ProtocolIEs.List {
+		if ie.Id.Value != ngapType.ProtocolIEIDNASPDU {
+			continue
+		}
+		nasPdu := ie.Value.NASPDU.Value
+		// a security protected message starts with a security header of 7 octets
+		if len(nasPdu) > 1 && nasPdu[1]&0x0f != nas.SecurityHeaderTypePlainNas {
+			nasPdu = nasPdu[7:]
+		}
+		if len(nasPdu) < 3 {
+			t.Fatalf("NAS PDU too short: %x", nasPdu)
+		}
+		return nasPdu[2]
+	}
+	t.Fatal("expected a NAS PDU in the Downlink NAS Transport")
+	return 0
+}
+
+// sentSecurityKey returns the security key of the UE Context Modification Request last sent on conn
+func sentSecurityKey(t *testing.T, conn *ngaputil.TestConn) []byte {
+	t.Helper()
+	pdu, err := ngap.Decoder(conn.Snapshot())
+	if err != nil {
+		t.Fatalf("decode NGAP message: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.Value.UEContextModification == nil {
+		t.Fatal("expected a UE Context Modification Request to be sent")
+	}
+	for _, ie := range pdu.InitiatingMessage.Value.UEContextModification.ProtocolIEs.List {
+		if ie.Id.Value == ngapType.ProtocolIEIDSecurityKey {
+			return ie.Value.SecurityKey.Value.Bytes
+		}
+	}
+	t.Fatal("expected a Security Key in the UE Context Modification Request")
+	return nil
+}
+
+// oamOutcome returns the outcome of the ended OAM procedure of result
+func oamOutcome(t *testing.T, result <-chan context.OamProcedureResult) context.OamProcedureOutcome {
+	t.Helper()
+	select {
+	case r := <-result:
+		return r.Outcome
+	default:
+		t.Fatal("expected the OAM procedure to have ended")
+		return ""
+	}
+}
+
+func TestReauthentication(t *testing.T) {
+	ue, conn := newOamTestUe(t)
+	stubAusf(t)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+
+	result, err := ue.StartOamProcedure(context.OamProcedureReauthentication)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = StartReauthentication(ctxt.Background(), ue, anType); err != nil {
+		t.Fatal(err)
+	}
+	if !ue.State[anType].Is(context.Authentication) {
+		t.Fatalf("expected GMM state Authentication, got %s", ue.State[anType].Current())
+	}
+	if messageType := sentGmmMessageType(t, conn); messageType != nas.MsgTypeAuthenticationRequest {
+		t.Fatalf("expected an Authentication Request, got message type 0x%x", messageType)
+	}
+
+	sendGmmMessage(t, ue, newAuthenticationResponse(oamTestResStar))
+	if !ue.State[anType].Is(context.SecurityMode) {
+		t.Fatalf("expected GMM state SecurityMode, got %s", ue.State[anType].Current())
+	}
+	if messageType := sentGmmMessageType(t, conn); messageType != nas.MsgTypeSecurityModeCommand {
+		t.Fatalf("expected a Security Mode Command, got message type 0x%x", messageType)
+	}
+	select {
+	case r := <-result:
+		t.Fatalf("expected the re-authentication to go on, got %+v", r)
+	default:
+	}
+
+	sendGmmMessage(t, ue, newSecurityModeComplete())
+	if !ue.State[anType].Is(context.Registered) {
+		t.Fatalf("expected GMM state Registered, got %s", ue.State[anType].Current())
+	}
+	if procedure := ue.GetOnGoing(anType).Procedure; procedure != context.OnGoingProcedureNothing {
+		t.Errorf("expected no ongoing procedure, got %s", procedure)
+	}
+	if securityKey := sentSecurityKey(t, conn); !bytes.Equal(securityKey, ue.Kgnb) {
+		t.Errorf("expected the new KgNB %x to be sent, got %x", ue.Kgnb, securityKey)
+	}
+	if ue.Kamf == oamTestKamf || ue.NgKsi.Ksi != 2 || !ue.SecurityContextIsValid() {
+		t.Errorf("expected the new security context to be used, got ngKSI %d", ue.NgKsi.Ksi)
+	}
+	if ue.GetSupi() != oamTestSupi {
+		t.Errorf("expected SUPI %s, got %s", oamTestSupi, ue.GetSupi())
+	}
+	if outcome := oamOutcome(t, result); outcome != context.OamOutcomeCompleted {
+		t.Errorf("expected the re-authentication to complete, got %s", outcome)
+	}
+}
+
+func TestReauthenticationFailure(t *testing.T) {
+	testCases := []struct {
+		name string
+		// setup runs before the re-authentication of ue is started
+		setup func(t *testing.T, ue *context.AmfUe)
+		// fail makes the started re-authentication of ue fail
+		fail     func(t *testing.T, ue *context.AmfUe)
+		state    fsm.StateType
+		restored bool
+	}{
+		{
+			name: "no AUSF",
+			setup: func(t *testing.T, ue *context.AmfUe) {
+				sendSearchNFInstancesForAuthentication = func(ctxt.Context, string, models.NFType, models.NFType,
+					consumer.SearchNFInstancesRequestConfigurer,
+				) (*models.SearchResult, error) {
+					return nil, errors.New("NRF unreachable")
+				}
+			},
+			fail:     func(t *testing.T, ue *context.AmfUe) {},
+			state:    context.Registered,
+			restored: true,
+		},
+		{
+			name:  "security mode rejected",
+			setup: func(t *testing.T, ue *context.AmfUe) {},
+			fail: func(t *testing.T, ue *context.AmfUe) {
+				sendGmmMessage(t, ue, newAuthenticationResponse(oamTestResStar))
+				sendGmmMessage(t, ue, newSecurityModeReject())
+			},
+			state:    context.Registered,
+			restored: true,
+		},
+		{
+			name: "T3560 expired",
+			setup: func(t *testing.T, ue *context.AmfUe) {
+				amfSelf := context.AMF_Self()
+				originalT3560Cfg := amfSelf.T3560Cfg
+				t.Cleanup(func() { amfSelf.T3560Cfg = originalT3560Cfg })
+				amfSelf.T3560Cfg = factory.TimerValue{Enable: true, ExpireTime: 10 * time.Millisecond, MaxRetryTimes: 0}
+				// the expiry is handled on the event loop of the UE
+				ue.EventChannel = ue.NewEventChannel()
+				go ue.EventChannel.Start(ctxt.Background())
+				t.Cleanup(func() { ue.EventChannel.Event <- "quit" })
+			},
+			fail: func(t *testing.T, ue *context.AmfUe) {
+				for deadline := time.Now().Add(time.Second); ue.State[models.ACCESSTYPE__3_GPP_ACCESS].Is(context.Authentication); {
+					if time.Now().After(deadline) {
+						t.Fatal("expected the re-authentication to end on T3560 expiry")
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+				// wait for the event loop to finish handling the expiry
+				ue.EventChannel.UpdateSbiHandler(func(ctxt.Context, string, string, any) (any, string, any, any) {
+					return nil, "", nil, nil
+				})
+				handled := make(chan context.SbiResponseMsg, 1)
+				ue.EventChannel.SubmitMessage(context.SbiMsg{Result: handled})
+				<-handled
+			},
+			state:    context.Registered,
+			restored: true,
+		},
+		{
+			name:  "authentication rejected",
+			setup: func(t *testing.T, ue *context.AmfUe) {},
+			fail: func(t *testing.T, ue *context.AmfUe) {
+				sendGmmMessage(t, ue, newAuthenticationResponse([16]uint8{}))
+			},
+			state: context.Deregistered,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ue, _ := newOamTestUe(t)
+			stubAusf(t)
+			tc.setup(t, ue)
+			anType := models.ACCESSTYPE__3_GPP_ACCESS
+
+			result, err := ue.StartOamProcedure(context.OamProcedureReauthentication)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = StartReauthentication(ctxt.Background(), ue, anType); err != nil {
+				t.Fatal(err)
+			}
+			tc.fail(t, ue)
+
+			if !ue.State[anType].Is(tc.state) {
+				t.Fatalf("expected GMM state %s, got %s", tc.state, ue.State[anType].Current())
+			}
+			if procedure := ue.GetOnGoing(anType).Procedure; procedure != context.OnGoingProcedureNothing {
+				t.Errorf("expected no ongoing procedure, got %s", procedure)
+			}
+			if restored := ue.Kamf == oamTestKamf && ue.NgKsi.Ksi == 1 && ue.SecurityContextIsValid(); restored != tc.restored {
+				t.Errorf("expected the previous security context to be restored: %t, got ngKSI %d", tc.restored, ue.NgKsi.Ksi)
+			}
+			if outcome := oamOutcome(t, result); outcome != context.OamOutcomeFailed {
+				t.Errorf("expected the re-authentication to fail, got %s", outcome)
+			}
+		})
+	}
+}
+
+func TestReauthenticationDefersNasMessages(t *testing.T) {
+	ue, _ := newOamTestUe(t)
+	stubAusf(t)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+
+	if err := ReallocateGuti(ue, anType); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartReauthentication(ctxt.Background(), ue, anType); err != nil {
+		t.Fatal(err)
+	}
+
+	sendGmmMessage(t, ue, newConfigurationUpdateComplete())
+	if !ue.State[anType].Is(context.Authentication) || !reauthenticating(ue, anType) {
+		t.Fatalf("expected the re-authentication to go on, got GMM state %s", ue.State[anType].Current())
+	}
+	if ue.GetOldGuti() == "" {
+		t.Fatal("expected the Configuration Update Complete to be deferred")
+	}
+
+	sendGmmMessage(t, ue, newAuthenticationResponse(oamTestResStar))
+	sendGmmMessage(t, ue, newSecurityModeComplete())
+	if !ue.State[anType].Is(context.Registered) {
+		t.Fatalf("expected GMM state Registered, got %s", ue.State[anType].Current())
+	}
+	if ue.GetOldGuti() != "" {
+		t.Error("expected the deferred Configuration Update Complete to be handled once the re-authentication ended")
+	}
+}
+
+func TestReallocateGuti(t *testing.T) {
+	ue, conn := newOamTestUe(t)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	amfSelf := context.AMF_Self()
+	oldGuti := ue.GetGuti()
+
+	result, err := ue.StartOamProcedure(context.OamProcedureGutiReallocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ReallocateGuti(ue, anType); err != nil {
+		t.Fatal(err)
+	}
+	if messageType := sentGmmMessageType(t, conn); messageType != nas.MsgTypeConfigurationUpdateCommand {
+		t.Fatalf("expected a Configuration Update Command, got message type 0x%x", messageType)
+	}
+	if ue.GetGuti() == oldGuti || ue.GetOldGuti() != oldGuti {
+		t.Fatalf("expected a new 5G-GUTI besides %s, got %s", oldGuti, ue.GetGuti())
+	}
+	if found, ok := amfSelf.AmfUeFindByGuti(oldGuti); !ok || found != ue {
+		t.Error("expected the old 5G-GUTI to identify the UE until the UE acknowledges the new one")
+	}
+
+	sendGmmMessage(t, ue, newConfigurationUpdateComplete())
+	if !ue.State[anType].Is(context.Registered) {
+		t.Fatalf("expected GMM state Registered, got %s", ue.State[anType].Current())
+	}
+	if ue.GetOldGuti() != "" {
+		t.Error("expected the old 5G-GUTI to be freed")
+	}
+	if _, ok := amfSelf.AmfUeFindByGuti(oldGuti); ok {
+		t.Error("expected the old 5G-GUTI not to identify the UE anymore")
+	}
+	if found, ok := amfSelf.AmfUeFindByGuti(ue.GetGuti()); !ok || found != ue {
+		t.Error("expected the new 5G-GUTI to identify the UE")
+	}
+	if outcome := oamOutcome(t, result); outcome != context.OamOutcomeCompleted {
+		t.Errorf("expected the 5G-GUTI reallocation to complete, got %s", outcome)
+	}
+}
+
+func TestReallocateGutiNotAcknowledged(t *testing.T) {
+	ue, _ := newOamTestUe(t)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	amfSelf := context.AMF_Self()
+	originalT3555Cfg := amfSelf.T3555Cfg
+	t.Cleanup(func() { amfSelf.T3555Cfg = originalT3555Cfg })
+	amfSelf.T3555Cfg = factory.TimerValue{Enable: true, ExpireTime: 10 * time.Millisecond, MaxRetryTimes: 1}
+	oldGuti := ue.GetGuti()
+
+	result, err := ue.StartOamProcedure(context.OamProcedureGutiReallocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ReallocateGuti(ue, anType); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-result:
+		if r.Outcome != context.OamOutcomeFailed {
+			t.Errorf("expected the 5G-GUTI reallocation to fail, got %s", r.Outcome)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the 5G-GUTI reallocation to end on T3555 expiry")
+	}
+	if ue.T3555 != nil {
+		t.Error("expected T3555 to be cleared")
+	}
+	// the UE may have received the new 5G-GUTI without its acknowledgement reaching the AMF
+	if found, ok := amfSelf.AmfUeFindByGuti(oldGuti); !ok || found != ue {
+		t.Error("expected the old 5G-GUTI to identify the UE")
+	}
+	if found, ok := amfSelf.AmfUeFindByGuti(ue.GetGuti()); !ok || found != ue {
+		t.Error("expected the new 5G-GUTI to identify the UE")
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"encoding/hex"
+
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/nas/v2/nasMessage"
+)
+
+// handleUeRadioCapabilityId stores the UE radio capability ID the UE included in the
+// registration request and resolves its UE radio capability from the dictionary of the AMF or
+// the UCMF (TS 23.502 5.2.18.2). An ID that cannot be resolved is still sent to the NG-RAN,
+// which then retrieves the UE radio capability from the UE.
+func handleUeRadioCapabilityId(ctx ctxt.Context, ue *context.AmfUe, registrationRequest *nasMessage.RegistrationRequest) {
+	amfSelf := context.AMF_Self()
+	if !amfSelf.RacsSupportedByRan {
+		return
+	}
+	if registrationRequest.UERadioCapabilityID == nil {
+		ue.UeRadioCapabilityId = ""
+		return
+	}
+
+	ueRadioCapabilityId := hex.EncodeToString(registrationRequest.UERadioCapabilityID.Buffer)
+	if ueRadioCapabilityId != ue.UeRadioCapabilityId {
+		ue.UeRadioCapabilityId = ueRadioCapabilityId
+		ue.UeRadioCapability = ""
+		ue.UeRadioCapabilityForPaging = nil
+	}
+	if ue.UeRadioCapability != "" {
+		return
+	}
+
+	entry, err := amfSelf.ResolveUeRadioCapabilityId(ctx, ueRadioCapabilityId)
+	if err != nil {
+		ue.GmmLog.Warnf("resolve UE radio capability ID[%s] failed: %+v", ueRadioCapabilityId, err)
+		return
+	}
+	ue.GmmLog.Debugf("UE radio capability ID[%s] resolved", ueRadioCapabilityId)
+	ue.UeRadioCapability = entry.UeRadioCapability
+	ue.UeRadioCapabilityForPaging = entry.UeRadioCapabilityForPaging
+}
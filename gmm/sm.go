@@ -9,11 +9,11 @@ package gmm
 
 import (
 	ctxt "context"
-	"fmt"
 
 	"github.com/omec-project/amf/context"
 	gmm_message "github.com/omec-project/amf/gmm/message"
 	"github.com/omec-project/amf/logger"
+	ngap_message "github.com/omec-project/amf/ngap/message"
 	"github.com/omec-project/amf/util"
 	"github.com/omec-project/nas/v2"
 	"github.com/omec-project/nas/v2/nasMessage"
@@ -28,6 +28,7 @@ func DeRegistered(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
 		amfUe.ClearRegistrationRequestData(accessType)
+		amfUe.SetRegisteredPopulation(accessType, false)
 		amfUe.GmmLog.Debugln("EntryEvent at GMM State[DeRegistered]")
 	case GmmMessageEvent:
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
@@ -59,7 +60,8 @@ func DeRegistered(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 	case NwInitiatedDeregistrationEvent:
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
-		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType); err != nil {
+		reRegistrationRequired, cause5GMM := nwInitiatedDeregistrationArgs(args)
+		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType, reRegistrationRequired, cause5GMM); err != nil {
 			logger.GmmLog.Errorln(err)
 		}
 	case StartAuthEvent:
@@ -78,6 +80,7 @@ func Registered(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args fs
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
 		amfUe.ClearRegistrationRequestData(accessType)
+		amfUe.SetRegisteredPopulation(accessType, true)
 		amfUe.GmmLog.Debugln("EntryEvent at GMM State[Registered]")
 		// store context in DB. Registration procedure is complete.
 		amfUe.PublishUeCtxtInfo()
@@ -110,6 +113,11 @@ func Registered(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args fs
 			if err := HandleConfigurationUpdateComplete(amfUe, gmmMessage.ConfigurationUpdateComplete); err != nil {
 				logger.GmmLog.Errorln(err)
 			}
+		case nas.MsgTypeNetworkSliceSpecificAuthenticationComplete:
+			if err := HandleNetworkSliceSpecificAuthenticationComplete(ctx, amfUe, accessType,
+				gmmMessage.NetworkSliceSpecificAuthenticationComplete); err != nil {
+				logger.GmmLog.Errorln(err)
+			}
 		case nas.MsgTypeServiceRequest:
 			if err := HandleServiceRequest(ctx, amfUe, accessType, gmmMessage.ServiceRequest); err != nil {
 				logger.GmmLog.Errorln(err)
@@ -141,7 +149,8 @@ func Registered(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args fs
 	case NwInitiatedDeregistrationEvent:
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
-		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType); err != nil {
+		reRegistrationRequired, cause5GMM := nwInitiatedDeregistrationArgs(args)
+		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType, reRegistrationRequired, cause5GMM); err != nil {
 			logger.GmmLog.Errorln(err)
 		}
 	/*TODO */
@@ -159,8 +168,7 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 	switch event {
 	case fsm.EntryEvent:
 		amfUe = args[ArgAmfUe].(*context.AmfUe)
-		amfUe.GmmLog = amfUe.GmmLog.With(logger.FieldSuci, amfUe.Suci)
-		amfUe.TxLog = amfUe.TxLog.With(logger.FieldSuci, amfUe.Suci)
+		amfUe.UpdateLoggers()
 		amfUe.GmmLog.Debugln("entryEvent at GMM State[Authentication]")
 		amfUe.PublishUeCtxtInfo()
 		fallthrough
@@ -171,7 +179,9 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 
 		pass, err := AuthenticationProcedure(ctx, amfUe, accessType)
 		if err != nil {
-			if err := GmmFSM.SendEvent(ctx, state, AuthErrorEvent, fsm.ArgsType{
+			if reauthenticating(amfUe, accessType) {
+				failReauthentication(ctx, state, amfUe, accessType, err.Error())
+			} else if err := GmmFSM.SendEvent(ctx, state, AuthErrorEvent, fsm.ArgsType{
 				ArgAmfUe:      amfUe,
 				ArgAccessType: accessType,
 			}); err != nil {
@@ -191,6 +201,11 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 		gmmMessage := args[ArgNASMessage].(*nas.GmmMessage)
 		accessType := args[ArgAccessType].(models.AccessType)
 		amfUe.GmmLog.Debugln("GmmMessageEvent at GMM State[Authentication]")
+		procedureCode, _ := args[ArgProcedureCode].(int64)
+		if reauthenticating(amfUe, accessType) &&
+			handleNasMessageDuringReauthentication(ctx, state, amfUe, accessType, gmmMessage, procedureCode) {
+			return
+		}
 
 		switch gmmMessage.GetMessageType() {
 		case nas.MsgTypeIdentityResponse:
@@ -204,11 +219,17 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 		case nas.MsgTypeAuthenticationResponse:
 			if err := HandleAuthenticationResponse(ctx, amfUe, accessType, gmmMessage.AuthenticationResponse); err != nil {
 				logger.GmmLog.Errorln(err)
+				if reauthenticating(amfUe, accessType) && state.Is(context.Authentication) {
+					failReauthentication(ctx, state, amfUe, accessType, err.Error())
+				}
 			}
 			amfUe.PublishUeCtxtInfo()
 		case nas.MsgTypeAuthenticationFailure:
 			if err := HandleAuthenticationFailure(ctx, amfUe, accessType, gmmMessage.AuthenticationFailure); err != nil {
 				logger.GmmLog.Errorln(err)
+				if reauthenticating(amfUe, accessType) && state.Is(context.Authentication) {
+					failReauthentication(ctx, state, amfUe, accessType, err.Error())
+				}
 			}
 		case nas.MsgTypeStatus5GMM:
 			if err := HandleStatus5GMM(amfUe, accessType, gmmMessage.Status5GMM); err != nil {
@@ -217,6 +238,10 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 		default:
 			logger.GmmLog.Errorf("UE state mismatch: receieve gmm message[message type 0x%0x] at %s state",
 				gmmMessage.GetMessageType(), state.Current())
+			if reauthenticating(amfUe, accessType) {
+				failReauthentication(ctx, state, amfUe, accessType, "unexpected NAS message during authentication")
+				return
+			}
 			// called SendEvent() to move to deregistered state if state mismatch occurs
 			err := GmmFSM.SendEvent(ctx, state, AuthFailEvent, fsm.ArgsType{
 				ArgAmfUe:      amfUe,
@@ -233,6 +258,11 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 	case AuthFailEvent:
 		logger.GmmLog.Debugln(event)
 		logger.GmmLog.Warnln("reject authentication")
+		amfUe := args[ArgAmfUe].(*context.AmfUe)
+		accessType := args[ArgAccessType].(models.AccessType)
+		if reauthenticating(amfUe, accessType) {
+			rejectReauthentication(ctx, amfUe, accessType)
+		}
 	case AuthErrorEvent:
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
@@ -240,10 +270,19 @@ func Authentication(ctx ctxt.Context, state *fsm.State, event fsm.EventType, arg
 		if err := HandleAuthenticationError(amfUe, accessType); err != nil {
 			logger.GmmLog.Errorln(err)
 		}
+	case ReauthenticationFailEvent:
+		logger.GmmLog.Debugln(event)
+		amfUe := args[ArgAmfUe].(*context.AmfUe)
+		if amfUe.T3560 != nil {
+			amfUe.T3560.Stop()
+			amfUe.T3560 = nil
+		}
+		amfUe.RestoreSecurityContext()
 	case NwInitiatedDeregistrationEvent:
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
-		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType); err != nil {
+		reRegistrationRequired, cause5GMM := nwInitiatedDeregistrationArgs(args)
+		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType, reRegistrationRequired, cause5GMM); err != nil {
 			logger.GmmLog.Errorln(err)
 		}
 	case fsm.ExitEvent:
@@ -263,13 +302,11 @@ func SecurityMode(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
 		// set log information
-		amfUe.NASLog = amfUe.NASLog.With(logger.FieldSupi, fmt.Sprintf("SUPI:%s", amfUe.GetSupi()))
-		amfUe.TxLog = amfUe.TxLog.With(logger.FieldSupi, fmt.Sprintf("SUPI:%s", amfUe.GetSupi()))
-		amfUe.GmmLog = amfUe.GmmLog.With(logger.FieldSupi, fmt.Sprintf("SUPI:%s", amfUe.GetSupi()))
-		amfUe.ProducerLog = logger.ProducerLog.With(logger.FieldSupi, fmt.Sprintf("SUPI:%s", amfUe.GetSupi()))
+		amfUe.UpdateLoggers()
 		amfUe.PublishUeCtxtInfo()
 		amfUe.GmmLog.Debugln("EntryEvent at GMM State[SecurityMode]")
-		if amfUe.SecurityContextIsValid() {
+		// a re-authentication always takes the new security context into use
+		if amfUe.SecurityContextIsValid() && amfUe.GetOnGoing(accessType).Procedure != context.OnGoingProcedureReauthentication {
 			amfUe.GmmLog.Debugln("UE has a valid security context - skip security mode control procedure")
 			if err := GmmFSM.SendEvent(ctx, state, SecurityModeSuccessEvent, fsm.ArgsType{
 				ArgAmfUe:      amfUe,
@@ -305,6 +342,10 @@ func SecurityMode(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 		gmmMessage := args[ArgNASMessage].(*nas.GmmMessage)
 		accessType := args[ArgAccessType].(models.AccessType)
 		amfUe.GmmLog.Debugln("GmmMessageEvent to GMM State[SecurityMode]")
+		if reauthenticating(amfUe, accessType) &&
+			handleNasMessageDuringReauthentication(ctx, state, amfUe, accessType, gmmMessage, procedureCode) {
+			return
+		}
 		switch gmmMessage.GetMessageType() {
 		case nas.MsgTypeSecurityModeComplete:
 			if err := HandleSecurityModeComplete(ctx, amfUe, accessType, procedureCode, gmmMessage.SecurityModeComplete); err != nil {
@@ -314,6 +355,10 @@ func SecurityMode(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 			if err := HandleSecurityModeReject(amfUe, accessType, gmmMessage.SecurityModeReject); err != nil {
 				logger.GmmLog.Errorln(err)
 			}
+			if reauthenticating(amfUe, accessType) {
+				failReauthentication(ctx, state, amfUe, accessType, "security mode rejected")
+				return
+			}
 			err := GmmFSM.SendEvent(ctx, state, SecurityModeFailEvent, fsm.ArgsType{
 				ArgAmfUe:      amfUe,
 				ArgAccessType: accessType,
@@ -348,6 +393,10 @@ func SecurityMode(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 		default:
 			amfUe.GmmLog.Errorf("state mismatch: receieve gmm message[message type 0x%0x] at %s state",
 				gmmMessage.GetMessageType(), state.Current())
+			if reauthenticating(amfUe, accessType) {
+				failReauthentication(ctx, state, amfUe, accessType, "unexpected NAS message during security mode control")
+				return
+			}
 			// called SendEvent() to move to deregistered state if state mismatch occurs
 			err := GmmFSM.SendEvent(ctx, state, SecurityModeFailEvent, fsm.ArgsType{
 				ArgAmfUe:      amfUe,
@@ -362,23 +411,36 @@ func SecurityMode(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 	case SecurityModeAbortEvent:
 		logger.GmmLog.Debugln(event)
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
+		accessType := args[ArgAccessType].(models.AccessType)
 		// stopping security mode command timer
 		amfUe.SecurityContextAvailable = false
 		amfUe.T3560.Stop()
 		amfUe.T3560 = nil
+		if reauthenticating(amfUe, accessType) {
+			amfUe.EndReauthentication(accessType, context.OamOutcomeFailed, "aborted by a Registration Request")
+		}
 	case NwInitiatedDeregistrationEvent:
 		logger.GmmLog.Debugln(event)
 		amfUe := args[ArgAmfUe].(*context.AmfUe)
 		accessType := args[ArgAccessType].(models.AccessType)
 		amfUe.T3560.Stop()
 		amfUe.T3560 = nil
-		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType); err != nil {
+		reRegistrationRequired, cause5GMM := nwInitiatedDeregistrationArgs(args)
+		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType, reRegistrationRequired, cause5GMM); err != nil {
 			logger.GmmLog.Errorln(err)
 		}
 	case SecurityModeSuccessEvent:
 		logger.GmmLog.Debugln(event)
 	case SecurityModeFailEvent:
 		logger.GmmLog.Debugln(event)
+	case ReauthenticationFailEvent:
+		logger.GmmLog.Debugln(event)
+		amfUe := args[ArgAmfUe].(*context.AmfUe)
+		if amfUe.T3560 != nil {
+			amfUe.T3560.Stop()
+			amfUe.T3560 = nil
+		}
+		amfUe.RestoreSecurityContext()
 	case fsm.ExitEvent:
 		logger.GmmLog.Debugln(event)
 		return
@@ -403,6 +465,23 @@ func ContextSetup(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 		}
 		amfUe.GmmLog.Debugln("EntryEvent at GMM State[ContextSetup]")
 		amfUe.PublishUeCtxtInfo()
+		if amfUe.GetOnGoing(accessType).Procedure == context.OnGoingProcedureReauthentication {
+			// the UE stays registered, there is no registration to set up the context for
+			if err := GmmFSM.SendEvent(ctx, state, ContextSetupSuccessEvent, fsm.ArgsType{
+				ArgAmfUe:      amfUe,
+				ArgAccessType: accessType,
+			}); err != nil {
+				logger.GmmLog.Errorln(err)
+				return
+			}
+			// the NG-RAN node still protects the AS with the KgNB of the old security context
+			if amfUe.CmConnect(accessType) {
+				ngap_message.SendUEContextModificationRequest(amfUe.GetRanUe(accessType))
+			}
+			amfUe.EndReauthentication(accessType, context.OamOutcomeCompleted, "")
+			handleDeferredNasMessages(ctx, amfUe, accessType)
+			return
+		}
 		switch message := gmmMessage.(type) {
 		case *nasMessage.RegistrationRequest:
 			amfUe.RegistrationRequest = message
@@ -515,7 +594,9 @@ func ContextSetup(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args
 		amfUe.T3550.Stop()
 		amfUe.T3550 = nil
 		amfUe.State[accessType].Set(context.Registered)
-		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType); err != nil {
+		amfUe.SetRegisteredPopulation(accessType, true)
+		reRegistrationRequired, cause5GMM := nwInitiatedDeregistrationArgs(args)
+		if err := NetworkInitiatedDeregistrationProcedure(ctx, amfUe, accessType, reRegistrationRequired, cause5GMM); err != nil {
 			logger.GmmLog.Errorln(err)
 		}
 	case ContextSetupFailEvent:
@@ -586,13 +667,17 @@ func SetDeregisteredState(amfUe *context.AmfUe, anType uint8) {
 	case nasMessage.AccessType3GPP:
 		amfUe.GmmLog.Warnln("UE accessType[3GPP] transfer to Deregistered state")
 		amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(context.Deregistered)
+		amfUe.SetRegisteredPopulation(models.ACCESSTYPE__3_GPP_ACCESS, false)
 	case nasMessage.AccessTypeNon3GPP:
 		amfUe.GmmLog.Warnln("UE accessType[Non3GPP] transfer to Deregistered state")
 		amfUe.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Set(context.Deregistered)
+		amfUe.SetRegisteredPopulation(models.ACCESSTYPE_NON_3_GPP_ACCESS, false)
 	default:
 		amfUe.GmmLog.Warnln("UE accessType[3GPP] transfer to Deregistered state")
 		amfUe.State[models.ACCESSTYPE__3_GPP_ACCESS].Set(context.Deregistered)
 		amfUe.GmmLog.Warnln("UE accessType[Non3GPP] transfer to Deregistered state")
 		amfUe.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Set(context.Deregistered)
+		amfUe.SetRegisteredPopulation(models.ACCESSTYPE__3_GPP_ACCESS, false)
+		amfUe.SetRegisteredPopulation(models.ACCESSTYPE_NON_3_GPP_ACCESS, false)
 	}
 }
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"fmt"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	gmm_message "github.com/omec-project/amf/gmm/message"
+	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+var (
+	putSorAck = consumer.PutSorAck
+	putUpuAck = consumer.PutUpuAck
+)
+
+// handleSorAck verifies the SoR acknowledgement of the UE and forwards its SoR-MAC-IUE to the
+// UDM, which checks it against the XMAC-IUE (TS 33.501 6.14.2.1)
+func handleSorAck(ctx ctxt.Context, ue *context.AmfUe, container []byte) error {
+	sorMacIue, err := gmm_message.ParseSorAck(container)
+	if err != nil {
+		return err
+	}
+	if !ue.SorAckRequested {
+		return fmt.Errorf("unexpected SoR ack from UE[%s]", ue.GetSupi())
+	}
+	ue.SorAckRequested = false
+	ue.GmmLog.Debugf("SorMac[%s] in SOR ACK NAS Msg", sorMacIue)
+	return putSorAck(ctx, ue, sorMacIue)
+}
+
+// handleUpuAck verifies the UE parameters update acknowledgement of the UE and forwards its
+// UPU-MAC-IUE to the UDM (TS 33.501 6.15.2.1)
+func handleUpuAck(ctx ctxt.Context, ue *context.AmfUe, container []byte) error {
+	upuMacIue, err := gmm_message.ParseUpuAck(container)
+	if err != nil {
+		return err
+	}
+	if !ue.UpuAckRequested {
+		return fmt.Errorf("unexpected UPU ack from UE[%s]", ue.GetSupi())
+	}
+	ue.UpuAckRequested = false
+	ue.GmmLog.Debugf("UpuMac[%s] in UPU ACK NAS Msg", upuMacIue)
+	return putUpuAck(ctx, ue, upuMacIue)
+}
+
+// DeliverSorAndUpu sends the SoR and UE parameters update information of the AM data of the UE
+// in DL NAS Transport (TS 23.122 C.3, TS 23.502 4.20.2). The containers of a CM-IDLE UE are
+// queued and the UE is paged; they are sent once it answers with a Service Request.
+func DeliverSorAndUpu(ue *context.AmfUe, anType models.AccessType) error {
+	return deliverTransparentContainers(ue, anType, true, true)
+}
+
+// deliverUpuAfterRegistration sends the UE parameters update information once the registration
+// has completed; the SoR information was already part of the Registration Accept.
+func deliverUpuAfterRegistration(ue *context.AmfUe, anType models.AccessType) {
+	if err := deliverTransparentContainers(ue, anType, false, true); err != nil {
+		ue.GmmLog.Errorf("UE parameters update failed: %+v", err)
+	}
+}
+
+func deliverTransparentContainers(ue *context.AmfUe, anType models.AccessType, sor, upu bool) error {
+	amData := ue.AccessAndMobilitySubscriptionData
+	if amData == nil {
+		return nil
+	}
+
+	var containers []context.TransparentContainer
+	if sorInfo, ok := amData.GetSorInfoOk(); ok && sor {
+		sorContainer, err := gmm_message.BuildSorTransparentContainer(sorInfo)
+		if err != nil {
+			return err
+		}
+		containers = append(containers, context.TransparentContainer{
+			PayloadContainerType: nasMessage.PayloadContainerTypeSOR,
+			Contents:             sorContainer,
+		})
+	}
+	if upuInfo, ok := amData.GetUpuInfoOk(); ok && upu {
+		upuContainer, err := gmm_message.BuildUpuTransparentContainer(upuInfo)
+		if err != nil {
+			return err
+		}
+		containers = append(containers, context.TransparentContainer{
+			PayloadContainerType: nasMessage.PayloadContainerTypeUEParameterUpdate,
+			Contents:             upuContainer,
+		})
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	if ue.CmConnect(anType) {
+		for _, container := range containers {
+			if err := sendTransparentContainer(ue, anType, container); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// newer AM data replaces the containers still queued
+	ue.PendingTransparentContainers = containers
+	return pageUe(ue, anType)
+}
+
+// deliverPendingTransparentContainers sends the containers queued while the UE was CM-IDLE.
+func deliverPendingTransparentContainers(ue *context.AmfUe, anType models.AccessType) {
+	for len(ue.PendingTransparentContainers) > 0 && ue.CmConnect(anType) {
+		if err := sendTransparentContainer(ue, anType, ue.PendingTransparentContainers[0]); err != nil {
+			ue.GmmLog.Errorf("send queued transparent container failed: %+v", err)
+			return
+		}
+		ue.PendingTransparentContainers = ue.PendingTransparentContainers[1:]
+	}
+}
+
+// sendTransparentContainer sends container to a CM-CONNECTED UE in DL NAS Transport.
+func sendTransparentContainer(ue *context.AmfUe, anType models.AccessType, container context.TransparentContainer) error {
+	nasMsg, err := gmm_message.BuildDLNASTransport(ue, anType, container.PayloadContainerType,
+		container.Contents, 0, nil, nil, 0)
+	if err != nil {
+		return fmt.Errorf("build DL NAS Transport failed: %w", err)
+	}
+	ngap_message.SendDownlinkNasTransport(ue.GetRanUe(anType), nasMsg, nil)
+	transparentContainerSent(ue, container)
+	return nil
+}
+
+// transparentContainerSent records whether the UE owes an ack for the container it was sent.
+func transparentContainerSent(ue *context.AmfUe, container context.TransparentContainer) {
+	if container.PayloadContainerType == nasMessage.PayloadContainerTypeSOR {
+		ue.SorAckRequested = gmm_message.SorContainerAckRequested(container.Contents)
+	} else {
+		ue.UpuAckRequested = gmm_message.UpuContainerAckRequested(container.Contents)
+	}
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/omec-project/amf/context"
+	gmm_message "github.com/omec-project/amf/gmm/message"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/openapi/v2/models"
+	"go.uber.org/zap"
+)
+
+func TestHandleSorAck(t *testing.T) {
+	origPutSorAck := putSorAck
+	defer func() { putSorAck = origPutSorAck }()
+
+	var forwardedMac string
+	putSorAck = func(_ ctxt.Context, _ *context.AmfUe, sorMacIue string) error {
+		forwardedMac = sorMacIue
+		return nil
+	}
+
+	mac := "00112233445566778899aabbccddeeff"
+	macBytes, _ := hex.DecodeString(mac)
+	ack := append([]byte{gmm_message.SorDataTypeAck}, macBytes...)
+
+	ue := &context.AmfUe{GmmLog: zap.NewNop().Sugar()}
+	if err := handleSorAck(ctxt.Background(), ue, ack); err == nil {
+		t.Fatal("expected unrequested SoR ack to be rejected")
+	}
+
+	ue.SorAckRequested = true
+	if err := handleSorAck(ctxt.Background(), ue, ack[:8]); err == nil {
+		t.Fatal("expected truncated SoR ack to be rejected")
+	}
+	if err := handleSorAck(ctxt.Background(), ue, ack); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if forwardedMac != mac {
+		t.Fatalf("expected SoR-MAC-IUE %s to be forwarded, got %s", mac, forwardedMac)
+	}
+	if ue.SorAckRequested {
+		t.Fatal("expected SoR ack to be consumed")
+	}
+}
+
+func TestBuildSorTransparentContainer(t *testing.T) {
+	mac := "00112233445566778899aabbccddeeff"
+	counter := "0001"
+	steeringInfo := []models.SteeringInfo{{
+		PlmnId:         models.PlmnId{Mcc: "208", Mnc: "93"},
+		AccessTechList: []models.AccessTech{models.ACCESSTECH_NR},
+	}}
+	sorInfo := &models.SorInfo{
+		AckInd:            true,
+		SorMacIausf:       &mac,
+		Countersor:        &counter,
+		SteeringContainer: &models.SteeringContainer{ArrayOfSteeringInfo: &steeringInfo},
+	}
+
+	container, err := gmm_message.BuildSorTransparentContainer(sorInfo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// header, SoR-MAC-IAUSF, CounterSoR and one PLMN ID and access technology entry
+	if len(container) != 1+16+2+5 {
+		t.Fatalf("unexpected container length %d", len(container))
+	}
+	if !gmm_message.SorContainerAckRequested(container) {
+		t.Fatal("expected the container to request an ack")
+	}
+	if got := hex.EncodeToString(container[1:17]); got != mac {
+		t.Fatalf("unexpected SoR-MAC-IAUSF %s", got)
+	}
+	if container[22] != 0x08 || container[23] != 0x00 {
+		t.Fatalf("unexpected access technology %x", container[22:24])
+	}
+
+	badMac := "0011"
+	sorInfo.SorMacIausf = &badMac
+	if _, err := gmm_message.BuildSorTransparentContainer(sorInfo); err == nil {
+		t.Fatal("expected invalid SoR-MAC-IAUSF to fail")
+	}
+}
+
+func TestDeliverPendingTransparentContainersWhileIdle(t *testing.T) {
+	mac := "00112233445566778899aabbccddeeff"
+	counter := "0001"
+	container, err := gmm_message.BuildSorTransparentContainer(&models.SorInfo{
+		AckInd:      true,
+		SorMacIausf: &mac,
+		Countersor:  &counter,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pending := []context.TransparentContainer{{
+		PayloadContainerType: nasMessage.PayloadContainerTypeSOR,
+		Contents:             container,
+	}}
+	ue := &context.AmfUe{GmmLog: zap.NewNop().Sugar(), PendingTransparentContainers: pending}
+	deliverPendingTransparentContainers(ue, models.ACCESSTYPE__3_GPP_ACCESS)
+	if len(ue.PendingTransparentContainers) != 1 {
+		t.Fatalf("expected the container to stay queued for a CM-IDLE UE, got %d", len(ue.PendingTransparentContainers))
+	}
+	if ue.SorAckRequested {
+		t.Fatal("expected no SoR ack to be requested before the container is sent")
+	}
+}
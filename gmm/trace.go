@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"fmt"
+	"net/http"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+var (
+	sdmGetTraceData     = consumer.SDMGetTraceData
+	sendTraceStart      = ngap_message.SendTraceStart
+	sendDeactivateTrace = ngap_message.SendDeactivateTrace
+)
+
+// RefreshTraceData retrieves the trace data of the UE from the UDM and activates, changes or
+// deactivates its trace session accordingly (TS 32.422 4.2.2.9, 4.2.4.9).
+func RefreshTraceData(ctx ctxt.Context, ue *context.AmfUe) error {
+	traceData, problemDetails, err := sdmGetTraceData(ctx, ue)
+	if problemDetails != nil && problemDetails.GetStatus() == http.StatusNotFound {
+		// no trace data is provisioned for the UE
+		traceData, problemDetails = nil, nil
+	}
+	if problemDetails != nil {
+		return fmt.Errorf("SDM_Get TraceData Failed Problem[%+v]", problemDetails)
+	} else if err != nil {
+		return fmt.Errorf("SDM_Get TraceData Error[%+v]", err)
+	}
+	applyTraceData(ue, traceData)
+	return nil
+}
+
+// applyTraceData updates the trace session of the UE. The NG-RAN nodes holding a UE context
+// are told with Trace Start and Deactivate Trace; a UE context set up later carries the Trace
+// Activation IE in Initial Context Setup Request or Handover Request.
+func applyTraceData(ue *context.AmfUe, traceData *models.TraceData) {
+	oldTraceData, oldTrsr := ue.TraceData, ue.TraceRecordingSessionRef
+	if traceData == nil {
+		if oldTraceData == nil {
+			return
+		}
+		ue.GmmLog.Infof("deactivate trace [%s]", oldTraceData.TraceRef)
+		for _, ranUe := range ranUesWithContext(ue) {
+			sendDeactivateTrace(ranUe, *oldTraceData, oldTrsr)
+		}
+		ue.DeactivateTrace()
+		return
+	}
+
+	if !ue.ActivateTrace(traceData) {
+		return
+	}
+	ue.GmmLog.Infof("activate trace [%s] with TRSR[%s]", traceData.TraceRef, ue.TraceRecordingSessionRef)
+	for _, ranUe := range ranUesWithContext(ue) {
+		if oldTraceData != nil {
+			sendDeactivateTrace(ranUe, *oldTraceData, oldTrsr)
+		}
+		sendTraceStart(ranUe)
+	}
+}
+
+// ranUesWithContext returns the RAN UE contexts of the UE that are established at the access
+// network
+func ranUesWithContext(ue *context.AmfUe) []*context.RanUe {
+	var ranUes []*context.RanUe
+	for _, anType := range []models.AccessType{models.ACCESSTYPE__3_GPP_ACCESS, models.ACCESSTYPE_NON_3_GPP_ACCESS} {
+		if ranUe := ue.GetRanUe(anType); ranUe != nil && ranUe.SentInitialContextSetupRequest {
+			ranUes = append(ranUes, ranUe)
+		}
+	}
+	return ranUes
+}
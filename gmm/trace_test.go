@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"net/http"
+	"testing"
+
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2/models"
+	"go.uber.org/zap"
+)
+
+func TestRefreshTraceData(t *testing.T) {
+	origGet, origStart, origDeactivate := sdmGetTraceData, sendTraceStart, sendDeactivateTrace
+	defer func() { sdmGetTraceData, sendTraceStart, sendDeactivateTrace = origGet, origStart, origDeactivate }()
+
+	var traceData *models.TraceData
+	var problemDetails *models.ProblemDetails
+	sdmGetTraceData = func(ctxt.Context, *context.AmfUe) (*models.TraceData, *models.ProblemDetails, error) {
+		return traceData, problemDetails, nil
+	}
+	var started, deactivated []string
+	sendTraceStart = func(ranUe *context.RanUe) {
+		started = append(started, ranUe.AmfUe.TraceRecordingSessionRef)
+	}
+	sendDeactivateTrace = func(_ *context.RanUe, _ models.TraceData, trsr string) {
+		deactivated = append(deactivated, trsr)
+	}
+
+	ue := &context.AmfUe{GmmLog: zap.NewNop().Sugar(), RanUe: map[models.AccessType]*context.RanUe{}}
+	ranUe := &context.RanUe{AmfUe: ue, SentInitialContextSetupRequest: true}
+	ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS] = ranUe
+
+	traceData = &models.TraceData{TraceRef: "00101-abcdef"}
+	if err := RefreshTraceData(ctxt.Background(), ue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trsr := ue.TraceRecordingSessionRef
+	if len(started) != 1 || started[0] != trsr {
+		t.Fatalf("expected Trace Start with TRSR %s, got %v", trsr, started)
+	}
+
+	// unchanged trace reference
+	if err := RefreshTraceData(ctxt.Background(), ue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(started) != 1 {
+		t.Fatalf("expected no new Trace Start, got %v", started)
+	}
+
+	// trace data removed at the UDM
+	traceData = nil
+	status := int32(http.StatusNotFound)
+	problemDetails = &models.ProblemDetails{Status: &status}
+	if err := RefreshTraceData(ctxt.Background(), ue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deactivated) != 1 || deactivated[0] != trsr || ue.TraceData != nil {
+		t.Fatalf("expected Deactivate Trace with TRSR %s, got %v", trsr, deactivated)
+	}
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("amf/gmm")
+
+// FSM args used by traceTransitions to hand the span of a state transition from the event
+// callback to the exit and entry callbacks
+const (
+	argTransitionSpan    string = "Transition Span"
+	argTransitionContext string = "Transition Context"
+)
+
+type transitionKey struct {
+	event fsm.EventType
+	from  fsm.StateType
+}
+
+// traceTransitions wraps the GMM FSM callbacks so that every event handled by the FSM gets a
+// span, a child of the span in the context the event was sent with. For a transition to
+// another state the span also covers the exit and entry callbacks, which then get its context,
+// and the transition is added to the flight recorder of the UE.
+func traceTransitions(transitions fsm.Transitions, callbacks fsm.Callbacks) fsm.Callbacks {
+	targets := make(map[transitionKey]fsm.StateType, len(transitions))
+	for _, transition := range transitions {
+		targets[transitionKey{event: transition.Event, from: transition.From}] = transition.To
+	}
+
+	traced := make(fsm.Callbacks, len(callbacks))
+	for from, callback := range callbacks {
+		traced[from] = func(ctx ctxt.Context, state *fsm.State, event fsm.EventType, args fsm.ArgsType) {
+			if event == fsm.ExitEvent || event == fsm.EntryEvent {
+				if transitionCtx, ok := args[argTransitionContext].(ctxt.Context); ok {
+					ctx = transitionCtx
+				}
+				callback(ctx, state, event, args)
+				if span, ok := args[argTransitionSpan].(trace.Span); ok && event == fsm.EntryEvent {
+					delete(args, argTransitionSpan)
+					delete(args, argTransitionContext)
+					span.End()
+				}
+				return
+			}
+
+			to := targets[transitionKey{event: event, from: from}]
+			attributes := []attribute.KeyValue{
+				attribute.String("gmm.event", string(event)),
+				attribute.String("gmm.fromState", string(from)),
+				attribute.String("gmm.toState", string(to)),
+			}
+			if accessType, ok := args[ArgAccessType].(models.AccessType); ok {
+				attributes = append(attributes, attribute.String("gmm.accessType", string(accessType)))
+			}
+			ctx, span := tracer.Start(ctx, "AMF GMM "+string(event), trace.WithAttributes(attributes...))
+			if ue, ok := args[ArgAmfUe].(*context.AmfUe); ok && to != from {
+				ue.RecordFlight(context.FlightRecord{
+					Kind:   context.FlightRecordGmm,
+					Name:   string(event),
+					Result: string(from) + " -> " + string(to),
+				})
+			}
+			callback(ctx, state, event, args)
+			if to == from || args == nil {
+				span.End()
+				return
+			}
+			args[argTransitionSpan] = span
+			args[argTransitionContext] = ctx
+		}
+	}
+	return traced
+}
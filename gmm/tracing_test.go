@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package gmm
+
+import (
+	ctxt "context"
+	"testing"
+
+	"github.com/omec-project/util/fsm"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceTransitions(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer func() { _ = provider.Shutdown(ctxt.Background()) }()
+	otel.SetTracerProvider(provider)
+
+	const (
+		idle    fsm.StateType = "Idle"
+		running fsm.StateType = "Running"
+		start   fsm.EventType = "Start"
+		poll    fsm.EventType = "Poll"
+	)
+	spans := make(map[fsm.EventType]trace.SpanContext)
+	record := func(ctx ctxt.Context, _ *fsm.State, event fsm.EventType, _ fsm.ArgsType) {
+		spans[event] = trace.SpanContextFromContext(ctx)
+	}
+	transitions := fsm.Transitions{
+		{Event: start, From: idle, To: running},
+		{Event: poll, From: running, To: running},
+	}
+	f, err := fsm.NewFSM(transitions, traceTransitions(transitions, fsm.Callbacks{idle: record, running: record}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := fsm.NewState(idle)
+	args := fsm.ArgsType{}
+	if err = f.SendEvent(ctxt.Background(), state, start, args); err != nil {
+		t.Fatal(err)
+	}
+	if !spans[start].IsValid() || !spans[fsm.ExitEvent].Equal(spans[start]) ||
+		!spans[fsm.EntryEvent].Equal(spans[start]) {
+		t.Errorf("expected the exit and entry callbacks to get the span of the transition, got %+v", spans)
+	}
+	if _, ok := args[argTransitionSpan]; ok {
+		t.Error("expected the transition span to be removed from the FSM args")
+	}
+
+	if err = f.SendEvent(ctxt.Background(), state, poll, fsm.ArgsType{}); err != nil {
+		t.Fatal(err)
+	}
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected a span per event, got %d", len(ended))
+	}
+	if ended[0].Name() != "AMF GMM Start" || ended[1].Name() != "AMF GMM Poll" {
+		t.Errorf("unexpected spans %s, %s", ended[0].Name(), ended[1].Name())
+	}
+}
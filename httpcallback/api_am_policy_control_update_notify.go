@@ -40,7 +40,7 @@ func HTTPAmPolicyControlUpdateNotifyUpdate(c *gin.Context) {
 	req := httpwrapper.NewRequest(c.Request, policyUpdate)
 	req.Params["polAssoId"] = c.Params.ByName("polAssoId")
 
-	rsp := producer.HandleAmPolicyControlUpdateNotifyUpdate(req)
+	rsp := producer.HandleAmPolicyControlUpdateNotifyUpdate(c.Request.Context(), req)
 
 	responseBody, err := openapi.SetBody(rsp.Body, "application/json")
 	if err != nil {
@@ -75,7 +75,7 @@ func HTTPAmPolicyControlUpdateNotifyTerminate(c *gin.Context) {
 	req := httpwrapper.NewRequest(c.Request, terminationNotification)
 	req.Params["polAssoId"] = c.Params.ByName("polAssoId")
 
-	rsp := producer.HandleAmPolicyControlUpdateNotifyTerminate(req)
+	rsp := producer.HandleAmPolicyControlUpdateNotifyTerminate(c.Request.Context(), req)
 
 	responseBody, err := openapi.SetBody(rsp.Body, "application/json")
 	if err != nil {
@@ -39,7 +39,7 @@ func HTTPN1MessageNotify(c *gin.Context) {
 
 	req := httpwrapper.NewRequest(c.Request, n1MessageNotification)
 
-	rsp := producer.HandleN1MessageNotify(req)
+	rsp := producer.HandleN1MessageNotify(c.Request.Context(), req)
 
 	responseBody, err := openapi.SetBody(rsp.Body, "application/json")
 	if err != nil {
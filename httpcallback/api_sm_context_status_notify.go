@@ -41,7 +41,7 @@ func HTTPSmContextStatusNotify(c *gin.Context) {
 	req.Params["guti"] = c.Params.ByName("guti")
 	req.Params["pduSessionId"] = c.Params.ByName("pduSessionId")
 
-	rsp := producer.HandleSmContextStatusNotify(req)
+	rsp := producer.HandleSmContextStatusNotify(c.Request.Context(), req)
 
 	responseBody, err := openapi.SetBody(rsp.Body, "application/json")
 	if err != nil {
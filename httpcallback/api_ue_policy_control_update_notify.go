@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package httpcallback
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/producer"
+	"github.com/omec-project/openapi/v2"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/openapi/v2/utils"
+	"github.com/omec-project/util/httpwrapper"
+)
+
+func HTTPUePolicyControlUpdateNotifyUpdate(c *gin.Context) {
+	var policyUpdate consumer.UePolicyUpdate
+	if !decodeUePolicyNotification(c, &policyUpdate) {
+		return
+	}
+
+	req := httpwrapper.NewRequest(c.Request, policyUpdate)
+	req.Params["polAssoId"] = c.Params.ByName("polAssoId")
+	writeUePolicyNotifyResponse(c, producer.HandleUePolicyControlUpdateNotifyUpdate(c.Request.Context(), req))
+}
+
+func HTTPUePolicyControlUpdateNotifyTerminate(c *gin.Context) {
+	var terminationNotification models.TerminationNotification
+	if !decodeUePolicyNotification(c, &terminationNotification) {
+		return
+	}
+
+	req := httpwrapper.NewRequest(c.Request, terminationNotification)
+	req.Params["polAssoId"] = c.Params.ByName("polAssoId")
+	writeUePolicyNotifyResponse(c, producer.HandleUePolicyControlUpdateNotifyTerminate(c.Request.Context(), req))
+}
+
+func decodeUePolicyNotification(c *gin.Context, notification any) bool {
+	requestBody, err := c.GetRawData()
+	if err != nil {
+		logger.CallbackLog.Errorf("Get Request Body error: %+v", err)
+		problemDetail := utils.ProblemDetailsSystemFailure(err.Error())
+		c.JSON(http.StatusInternalServerError, problemDetail)
+		return false
+	}
+
+	err = openapi.Decode(notification, requestBody, "application/json")
+	if err != nil {
+		problemDetail := "[Request Body] " + err.Error()
+		rsp := utils.ProblemDetailsMalformedRequestSyntax(problemDetail)
+		logger.CallbackLog.Errorln(problemDetail)
+		c.JSON(http.StatusBadRequest, rsp)
+		return false
+	}
+	return true
+}
+
+func writeUePolicyNotifyResponse(c *gin.Context, rsp *httpwrapper.Response) {
+	responseBody, err := openapi.SetBody(rsp.Body, "application/json")
+	if err != nil {
+		logger.CallbackLog.Errorln(err)
+		problemDetails := utils.ProblemDetailsSystemFailure(err.Error())
+		c.JSON(http.StatusInternalServerError, problemDetails)
+	} else {
+		c.Data(rsp.Status, "application/json", responseBody.Bytes())
+	}
+}
@@ -82,6 +82,20 @@ var routes = Routes{
 		HTTPAmPolicyControlUpdateNotifyTerminate,
 	},
 
+	{
+		"UePolicyControlUpdateNotifyUpdate",
+		strings.ToUpper("Post"),
+		"/ue-policy/:polAssoId/update",
+		HTTPUePolicyControlUpdateNotifyUpdate,
+	},
+
+	{
+		"UePolicyControlUpdateNotifyTerminate",
+		strings.ToUpper("Post"),
+		"/ue-policy/:polAssoId/terminate",
+		HTTPUePolicyControlUpdateNotifyTerminate,
+	},
+
 	{
 		"N1MessageNotify",
 		strings.ToUpper("Post"),
@@ -100,4 +114,22 @@ var routes = Routes{
 		":supi/deregistration-notify",
 		HTTPDeregistrationNotification,
 	},
+	{
+		"NssaaReauthNotification",
+		strings.ToUpper("Post"),
+		":supi/nssaa-reauth",
+		HTTPNssaaNotification,
+	},
+	{
+		"NssaaRevocNotification",
+		strings.ToUpper("Post"),
+		":supi/nssaa-revoc",
+		HTTPNssaaNotification,
+	},
+	{
+		"SdmChangeNotification",
+		strings.ToUpper("Post"),
+		":supi/sdm-change-notify",
+		HTTPSdmChangeNotification,
+	},
 }
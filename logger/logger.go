@@ -7,12 +7,17 @@
 package logger
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var (
-	log                *zap.Logger
 	AppLog             *zap.SugaredLogger
 	InitLog            *zap.SugaredLogger
 	CfgLog             *zap.SugaredLogger
@@ -36,7 +41,16 @@ var (
 	KafkaLog           *zap.SugaredLogger
 	NrfRegistrationLog *zap.SugaredLogger
 	PollConfigLog      *zap.SugaredLogger
-	atomicLevel        zap.AtomicLevel
+
+	consoleEncoder zapcore.Encoder
+	jsonEncoder    zapcore.Encoder
+	sink           zapcore.WriteSyncer
+	// jsonEncoding selects the encoder of every logger, it is switched at runtime
+	jsonEncoding atomic.Bool
+
+	subsystemsMu sync.RWMutex
+	// subsystems holds the level of each logging category, keyed by category name
+	subsystems = make(map[string]zap.AtomicLevel)
 )
 
 const (
@@ -45,61 +59,184 @@ const (
 	FieldAmfUeNgapID string = "amf_ue_ngap_id"
 	FieldSupi        string = "supi"
 	FieldSuci        string = "suci"
+	FieldProcedureId string = "procedure_id"
+)
+
+const (
+	EncodingConsole string = "console"
+	EncodingJson    string = "json"
 )
 
 func init() {
-	atomicLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
-	config := zap.Config{
-		Level:            atomicLevel,
-		Development:      false,
-		Encoding:         "console",
-		EncoderConfig:    zap.NewProductionEncoderConfig(),
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.LevelKey = "level"
+	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	encoderConfig.CallerKey = "caller"
+	encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
+	encoderConfig.MessageKey = "message"
+	encoderConfig.StacktraceKey = ""
+
+	consoleEncoder = zapcore.NewConsoleEncoder(encoderConfig)
+	jsonEncoder = zapcore.NewJSONEncoder(encoderConfig)
+	sink = zapcore.Lock(os.Stdout)
+
+	AppLog = newSubsystemLogger("App")
+	InitLog = newSubsystemLogger("Init")
+	CfgLog = newSubsystemLogger("CFG")
+	ContextLog = newSubsystemLogger("Context")
+	DataRepoLog = newSubsystemLogger("DBRepo")
+	NgapLog = newSubsystemLogger("NGAP")
+	HandlerLog = newSubsystemLogger("Handler")
+	HttpLog = newSubsystemLogger("HTTP")
+	GmmLog = newSubsystemLogger("GMM")
+	MtLog = newSubsystemLogger("MT")
+	ProducerLog = newSubsystemLogger("Producer")
+	LocationLog = newSubsystemLogger("LocInfo")
+	CommLog = newSubsystemLogger("Comm")
+	CallbackLog = newSubsystemLogger("Callback")
+	UtilLog = newSubsystemLogger("Util")
+	NasLog = newSubsystemLogger("NAS")
+	ConsumerLog = newSubsystemLogger("Consumer")
+	EeLog = newSubsystemLogger("EventExposure")
+	GinLog = newSubsystemLogger("GIN")
+	GrpcLog = newSubsystemLogger("GRPC")
+	KafkaLog = newSubsystemLogger("Kafka")
+	NrfRegistrationLog = newSubsystemLogger("NrfRegistration")
+	PollConfigLog = newSubsystemLogger("PollConfig")
+}
+
+// newSubsystemLogger creates the logger of a category with its own level, so that the
+// verbosity of e.g. NGAP can be raised without flooding the log with GIN access lines
+func newSubsystemLogger(category string) *zap.SugaredLogger {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	subsystemsMu.Lock()
+	subsystems[category] = level
+	subsystemsMu.Unlock()
+
+	core := &encodingCore{
+		LevelEnabler: level,
+		console:      zapcore.NewCore(consoleEncoder, sink, zapcore.DebugLevel),
+		json:         zapcore.NewCore(jsonEncoder, sink, zapcore.DebugLevel),
 	}
+	log := zap.New(core, zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr)))
+	return log.Sugar().With("component", "AMF", "category", category)
+}
+
+// encodingCore writes an entry with either the console or the JSON core, depending on the
+// encoding currently selected. Fields added with With are kept on both cores, so that a
+// logger created before the encoding is switched keeps its context fields.
+type encodingCore struct {
+	zapcore.LevelEnabler
+	console zapcore.Core
+	json    zapcore.Core
+}
+
+func (c *encodingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &encodingCore{
+		LevelEnabler: c.LevelEnabler,
+		console:      c.console.With(fields),
+		json:         c.json.With(fields),
+	}
+}
 
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.EncoderConfig.LevelKey = "level"
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-	config.EncoderConfig.CallerKey = "caller"
-	config.EncoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
-	config.EncoderConfig.MessageKey = "message"
-	config.EncoderConfig.StacktraceKey = ""
-
-	var err error
-	log, err = config.Build()
-	if err != nil {
-		panic(err)
+func (c *encodingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
 	}
+	return checked
+}
+
+func (c *encodingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if jsonEncoding.Load() {
+		return c.json.Write(entry, fields)
+	}
+	return c.console.Write(entry, fields)
+}
 
-	AppLog = log.Sugar().With("component", "AMF", "category", "App")
-	InitLog = log.Sugar().With("component", "AMF", "category", "Init")
-	CfgLog = log.Sugar().With("component", "AMF", "category", "CFG")
-	ContextLog = log.Sugar().With("component", "AMF", "category", "Context")
-	DataRepoLog = log.Sugar().With("component", "AMF", "category", "DBRepo")
-	NgapLog = log.Sugar().With("component", "AMF", "category", "NGAP")
-	HandlerLog = log.Sugar().With("component", "AMF", "category", "Handler")
-	HttpLog = log.Sugar().With("component", "AMF", "category", "HTTP")
-	GmmLog = log.Sugar().With("component", "AMF", "category", "GMM")
-	MtLog = log.Sugar().With("component", "AMF", "category", "MT")
-	ProducerLog = log.Sugar().With("component", "AMF", "category", "Producer")
-	LocationLog = log.Sugar().With("component", "AMF", "category", "LocInfo")
-	CommLog = log.Sugar().With("component", "AMF", "category", "Comm")
-	CallbackLog = log.Sugar().With("component", "AMF", "category", "Callback")
-	UtilLog = log.Sugar().With("component", "AMF", "category", "Util")
-	NasLog = log.Sugar().With("component", "AMF", "category", "NAS")
-	ConsumerLog = log.Sugar().With("component", "AMF", "category", "Consumer")
-	EeLog = log.Sugar().With("component", "AMF", "category", "EventExposure")
-	GinLog = log.Sugar().With("component", "AMF", "category", "GIN")
-	GrpcLog = log.Sugar().With("component", "AMF", "category", "GRPC")
-	KafkaLog = log.Sugar().With("component", "AMF", "category", "Kafka")
-	NrfRegistrationLog = log.Sugar().With("component", "AMF", "category", "NrfRegistration")
-	PollConfigLog = log.Sugar().With("component", "AMF", "category", "PollConfig")
+func (c *encodingCore) Sync() error {
+	return sink.Sync()
 }
 
-// SetLogLevel: set the log level (panic|fatal|error|warn|info|debug)
+// SetLogLevel: set the log level (panic|fatal|error|warn|info|debug) of all subsystems
 func SetLogLevel(level zapcore.Level) {
 	CfgLog.Infoln("set log level:", level)
-	atomicLevel.SetLevel(level)
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+	for _, subsystemLevel := range subsystems {
+		subsystemLevel.SetLevel(level)
+	}
+}
+
+// SetEncoding selects the encoding (console|json) of all log lines
+func SetEncoding(encoding string) error {
+	switch encoding {
+	case EncodingConsole:
+		jsonEncoding.Store(false)
+	case EncodingJson:
+		jsonEncoding.Store(true)
+	default:
+		return fmt.Errorf("unsupported log encoding %q", encoding)
+	}
+	CfgLog.Infoln("set log encoding:", encoding)
+	return nil
+}
+
+// Encoding returns the encoding currently selected
+func Encoding() string {
+	if jsonEncoding.Load() {
+		return EncodingJson
+	}
+	return EncodingConsole
+}
+
+// lookupSubsystem returns the canonical name and level of a subsystem, matching the
+// category name case-insensitively
+func lookupSubsystem(subsystem string) (string, zap.AtomicLevel, bool) {
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+	for name, level := range subsystems {
+		if strings.EqualFold(name, subsystem) {
+			return name, level, true
+		}
+	}
+	return "", zap.AtomicLevel{}, false
+}
+
+// IsSubsystem reports whether subsystem names a logging category
+func IsSubsystem(subsystem string) bool {
+	_, _, ok := lookupSubsystem(subsystem)
+	return ok
+}
+
+// SetSubsystemLevel sets the log level of one subsystem, named after its category (e.g. NGAP, GMM, Consumer)
+func SetSubsystemLevel(subsystem string, level zapcore.Level) error {
+	name, subsystemLevel, ok := lookupSubsystem(subsystem)
+	if !ok {
+		return fmt.Errorf("unknown log subsystem %q", subsystem)
+	}
+	subsystemLevel.SetLevel(level)
+	CfgLog.Infof("set log level of %s: %s", name, level)
+	return nil
+}
+
+// SubsystemLevel returns the log level of one subsystem
+func SubsystemLevel(subsystem string) (zapcore.Level, error) {
+	_, subsystemLevel, ok := lookupSubsystem(subsystem)
+	if !ok {
+		return zapcore.InfoLevel, fmt.Errorf("unknown log subsystem %q", subsystem)
+	}
+	return subsystemLevel.Level(), nil
+}
+
+// SubsystemLevels returns the log level of every subsystem, keyed by category name
+func SubsystemLevels() map[string]string {
+	subsystemsMu.RLock()
+	defer subsystemsMu.RUnlock()
+	levels := make(map[string]string, len(subsystems))
+	for name, level := range subsystems {
+		levels[name] = level.Level().String()
+	}
+	return levels
 }
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSubsystemLevel(t *testing.T) {
+	t.Cleanup(func() { SetLogLevel(zapcore.InfoLevel) })
+
+	if err := SetSubsystemLevel("ngap", zapcore.DebugLevel); err != nil {
+		t.Fatalf("SetSubsystemLevel: %v", err)
+	}
+	if !NgapLog.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		t.Errorf("expected debug level enabled on NGAP")
+	}
+	if GmmLog.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		t.Errorf("expected debug level disabled on GMM")
+	}
+	if levels := SubsystemLevels(); levels["NGAP"] != "debug" || levels["GMM"] != "info" {
+		t.Errorf("unexpected subsystem levels: %v", levels)
+	}
+	if err := SetSubsystemLevel("unknown", zapcore.DebugLevel); err == nil {
+		t.Errorf("expected unknown subsystem to be rejected")
+	}
+
+	// a logger derived with context fields follows the level of its subsystem
+	ueLog := GmmLog.With(FieldSupi, "imsi-208930000000001")
+	if err := SetSubsystemLevel("GMM", zapcore.ErrorLevel); err != nil {
+		t.Fatalf("SetSubsystemLevel: %v", err)
+	}
+	if ueLog.Desugar().Core().Enabled(zapcore.WarnLevel) {
+		t.Errorf("expected warn level disabled on derived GMM logger")
+	}
+
+	SetLogLevel(zapcore.WarnLevel)
+	if level, _ := SubsystemLevel("Consumer"); level != zapcore.WarnLevel {
+		t.Errorf("expected warn level on Consumer, got %s", level)
+	}
+}
+
+func TestEncodingCore(t *testing.T) {
+	t.Cleanup(func() { jsonEncoding.Store(false) })
+
+	buf := &bytes.Buffer{}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = ""
+	core := &encodingCore{
+		LevelEnabler: zapcore.DebugLevel,
+		console:      zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(buf), zapcore.DebugLevel),
+		json:         zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(buf), zapcore.DebugLevel),
+	}
+	log := zap.New(core).Sugar().With(FieldSupi, "imsi-208930000000001")
+
+	log.Info("console line")
+	if line := buf.String(); !strings.Contains(line, "console line") || strings.HasPrefix(line, "{") {
+		t.Errorf("unexpected console line: %q", line)
+	}
+
+	buf.Reset()
+	if err := SetEncoding(EncodingJson); err != nil {
+		t.Fatalf("SetEncoding: %v", err)
+	}
+	log.Infow("json line", FieldAmfUeNgapID, 1)
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON line, got %q: %v", buf.String(), err)
+	}
+	if entry[FieldSupi] != "imsi-208930000000001" || entry[FieldAmfUeNgapID] != float64(1) {
+		t.Errorf("unexpected JSON fields: %v", entry)
+	}
+
+	if err := SetEncoding("xml"); err == nil {
+		t.Errorf("expected unsupported encoding to be rejected")
+	}
+	if Encoding() != EncodingJson {
+		t.Errorf("expected encoding to stay json, got %s", Encoding())
+	}
+}
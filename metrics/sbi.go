@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SbiStatusClassError is the status class of SBI requests that got no response
+	SbiStatusClassError = "error"
+
+	// sbiPeerInfoHeader carries the NF instance IDs of the consumer and producer of an
+	// SBI request (TS 29.500 5.2.3.2.21)
+	sbiPeerInfoHeader = "3gpp-Sbi-NF-Peer-Info"
+	sbiUnknown        = "unknown"
+)
+
+// SbiStatusClass returns the status class of an HTTP status code, e.g. "2xx".
+func SbiStatusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return SbiStatusClassError
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+// SbiServiceName returns the SBI service of a request path, i.e. its first segment such as
+// "nudm-sdm" (TS 29.501 4.4.1).
+func SbiServiceName(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return sbiUnknown
+	}
+	return path
+}
+
+// ObserveSbiClientRequest counts an SBI request sent to a peer NF and records how long it
+// took until the response.
+func ObserveSbiClientRequest(service, operation, peer, statusClass string, duration time.Duration) {
+	service = sanitizeLabelValue(service)
+	operation = sanitizeLabelValue(operation)
+	peer = sanitizeLabelValue(peer)
+	amfStats.sbiClientRequests.WithLabelValues(service, operation, peer, sanitizeLabelValue(statusClass)).Inc()
+	amfStats.sbiClientDuration.WithLabelValues(service, operation, peer).Observe(duration.Seconds())
+}
+
+// IncrementSbiClientRetries counts an SBI request sent again after a failed attempt.
+func IncrementSbiClientRetries(service, operation, peer string) {
+	amfStats.sbiClientRetries.WithLabelValues(sanitizeLabelValue(service), sanitizeLabelValue(operation),
+		sanitizeLabelValue(peer)).Inc()
+}
+
+// SbiServerMetrics returns a gin middleware that counts the SBI requests received by the
+// AMF and records how long they took to handle. The operation is the route of the request
+// and the peer the NF instance ID of the consumer from the 3gpp-Sbi-NF-Peer-Info header.
+func SbiServerMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		operation := c.FullPath()
+		if operation == "" {
+			// unmatched routes would otherwise create a series per request path
+			operation = "unmatched"
+		}
+		service := sanitizeLabelValue(SbiServiceName(c.Request.URL.Path))
+		operation = sanitizeLabelValue(c.Request.Method + " " + operation)
+		peer := sanitizeLabelValue(sbiConsumerInstance(c.GetHeader(sbiPeerInfoHeader)))
+		amfStats.sbiServerRequests.WithLabelValues(service, operation, peer,
+			SbiStatusClass(c.Writer.Status())).Inc()
+		amfStats.sbiServerDuration.WithLabelValues(service, operation, peer).Observe(time.Since(start).Seconds())
+	}
+}
+
+// sbiConsumerInstance returns the srcinst parameter of a 3gpp-Sbi-NF-Peer-Info header, e.g.
+// "srcinst=54804518-4191-46b3-955c-ac631f953ed8; dstinst=...".
+func sbiConsumerInstance(peerInfo string) string {
+	for _, param := range strings.Split(peerInfo, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && name == "srcinst" && value != "" {
+			return value
+		}
+	}
+	return sbiUnknown
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatalf("read counter: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestSbiStatusClass(t *testing.T) {
+	tests := map[int]string{200: "2xx", 204: "2xx", 404: "4xx", 503: "5xx", 0: SbiStatusClassError}
+	for statusCode, expected := range tests {
+		if class := SbiStatusClass(statusCode); class != expected {
+			t.Errorf("status %d: expected %s, got %s", statusCode, expected, class)
+		}
+	}
+}
+
+func TestSbiServiceName(t *testing.T) {
+	if service := SbiServiceName("/nudm-sdm/v2/imsi-208930000000001/am-data"); service != "nudm-sdm" {
+		t.Errorf("expected nudm-sdm, got %s", service)
+	}
+	if service := SbiServiceName("/"); service != sbiUnknown {
+		t.Errorf("expected %s for an empty path, got %s", sbiUnknown, service)
+	}
+}
+
+func TestSbiServerMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SbiServerMetrics())
+	router.GET("/namf-loc/v1/:ueContextId/provide-loc-info", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/namf-loc/v1/imsi-208930000000001/provide-loc-info", nil)
+	req.Header.Set(sbiPeerInfoHeader, "srcinst=2a1c4a2e-0e0b-4b6e-9a57-2b8f4e0d1c11; dstinst=amf")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	counter := amfStats.sbiServerRequests.WithLabelValues("namf-loc", "GET /namf-loc/v1/:ueContextId/provide-loc-info",
+		"2a1c4a2e-0e0b-4b6e-9a57-2b8f4e0d1c11", "2xx")
+	if count := counterValue(t, counter); count != 1 {
+		t.Errorf("expected 1 request, got %v", count)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/namf-loc/v1/unknown", nil))
+	counter = amfStats.sbiServerRequests.WithLabelValues("namf-loc", "GET unmatched", sbiUnknown, "4xx")
+	if count := counterValue(t, counter); count != 1 {
+		t.Errorf("expected 1 unmatched request, got %v", count)
+	}
+}
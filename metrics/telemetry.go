@@ -14,6 +14,7 @@ package metrics
 import (
 	"encoding/hex"
 	"net/http"
+	"time"
 	"unicode/utf8"
 
 	"github.com/omec-project/amf/logger"
@@ -26,6 +27,23 @@ type AmfStats struct {
 	ngapMsg           *prometheus.CounterVec
 	gnbSessionProfile *prometheus.GaugeVec
 	dbWriteDropped    prometheus.Counter
+	overloadActive    prometheus.Gauge
+	overloadLoad      *prometheus.GaugeVec
+	overloadRejected  prometheus.Counter
+	pagingAttempts    *prometheus.CounterVec
+	pagingSuccess     *prometheus.CounterVec
+	procedureAttempts *prometheus.CounterVec
+	procedureSuccess  *prometheus.CounterVec
+	procedureFailures *prometheus.CounterVec
+	procedureDuration *prometheus.HistogramVec
+	registeredUes     *prometheus.GaugeVec
+	pduSessions       *prometheus.GaugeVec
+	gnbUes            *prometheus.GaugeVec
+	sbiClientRequests *prometheus.CounterVec
+	sbiClientDuration *prometheus.HistogramVec
+	sbiClientRetries  *prometheus.CounterVec
+	sbiServerRequests *prometheus.CounterVec
+	sbiServerDuration *prometheus.HistogramVec
 }
 
 var amfStats *AmfStats
@@ -46,6 +64,94 @@ func initAmfStats() *AmfStats {
 			Name: "amf_db_write_dropped_total",
 			Help: "Total number of UE context DB writes dropped due to a full write queue.",
 		}),
+
+		overloadActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "amf_overload_active",
+			Help: "Set to 1 while the AMF is in overload and has sent NGAP Overload Start.",
+		}),
+
+		overloadLoad: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "amf_overload_load",
+			Help: "Load indicators watched by overload control.",
+		}, []string{"indicator"}),
+
+		overloadRejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "amf_overload_rejected_registrations_total",
+			Help: "Total number of registrations rejected with congestion during overload.",
+		}),
+
+		pagingAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_paging_attempts_total",
+			Help: "Total number of paging attempts per paging stage and priority.",
+		}, []string{"stage", "priority"}),
+
+		pagingSuccess: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_paging_success_total",
+			Help: "Total number of paging procedures answered by the UE per paging stage and priority.",
+		}, []string{"stage", "priority"}),
+
+		procedureAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_procedure_attempts_total",
+			Help: "Total number of attempted procedures per procedure type.",
+		}, []string{"procedure", "plmn", "tac", "snssai"}),
+
+		procedureSuccess: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_procedure_success_total",
+			Help: "Total number of successful procedures per procedure type.",
+		}, []string{"procedure", "plmn", "tac", "snssai"}),
+
+		procedureFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_procedure_failures_total",
+			Help: "Total number of failed procedures per procedure type and 5GMM or NGAP cause.",
+		}, []string{"procedure", "cause_type", "cause", "plmn", "tac", "snssai"}),
+
+		procedureDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "amf_procedure_duration_seconds",
+			Help:    "Time from the first NGAP or NAS message of a procedure to its successful completion.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"procedure", "plmn", "tac", "snssai"}),
+
+		registeredUes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "amf_registered_ues",
+			Help: "Number of registered UEs per access type, TAC, S-NSSAI and CM state.",
+		}, []string{"access_type", "tac", "snssai", "cm_state"}),
+
+		pduSessions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "amf_pdu_sessions",
+			Help: "Number of PDU sessions per S-NSSAI and DNN.",
+		}, []string{"snssai", "dnn"}),
+
+		gnbUes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "amf_gnb_ues",
+			Help: "Number of UE-associated NG connections per NG-RAN node.",
+		}, []string{"gnb_id"}),
+
+		sbiClientRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_sbi_client_requests_total",
+			Help: "Total number of SBI requests sent per service operation, peer NF instance and status class.",
+		}, []string{"service", "operation", "peer", "status_class"}),
+
+		sbiClientDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "amf_sbi_client_request_duration_seconds",
+			Help:    "Time until the response to an SBI request sent per service operation and peer NF instance.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"service", "operation", "peer"}),
+
+		sbiClientRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_sbi_client_retries_total",
+			Help: "Total number of SBI requests sent again after a failed attempt.",
+		}, []string{"service", "operation", "peer"}),
+
+		sbiServerRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "amf_sbi_server_requests_total",
+			Help: "Total number of SBI requests received per service operation, peer NF instance and status class.",
+		}, []string{"service", "operation", "peer", "status_class"}),
+
+		sbiServerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "amf_sbi_server_request_duration_seconds",
+			Help:    "Time to handle an SBI request received per service operation and peer NF instance.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"service", "operation", "peer"}),
 	}
 }
 
@@ -62,6 +168,74 @@ func (ps *AmfStats) register() error {
 	if err := prometheus.Register(ps.dbWriteDropped); err != nil {
 		return err
 	}
+	prometheus.Unregister(ps.overloadActive)
+	if err := prometheus.Register(ps.overloadActive); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.overloadLoad)
+	if err := prometheus.Register(ps.overloadLoad); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.overloadRejected)
+	if err := prometheus.Register(ps.overloadRejected); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.pagingAttempts)
+	if err := prometheus.Register(ps.pagingAttempts); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.pagingSuccess)
+	if err := prometheus.Register(ps.pagingSuccess); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.procedureAttempts)
+	if err := prometheus.Register(ps.procedureAttempts); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.procedureSuccess)
+	if err := prometheus.Register(ps.procedureSuccess); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.procedureFailures)
+	if err := prometheus.Register(ps.procedureFailures); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.procedureDuration)
+	if err := prometheus.Register(ps.procedureDuration); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.registeredUes)
+	if err := prometheus.Register(ps.registeredUes); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.pduSessions)
+	if err := prometheus.Register(ps.pduSessions); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.gnbUes)
+	if err := prometheus.Register(ps.gnbUes); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.sbiClientRequests)
+	if err := prometheus.Register(ps.sbiClientRequests); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.sbiClientDuration)
+	if err := prometheus.Register(ps.sbiClientDuration); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.sbiClientRetries)
+	if err := prometheus.Register(ps.sbiClientRetries); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.sbiServerRequests)
+	if err := prometheus.Register(ps.sbiServerRequests); err != nil {
+		return err
+	}
+	prometheus.Unregister(ps.sbiServerDuration)
+	if err := prometheus.Register(ps.sbiServerDuration); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -115,3 +289,73 @@ func SetGnbSessProfileStats(id, ip, state, tac string, count uint64) {
 func IncrementDbWriteDropped() {
 	amfStats.dbWriteDropped.Inc()
 }
+
+// SetOverloadActive records whether the AMF is currently in overload.
+func SetOverloadActive(active bool) {
+	if active {
+		amfStats.overloadActive.Set(1)
+	} else {
+		amfStats.overloadActive.Set(0)
+	}
+}
+
+// SetOverloadLoad records the latest value of an overload indicator
+// (queue fill percentage or SBI latency in milliseconds).
+func SetOverloadLoad(indicator string, value float64) {
+	indicator = sanitizeLabelValue(indicator)
+	amfStats.overloadLoad.WithLabelValues(indicator).Set(value)
+}
+
+// IncrementOverloadRejected increments the counter of registrations rejected during overload.
+func IncrementOverloadRejected() {
+	amfStats.overloadRejected.Inc()
+}
+
+// IncrementPagingAttempts increments the counter of paging messages sent at a paging stage.
+func IncrementPagingAttempts(stage, priority string) {
+	amfStats.pagingAttempts.WithLabelValues(sanitizeLabelValue(stage), sanitizeLabelValue(priority)).Inc()
+}
+
+// IncrementPagingSuccess increments the counter of paging procedures answered at a paging stage.
+func IncrementPagingSuccess(stage, priority string) {
+	amfStats.pagingSuccess.WithLabelValues(sanitizeLabelValue(stage), sanitizeLabelValue(priority)).Inc()
+}
+
+// IncrementProcedureAttempts increments the counter of attempted procedures.
+func IncrementProcedureAttempts(procedure, plmn, tac, snssai string) {
+	amfStats.procedureAttempts.WithLabelValues(sanitizeLabelValue(procedure), sanitizeLabelValue(plmn),
+		sanitizeLabelValue(tac), sanitizeLabelValue(snssai)).Inc()
+}
+
+// ObserveProcedureSuccess increments the counter of successful procedures and records how long
+// the procedure took.
+func ObserveProcedureSuccess(procedure, plmn, tac, snssai string, duration time.Duration) {
+	procedure = sanitizeLabelValue(procedure)
+	plmn = sanitizeLabelValue(plmn)
+	tac = sanitizeLabelValue(tac)
+	snssai = sanitizeLabelValue(snssai)
+	amfStats.procedureSuccess.WithLabelValues(procedure, plmn, tac, snssai).Inc()
+	amfStats.procedureDuration.WithLabelValues(procedure, plmn, tac, snssai).Observe(duration.Seconds())
+}
+
+// IncrementProcedureFailures increments the counter of failed procedures per failure cause.
+func IncrementProcedureFailures(procedure, causeType, cause, plmn, tac, snssai string) {
+	amfStats.procedureFailures.WithLabelValues(sanitizeLabelValue(procedure), sanitizeLabelValue(causeType),
+		sanitizeLabelValue(cause), sanitizeLabelValue(plmn), sanitizeLabelValue(tac), sanitizeLabelValue(snssai)).Inc()
+}
+
+// AddRegisteredUes adds delta to the number of registered UEs with the given labels.
+func AddRegisteredUes(accessType, tac, snssai, cmState string, delta float64) {
+	amfStats.registeredUes.WithLabelValues(sanitizeLabelValue(accessType), sanitizeLabelValue(tac),
+		sanitizeLabelValue(snssai), sanitizeLabelValue(cmState)).Add(delta)
+}
+
+// AddPduSessions adds delta to the number of PDU sessions of an S-NSSAI and DNN.
+func AddPduSessions(snssai, dnn string, delta float64) {
+	amfStats.pduSessions.WithLabelValues(sanitizeLabelValue(snssai), sanitizeLabelValue(dnn)).Add(delta)
+}
+
+// AddGnbUes adds delta to the number of UE-associated NG connections of an NG-RAN node.
+func AddGnbUes(gnbID string, delta float64) {
+	amfStats.gnbUes.WithLabelValues(sanitizeLabelValue(gnbID)).Add(delta)
+}
@@ -35,16 +35,24 @@ func Dispatch(ctx ctxt.Context, ue *context.AmfUe, accessType models.AccessType,
 		return fmt.Errorf("UE State is empty (accessType=%q). Can't send GSM Message", accessType)
 	}
 
+	ue.RecordActivity()
+
 	msgTypeName := nas.MessageName(msg.GmmHeader.GetMessageType())
 	spanName := fmt.Sprintf("AMF NAS %s", msgTypeName)
 
-	_, span := tracer.Start(ctx, spanName,
+	spanOptions := []trace.SpanStartOption{
 		trace.WithAttributes(
 			attribute.String("nas.accessType", string(accessType)),
 			attribute.Int64("nas.procedureCode", procedureCode),
 			attribute.String("nas.messageType", msgTypeName),
 		),
-	)
+	}
+	// the later messages of a registration arrive in NGAP messages of their own, link them
+	// to the UE-level registration span
+	if registration := ue.RegistrationSpanContext(); registration.IsValid() {
+		spanOptions = append(spanOptions, trace.WithLinks(trace.Link{SpanContext: registration}))
+	}
+	ctx, span := tracer.Start(ctx, spanName, spanOptions...)
 	defer span.End()
 
 	return gmm.GmmFSM.SendEvent(ctx, ue.State[accessType], gmm.GmmMessageEvent, fsm.ArgsType{
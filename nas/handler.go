@@ -35,7 +35,7 @@ func HandleNAS(ctx ctxt.Context, ue *context.RanUe, procedureCode int64, nasPdu
 		if ue.AmfUe == nil {
 			ue.AmfUe = amfSelf.NewAmfUe("")
 		} else {
-			if amfSelf.EnableSctpLb && amfSelf.EnableDbStore {
+			if amfSelf.EnableSctpLb && amfSelf.Drsm != nil {
 				/* checking the guti-ue belongs to this amf instance */
 				id, err := amfSelf.Drsm.FindOwnerInt32ID(ue.AmfUe.Tmsi)
 				if err != nil {
@@ -117,4 +117,9 @@ func DispatchMsg(amfUe *context.AmfUe, transInfo context.NasMsg) {
 	if err := Dispatch(transInfo.Context, amfUe, transInfo.AnType, transInfo.ProcedureCode, msg); err != nil {
 		amfUe.NASLog.Errorf("handle NAS Error: %v", err)
 	}
+	// persist every procedure step, so that another AMF instance can resume the
+	// procedure from the DB if this one fails
+	if amfUe.GetSupi() != "" && !amfUe.State[transInfo.AnType].Is(context.Deregistered) {
+		context.StoreContextInDB(amfUe)
+	}
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/omec-project/amf/context"
 	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/tracerecording"
 	"github.com/omec-project/nas/v2"
 	"github.com/omec-project/nas/v2/nasConvert"
 	"github.com/omec-project/nas/v2/nasMessage"
@@ -33,7 +34,11 @@ func Encode(ue *context.AmfUe, msg *nas.Message, accessType models.AccessType) (
 
 	// Plain NAS message
 	if !ue.SecurityContextAvailable {
-		return msg.PlainNasEncode()
+		payload, err := msg.PlainNasEncode()
+		if err == nil {
+			ue.RecordNasTrace(tracerecording.Downlink, payload)
+		}
+		return payload, err
 	} else {
 		// security protected NAS Message
 		// a security protected NAS message must be integrity protected, and ciphering is optional
@@ -46,8 +51,7 @@ func Encode(ue *context.AmfUe, msg *nas.Message, accessType models.AccessType) (
 			needCiphering = true
 		case nas.SecurityHeaderTypeIntegrityProtectedWithNew5gNasSecurityContext:
 			ue.NASLog.Debugln("security header type: Integrity Protected With New 5G Security Context")
-			ue.ULCount.Set(0, 0)
-			ue.DLCount.Set(0, 0)
+			ue.StartNewNasCounts()
 		default:
 			return nil, fmt.Errorf("wrong security header type: 0x%0x", msg.SecurityHeaderType)
 		}
@@ -59,6 +63,8 @@ func Encode(ue *context.AmfUe, msg *nas.Message, accessType models.AccessType) (
 		}
 
 		ue.NASLog.Debugf("plain payload: %+v", hex.Dump(payload))
+		// recorded before ciphering, which is done in place
+		ue.RecordNasTrace(tracerecording.Downlink, payload)
 
 		if needCiphering {
 			ue.NASLog.Debugf("encrypt NAS message (algorithm: %+v, DLCount: 0x%0x)", ue.CipheringAlg, ue.DLCount.Get())
@@ -240,6 +246,7 @@ func Decode(ue *context.AmfUe, accessType models.AccessType, payload []byte) (*n
 			ue.NASLog.Warnln("Received Plain NAS message")
 			ue.MacFailed = false
 			ue.SecurityContextAvailable = false
+			ue.RecordNasTrace(tracerecording.Uplink, payload)
 			if err := msg.PlainNasDecode(&payload); err != nil {
 				return nil, err
 			}
@@ -272,6 +279,7 @@ func Decode(ue *context.AmfUe, accessType models.AccessType, payload []byte) (*n
 			}
 		} else {
 			ue.MacFailed = false
+			ue.RecordNasTrace(tracerecording.Uplink, payload)
 			err := msg.PlainNasDecode(&payload)
 			return msg, err
 		}
@@ -342,6 +350,7 @@ func Decode(ue *context.AmfUe, accessType models.AccessType, payload []byte) (*n
 
 		// remove sequece Number
 		payload = payload[1:]
+		ue.RecordNasTrace(tracerecording.Uplink, payload)
 		err = msg.PlainNasDecode(&payload)
 
 		/*
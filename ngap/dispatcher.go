@@ -20,6 +20,7 @@ import (
 	"github.com/omec-project/amf/metrics"
 	"github.com/omec-project/amf/msgtypes/ngapmsgtypes"
 	"github.com/omec-project/amf/protos/sdcoreAmfServer"
+	"github.com/omec-project/amf/tracerecording"
 	"github.com/omec-project/ngap/v2"
 	"github.com/omec-project/ngap/v2/ngapType"
 	"go.opentelemetry.io/otel"
@@ -77,12 +78,15 @@ func DispatchLb(ctx ctxt.Context, sctplbMsg *sdcoreAmfServer.SctplbMessage, Amf2
 		return
 	}
 
+	msgCtx, span := startNgapSpan(ctx, ran, pdu)
+
 	ranUe, ngapId := FetchRanUeContext(ran, pdu)
+	ran.CaptureNgap(ranUe, tracerecording.Uplink, sctplbMsg.Msg)
 	if ngapId != nil {
 		//ranUe.Log.Debugln("RanUe RanNgapId AmfNgapId: ", ranUe.RanUeNgapId, ranUe.AmfUeNgapId)
 		/* checking whether same AMF instance can handle this message */
 		/* redirect it to correct owner if required */
-		if amfSelf.EnableDbStore {
+		if amfSelf.Drsm != nil {
 			id, err := amfSelf.Drsm.FindOwnerInt32ID(int32(ngapId.Value))
 			if id == nil || err != nil {
 				ran.Log.Warnf("dispatchLb, Couldn't find owner for amfUeNgapid: %v", ngapId.Value)
@@ -107,6 +111,7 @@ func DispatchLb(ctx ctxt.Context, sctplbMsg *sdcoreAmfServer.SctplbMessage, Amf2
 						ran.Log.Errorf("could not remove ranUe: %v", err)
 					}
 				}
+				span.End()
 				return
 			} else {
 				ran.Log.Debugf("DispatchLb, amfNgapId: %v for this amf instance", ngapId.Value)
@@ -116,6 +121,7 @@ func DispatchLb(ctx ctxt.Context, sctplbMsg *sdcoreAmfServer.SctplbMessage, Amf2
 
 	/* uecontext is found, submit the message to transaction queue*/
 	if ranUe != nil && ranUe.AmfUe != nil {
+		ranUe.AmfUe.RecordNgapTrace(tracerecording.Uplink, sctplbMsg.Msg)
 		ranUe.AmfUe.SetEventChannel(ctx, NgapMsgHandler)
 		// ranUe.AmfUe.TxLog.Infoln("Uecontext found. queuing ngap message to uechannel")
 		ranUe.AmfUe.EventChannel.UpdateNgapHandler(NgapMsgHandler)
@@ -123,11 +129,12 @@ func DispatchLb(ctx ctxt.Context, sctplbMsg *sdcoreAmfServer.SctplbMessage, Amf2
 			Ran:       ran,
 			NgapMsg:   pdu,
 			SctplbMsg: sctplbMsg,
+			Context:   msgCtx,
 		}
 
 		ranUe.AmfUe.EventChannel.SubmitMessage(ngapMsg)
 	} else {
-		go DispatchNgapMsg(ctx, ran, pdu, sctplbMsg)
+		go DispatchNgapMsg(msgCtx, ran, pdu, sctplbMsg)
 	}
 }
 
@@ -168,10 +175,15 @@ func Dispatch(conn net.Conn, msg []byte) {
 		return
 	}
 
+	// the span covers the time the message waits in the event queue of the UE
+	msgCtx, _ := startNgapSpan(ctx, ran, pdu)
+
 	ranUe, _ := FetchRanUeContext(ran, pdu)
+	ran.CaptureNgap(ranUe, tracerecording.Uplink, msg)
 
 	/* uecontext is found, submit the message to transaction queue*/
 	if ranUe != nil && ranUe.AmfUe != nil {
+		ranUe.AmfUe.RecordNgapTrace(tracerecording.Uplink, msg)
 		ranUe.AmfUe.SetEventChannel(ctx, NgapMsgHandler)
 		ranUe.AmfUe.TxLog.Infoln("Uecontext found. queuing ngap message to uechannel")
 		ranUe.AmfUe.EventChannel.UpdateNgapHandler(NgapMsgHandler)
@@ -179,6 +191,7 @@ func Dispatch(conn net.Conn, msg []byte) {
 			Ran:       ran,
 			NgapMsg:   pdu,
 			SctplbMsg: nil,
+			Context:   msgCtx,
 		}
 		if ranUe.Ran.GnbId == ran.GnbId {
 			ranUe.AmfUe.TxLog.Infoln("gnbid match")
@@ -189,12 +202,16 @@ func Dispatch(conn net.Conn, msg []byte) {
 		}
 		ranUe.AmfUe.EventChannel.SubmitMessage(ngapMsg)
 	} else {
-		go DispatchNgapMsg(ctx, ran, pdu, nil)
+		go DispatchNgapMsg(msgCtx, ran, pdu, nil)
 	}
 }
 
 func NgapMsgHandler(ue *context.AmfUe, msg context.NgapMsg) {
-	DispatchNgapMsg(ctxt.Background(), msg.Ran, msg.NgapMsg, msg.SctplbMsg)
+	ctx := ctxt.Background()
+	if msg.Context != nil {
+		ctx = ctxt.WithoutCancel(msg.Context)
+	}
+	DispatchNgapMsg(ctx, msg.Ran, msg.NgapMsg, msg.SctplbMsg)
 }
 
 func DispatchNgapMsg(ctx ctxt.Context, ran *context.AmfRan, pdu *ngapType.NGAPPDU, sctplbMsg *sdcoreAmfServer.SctplbMessage) {
@@ -208,44 +225,7 @@ func DispatchNgapMsg(ctx ctxt.Context, ran *context.AmfRan, pdu *ngapType.NGAPPD
 		return
 	}
 
-	var code int64
-	switch pdu.Present {
-	case ngapType.NGAPPDUPresentInitiatingMessage:
-		if pdu.InitiatingMessage != nil {
-			code = pdu.InitiatingMessage.ProcedureCode.Value
-		}
-	case ngapType.NGAPPDUPresentSuccessfulOutcome:
-		if pdu.SuccessfulOutcome != nil {
-			code = pdu.SuccessfulOutcome.ProcedureCode.Value
-		}
-	case ngapType.NGAPPDUPresentUnsuccessfulOutcome:
-		if pdu.UnsuccessfulOutcome != nil {
-			code = pdu.UnsuccessfulOutcome.ProcedureCode.Value
-		}
-	}
-	procName := ngapType.ProcedureName(code)
-
-	peer := "unknown"
-	if ran.Conn != nil {
-		if addr := ran.Conn.RemoteAddr(); addr != nil {
-			peer = addr.String()
-		}
-	}
-
-	if procName == "" {
-		procName = fmt.Sprintf("UnknownProcedureCode_%d", code)
-		logger.AppLog.Warnf("Encountered unknown NGAP procedure code: %d from RAN: %s", code, peer)
-	}
-
-	spanName := fmt.Sprintf("AMF NGAP %s", procName)
-	ctx, span := tracer.Start(ctx, spanName,
-		trace.WithAttributes(
-			attribute.String("net.peer", peer),
-			attribute.String("ngap.pdu_present", fmt.Sprintf("%d", pdu.Present)),
-			attribute.String("ngap.procedureCode", procName),
-		),
-		trace.WithSpanKind(trace.SpanKindServer),
-	)
+	ctx, span := startNgapSpan(ctx, ran, pdu)
 	defer span.End()
 
 	switch pdu.Present {
@@ -282,6 +262,8 @@ func DispatchNgapMsg(ctx ctxt.Context, ran *context.AmfRan, pdu *ngapType.NGAPPD
 			HandleErrorIndication(ran, pdu)
 		case ngapType.ProcedureCodeUERadioCapabilityInfoIndication:
 			HandleUERadioCapabilityInfoIndication(ran, pdu)
+		case ngapType.ProcedureCodeUERadioCapabilityIDMapping:
+			HandleUERadioCapabilityIDMappingRequest(ctx, ran, pdu)
 		case ngapType.ProcedureCodeHandoverNotification:
 			HandleHandoverNotify(ctx, ran, pdu)
 		case ngapType.ProcedureCodeHandoverPreparation:
@@ -372,6 +354,63 @@ func DispatchNgapMsg(ctx ctxt.Context, ran *context.AmfRan, pdu *ngapType.NGAPPD
 	}
 }
 
+// ngapSpan is the span of a received NGAP message
+type ngapSpan struct {
+	pdu  *ngapType.NGAPPDU
+	span trace.Span
+}
+
+type ngapSpanKey struct{}
+
+// startNgapSpan starts the span of the NGAP message pdu received from ran. The span is
+// started when the message is received and ended by DispatchNgapMsg once it is handled; if ctx
+// already carries the span of pdu, it is returned instead of starting another one.
+func startNgapSpan(ctx ctxt.Context, ran *context.AmfRan, pdu *ngapType.NGAPPDU) (ctxt.Context, trace.Span) {
+	if started, ok := ctx.Value(ngapSpanKey{}).(ngapSpan); ok && started.pdu == pdu {
+		return ctx, started.span
+	}
+
+	var code int64
+	switch pdu.Present {
+	case ngapType.NGAPPDUPresentInitiatingMessage:
+		if pdu.InitiatingMessage != nil {
+			code = pdu.InitiatingMessage.ProcedureCode.Value
+		}
+	case ngapType.NGAPPDUPresentSuccessfulOutcome:
+		if pdu.SuccessfulOutcome != nil {
+			code = pdu.SuccessfulOutcome.ProcedureCode.Value
+		}
+	case ngapType.NGAPPDUPresentUnsuccessfulOutcome:
+		if pdu.UnsuccessfulOutcome != nil {
+			code = pdu.UnsuccessfulOutcome.ProcedureCode.Value
+		}
+	}
+	procName := ngapType.ProcedureName(code)
+
+	peer := "unknown"
+	if ran.Conn != nil {
+		if addr := ran.Conn.RemoteAddr(); addr != nil {
+			peer = addr.String()
+		}
+	}
+
+	if procName == "" {
+		procName = fmt.Sprintf("UnknownProcedureCode_%d", code)
+		logger.AppLog.Warnf("Encountered unknown NGAP procedure code: %d from RAN: %s", code, peer)
+	}
+
+	spanName := fmt.Sprintf("AMF NGAP %s", procName)
+	ctx, span := tracer.Start(ctx, spanName,
+		trace.WithAttributes(
+			attribute.String("net.peer", peer),
+			attribute.String("ngap.pdu_present", fmt.Sprintf("%d", pdu.Present)),
+			attribute.String("ngap.procedureCode", procName),
+		),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	return ctxt.WithValue(ctx, ngapSpanKey{}, ngapSpan{pdu: pdu, span: span}), span
+}
+
 func HandleSCTPNotification(conn net.Conn, notificationData []byte) {
 	if conn == nil {
 		logger.NgapLog.Infof("handle global SCTP notification")
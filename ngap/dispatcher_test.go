@@ -13,6 +13,7 @@ import (
 
 	"github.com/omec-project/amf/context"
 	"github.com/omec-project/amf/protos/sdcoreAmfServer"
+	"github.com/omec-project/ngap/v2/ngapType"
 )
 
 // mockConn is a minimal net.Conn used to exercise Dispatch without a real SCTP socket.
@@ -77,3 +78,20 @@ func TestDispatchEmptyMsgUnknownConnDoesNotPanic(t *testing.T) {
 		t.Error("Dispatch must not create an AmfRan for an unknown connection with an empty message")
 	}
 }
+
+func TestStartNgapSpanReusesSpanOfMessage(t *testing.T) {
+	ran := context.NewAmfRanDefault()
+	pdu := &ngapType.NGAPPDU{Present: ngapType.NGAPPDUPresentInitiatingMessage}
+
+	ctx, span := startNgapSpan(ctxt.Background(), ran, pdu)
+	defer span.End()
+	if _, reused := startNgapSpan(ctx, ran, pdu); reused != span {
+		t.Error("expected the span started in Dispatch to be used when the message is handled")
+	}
+
+	other, otherSpan := startNgapSpan(ctx, ran, &ngapType.NGAPPDU{Present: ngapType.NGAPPDUPresentSuccessfulOutcome})
+	defer otherSpan.End()
+	if started, _ := other.Value(ngapSpanKey{}).(ngapSpan); started.span != otherSpan {
+		t.Error("expected a new span for another message")
+	}
+}
@@ -10,6 +10,7 @@ package ngap
 import (
 	ctxt "context"
 	"encoding/hex"
+	"errors"
 	"io"
 	"os"
 	"strconv"
@@ -22,7 +23,9 @@ import (
 	"github.com/omec-project/amf/metrics"
 	"github.com/omec-project/amf/nas"
 	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/amf/producer/callback"
 	"github.com/omec-project/amf/protos/sdcoreAmfServer"
+	"github.com/omec-project/amf/tracerecording"
 	"github.com/omec-project/amf/util"
 	"github.com/omec-project/nas/v2/nasMessage"
 	libngap "github.com/omec-project/ngap/v2"
@@ -700,6 +703,9 @@ func HandleNGSetupRequest(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 
 	if sendResponse {
 		ngap_message.SendNGSetupResponse(ran)
+		if context.AMF_Self().OverloadControl.IsOverloaded() {
+			ngap_message.SendAmfOverloadStart(ran)
+		}
 		// send nf(gnb) status notification
 		gnbStatus := mi.MetricEvent{
 			EventType: mi.CNfStatusEvt,
@@ -963,6 +969,78 @@ func HandleNGResetAcknowledge(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 	}
 }
 
+// infoOnRecommendedCellsAndRanNodesForPagingToContext converts the recommended cells and RAN
+// nodes for paging of UE Context Release Complete, skipping the items that cannot be decoded
+func infoOnRecommendedCellsAndRanNodesForPagingToContext(ran *context.AmfRan,
+	info *ngapType.InfoOnRecommendedCellsAndRANNodesForPaging,
+) *context.InfoOnRecommendedCellsAndRanNodesForPaging {
+	result := new(context.InfoOnRecommendedCellsAndRanNodesForPaging)
+
+	for _, item := range info.RecommendedCellsForPaging.RecommendedCellList.List {
+		recommendedCell := context.RecommendedCell{}
+
+		switch item.NGRANCGI.Present {
+		case ngapType.NGRANCGIPresentNRCGI:
+			plmnID, err := ngapConvert.PlmnIdToModels(item.NGRANCGI.NRCGI.PLMNIdentity)
+			if err != nil {
+				ran.Log.Errorf("decode recommended NR CGI PLMN failed: %+v", err)
+				continue
+			}
+			recommendedCell.NgRanCGI.Present = context.NgRanCgiPresentNRCGI
+			recommendedCell.NgRanCGI.NRCGI = &models.Ncgi{
+				PlmnId:   plmnID,
+				NrCellId: ngapConvert.BitStringToHex(&item.NGRANCGI.NRCGI.NRCellIdentity.Value),
+			}
+		case ngapType.NGRANCGIPresentEUTRACGI:
+			plmnID, err := ngapConvert.PlmnIdToModels(item.NGRANCGI.EUTRACGI.PLMNIdentity)
+			if err != nil {
+				ran.Log.Errorf("decode recommended EUTRA CGI PLMN failed: %+v", err)
+				continue
+			}
+			recommendedCell.NgRanCGI.Present = context.NgRanCgiPresentEUTRACGI
+			recommendedCell.NgRanCGI.EUTRACGI = &models.Ecgi{
+				PlmnId:      plmnID,
+				EutraCellId: ngapConvert.BitStringToHex(&item.NGRANCGI.EUTRACGI.EUTRACellIdentity.Value),
+			}
+		default:
+			continue
+		}
+
+		if item.TimeStayedInCell != nil {
+			recommendedCell.TimeStayedInCell = new(int64)
+			*recommendedCell.TimeStayedInCell = *item.TimeStayedInCell
+		}
+		result.RecommendedCells = append(result.RecommendedCells, recommendedCell)
+	}
+
+	for _, item := range info.RecommendRANNodesForPaging.RecommendedRANNodeList.List {
+		recommendedRanNode := context.RecommendRanNode{}
+
+		switch item.AMFPagingTarget.Present {
+		case ngapType.AMFPagingTargetPresentGlobalRANNodeID:
+			globalRanNodeID, err := ngapConvert.RanIdToModels(*item.AMFPagingTarget.GlobalRANNodeID)
+			if err != nil {
+				ran.Log.Errorf("decode recommended paging RAN node failed: %+v", err)
+				continue
+			}
+			recommendedRanNode.Present = context.RecommendRanNodePresentRanNode
+			recommendedRanNode.GlobalRanNodeId = &globalRanNodeID
+		case ngapType.AMFPagingTargetPresentTAI:
+			tai, err := ngapConvert.TaiToModels(*item.AMFPagingTarget.TAI)
+			if err != nil {
+				ran.Log.Errorf("decode recommended paging TAI failed: %+v", err)
+				continue
+			}
+			recommendedRanNode.Present = context.RecommendRanNodePresentTAI
+			recommendedRanNode.Tai = &tai
+		default:
+			continue
+		}
+		result.RecommendedRanNodes = append(result.RecommendedRanNodes, recommendedRanNode)
+	}
+	return result
+}
+
 func HandleUEContextReleaseComplete(ctx ctxt.Context, ran *context.AmfRan, message *ngapType.NGAPPDU) {
 	var aMFUENGAPID *ngapType.AMFUENGAPID
 	var rANUENGAPID *ngapType.RANUENGAPID
@@ -1010,9 +1088,6 @@ func HandleUEContextReleaseComplete(ctx ctxt.Context, ran *context.AmfRan, messa
 		case ngapType.ProtocolIEIDInfoOnRecommendedCellsAndRANNodesForPaging:
 			infoOnRecommendedCellsAndRANNodesForPaging = ie.Value.InfoOnRecommendedCellsAndRANNodesForPaging
 			ran.Log.Debugln("decode IE InfoOnRecommendedCellsAndRANNodesForPaging")
-			if infoOnRecommendedCellsAndRANNodesForPaging != nil {
-				ran.Log.Warnln("IE infoOnRecommendedCellsAndRANNodesForPaging is not support")
-			}
 		case ngapType.ProtocolIEIDPDUSessionResourceListCxtRelCpl:
 			pDUSessionResourceList = ie.Value.PDUSessionResourceListCxtRelCpl
 			ran.Log.Debugln("decode IE PDUSessionResourceList")
@@ -1068,78 +1143,10 @@ func HandleUEContextReleaseComplete(ctx ctxt.Context, ran *context.AmfRan, messa
 		}
 		return
 	}
-	// TODO: AMF shall, if supported, store it and may use it for subsequent paging
+	// stored with the UE context for subsequent paging (TS 23.502 4.2.6 step 5)
 	if infoOnRecommendedCellsAndRANNodesForPaging != nil {
-		amfUe.InfoOnRecommendedCellsAndRanNodesForPaging = new(context.InfoOnRecommendedCellsAndRanNodesForPaging)
-
-		recommendedCells := &amfUe.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedCells
-		for _, item := range infoOnRecommendedCellsAndRANNodesForPaging.RecommendedCellsForPaging.RecommendedCellList.List {
-			recommendedCell := context.RecommendedCell{}
-			skipRecommendedCell := false
-
-			switch item.NGRANCGI.Present {
-			case ngapType.NGRANCGIPresentNRCGI:
-				recommendedCell.NgRanCGI.Present = context.NgRanCgiPresentNRCGI
-				recommendedCell.NgRanCGI.NRCGI = new(models.Ncgi)
-				plmnID, err := ngapConvert.PlmnIdToModels(item.NGRANCGI.NRCGI.PLMNIdentity)
-				if err != nil {
-					ran.Log.Errorf("decode recommended NR CGI PLMN failed: %+v", err)
-					skipRecommendedCell = true
-					break
-				}
-				plmnIDCopy := plmnID
-				recommendedCell.NgRanCGI.NRCGI.PlmnId = plmnIDCopy
-				recommendedCell.NgRanCGI.NRCGI.NrCellId = ngapConvert.BitStringToHex(&item.NGRANCGI.NRCGI.NRCellIdentity.Value)
-			case ngapType.NGRANCGIPresentEUTRACGI:
-				recommendedCell.NgRanCGI.Present = context.NgRanCgiPresentEUTRACGI
-				recommendedCell.NgRanCGI.EUTRACGI = new(models.Ecgi)
-				plmnID, err := ngapConvert.PlmnIdToModels(item.NGRANCGI.EUTRACGI.PLMNIdentity)
-				if err != nil {
-					ran.Log.Errorf("decode recommended EUTRA CGI PLMN failed: %+v", err)
-					skipRecommendedCell = true
-					break
-				}
-				plmnIDCopy := plmnID
-				recommendedCell.NgRanCGI.EUTRACGI.PlmnId = plmnIDCopy
-				recommendedCell.NgRanCGI.EUTRACGI.EutraCellId = ngapConvert.BitStringToHex(
-					&item.NGRANCGI.EUTRACGI.EUTRACellIdentity.Value)
-			}
-
-			if skipRecommendedCell {
-				continue
-			}
-
-			if item.TimeStayedInCell != nil {
-				recommendedCell.TimeStayedInCell = new(int64)
-				*recommendedCell.TimeStayedInCell = *item.TimeStayedInCell
-			}
-
-			*recommendedCells = append(*recommendedCells, recommendedCell)
-		}
-
-		recommendedRanNodes := &amfUe.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedRanNodes
-		ranNodeList := infoOnRecommendedCellsAndRANNodesForPaging.RecommendRANNodesForPaging.RecommendedRANNodeList.List
-		for _, item := range ranNodeList {
-			recommendedRanNode := context.RecommendRanNode{}
-
-			switch item.AMFPagingTarget.Present {
-			case ngapType.AMFPagingTargetPresentGlobalRANNodeID:
-				recommendedRanNode.Present = context.RecommendRanNodePresentRanNode
-				recommendedRanNode.GlobalRanNodeId = new(models.GlobalRanNodeId)
-				// TODO: Convert item.AMFPagingTarget.GlobalRANNodeID with
-				// globalRanNodeID, err := ngapConvert.RanIdToModels(item.AMFPagingTarget.GlobalRANNodeID),
-				// handle err, then assign recommendedRanNode.GlobalRanNodeId = &globalRanNodeID.
-			case ngapType.AMFPagingTargetPresentTAI:
-				recommendedRanNode.Present = context.RecommendRanNodePresentTAI
-				tai, err := ngapConvert.TaiToModels(*item.AMFPagingTarget.TAI)
-				if err != nil {
-					ran.Log.Errorf("decode recommended paging TAI failed: %+v", err)
-					continue
-				}
-				recommendedRanNode.Tai = &tai
-			}
-			*recommendedRanNodes = append(*recommendedRanNodes, recommendedRanNode)
-		}
+		amfUe.InfoOnRecommendedCellsAndRanNodesForPaging = infoOnRecommendedCellsAndRanNodesForPagingToContext(
+			ran, infoOnRecommendedCellsAndRANNodesForPaging)
 	}
 
 	// for each pduSessionID invoke Nsmf_PDUSession_UpdateSMContext Request
@@ -1200,6 +1207,7 @@ func HandleUEContextReleaseComplete(ctx ctxt.Context, ran *context.AmfRan, messa
 		}
 		amfUe.PublishUeCtxtInfo()
 		context.StoreContextInDB(amfUe)
+		amfUe.EndOamProcedure(context.OamProcedureUeContextRelease, context.OamOutcomeCompleted, "")
 	case context.UeContextReleaseUeContext:
 		ran.Log.Infof("Release UE[%s] Context : Release Ue Context", amfUe.GetSupi())
 		err := ranUe.Remove()
@@ -1671,7 +1679,7 @@ func HandleInitialUEMessage(ctx ctxt.Context, ran *context.AmfRan, message *ngap
 			} else {
 				ranUe.Log.Debugf("find AmfUe [GUTI: %s]", guti)
 				/* checking the guti-ue belongs to this amf instance */
-				if amfSelf.EnableDbStore {
+				if amfSelf.Drsm != nil {
 					id, err := amfSelf.Drsm.FindOwnerInt32ID(amfUe.GetTmsi())
 					if err != nil {
 						ranUe.Log.Errorf("error checking the guti-ue in this instance: %v", err)
@@ -2645,6 +2653,7 @@ func HandleInitialContextSetupFailure(ctx ctxt.Context, ran *context.AmfRan, mes
 		amfUe.T3550.Stop()
 		amfUe.T3550 = nil
 		amfUe.State[ran.AnType].Set(context.Deregistered)
+		amfUe.SetRegisteredPopulation(ran.AnType, false)
 		amfUe.ClearRegistrationRequestData(ran.AnType)
 	}
 	if pDUSessionResourceFailedToSetupList != nil {
@@ -3054,18 +3063,40 @@ func HandleRRCInactiveTransitionReport(ran *context.AmfRan, message *ngapType.NG
 	} else {
 		ran.Log.Debugf("RANUENGAPID[%d] AMFUENGAPID[%d]", ranUe.RanUeNgapId, ranUe.AmfUeNgapId)
 
+		ranUe.UpdateLocation(userLocationInformation)
 		if rRCState != nil {
-			switch rRCState.Value {
-			case ngapType.RRCStatePresentInactive:
-				ran.Log.Debugln("UE RRC State: Inactive")
-			case ngapType.RRCStatePresentConnected:
-				ran.Log.Debugln("UE RRC State: Connected")
-			}
+			rrcStateToContext(ranUe, rRCState)
 		}
-		ranUe.UpdateLocation(userLocationInformation)
 	}
 }
 
+// rrcStateToContext records the RRC state reported for ranUe and reports the transition to
+// the connectivity state and reachability subscribers of the UE. A UE in RRC_INACTIVE stays
+// CM-CONNECTED and reachable through RAN paging (TS 23.501 5.3.3.2.5).
+func rrcStateToContext(ranUe *context.RanUe, rrcState *ngapType.RRCState) {
+	var state context.RrcState
+	switch rrcState.Value {
+	case ngapType.RRCStatePresentInactive:
+		state = context.RrcStateInactive
+	case ngapType.RRCStatePresentConnected:
+		state = context.RrcStateConnected
+	default:
+		ranUe.Log.Warnf("unknown RRC State: %d", rrcState.Value)
+		return
+	}
+	ranUe.Log.Debugf("UE RRC State: %s", state)
+	if !ranUe.UpdateRrcState(state) {
+		return
+	}
+	amfUe := ranUe.AmfUe
+	if amfUe == nil {
+		return
+	}
+	amfUe.Reachability = models.UEREACHABILITY_REACHABLE
+	callback.SendAmfEventNotify(amfUe,
+		models.AMFEVENTTYPE_CONNECTIVITY_STATE_REPORT, models.AMFEVENTTYPE_REACHABILITY_REPORT)
+}
+
 func HandleHandoverNotify(ctx ctxt.Context, ran *context.AmfRan, message *ngapType.NGAPPDU) {
 	var aMFUENGAPID *ngapType.AMFUENGAPID
 	var rANUENGAPID *ngapType.RANUENGAPID
@@ -3165,8 +3196,10 @@ func HandleHandoverNotify(ctx ctxt.Context, ran *context.AmfRan, message *ngapTy
 		}
 		amfUe.AttachRanUe(targetUe)
 		context.StoreContextInDB(amfUe)
+		amfUe.CompleteProcedure(context.KpiN2Handover)
 		ngap_message.SendUEContextReleaseCommand(sourceUe, context.UeContextReleaseHandover, ngapType.CausePresentRadioNetwork,
 			ngapType.CauseRadioNetworkPresentSuccessfulHandover)
+		retransmitDeferredDlNas(sourceUe, targetUe)
 	}
 	targetUe.SentInitialContextSetupRequest = true
 	// TODO: The UE initiates Mobility Registration Update procedure as described in clause 4.2.2.2.2.
@@ -3254,12 +3287,14 @@ func HandlePathSwitchRequest(ctx ctxt.Context, ran *context.AmfRan, message *nga
 		ngap_message.SendPathSwitchRequestFailure(ran, sourceAMFUENGAPID.Value, rANUENGAPID.Value, nil, nil)
 		return
 	}
+	amfUe.StartProcedure(context.KpiXnHandover)
 
 	if amfUe.SecurityContextIsValid() {
 		// Update NH
 		amfUe.UpdateNH()
 	} else {
 		ranUe.Log.Errorf("No Security Context : SUPI[%s]", amfUe.GetSupi())
+		amfUe.FailProcedure(context.KpiXnHandover, context.KpiCauseTypeAmf, "no_security_context")
 		ngap_message.SendPathSwitchRequestFailure(ran, sourceAMFUENGAPID.Value, rANUENGAPID.Value, nil, nil)
 		return
 	}
@@ -3375,16 +3410,24 @@ func HandlePathSwitchRequest(ctx ctxt.Context, ran *context.AmfRan, message *nga
 		err := ranUe.SwitchToRan(ran, rANUENGAPID.Value)
 		if err != nil {
 			ranUe.Log.Errorln(err.Error())
+			amfUe.FailProcedure(context.KpiXnHandover, context.KpiCauseTypeAmf, "switch_to_ran")
+			rejectDeferredDlNas(ranUe)
 			return
 		}
 		context.StoreContextInDB(amfUe)
 		ngap_message.SendPathSwitchRequestAcknowledge(ranUe, pduSessionResourceSwitchedList,
 			pduSessionResourceReleasedListPSAck, false, nil, nil, nil)
+		amfUe.CompleteProcedure(context.KpiXnHandover)
+		retransmitDeferredDlNas(ranUe, ranUe)
 	} else if len(pduSessionResourceReleasedListPSFail.List) > 0 {
+		amfUe.FailProcedure(context.KpiXnHandover, context.KpiCauseTypeAmf, "no_pdu_session_switched")
 		ngap_message.SendPathSwitchRequestFailure(ran, sourceAMFUENGAPID.Value, rANUENGAPID.Value,
 			&pduSessionResourceReleasedListPSFail, nil)
+		rejectDeferredDlNas(ranUe)
 	} else {
+		amfUe.FailProcedure(context.KpiXnHandover, context.KpiCauseTypeAmf, "no_pdu_session_switched")
 		ngap_message.SendPathSwitchRequestFailure(ran, sourceAMFUENGAPID.Value, rANUENGAPID.Value, nil, nil)
+		rejectDeferredDlNas(ranUe)
 	}
 }
 
@@ -3558,7 +3601,7 @@ func HandleHandoverRequestAcknowledge(ctx ctxt.Context, ran *context.AmfRan, mes
 					Value: ngapType.CauseRadioNetworkPresentHoFailureInTarget5GCNgranNodeOrTargetSystem,
 				},
 			}
-			ngap_message.SendHandoverPreparationFailure(sourceUe, *cause, nil)
+			sendHandoverPreparationFailure(sourceUe, *cause, nil)
 			return
 		}
 		ngap_message.SendHandoverCommand(sourceUe, pduSessionResourceHandoverList, pduSessionResourceToReleaseList,
@@ -3661,7 +3704,7 @@ func HandleHandoverFailure(ctx ctxt.Context, ran *context.AmfRan, message *ngapT
 				return true
 			})
 		}
-		ngap_message.SendHandoverPreparationFailure(sourceUe, *cause, criticalityDiagnostics)
+		sendHandoverPreparationFailure(sourceUe, *cause, criticalityDiagnostics)
 	}
 
 	ngap_message.SendUEContextReleaseCommand(targetUe, context.UeContextReleaseHandover, causePresent, causeValue)
@@ -3794,6 +3837,7 @@ func HandleHandoverRequired(ctx ctxt.Context, ran *context.AmfRan, message *ngap
 	amfUe.SetOnGoing(sourceUe.Ran.AnType, &context.OnGoingProcedureWithPrio{
 		Procedure: context.OnGoingProcedureN2Handover,
 	})
+	amfUe.StartProcedure(context.KpiN2Handover)
 	if !amfUe.SecurityContextIsValid() {
 		sourceUe.Log.Infoln("handle Handover Preparation Failure [Authentication Failure]")
 		cause = &ngapType.Cause{
@@ -3802,7 +3846,7 @@ func HandleHandoverRequired(ctx ctxt.Context, ran *context.AmfRan, message *ngap
 				Value: ngapType.CauseNasPresentAuthenticationFailure,
 			},
 		}
-		ngap_message.SendHandoverPreparationFailure(sourceUe, *cause, nil)
+		sendHandoverPreparationFailure(sourceUe, *cause, nil)
 		return
 	}
 	aMFSelf := context.AMF_Self()
@@ -3815,7 +3859,7 @@ func HandleHandoverRequired(ctx ctxt.Context, ran *context.AmfRan, message *ngap
 				Value: ngapType.CauseProtocolPresentSemanticError,
 			},
 		}
-		ngap_message.SendHandoverPreparationFailure(sourceUe, *cause, nil)
+		sendHandoverPreparationFailure(sourceUe, *cause, nil)
 		return
 	}
 	targetRan, ok := aMFSelf.AmfRanFindByRanID(targetRanNodeId)
@@ -3838,7 +3882,7 @@ func HandleHandoverRequired(ctx ctxt.Context, ran *context.AmfRan, message *ngap
 					Value: ngapType.CauseMiscPresentUnspecified,
 				},
 			}
-			ngap_message.SendHandoverPreparationFailure(sourceUe, *cause, nil)
+			sendHandoverPreparationFailure(sourceUe, *cause, nil)
 			return
 		}
 		ranNodeId := models.NewNullableGlobalRanNodeId(&targetRanNodeId)
@@ -3876,7 +3920,7 @@ func HandleHandoverRequired(ctx ctxt.Context, ran *context.AmfRan, message *ngap
 					Value: ngapType.CauseRadioNetworkPresentHoFailureInTarget5GCNgranNodeOrTargetSystem,
 				},
 			}
-			ngap_message.SendHandoverPreparationFailure(sourceUe, *cause, nil)
+			sendHandoverPreparationFailure(sourceUe, *cause, nil)
 			return
 		}
 		// Update NH
@@ -3967,6 +4011,9 @@ func HandleHandoverCancel(ctx ctxt.Context, ran *context.AmfRan, message *ngapTy
 	if cause != nil {
 		causePresent, causeValue = printAndGetCause(ran, cause)
 	}
+	if sourceUe.AmfUe != nil {
+		sourceUe.AmfUe.FailProcedure(context.KpiN2Handover, context.KpiCauseTypeNgap, context.KpiNgapCause(cause))
+	}
 	targetUe := sourceUe.TargetUe
 	if targetUe == nil {
 		// Described in (23.502 4.11.1.2.3) step 2
@@ -3992,6 +4039,7 @@ func HandleHandoverCancel(ctx ctxt.Context, ran *context.AmfRan, message *ngapTy
 		ngap_message.SendUEContextReleaseCommand(targetUe, context.UeContextReleaseHandover, causePresent, causeValue)
 		ngap_message.SendHandoverCancelAcknowledge(sourceUe, nil)
 	}
+	retransmitDeferredDlNas(sourceUe, sourceUe)
 }
 
 func HandleUplinkRanStatusTransfer(ran *context.AmfRan, message *ngapType.NGAPPDU) {
@@ -4145,7 +4193,69 @@ func HandleNasNonDeliveryIndication(ctx ctxt.Context, ran *context.AmfRan, messa
 
 	printAndGetCause(ran, cause)
 
-	nas.HandleNAS(ctx, ranUe, ngapType.ProcedureCodeNASNonDeliveryIndication, nASPDU.Value)
+	dlNas, ok := ranUe.TakeDlNas(nASPDU.Value)
+	if !ok {
+		ranUe.Log.Warnln("undelivered NAS PDU is not a pending downlink NAS PDU of the UE")
+		return
+	}
+	if nasNonDeliveryDueToHandover(cause) {
+		ranUe.Log.Infoln("NAS PDU not delivered due to handover, retransmit once it completes")
+		ranUe.DeferDlNas(dlNas)
+		return
+	}
+	if dlNas.Origin == nil {
+		// NAS procedures of the AMF are guarded by their own retransmission timers
+		ranUe.Log.Warnln("NAS PDU of the AMF not delivered")
+		return
+	}
+	ranUe.Log.Infof("%s NAS PDU not delivered, notify the NF service consumer", dlNas.Origin.N1MessageClass)
+	callback.SendN1N2DeliveryFailureNotification(dlNas.Origin, models.N1N2MESSAGETRANSFERCAUSE_N1_MSG_NOT_TRANSFERRED)
+}
+
+// nasNonDeliveryDueToHandover reports whether the NG-RAN did not deliver a NAS PDU because a
+// handover was triggered for the UE
+func nasNonDeliveryDueToHandover(cause *ngapType.Cause) bool {
+	if cause == nil || cause.Present != ngapType.CausePresentRadioNetwork || cause.RadioNetwork == nil {
+		return false
+	}
+	switch cause.RadioNetwork.Value {
+	case ngapType.CauseRadioNetworkPresentNgIntraSystemHandoverTriggered,
+		ngapType.CauseRadioNetworkPresentNgInterSystemHandoverTriggered,
+		ngapType.CauseRadioNetworkPresentXnHandoverTriggered:
+		return true
+	}
+	return false
+}
+
+// retransmitDeferredDlNas sends the NAS PDUs deferred on from during a handover over to, once
+// the handover or path switch completed, failed or was cancelled
+func retransmitDeferredDlNas(from, to *context.RanUe) {
+	for _, dlNas := range from.TakeDeferredDlNas() {
+		to.Log.Infoln("retransmit NAS PDU not delivered during handover")
+		ngap_message.SendN1N2DownlinkNasTransport(to, dlNas.NasPdu, dlNas.Origin)
+	}
+}
+
+// rejectDeferredDlNas drops the NAS PDUs deferred on ranUe during a path switch that failed,
+// and notifies the NF service consumers they were transferred for
+func rejectDeferredDlNas(ranUe *context.RanUe) {
+	for _, dlNas := range ranUe.TakeDeferredDlNas() {
+		if dlNas.Origin == nil {
+			continue
+		}
+		ranUe.Log.Infof("%s NAS PDU not delivered after path switch failure, notify the NF service consumer",
+			dlNas.Origin.N1MessageClass)
+		callback.SendN1N2DeliveryFailureNotification(dlNas.Origin, models.N1N2MESSAGETRANSFERCAUSE_N1_MSG_NOT_TRANSFERRED)
+	}
+}
+
+// sendHandoverPreparationFailure ends the handover preparation of sourceUe, which stays with
+// its NG-RAN node and gets the NAS PDUs deferred during the preparation retransmitted
+func sendHandoverPreparationFailure(sourceUe *context.RanUe, cause ngapType.Cause,
+	criticalityDiagnostics *ngapType.CriticalityDiagnostics,
+) {
+	ngap_message.SendHandoverPreparationFailure(sourceUe, cause, criticalityDiagnostics)
+	retransmitDeferredDlNas(sourceUe, sourceUe)
 }
 
 func HandleRanConfigurationUpdate(ran *context.AmfRan, message *ngapType.NGAPPDU) {
@@ -4698,11 +4808,99 @@ func HandleUERadioCapabilityInfoIndication(ran *context.AmfRan, message *ngapTyp
 				uERadioCapabilityForPaging.UERadioCapabilityForPagingOfEUTRA.Value)
 		}
 	}
+	// the capability the NG-RAN retrieved for an unknown UE radio capability ID completes the
+	// dictionary (TS 23.502 5.2.18.2)
+	if dictionary := context.AMF_Self().UeRadioCapabilityDictionary; dictionary != nil &&
+		amfUe.UeRadioCapabilityId != "" && amfUe.UeRadioCapability != "" {
+		dictionary.Put(amfUe.UeRadioCapabilityId, context.UeRadioCapabilityEntry{
+			UeRadioCapability:          amfUe.UeRadioCapability,
+			UeRadioCapabilityForPaging: amfUe.UeRadioCapabilityForPaging,
+		})
+	}
 
 	// TS 38.413 8.14.1.2/TS 23.502 4.2.8a step5/TS 23.501, clause 5.4.4.1.
 	// send its most up to date UE Radio Capability information to the RAN in the N2 REQUEST message.
 }
 
+// HandleUERadioCapabilityIDMappingRequest answers an NG-RAN node asking for the UE radio
+// capability of a UE radio capability ID it has no dictionary entry for (TS 38.413 8.14.4)
+func HandleUERadioCapabilityIDMappingRequest(ctx ctxt.Context, ran *context.AmfRan, message *ngapType.NGAPPDU) {
+	var uERadioCapabilityID *ngapType.UERadioCapabilityID
+
+	if message == nil {
+		ran.Log.Errorln("NGAP Message is nil")
+		return
+	}
+	initiatingMessage := message.InitiatingMessage
+	if initiatingMessage == nil {
+		ran.Log.Errorln("InitiatingMessage is nil")
+		return
+	}
+	uERadioCapabilityIDMappingRequest := initiatingMessage.Value.UERadioCapabilityIDMapping
+	if uERadioCapabilityIDMappingRequest == nil {
+		ran.Log.Errorln("UERadioCapabilityIDMappingRequest is nil")
+		return
+	}
+
+	ran.Log.Infoln("handle UE Radio Capability ID Mapping Request")
+
+	for i := 0; i < len(uERadioCapabilityIDMappingRequest.ProtocolIEs.List); i++ {
+		ie := uERadioCapabilityIDMappingRequest.ProtocolIEs.List[i]
+		switch ie.Id.Value {
+		case ngapType.ProtocolIEIDUERadioCapabilityID:
+			uERadioCapabilityID = ie.Value.UERadioCapabilityID
+			ran.Log.Debugln("decode IE UERadioCapabilityID")
+		}
+	}
+
+	if uERadioCapabilityID == nil {
+		ran.Log.Errorln("UERadioCapabilityID IE missing from UERadioCapabilityIDMappingRequest")
+		cause := ngapType.Cause{
+			Present: ngapType.CausePresentProtocol,
+			Protocol: &ngapType.CauseProtocol{
+				Value: ngapType.CauseProtocolPresentAbstractSyntaxErrorFalselyConstructedMessage,
+			},
+		}
+		ngap_message.SendErrorIndication(ran, nil, nil, &cause, nil)
+		return
+	}
+
+	ueRadioCapabilityId := hex.EncodeToString(uERadioCapabilityID.Value)
+	amfSelf := context.AMF_Self()
+	entry, err := amfSelf.LookupUeRadioCapabilityId(ueRadioCapabilityId)
+	if errors.Is(err, context.ErrUeRadioCapabilityIdUnknown) && amfSelf.Ucmf != nil {
+		// the UCMF is not queried on the goroutine handling the messages of the NG-RAN node
+		go func() {
+			entry, err := amfSelf.ResolveUeRadioCapabilityId(ctx, ueRadioCapabilityId)
+			sendUERadioCapabilityIDMappingResult(ran, *uERadioCapabilityID, entry, err)
+		}()
+		return
+	}
+	sendUERadioCapabilityIDMappingResult(ran, *uERadioCapabilityID, entry, err)
+}
+
+// sendUERadioCapabilityIDMappingResult answers the UE Radio Capability ID Mapping Request of
+// ran with the resolved entry, or with an Error Indication if the ID could not be resolved
+func sendUERadioCapabilityIDMappingResult(ran *context.AmfRan, uERadioCapabilityID ngapType.UERadioCapabilityID,
+	entry *context.UeRadioCapabilityEntry, err error,
+) {
+	if err == nil {
+		var uERadioCapability []byte
+		if uERadioCapability, err = hex.DecodeString(entry.UeRadioCapability); err == nil {
+			ngap_message.SendUERadioCapabilityIDMappingResponse(ran, uERadioCapabilityID, uERadioCapability)
+			return
+		}
+	}
+	ran.Log.Errorf("resolve UE radio capability ID[%x] failed: %+v", uERadioCapabilityID.Value, err)
+	cause := ngapType.Cause{
+		Present: ngapType.CausePresentMisc,
+		Misc: &ngapType.CauseMisc{
+			Value: ngapType.CauseMiscPresentUnspecified,
+		},
+	}
+	ngap_message.SendErrorIndication(ran, nil, nil, &cause, nil)
+}
+
 func HandleAMFconfigurationUpdateFailure(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 	var cause *ngapType.Cause
 	var criticalityDiagnostics *ngapType.CriticalityDiagnostics
@@ -4958,6 +5156,7 @@ func HandleCellTrafficTrace(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 
 	ranUe.Log.Debugf("TRSR[%s]", ranUe.Trsr)
 
+	var cgi string
 	switch nGRANCGI.Present {
 	case ngapType.NGRANCGIPresentNRCGI:
 		plmnID, err := ngapConvert.PlmnIdToModels(nGRANCGI.NRCGI.PLMNIdentity)
@@ -4967,6 +5166,7 @@ func HandleCellTrafficTrace(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 		}
 		cellID := ngapConvert.BitStringToHex(&nGRANCGI.NRCGI.NRCellIdentity.Value)
 		ranUe.Log.Debugf("NRCGI[plmn: %s, cellID: %s]", plmnID, cellID)
+		cgi = plmnID.Mcc + plmnID.Mnc + cellID
 	case ngapType.NGRANCGIPresentEUTRACGI:
 		plmnID, err := ngapConvert.PlmnIdToModels(nGRANCGI.EUTRACGI.PLMNIdentity)
 		if err != nil {
@@ -4975,6 +5175,7 @@ func HandleCellTrafficTrace(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 		}
 		cellID := ngapConvert.BitStringToHex(&nGRANCGI.EUTRACGI.EUTRACellIdentity.Value)
 		ranUe.Log.Debugf("EUTRACGI[plmn: %s, cellID: %s]", plmnID, cellID)
+		cgi = plmnID.Mcc + plmnID.Mnc + cellID
 	}
 
 	tceIpv4, tceIpv6 := ngapConvert.IPAddressToString(*traceCollectionEntityIPAddress)
@@ -4985,11 +5186,31 @@ func HandleCellTrafficTrace(ran *context.AmfRan, message *ngapType.NGAPPDU) {
 		ranUe.Log.Debugf("TCE IP Address[v6: %s]", tceIpv6)
 	}
 
-	// TODO: TS 32.422 4.2.2.10
+	// TS 32.422 4.2.2.10
 	// When AMF receives this new NG signalling message containing the Trace Recording Session Reference (TRSR)
 	// and Trace Reference (TR), the AMF shall look up the SUPI/IMEI(SV) of the given call from its database and
 	// shall send the SUPI/IMEI(SV) numbers together with the Trace Recording Session Reference and Trace Reference
 	// to the Trace Collection Entity.
+	amfUe := ranUe.AmfUe
+	if amfUe == nil {
+		ranUe.Log.Warnln("no AmfUe for CellTrafficTrace")
+		return
+	}
+	plmnID, err := ngapConvert.PlmnIdToModels(ngapType.PLMNIdentity{Value: nGRANTraceID.Value[:3]})
+	if err != nil {
+		ranUe.Log.Errorf("decode NGRANTraceID PLMN failed: %+v", err)
+		return
+	}
+	traceRef := plmnID.Mcc + plmnID.Mnc + "-" + hex.EncodeToString(nGRANTraceID.Value[3:6])
+	tceIpAddress := tceIpv4
+	if tceIpAddress == "" {
+		tceIpAddress = tceIpv6
+	}
+	amfUe.RecordCellTrafficTrace(traceRef, ranUe.Trsr, tracerecording.CellTrafficTrace{
+		NgranTraceId: hex.EncodeToString(nGRANTraceID.Value),
+		Cgi:          cgi,
+		TceIpAddress: tceIpAddress,
+	})
 }
 
 func printAndGetCause(ran *context.AmfRan, cause *ngapType.Cause) (present int, value aper.Enumerated) {
@@ -10,6 +10,9 @@ import (
 	"github.com/omec-project/amf/context"
 	"github.com/omec-project/amf/factory"
 	"github.com/omec-project/amf/logger"
+	ngaputil "github.com/omec-project/amf/ngap/util"
+	"github.com/omec-project/ngap/v2/aper"
+	"github.com/omec-project/ngap/v2/ngapConvert"
 	"github.com/omec-project/ngap/v2/ngapType"
 	"github.com/omec-project/openapi/v2/models"
 )
@@ -480,3 +483,109 @@ func TestHandleUEContextReleaseCompleteHandoverPromotesTargetRanUe(t *testing.T)
 		t.Fatal("expected target RanUe to remain in the pool")
 	}
 }
+
+func TestInfoOnRecommendedCellsAndRanNodesForPagingToContext(t *testing.T) {
+	plmnID := ngapConvert.PlmnIdToNgap(models.PlmnId{Mcc: "208", Mnc: "93"})
+	timeStayedInCell := int64(30)
+	info := &ngapType.InfoOnRecommendedCellsAndRANNodesForPaging{}
+	info.RecommendedCellsForPaging.RecommendedCellList.List = []ngapType.RecommendedCellItem{{
+		NGRANCGI: ngapType.NGRANCGI{
+			Present: ngapType.NGRANCGIPresentNRCGI,
+			NRCGI: &ngapType.NRCGI{
+				PLMNIdentity:   plmnID,
+				NRCellIdentity: ngapType.NRCellIdentity{Value: ngapConvert.HexToBitString("000001010", 36)},
+			},
+		},
+		TimeStayedInCell: &timeStayedInCell,
+	}}
+	info.RecommendRANNodesForPaging.RecommendedRANNodeList.List = []ngapType.RecommendedRANNodeItem{
+		{AMFPagingTarget: ngapType.AMFPagingTarget{
+			Present: ngapType.AMFPagingTargetPresentGlobalRANNodeID,
+			GlobalRANNodeID: &ngapType.GlobalRANNodeID{
+				Present: ngapType.GlobalRANNodeIDPresentGlobalGNBID,
+				GlobalGNBID: &ngapType.GlobalGNBID{
+					PLMNIdentity: plmnID,
+					GNBID: ngapType.GNBID{
+						Present: ngapType.GNBIDPresentGNBID,
+						GNBID:   &aper.BitString{Bytes: []byte{0x00, 0x00, 0x01}, BitLength: 24},
+					},
+				},
+			},
+		}},
+		{AMFPagingTarget: ngapType.AMFPagingTarget{
+			Present: ngapType.AMFPagingTargetPresentTAI,
+			TAI:     &ngapType.TAI{PLMNIdentity: plmnID, TAC: ngapType.TAC{Value: aper.OctetString{0x00, 0x00, 0x02}}},
+		}},
+	}
+
+	result := infoOnRecommendedCellsAndRanNodesForPagingToContext(context.NewAmfRanDefault(), info)
+	if len(result.RecommendedCells) != 1 || result.RecommendedCells[0].NgRanCGI.NRCGI == nil ||
+		result.RecommendedCells[0].NgRanCGI.NRCGI.NrCellId != "000001010" ||
+		result.RecommendedCells[0].TimeStayedInCell == nil || *result.RecommendedCells[0].TimeStayedInCell != 30 {
+		t.Fatalf("unexpected recommended cells %+v", result.RecommendedCells)
+	}
+	if len(result.RecommendedRanNodes) != 2 {
+		t.Fatalf("expected 2 recommended RAN nodes, got %+v", result.RecommendedRanNodes)
+	}
+	ranNode := result.RecommendedRanNodes[0]
+	if ranNode.Present != context.RecommendRanNodePresentRanNode || ranNode.GlobalRanNodeId == nil ||
+		ranNode.GlobalRanNodeId.GNbId == nil || ranNode.GlobalRanNodeId.GNbId.GNBValue != "000001" {
+		t.Errorf("unexpected recommended RAN node %+v", ranNode)
+	}
+	if tai := result.RecommendedRanNodes[1].Tai; tai == nil || tai.Tac != "000002" {
+		t.Errorf("unexpected recommended TAI %+v", tai)
+	}
+}
+
+func TestNasNonDeliveryDueToHandover(t *testing.T) {
+	radioNetworkCause := func(value aper.Enumerated) *ngapType.Cause {
+		return &ngapType.Cause{
+			Present:      ngapType.CausePresentRadioNetwork,
+			RadioNetwork: &ngapType.CauseRadioNetwork{Value: value},
+		}
+	}
+	if !nasNonDeliveryDueToHandover(radioNetworkCause(ngapType.CauseRadioNetworkPresentXnHandoverTriggered)) {
+		t.Error("expected Xn handover to defer the NAS PDU")
+	}
+	if !nasNonDeliveryDueToHandover(radioNetworkCause(ngapType.CauseRadioNetworkPresentNgIntraSystemHandoverTriggered)) {
+		t.Error("expected NG handover to defer the NAS PDU")
+	}
+	if nasNonDeliveryDueToHandover(radioNetworkCause(ngapType.CauseRadioNetworkPresentRadioConnectionWithUeLost)) {
+		t.Error("expected radio connection loss not to defer the NAS PDU")
+	}
+	if nasNonDeliveryDueToHandover(nil) {
+		t.Error("expected a missing cause not to defer the NAS PDU")
+	}
+}
+
+func TestSendHandoverPreparationFailureRetransmitsDeferredDlNas(t *testing.T) {
+	self := context.AMF_Self()
+	disableKafkaForTest(t)
+	ran := context.NewAmfRanDefault()
+	ran.AnType = models.ACCESSTYPE__3_GPP_ACCESS
+	ran.Conn = &ngaputil.TestConn{}
+	sourceRanUe, err := ran.NewRanUe(30)
+	if err != nil {
+		t.Fatalf("unexpected error creating source RanUe: %v", err)
+	}
+	sourceRanUe.Log = logger.NgapLog
+	amfUe := self.NewAmfUe("")
+	amfUe.AttachRanUe(sourceRanUe)
+
+	nasPdu := []byte{0x7e, 0x00, 0x68}
+	sourceRanUe.DeferDlNas(context.PendingDlNas{NasPdu: nasPdu})
+
+	sendHandoverPreparationFailure(sourceRanUe, ngapType.Cause{
+		Present: ngapType.CausePresentRadioNetwork,
+		RadioNetwork: &ngapType.CauseRadioNetwork{
+			Value: ngapType.CauseRadioNetworkPresentHoFailureInTarget5GCNgranNodeOrTargetSystem,
+		},
+	}, nil)
+
+	if deferred := sourceRanUe.TakeDeferredDlNas(); len(deferred) != 0 {
+		t.Errorf("expected no NAS PDU left deferred, got %d", len(deferred))
+	}
+	if _, ok := sourceRanUe.TakeDlNas(nasPdu); !ok {
+		t.Error("expected the deferred NAS PDU to be sent again to the source NG-RAN node")
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/omec-project/amf/logger"
 	"github.com/omec-project/amf/metrics"
 	"github.com/omec-project/amf/msgtypes/ngapmsgtypes"
+	"github.com/omec-project/nas/v2/nasConvert"
+	"github.com/omec-project/nas/v2/nasMessage"
 	"github.com/omec-project/ngap/v2"
 	"github.com/omec-project/ngap/v2/aper"
 	"github.com/omec-project/ngap/v2/ngapConvert"
@@ -23,6 +25,12 @@ import (
 
 const maxAllowedNSSAIItems = 8
 
+// maxnoofRecommendedCells bounds the Recommended Cell List (TS 38.413 9.4.7)
+const maxnoofRecommendedCells = 16
+
+// maxnoofTAIforInactive bounds the TAI List for RRC Inactive (TS 38.413 9.4.7)
+const maxnoofTAIforInactive = 16
+
 func buildAllowedNSSAIFromAllowedSnssai(snssais []models.AllowedSnssai) (*ngapType.AllowedNSSAI, error) {
 	allowedNSSAI := new(ngapType.AllowedNSSAI)
 	seen := make(map[string]struct{}, len(snssais))
@@ -56,6 +64,177 @@ func appendAllowedNSSAIItem(
 	return nil
 }
 
+// buildTraceActivation returns the Trace Activation IE of the trace session of the UE, whose
+// NG-RAN Trace ID is the Trace Reference followed by the TRSR of the AMF (TS 32.422 4.2.2.9)
+func buildTraceActivation(amfUe *context.AmfUe) (ngapType.TraceActivation, bool) {
+	if amfUe.TraceData == nil {
+		return ngapType.TraceActivation{}, false
+	}
+	traceActivation := ngapConvert.TraceDataToNgap(*amfUe.TraceData, amfUe.TraceRecordingSessionRef)
+	return traceActivation, len(traceActivation.NGRANTraceID.Value) == 8
+}
+
+// buildUERadioCapabilityID returns the UE Radio Capability ID IE of the UE, which is sent to an
+// NG-RAN supporting RACS instead of the UE radio capability (TS 23.501 5.4.4.1a)
+func buildUERadioCapabilityID(amfUe *context.AmfUe) (ngapType.UERadioCapabilityID, bool) {
+	if !context.AMF_Self().RacsSupportedByRan || amfUe.UeRadioCapabilityId == "" {
+		return ngapType.UERadioCapabilityID{}, false
+	}
+	id, err := hex.DecodeString(amfUe.UeRadioCapabilityId)
+	if err != nil {
+		logger.NgapLog.Errorf("[Build Error] DecodeString amfUe.UeRadioCapabilityId error: %+v", err)
+		return ngapType.UERadioCapabilityID{}, false
+	}
+	return ngapType.UERadioCapabilityID{Value: id}, true
+}
+
+// buildCoreNetworkAssistanceInformationForInactive returns the Core Network Assistance
+// Information for RRC INACTIVE the NG-RAN uses to decide on and page a UE in RRC_INACTIVE
+// (TS 23.501 5.3.3.2.5, TS 38.413 9.3.1.15). It returns nil while the UE has no registration
+// area for 3GPP access.
+func buildCoreNetworkAssistanceInformationForInactive(
+	amfUe *context.AmfUe,
+) *ngapType.CoreNetworkAssistanceInformationForInactive {
+	registrationArea := amfUe.RegistrationArea[models.ACCESSTYPE__3_GPP_ACCESS]
+	if len(registrationArea) == 0 {
+		return nil
+	}
+	info := new(ngapType.CoreNetworkAssistanceInformationForInactive)
+
+	// UE Identity Index Value: 5G-S-TMSI mod 1024 (TS 38.304 7.1)
+	ueIdentityIndex := uint32(amfUe.GetTmsi()) & 0x3ff
+	info.UEIdentityIndexValue.Present = ngapType.UEIdentityIndexValuePresentIndexLength10
+	info.UEIdentityIndexValue.IndexLength10 = &aper.BitString{
+		Bytes:     []byte{byte(ueIdentityIndex >> 2), byte(ueIdentityIndex << 6)},
+		BitLength: 10,
+	}
+
+	// UE Specific DRX
+	if pagingDRX, ok := pagingDRXFromNas(amfUe.UESpecificDRX); ok {
+		info.UESpecificDRX = &ngapType.PagingDRX{Value: pagingDRX}
+	}
+
+	// Periodic Registration Update Timer, coded as GPRS Timer 3 (TS 38.413 9.3.3.27)
+	t3512Value := amfUe.T3512Value
+	if t3512Value == 0 {
+		t3512Value = context.AMF_Self().T3512Value
+	}
+	info.PeriodicRegistrationUpdateTimer.Value = aper.BitString{
+		Bytes:     []byte{nasConvert.GPRSTimer3ToNas(t3512Value)},
+		BitLength: 8,
+	}
+
+	if amfUe.MicoMode {
+		info.MICOModeIndication = &ngapType.MICOModeIndication{Value: ngapType.MICOModeIndicationPresentTrue}
+	}
+
+	for i, tai := range registrationArea {
+		if i == maxnoofTAIforInactive {
+			break
+		}
+		tac, err := hex.DecodeString(tai.Tac)
+		if err != nil {
+			logger.NgapLog.Errorf("[Build Error] DecodeString tai.Tac error: %+v", err)
+			continue
+		}
+		item := ngapType.TAIListForInactiveItem{}
+		item.TAI.PLMNIdentity = ngapConvert.PlmnIdToNgap(tai.PlmnId)
+		item.TAI.TAC.Value = tac
+		info.TAIListForInactive.List = append(info.TAIListForInactive.List, item)
+	}
+	if len(info.TAIListForInactive.List) == 0 {
+		return nil
+	}
+
+	info.ExpectedUEBehaviour = buildExpectedUEBehaviour(amfUe)
+	return info
+}
+
+// buildRRCInactiveTransitionReportRequest asks the NG-RAN to report every RRC state transition
+// of a UE whose connectivity state or reachability is subscribed to (TS 23.502 4.8.3)
+func buildRRCInactiveTransitionReportRequest(amfUe *context.AmfUe) *ngapType.RRCInactiveTransitionReportRequest {
+	if !amfUe.RrcStateReportRequired() {
+		return nil
+	}
+	return &ngapType.RRCInactiveTransitionReportRequest{
+		Value: ngapType.RRCInactiveTransitionReportRequestPresentSubsequentStateTransitionReport,
+	}
+}
+
+// pagingDRXFromNas maps the DRX value negotiated with the UE (TS 24.501 9.11.3.2A) to the
+// NGAP Paging DRX
+func pagingDRXFromNas(drxValue uint8) (aper.Enumerated, bool) {
+	switch drxValue {
+	case nasMessage.DRXcycleParameterT32:
+		return ngapType.PagingDRXPresentV32, true
+	case nasMessage.DRXcycleParameterT64:
+		return ngapType.PagingDRXPresentV64, true
+	case nasMessage.DRXcycleParameterT128:
+		return ngapType.PagingDRXPresentV128, true
+	case nasMessage.DRXcycleParameterT256:
+		return ngapType.PagingDRXPresentV256, true
+	}
+	return 0, false
+}
+
+// buildExpectedUEBehaviour derives the Expected UE Behaviour from the expected UE behaviour
+// parameters in the subscription (TS 23.501 5.4.6.2)
+func buildExpectedUEBehaviour(amfUe *context.AmfUe) *ngapType.ExpectedUEBehaviour {
+	if amfUe.AccessAndMobilitySubscriptionData == nil {
+		return nil
+	}
+	expected := amfUe.AccessAndMobilitySubscriptionData.ExpectedUeBehaviourList
+	if expected == nil {
+		return nil
+	}
+	behaviour := new(ngapType.ExpectedUEBehaviour)
+	if communicationDurationTime := expected.CommunicationDurationTime; communicationDurationTime != nil {
+		activity := &ngapType.ExpectedUEActivityBehaviour{
+			ExpectedActivityPeriod: &ngapType.ExpectedActivityPeriod{
+				Value: expectedPeriodToNgap(*communicationDurationTime),
+			},
+			SourceOfUEActivityBehaviourInformation: &ngapType.SourceOfUEActivityBehaviourInformation{
+				Value: ngapType.SourceOfUEActivityBehaviourInformationPresentSubscriptionInformation,
+			},
+		}
+		if periodicTime := expected.PeriodicTime; periodicTime != nil && *periodicTime > *communicationDurationTime {
+			activity.ExpectedIdlePeriod = &ngapType.ExpectedIdlePeriod{
+				Value: expectedPeriodToNgap(*periodicTime - *communicationDurationTime),
+			}
+		}
+		behaviour.ExpectedUEActivityBehaviour = activity
+	}
+	if expected.StationaryIndication != nil {
+		mobility := &ngapType.ExpectedUEMobility{Value: ngapType.ExpectedUEMobilityPresentMobile}
+		if *expected.StationaryIndication == models.STATIONARYINDICATION_STATIONARY {
+			mobility.Value = ngapType.ExpectedUEMobilityPresentStationary
+		}
+		behaviour.ExpectedUEMobility = mobility
+	}
+	if behaviour.ExpectedUEActivityBehaviour == nil && behaviour.ExpectedUEMobility == nil {
+		return nil
+	}
+	return behaviour
+}
+
+// expectedPeriodToNgap encodes a period in seconds as Expected Activity/Idle Period, rounding
+// up to the next value of 1..30|40|50|60|80|100|120|150|180|181 where 181 stands for more than
+// 180 seconds (TS 38.413 9.3.1.94)
+func expectedPeriodToNgap(seconds int32) int64 {
+	if seconds < 1 {
+		return 1
+	}
+	if seconds <= 30 {
+		return int64(seconds)
+	}
+	for _, period := range []int32{40, 50, 60, 80, 100, 120, 150, 180} {
+		if seconds <= period {
+			return int64(period)
+		}
+	}
+	return 181
+}
+
 func IncrementNGAPMsgCount(pdu ngapType.NGAPPDU) {
 	if pdu.InitiatingMessage != nil {
 		metrics.IncrementNgapMsgStats(context.AMF_Self().NfId,
@@ -257,6 +436,49 @@ func BuildNGSetupFailure(cause ngapType.Cause) ([]byte, error) {
 	return ngap.Encoder(pdu)
 }
 
+// TS 38.413 8.7.4.2.1: the AMF resets the whole NG interface, all UE-associated logical
+// NG-connections of the NG-RAN node are released
+func BuildNGReset(cause ngapType.Cause) ([]byte, error) {
+	var pdu ngapType.NGAPPDU
+
+	pdu.Present = ngapType.NGAPPDUPresentInitiatingMessage
+	pdu.InitiatingMessage = new(ngapType.InitiatingMessage)
+
+	initiatingMessage := pdu.InitiatingMessage
+	initiatingMessage.ProcedureCode.Value = ngapType.ProcedureCodeNGReset
+	initiatingMessage.Criticality.Value = ngapType.CriticalityPresentReject
+
+	initiatingMessage.Value.Present = ngapType.InitiatingMessagePresentNGReset
+	initiatingMessage.Value.NGReset = new(ngapType.NGReset)
+
+	nGReset := initiatingMessage.Value.NGReset
+	nGResetIEs := &nGReset.ProtocolIEs
+
+	// Cause
+	ie := ngapType.NGResetIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDCause
+	ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+	ie.Value.Present = ngapType.NGResetIEsPresentCause
+	ie.Value.Cause = &cause
+
+	nGResetIEs.List = append(nGResetIEs.List, ie)
+
+	// Reset Type
+	ie = ngapType.NGResetIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDResetType
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.NGResetIEsPresentResetType
+	ie.Value.ResetType = &ngapType.ResetType{
+		Present:     ngapType.ResetTypePresentNGInterface,
+		NGInterface: &ngapType.ResetAll{Value: ngapType.ResetAllPresentResetAll},
+	}
+
+	nGResetIEs.List = append(nGResetIEs.List, ie)
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
+
 func BuildNGResetAcknowledge(partOfNGInterface *ngapType.UEAssociatedLogicalNGConnectionList,
 	criticalityDiagnostics *ngapType.CriticalityDiagnostics,
 ) ([]byte, error) {
@@ -498,6 +720,74 @@ func BuildUEContextReleaseCommand(
 	return ngap.Encoder(pdu)
 }
 
+// BuildUEContextModificationRequest builds the UE Context Modification Request that hands the
+// NG-RAN node the security key of the UE's new 5G NAS security context (TS 38.413 8.3.4)
+func BuildUEContextModificationRequest(ue *context.RanUe) ([]byte, error) {
+	amfUe := ue.AmfUe
+	if amfUe == nil {
+		return nil, fmt.Errorf("AmfUe is nil")
+	}
+
+	var pdu ngapType.NGAPPDU
+
+	pdu.Present = ngapType.NGAPPDUPresentInitiatingMessage
+	pdu.InitiatingMessage = new(ngapType.InitiatingMessage)
+
+	initiatingMessage := pdu.InitiatingMessage
+	initiatingMessage.ProcedureCode.Value = ngapType.ProcedureCodeUEContextModification
+	initiatingMessage.Criticality.Value = ngapType.CriticalityPresentReject
+
+	initiatingMessage.Value.Present = ngapType.InitiatingMessagePresentUEContextModification
+	initiatingMessage.Value.UEContextModification = new(ngapType.UEContextModificationRequest)
+
+	ueContextModificationRequest := initiatingMessage.Value.UEContextModification
+	ueContextModificationRequestIEs := &ueContextModificationRequest.ProtocolIEs
+
+	// AMF UE NGAP ID
+	ie := ngapType.UEContextModificationRequestIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDAMFUENGAPID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.UEContextModificationRequestIEsPresentAMFUENGAPID
+	ie.Value.AMFUENGAPID = new(ngapType.AMFUENGAPID)
+
+	aMFUENGAPID := ie.Value.AMFUENGAPID
+	aMFUENGAPID.Value = ue.AmfUeNgapId
+
+	ueContextModificationRequestIEs.List = append(ueContextModificationRequestIEs.List, ie)
+
+	// RAN UE NGAP ID
+	ie = ngapType.UEContextModificationRequestIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDRANUENGAPID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.UEContextModificationRequestIEsPresentRANUENGAPID
+	ie.Value.RANUENGAPID = new(ngapType.RANUENGAPID)
+
+	rANUENGAPID := ie.Value.RANUENGAPID
+	rANUENGAPID.Value = ue.RanUeNgapId
+
+	ueContextModificationRequestIEs.List = append(ueContextModificationRequestIEs.List, ie)
+
+	// Security Key
+	ie = ngapType.UEContextModificationRequestIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDSecurityKey
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.UEContextModificationRequestIEsPresentSecurityKey
+	ie.Value.SecurityKey = new(ngapType.SecurityKey)
+
+	securityKey := ie.Value.SecurityKey
+	switch ue.Ran.AnType {
+	case models.ACCESSTYPE__3_GPP_ACCESS:
+		securityKey.Value = ngapConvert.ByteToBitString(amfUe.Kgnb, 256)
+	case models.ACCESSTYPE_NON_3_GPP_ACCESS:
+		securityKey.Value = ngapConvert.ByteToBitString(amfUe.Kn3iwf, 256)
+	}
+
+	ueContextModificationRequestIEs.List = append(ueContextModificationRequestIEs.List, ie)
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
+
 func BuildErrorIndication(amfUeNgapId, ranUeNgapId *int64, cause *ngapType.Cause,
 	criticalityDiagnostics *ngapType.CriticalityDiagnostics,
 ) ([]byte, error) {
@@ -1074,15 +1364,11 @@ func BuildInitialContextSetupRequest(
 	initialContextSetupRequestIEs.List = append(initialContextSetupRequestIEs.List, ie)
 
 	// Trace Activation (optional)
-	if amfUe.TraceData != nil {
+	if traceActivation, ok := buildTraceActivation(amfUe); ok {
 		ie = ngapType.InitialContextSetupRequestIEs{}
 		ie.Id.Value = ngapType.ProtocolIEIDTraceActivation
 		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
 		ie.Value.Present = ngapType.InitialContextSetupRequestIEsPresentTraceActivation
-		ie.Value.TraceActivation = new(ngapType.TraceActivation)
-		// TS 32.422 4.2.2.9
-		// TODO: AMF allocate Trace Recording Session Reference
-		traceActivation := ngapConvert.TraceDataToNgap(*amfUe.TraceData, ranUe.Trsr)
 		ie.Value.TraceActivation = &traceActivation
 		initialContextSetupRequestIEs.List = append(initialContextSetupRequestIEs.List, ie)
 	}
@@ -1101,8 +1387,16 @@ func BuildInitialContextSetupRequest(
 		initialContextSetupRequestIEs.List = append(initialContextSetupRequestIEs.List, ie)
 	}
 
-	// UE Radio Capability (optional)
-	if amfUe.UeRadioCapability != "" {
+	// UE Radio Capability ID (optional), in place of the UE Radio Capability
+	if uERadioCapabilityID, ok := buildUERadioCapabilityID(amfUe); ok {
+		ie = ngapType.InitialContextSetupRequestIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDUERadioCapabilityID
+		ie.Criticality.Value = ngapType.CriticalityPresentReject
+		ie.Value.Present = ngapType.InitialContextSetupRequestIEsPresentUERadioCapabilityID
+		ie.Value.UERadioCapabilityID = &uERadioCapabilityID
+		initialContextSetupRequestIEs.List = append(initialContextSetupRequestIEs.List, ie)
+	} else if amfUe.UeRadioCapability != "" {
+		// UE Radio Capability (optional)
 		ie = ngapType.InitialContextSetupRequestIEs{}
 		ie.Id.Value = ngapType.ProtocolIEIDUERadioCapability
 		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
@@ -1584,13 +1878,15 @@ func BuildHandoverRequest(ue *context.RanUe, cause ngapType.Cause,
 
 	handoverRequestIEs.List = append(handoverRequestIEs.List, ie)
 
-	// //Core Network Assistance Information(optional)
-	// ie = ngapType.HandoverRequestIEs{}
-	// ie.Id.Value = ngapType.ProtocolIEIDCoreNetworkAssistanceInformation
-	// ie.Criticality.Value = ngapType.CriticalityPresentReject
-	// ie.Value.Present = ngapType.HandoverRequestIEsPresentCoreNetworkAssistanceInformation
-	// ie.Value.CoreNetworkAssistanceInformation = new(ngapType.CoreNetworkAssistanceInformation)
-	// handoverRequestIEs.List = append(handoverRequestIEs.List, ie)
+	// Core Network Assistance Information for RRC INACTIVE (optional)
+	if coreNetworkAssistanceInfo := buildCoreNetworkAssistanceInformationForInactive(amfUe); coreNetworkAssistanceInfo != nil {
+		ie = ngapType.HandoverRequestIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDCoreNetworkAssistanceInformationForInactive
+		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+		ie.Value.Present = ngapType.HandoverRequestIEsPresentCoreNetworkAssistanceInformationForInactive
+		ie.Value.CoreNetworkAssistanceInformationForInactive = coreNetworkAssistanceInfo
+		handoverRequestIEs.List = append(handoverRequestIEs.List, ie)
+	}
 
 	// New Security ContextInd(optional)
 	if nsci {
@@ -1613,6 +1909,25 @@ func BuildHandoverRequest(ue *context.RanUe, cause ngapType.Cause,
 	// handoverRequestIEs.List = append(handoverRequestIEs.List, ie)
 
 	// Trace Activation(optional)
+	if traceActivation, ok := buildTraceActivation(amfUe); ok {
+		ie = ngapType.HandoverRequestIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDTraceActivation
+		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+		ie.Value.Present = ngapType.HandoverRequestIEsPresentTraceActivation
+		ie.Value.TraceActivation = &traceActivation
+		handoverRequestIEs.List = append(handoverRequestIEs.List, ie)
+	}
+
+	// UE Radio Capability ID(optional)
+	if uERadioCapabilityID, ok := buildUERadioCapabilityID(amfUe); ok {
+		ie = ngapType.HandoverRequestIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDUERadioCapabilityID
+		ie.Criticality.Value = ngapType.CriticalityPresentReject
+		ie.Value.Present = ngapType.HandoverRequestIEsPresentUERadioCapabilityID
+		ie.Value.UERadioCapabilityID = &uERadioCapabilityID
+		handoverRequestIEs.List = append(handoverRequestIEs.List, ie)
+	}
+
 	// Masked IMEISV(optional)
 	// Mobility Restriction List(optional)
 	// Location Reporting Request Type(optional)
@@ -2057,8 +2372,9 @@ func BuildPaging(
 		pagingIEs.List = append(pagingIEs.List, ie)
 	}
 
-	// Assistance Data for Paing (optional)
-	if ue.InfoOnRecommendedCellsAndRanNodesForPaging != nil {
+	// Assistance Data for Paging (optional)
+	if ue.InfoOnRecommendedCellsAndRanNodesForPaging != nil &&
+		len(ue.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedCells) > 0 {
 		ie = ngapType.PagingIEs{}
 		ie.Id.Value = ngapType.ProtocolIEIDAssistanceDataForPaging
 		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
@@ -2071,6 +2387,9 @@ func BuildPaging(
 			AssistanceDataForRecommendedCells.RecommendedCellsForPaging.RecommendedCellList
 
 		for _, recommendedCell := range ue.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedCells {
+			if len(recommendedCellList.List) == maxnoofRecommendedCells {
+				break
+			}
 			recommendedCellItem := ngapType.RecommendedCellItem{}
 			switch recommendedCell.NgRanCGI.Present {
 			case context.NgRanCgiPresentNRCGI:
@@ -2108,6 +2427,23 @@ func BuildPaging(
 		pagingIEs.List = append(pagingIEs.List, ie)
 	}
 
+	// NR Paging eDRX Information (optional)
+	if ue.EdrxParameters != nil {
+		ie = ngapType.PagingIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDNRPagingeDRXInformation
+		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+		ie.Value.Present = ngapType.PagingIEsPresentNRPagingeDRXInformation
+		ie.Value.NRPagingeDRXInformation = &ngapType.NRPagingeDRXInformation{
+			NRPagingEDRXCycle: ngapType.NRPagingEDRXCycle{
+				Value: aper.Enumerated(ue.EdrxParameters.EdrxValue),
+			},
+			NRPagingTimeWindow: &ngapType.NRPagingTimeWindow{
+				Value: aper.Enumerated(ue.EdrxParameters.PagingTimeWindow),
+			},
+		}
+		pagingIEs.List = append(pagingIEs.List, ie)
+	}
+
 	IncrementNGAPMsgCount(pdu)
 	return ngap.Encoder(pdu)
 }
@@ -2338,6 +2674,103 @@ func BuildAMFStatusIndication(unavailableGUAMIList ngapType.UnavailableGUAMIList
 	return ngap.Encoder(pdu)
 }
 
+// TS 38.413 8.7.6: the AMF sends Overload Start to indicate to the NG-RAN node that
+// it is overloaded. trafficLoadReduction is the percentage of the signalling traffic
+// that the NG-RAN node is asked to reject (1-99); 0 omits the IE.
+// overloadedSnssaiList, when present, applies the same action per S-NSSAI.
+func BuildOverloadStart(
+	overloadAction aper.Enumerated, trafficLoadReduction int64, overloadedSnssaiList []models.Snssai,
+) ([]byte, error) {
+	var pdu ngapType.NGAPPDU
+
+	pdu.Present = ngapType.NGAPPDUPresentInitiatingMessage
+	pdu.InitiatingMessage = new(ngapType.InitiatingMessage)
+
+	initiatingMessage := pdu.InitiatingMessage
+	initiatingMessage.ProcedureCode.Value = ngapType.ProcedureCodeOverloadStart
+	initiatingMessage.Criticality.Value = ngapType.CriticalityPresentIgnore
+
+	initiatingMessage.Value.Present = ngapType.InitiatingMessagePresentOverloadStart
+	initiatingMessage.Value.OverloadStart = new(ngapType.OverloadStart)
+
+	overloadStart := initiatingMessage.Value.OverloadStart
+	overloadStartIEs := &overloadStart.ProtocolIEs
+
+	// AMF Overload Response (optional)
+	ie := ngapType.OverloadStartIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDAMFOverloadResponse
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.OverloadStartIEsPresentAMFOverloadResponse
+	ie.Value.AMFOverloadResponse = buildOverloadResponse(overloadAction)
+
+	overloadStartIEs.List = append(overloadStartIEs.List, ie)
+
+	// AMF Traffic Load Reduction Indication (optional)
+	if trafficLoadReduction != 0 {
+		ie = ngapType.OverloadStartIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDAMFTrafficLoadReductionIndication
+		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+		ie.Value.Present = ngapType.OverloadStartIEsPresentAMFTrafficLoadReductionIndication
+		ie.Value.AMFTrafficLoadReductionIndication = new(ngapType.TrafficLoadReductionIndication)
+		ie.Value.AMFTrafficLoadReductionIndication.Value = trafficLoadReduction
+
+		overloadStartIEs.List = append(overloadStartIEs.List, ie)
+	}
+
+	// Overload Start NSSAI List (optional)
+	if len(overloadedSnssaiList) > 0 {
+		ie = ngapType.OverloadStartIEs{}
+		ie.Id.Value = ngapType.ProtocolIEIDOverloadStartNSSAIList
+		ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+		ie.Value.Present = ngapType.OverloadStartIEsPresentOverloadStartNSSAIList
+		ie.Value.OverloadStartNSSAIList = new(ngapType.OverloadStartNSSAIList)
+
+		item := ngapType.OverloadStartNSSAIItem{}
+		for _, snssai := range overloadedSnssaiList {
+			sliceOverloadItem := ngapType.SliceOverloadItem{}
+			sliceOverloadItem.SNSSAI = ngapConvert.SNssaiToNgap(snssai)
+			item.SliceOverloadList.List = append(item.SliceOverloadList.List, sliceOverloadItem)
+		}
+		item.SliceOverloadResponse = buildOverloadResponse(overloadAction)
+		if trafficLoadReduction != 0 {
+			item.SliceTrafficLoadReductionIndication = new(ngapType.TrafficLoadReductionIndication)
+			item.SliceTrafficLoadReductionIndication.Value = trafficLoadReduction
+		}
+		ie.Value.OverloadStartNSSAIList.List = append(ie.Value.OverloadStartNSSAIList.List, item)
+
+		overloadStartIEs.List = append(overloadStartIEs.List, ie)
+	}
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
+
+func buildOverloadResponse(overloadAction aper.Enumerated) *ngapType.OverloadResponse {
+	overloadResponse := new(ngapType.OverloadResponse)
+	overloadResponse.Present = ngapType.OverloadResponsePresentOverloadAction
+	overloadResponse.OverloadAction = new(ngapType.OverloadAction)
+	overloadResponse.OverloadAction.Value = overloadAction
+	return overloadResponse
+}
+
+// TS 38.413 8.7.7: the AMF sends Overload Stop once it is no longer overloaded
+func BuildOverloadStop() ([]byte, error) {
+	var pdu ngapType.NGAPPDU
+
+	pdu.Present = ngapType.NGAPPDUPresentInitiatingMessage
+	pdu.InitiatingMessage = new(ngapType.InitiatingMessage)
+
+	initiatingMessage := pdu.InitiatingMessage
+	initiatingMessage.ProcedureCode.Value = ngapType.ProcedureCodeOverloadStop
+	initiatingMessage.Criticality.Value = ngapType.CriticalityPresentReject
+
+	initiatingMessage.Value.Present = ngapType.InitiatingMessagePresentOverloadStop
+	initiatingMessage.Value.OverloadStop = new(ngapType.OverloadStop)
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
+
 func BuildDownlinkRanConfigurationTransfer(
 	sONConfigurationTransfer *ngapType.SONConfigurationTransfer,
 ) ([]byte, error) {
@@ -2462,3 +2895,153 @@ func BuildLocationReportingControl(
 	IncrementNGAPMsgCount(pdu)
 	return ngap.Encoder(pdu)
 }
+
+// BuildTraceStart builds the Trace Start message that activates trace of the UE at the NG-RAN
+// node (TS 38.413 8.2.1, TS 32.422 4.2.2.9)
+func BuildTraceStart(ue *context.RanUe, traceActivation ngapType.TraceActivation) ([]byte, error) {
+	var pdu ngapType.NGAPPDU
+
+	pdu.Present = ngapType.NGAPPDUPresentInitiatingMessage
+	pdu.InitiatingMessage = new(ngapType.InitiatingMessage)
+
+	initiatingMessage := pdu.InitiatingMessage
+	initiatingMessage.ProcedureCode.Value = ngapType.ProcedureCodeTraceStart
+	initiatingMessage.Criticality.Value = ngapType.CriticalityPresentIgnore
+
+	initiatingMessage.Value.Present = ngapType.InitiatingMessagePresentTraceStart
+	initiatingMessage.Value.TraceStart = new(ngapType.TraceStart)
+
+	traceStart := initiatingMessage.Value.TraceStart
+	traceStartIEs := &traceStart.ProtocolIEs
+
+	// AMF UE NGAP ID
+	ie := ngapType.TraceStartIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDAMFUENGAPID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.TraceStartIEsPresentAMFUENGAPID
+	ie.Value.AMFUENGAPID = new(ngapType.AMFUENGAPID)
+
+	aMFUENGAPID := ie.Value.AMFUENGAPID
+	aMFUENGAPID.Value = ue.AmfUeNgapId
+
+	traceStartIEs.List = append(traceStartIEs.List, ie)
+
+	// RAN UE NGAP ID
+	ie = ngapType.TraceStartIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDRANUENGAPID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.TraceStartIEsPresentRANUENGAPID
+	ie.Value.RANUENGAPID = new(ngapType.RANUENGAPID)
+
+	rANUENGAPID := ie.Value.RANUENGAPID
+	rANUENGAPID.Value = ue.RanUeNgapId
+
+	traceStartIEs.List = append(traceStartIEs.List, ie)
+
+	// Trace Activation
+	ie = ngapType.TraceStartIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDTraceActivation
+	ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+	ie.Value.Present = ngapType.TraceStartIEsPresentTraceActivation
+	ie.Value.TraceActivation = &traceActivation
+
+	traceStartIEs.List = append(traceStartIEs.List, ie)
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
+
+// BuildDeactivateTrace builds the Deactivate Trace message that ends the trace session
+// identified by nGRANTraceID at the NG-RAN node (TS 38.413 8.2.3)
+func BuildDeactivateTrace(ue *context.RanUe, nGRANTraceID ngapType.NGRANTraceID) ([]byte, error) {
+	var pdu ngapType.NGAPPDU
+
+	pdu.Present = ngapType.NGAPPDUPresentInitiatingMessage
+	pdu.InitiatingMessage = new(ngapType.InitiatingMessage)
+
+	initiatingMessage := pdu.InitiatingMessage
+	initiatingMessage.ProcedureCode.Value = ngapType.ProcedureCodeDeactivateTrace
+	initiatingMessage.Criticality.Value = ngapType.CriticalityPresentIgnore
+
+	initiatingMessage.Value.Present = ngapType.InitiatingMessagePresentDeactivateTrace
+	initiatingMessage.Value.DeactivateTrace = new(ngapType.DeactivateTrace)
+
+	deactivateTrace := initiatingMessage.Value.DeactivateTrace
+	deactivateTraceIEs := &deactivateTrace.ProtocolIEs
+
+	// AMF UE NGAP ID
+	ie := ngapType.DeactivateTraceIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDAMFUENGAPID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.DeactivateTraceIEsPresentAMFUENGAPID
+	ie.Value.AMFUENGAPID = new(ngapType.AMFUENGAPID)
+
+	aMFUENGAPID := ie.Value.AMFUENGAPID
+	aMFUENGAPID.Value = ue.AmfUeNgapId
+
+	deactivateTraceIEs.List = append(deactivateTraceIEs.List, ie)
+
+	// RAN UE NGAP ID
+	ie = ngapType.DeactivateTraceIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDRANUENGAPID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.DeactivateTraceIEsPresentRANUENGAPID
+	ie.Value.RANUENGAPID = new(ngapType.RANUENGAPID)
+
+	rANUENGAPID := ie.Value.RANUENGAPID
+	rANUENGAPID.Value = ue.RanUeNgapId
+
+	deactivateTraceIEs.List = append(deactivateTraceIEs.List, ie)
+
+	// NG-RAN Trace ID
+	ie = ngapType.DeactivateTraceIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDNGRANTraceID
+	ie.Criticality.Value = ngapType.CriticalityPresentIgnore
+	ie.Value.Present = ngapType.DeactivateTraceIEsPresentNGRANTraceID
+	ie.Value.NGRANTraceID = &nGRANTraceID
+
+	deactivateTraceIEs.List = append(deactivateTraceIEs.List, ie)
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
+
+// BuildUERadioCapabilityIDMappingResponse builds the response to the UE Radio Capability ID
+// Mapping procedure with the UE radio capability of the UE radio capability ID (TS 38.413 8.14.4)
+func BuildUERadioCapabilityIDMappingResponse(uERadioCapabilityID ngapType.UERadioCapabilityID,
+	uERadioCapability []byte,
+) ([]byte, error) {
+	var pdu ngapType.NGAPPDU
+	pdu.Present = ngapType.NGAPPDUPresentSuccessfulOutcome
+	pdu.SuccessfulOutcome = new(ngapType.SuccessfulOutcome)
+
+	successfulOutcome := pdu.SuccessfulOutcome
+	successfulOutcome.ProcedureCode.Value = ngapType.ProcedureCodeUERadioCapabilityIDMapping
+	successfulOutcome.Criticality.Value = ngapType.CriticalityPresentReject
+	successfulOutcome.Value.Present = ngapType.SuccessfulOutcomePresentUERadioCapabilityIDMapping
+	successfulOutcome.Value.UERadioCapabilityIDMapping = new(ngapType.UERadioCapabilityIDMappingResponse)
+
+	uERadioCapabilityIDMappingResponse := successfulOutcome.Value.UERadioCapabilityIDMapping
+	uERadioCapabilityIDMappingResponseIEs := &uERadioCapabilityIDMappingResponse.ProtocolIEs
+
+	// UE Radio Capability ID
+	ie := ngapType.UERadioCapabilityIDMappingResponseIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDUERadioCapabilityID
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.UERadioCapabilityIDMappingResponseIEsPresentUERadioCapabilityID
+	ie.Value.UERadioCapabilityID = &uERadioCapabilityID
+
+	uERadioCapabilityIDMappingResponseIEs.List = append(uERadioCapabilityIDMappingResponseIEs.List, ie)
+
+	// UE Radio Capability
+	ie = ngapType.UERadioCapabilityIDMappingResponseIEs{}
+	ie.Id.Value = ngapType.ProtocolIEIDUERadioCapability
+	ie.Criticality.Value = ngapType.CriticalityPresentReject
+	ie.Value.Present = ngapType.UERadioCapabilityIDMappingResponseIEsPresentUERadioCapability
+	ie.Value.UERadioCapability = &ngapType.UERadioCapability{Value: uERadioCapability}
+
+	uERadioCapabilityIDMappingResponseIEs.List = append(uERadioCapabilityIDMappingResponseIEs.List, ie)
+
+	IncrementNGAPMsgCount(pdu)
+	return ngap.Encoder(pdu)
+}
@@ -9,7 +9,10 @@ import (
 	"testing"
 
 	"github.com/omec-project/amf/context"
+	"github.com/omec-project/nas/v2/nasConvert"
+	"github.com/omec-project/nas/v2/nasMessage"
 	"github.com/omec-project/nas/v2/nasType"
+	"github.com/omec-project/ngap/v2"
 	"github.com/omec-project/ngap/v2/ngapConvert"
 	"github.com/omec-project/ngap/v2/ngapType"
 	"github.com/omec-project/openapi/v2"
@@ -183,3 +186,324 @@ func TestBuildPagingRejectsInvalidGuti(t *testing.T) {
 		t.Fatal("BuildPaging with empty GUTI: want error, got nil")
 	}
 }
+
+func TestBuildOverloadStart(t *testing.T) {
+	snssaiList := []models.Snssai{
+		{Sst: 1, Sd: openapi.PtrString("010203")},
+		{Sst: 2},
+	}
+	pkt, err := BuildOverloadStart(ngapType.OverloadActionPresentRejectNonEmergencyMoDt, 30, snssaiList)
+	if err != nil {
+		t.Fatalf("build OverloadStart failed: %v", err)
+	}
+
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode OverloadStart failed: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.Value.OverloadStart == nil {
+		t.Fatalf("expected OverloadStart initiating message")
+	}
+
+	var reduction int64
+	var nssaiItems int
+	for _, ie := range pdu.InitiatingMessage.Value.OverloadStart.ProtocolIEs.List {
+		switch ie.Id.Value {
+		case ngapType.ProtocolIEIDAMFTrafficLoadReductionIndication:
+			reduction = ie.Value.AMFTrafficLoadReductionIndication.Value
+		case ngapType.ProtocolIEIDOverloadStartNSSAIList:
+			nssaiItems = len(ie.Value.OverloadStartNSSAIList.List[0].SliceOverloadList.List)
+		}
+	}
+	if reduction != 30 {
+		t.Errorf("expected traffic load reduction 30, got %d", reduction)
+	}
+	if nssaiItems != len(snssaiList) {
+		t.Errorf("expected %d overloaded S-NSSAIs, got %d", len(snssaiList), nssaiItems)
+	}
+}
+
+func TestBuildOverloadStop(t *testing.T) {
+	pkt, err := BuildOverloadStop()
+	if err != nil {
+		t.Fatalf("build OverloadStop failed: %v", err)
+	}
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode OverloadStop failed: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.ProcedureCode.Value != ngapType.ProcedureCodeOverloadStop {
+		t.Errorf("expected OverloadStop initiating message")
+	}
+}
+
+func TestBuildNGReset(t *testing.T) {
+	cause := ngapType.Cause{
+		Present: ngapType.CausePresentMisc,
+		Misc:    &ngapType.CauseMisc{Value: ngapType.CauseMiscPresentOmIntervention},
+	}
+	pkt, err := BuildNGReset(cause)
+	if err != nil {
+		t.Fatalf("build NGReset failed: %v", err)
+	}
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode NGReset failed: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.Value.NGReset == nil {
+		t.Fatalf("expected NGReset initiating message")
+	}
+	var decodedCause *ngapType.Cause
+	var resetType *ngapType.ResetType
+	for _, ie := range pdu.InitiatingMessage.Value.NGReset.ProtocolIEs.List {
+		switch ie.Id.Value {
+		case ngapType.ProtocolIEIDCause:
+			decodedCause = ie.Value.Cause
+		case ngapType.ProtocolIEIDResetType:
+			resetType = ie.Value.ResetType
+		}
+	}
+	if decodedCause == nil || decodedCause.Misc == nil || decodedCause.Misc.Value != ngapType.CauseMiscPresentOmIntervention {
+		t.Errorf("expected O&M intervention cause, got %+v", decodedCause)
+	}
+	if resetType == nil || resetType.Present != ngapType.ResetTypePresentNGInterface {
+		t.Errorf("expected reset of the whole NG interface, got %+v", resetType)
+	}
+}
+
+func TestBuildPagingIncludesEdrxInformation(t *testing.T) {
+	ue := &context.AmfUe{
+		Guti: "208930000ff00000001",
+		RegistrationArea: map[models.AccessType][]models.Tai{
+			models.ACCESSTYPE__3_GPP_ACCESS: {{PlmnId: models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"}},
+		},
+		EdrxParameters: &context.EdrxParameters{EdrxValue: 5, PagingTimeWindow: 3},
+	}
+	pkt, err := BuildPaging(ue, nil, false)
+	if err != nil {
+		t.Fatalf("build Paging failed: %v", err)
+	}
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode Paging failed: %v", err)
+	}
+	var edrx *ngapType.NRPagingeDRXInformation
+	for _, ie := range pdu.InitiatingMessage.Value.Paging.ProtocolIEs.List {
+		if ie.Id.Value == ngapType.ProtocolIEIDNRPagingeDRXInformation {
+			edrx = ie.Value.NRPagingeDRXInformation
+		}
+	}
+	if edrx == nil {
+		t.Fatal("expected NR Paging eDRX Information IE")
+	}
+	if edrx.NRPagingEDRXCycle.Value != 5 || edrx.NRPagingTimeWindow == nil || edrx.NRPagingTimeWindow.Value != 3 {
+		t.Errorf("expected eDRX cycle 5 and PTW 3, got %+v", edrx)
+	}
+}
+
+func TestBuildTraceStartAndDeactivateTrace(t *testing.T) {
+	ue := newRanUeForAllowedNSSAITest(models.ACCESSTYPE__3_GPP_ACCESS)
+	ue.AmfUe.TraceData = &models.TraceData{
+		TraceRef:                 "00101-abcdef",
+		TraceDepth:               models.TRACEDEPTH_MAXIMUM,
+		CollectionEntityIpv4Addr: openapi.PtrString("10.0.0.1"),
+	}
+	ue.AmfUe.TraceRecordingSessionRef = "0102"
+
+	traceActivation, ok := buildTraceActivation(ue.AmfUe)
+	if !ok {
+		t.Fatal("expected a valid Trace Activation")
+	}
+	pkt, err := BuildTraceStart(ue, traceActivation)
+	if err != nil {
+		t.Fatalf("build TraceStart failed: %v", err)
+	}
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode TraceStart failed: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.Value.TraceStart == nil {
+		t.Fatal("expected TraceStart initiating message")
+	}
+	var nGRANTraceID []byte
+	for _, ie := range pdu.InitiatingMessage.Value.TraceStart.ProtocolIEs.List {
+		if ie.Id.Value == ngapType.ProtocolIEIDTraceActivation {
+			nGRANTraceID = ie.Value.TraceActivation.NGRANTraceID.Value
+		}
+	}
+	if got := fmt.Sprintf("%x", nGRANTraceID); got != "00f110abcdef0102" {
+		t.Fatalf("unexpected NG-RAN Trace ID %s", got)
+	}
+
+	pkt, err = BuildDeactivateTrace(ue, traceActivation.NGRANTraceID)
+	if err != nil {
+		t.Fatalf("build DeactivateTrace failed: %v", err)
+	}
+	pdu, err = ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode DeactivateTrace failed: %v", err)
+	}
+	if pdu.InitiatingMessage == nil || pdu.InitiatingMessage.Value.DeactivateTrace == nil ||
+		len(pdu.InitiatingMessage.Value.DeactivateTrace.ProtocolIEs.List) != 3 {
+		t.Fatal("expected DeactivateTrace initiating message with three IEs")
+	}
+
+	ue.AmfUe.TraceRecordingSessionRef = ""
+	if _, ok = buildTraceActivation(ue.AmfUe); ok {
+		t.Error("expected Trace Activation without TRSR to be rejected")
+	}
+}
+
+func TestBuildUERadioCapabilityIDMappingResponse(t *testing.T) {
+	uERadioCapabilityID := ngapType.UERadioCapabilityID{Value: []byte{0x10, 0xf8, 0x39, 0x01}}
+	pkt, err := BuildUERadioCapabilityIDMappingResponse(uERadioCapabilityID, []byte{0x04, 0x01})
+	if err != nil {
+		t.Fatalf("build UERadioCapabilityIDMappingResponse failed: %v", err)
+	}
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode UERadioCapabilityIDMappingResponse failed: %v", err)
+	}
+	if pdu.SuccessfulOutcome == nil || pdu.SuccessfulOutcome.Value.UERadioCapabilityIDMapping == nil {
+		t.Fatal("expected UERadioCapabilityIDMapping successful outcome")
+	}
+	var id, capability []byte
+	for _, ie := range pdu.SuccessfulOutcome.Value.UERadioCapabilityIDMapping.ProtocolIEs.List {
+		switch ie.Id.Value {
+		case ngapType.ProtocolIEIDUERadioCapabilityID:
+			id = ie.Value.UERadioCapabilityID.Value
+		case ngapType.ProtocolIEIDUERadioCapability:
+			capability = ie.Value.UERadioCapability.Value
+		}
+	}
+	if fmt.Sprintf("%x/%x", id, capability) != "10f83901/0401" {
+		t.Errorf("unexpected UE radio capability ID %x and capability %x", id, capability)
+	}
+}
+
+func TestBuildUERadioCapabilityID(t *testing.T) {
+	self := context.AMF_Self()
+	origRacsSupportedByRan := self.RacsSupportedByRan
+	t.Cleanup(func() { self.RacsSupportedByRan = origRacsSupportedByRan })
+
+	amfUe := &context.AmfUe{UeRadioCapabilityId: "10f8390102030405"}
+	self.RacsSupportedByRan = false
+	if _, ok := buildUERadioCapabilityID(amfUe); ok {
+		t.Error("expected no UE Radio Capability ID without RACS support")
+	}
+	self.RacsSupportedByRan = true
+	if id, ok := buildUERadioCapabilityID(amfUe); !ok || fmt.Sprintf("%x", id.Value) != amfUe.UeRadioCapabilityId {
+		t.Errorf("unexpected UE Radio Capability ID %x", id.Value)
+	}
+}
+
+func TestBuildPagingIncludesRecommendedCells(t *testing.T) {
+	ue := &context.AmfUe{
+		Guti: "208930000ff00000001",
+		RegistrationArea: map[models.AccessType][]models.Tai{
+			models.ACCESSTYPE__3_GPP_ACCESS: {{PlmnId: models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"}},
+		},
+		InfoOnRecommendedCellsAndRanNodesForPaging: &context.InfoOnRecommendedCellsAndRanNodesForPaging{},
+	}
+	for range maxnoofRecommendedCells + 1 {
+		ue.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedCells = append(
+			ue.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedCells, context.RecommendedCell{
+				NgRanCGI: context.NGRANCGI{
+					Present: context.NgRanCgiPresentNRCGI,
+					NRCGI:   &models.Ncgi{PlmnId: models.PlmnId{Mcc: "208", Mnc: "93"}, NrCellId: "000001010"},
+				},
+			})
+	}
+	pkt, err := BuildPaging(ue, nil, false)
+	if err != nil {
+		t.Fatalf("build Paging failed: %v", err)
+	}
+	pdu, err := ngap.Decoder(pkt)
+	if err != nil {
+		t.Fatalf("decode Paging failed: %v", err)
+	}
+	var assistanceData *ngapType.AssistanceDataForPaging
+	for _, ie := range pdu.InitiatingMessage.Value.Paging.ProtocolIEs.List {
+		if ie.Id.Value == ngapType.ProtocolIEIDAssistanceDataForPaging {
+			assistanceData = ie.Value.AssistanceDataForPaging
+		}
+	}
+	if assistanceData == nil || assistanceData.AssistanceDataForRecommendedCells == nil {
+		t.Fatal("expected Assistance Data for Paging IE")
+	}
+	if cells := assistanceData.AssistanceDataForRecommendedCells.RecommendedCellsForPaging.RecommendedCellList.List; len(cells) !=
+		maxnoofRecommendedCells {
+		t.Errorf("expected %d recommended cells, got %d", maxnoofRecommendedCells, len(cells))
+	}
+
+	ue.InfoOnRecommendedCellsAndRanNodesForPaging.RecommendedCells = nil
+	if _, err = BuildPaging(ue, nil, false); err != nil {
+		t.Errorf("expected Paging without recommended cells, got error: %v", err)
+	}
+}
+
+func TestBuildCoreNetworkAssistanceInformationForInactive(t *testing.T) {
+	stationary := models.STATIONARYINDICATION_STATIONARY
+	ue := &context.AmfUe{
+		Tmsi:          0x12345,
+		UESpecificDRX: nasMessage.DRXcycleParameterT64,
+		T3512Value:    3600,
+		MicoMode:      true,
+		RegistrationArea: map[models.AccessType][]models.Tai{
+			models.ACCESSTYPE__3_GPP_ACCESS: {{PlmnId: models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"}},
+		},
+		AccessAndMobilitySubscriptionData: &models.AccessAndMobilitySubscriptionData{
+			ExpectedUeBehaviourList: &models.ExpectedUeBehaviourData{
+				StationaryIndication:      &stationary,
+				CommunicationDurationTime: openapi.PtrInt32(35),
+				PeriodicTime:              openapi.PtrInt32(600),
+			},
+		},
+	}
+
+	info := buildCoreNetworkAssistanceInformationForInactive(ue)
+	if info == nil {
+		t.Fatal("expected Core Network Assistance Information")
+	}
+	// 0x12345 mod 1024 = 0x345
+	if index := info.UEIdentityIndexValue.IndexLength10; index == nil || index.BitLength != 10 ||
+		index.Bytes[0] != 0xd1 || index.Bytes[1] != 0x40 {
+		t.Errorf("unexpected UE Identity Index Value: %+v", index)
+	}
+	if info.UESpecificDRX == nil || info.UESpecificDRX.Value != ngapType.PagingDRXPresentV64 {
+		t.Errorf("unexpected UE Specific DRX: %+v", info.UESpecificDRX)
+	}
+	if timer := info.PeriodicRegistrationUpdateTimer.Value; timer.BitLength != 8 ||
+		timer.Bytes[0] != nasConvert.GPRSTimer3ToNas(3600) {
+		t.Errorf("unexpected Periodic Registration Update Timer: %+v", timer)
+	}
+	if info.MICOModeIndication == nil {
+		t.Error("expected MICO Mode Indication")
+	}
+	if len(info.TAIListForInactive.List) != 1 {
+		t.Errorf("expected 1 TAI for RRC Inactive, got %d", len(info.TAIListForInactive.List))
+	}
+	behaviour := info.ExpectedUEBehaviour
+	if behaviour == nil || behaviour.ExpectedUEMobility == nil ||
+		behaviour.ExpectedUEMobility.Value != ngapType.ExpectedUEMobilityPresentStationary {
+		t.Fatalf("unexpected Expected UE Behaviour: %+v", behaviour)
+	}
+	activity := behaviour.ExpectedUEActivityBehaviour
+	if activity.ExpectedActivityPeriod.Value != 40 || activity.ExpectedIdlePeriod.Value != 181 {
+		t.Errorf("unexpected Expected UE Activity Behaviour: %+v %+v",
+			activity.ExpectedActivityPeriod, activity.ExpectedIdlePeriod)
+	}
+
+	ue.RegistrationArea = map[models.AccessType][]models.Tai{}
+	if buildCoreNetworkAssistanceInformationForInactive(ue) != nil {
+		t.Error("expected no Core Network Assistance Information without registration area")
+	}
+}
+
+func TestExpectedPeriodToNgap(t *testing.T) {
+	for seconds, expected := range map[int32]int64{0: 1, 30: 30, 31: 40, 180: 180, 181: 181, 3600: 181} {
+		if period := expectedPeriodToNgap(seconds); period != expected {
+			t.Errorf("expectedPeriodToNgap(%d) = %d, expected %d", seconds, period, expected)
+		}
+	}
+}
@@ -159,7 +159,10 @@ func BuildIEMobilityRestrictionList(ue *context.AmfUe) ngapType.MobilityRestrict
 	return mobilityRestrictionList
 }
 
-func BuildUnavailableGUAMIList(guamiList []models.Guami) (unavailableGUAMIList ngapType.UnavailableGUAMIList) {
+// backupAmfName, if not empty, is the AMF NG-RAN should select for UEs served by the unavailable GUAMIs
+func BuildUnavailableGUAMIList(guamiList []models.Guami, backupAmfName string) (
+	unavailableGUAMIList ngapType.UnavailableGUAMIList,
+) {
 	for _, guami := range guamiList {
 		item := ngapType.UnavailableGUAMIItem{}
 		plmnId := models.PlmnId{
@@ -171,7 +174,10 @@ func BuildUnavailableGUAMIList(guamiList []models.Guami) (unavailableGUAMIList n
 		item.GUAMI.AMFRegionID.Value = regionId
 		item.GUAMI.AMFSetID.Value = setId
 		item.GUAMI.AMFPointer.Value = ptrId
-		// TODO: item.TimerApproachForGUAMIRemoval and item.BackupAMFName not support yet
+		// TODO: item.TimerApproachForGUAMIRemoval not support yet
+		if backupAmfName != "" {
+			item.BackupAMFName = &ngapType.AMFName{Value: backupAmfName}
+		}
 		unavailableGUAMIList.List = append(unavailableGUAMIList.List, item)
 	}
 	return
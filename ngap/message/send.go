@@ -6,18 +6,28 @@
 package message
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/factory"
 	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/metrics"
 	"github.com/omec-project/amf/producer/callback"
 	"github.com/omec-project/amf/protos/sdcoreAmfServer"
+	"github.com/omec-project/amf/tracerecording"
 	"github.com/omec-project/ngap/v2/aper"
+	"github.com/omec-project/ngap/v2/ngapConvert"
 	"github.com/omec-project/ngap/v2/ngapType"
 	"github.com/omec-project/openapi/v2/models"
 )
 
 func SendToRan(ran *context.AmfRan, packet []byte) {
+	sendToRan(ran, nil, packet)
+}
+
+// sendToRan sends packet to ran; ranUe is the UE of a UE-associated NGAP message, nil otherwise.
+func sendToRan(ran *context.AmfRan, ranUe *context.RanUe, packet []byte) {
 	defer func() {
 		err := recover()
 		if err != nil {
@@ -34,6 +44,7 @@ func SendToRan(ran *context.AmfRan, packet []byte) {
 		ran.Log.Errorln("packet len is 0")
 		return
 	}
+	ran.CaptureNgap(ranUe, tracerecording.Downlink, packet)
 
 	if context.AMF_Self().EnableSctpLb {
 		msg := &sdcoreAmfServer.AmfMessage{VerboseMsg: "Message from AMF"}
@@ -80,9 +91,11 @@ func SendToRanUe(ue *context.RanUe, packet []byte) {
 
 	if ue.AmfUe == nil {
 		ue.Log.Warnln("AmfUe is nil")
+	} else {
+		ue.AmfUe.RecordNgapTrace(tracerecording.Downlink, packet)
 	}
 
-	SendToRan(ran, packet)
+	sendToRan(ran, ue, packet)
 }
 
 func NasSendToRan(ue *context.AmfUe, accessType models.AccessType, packet []byte) {
@@ -128,6 +141,24 @@ func SendNGSetupFailure(ran *context.AmfRan, cause ngapType.Cause) {
 	SendToRan(ran, pkt)
 }
 
+// SendNGReset resets the whole NG interface of ran. The UE-associated NG connections of the
+// RAN are released by the caller, the NG Reset Acknowledge is only logged.
+func SendNGReset(ran *context.AmfRan, cause ngapType.Cause) {
+	if ran == nil {
+		logger.NgapLog.Errorln("Ran is nil")
+		return
+	}
+
+	ran.Log.Infoln("send NG Reset")
+
+	pkt, err := BuildNGReset(cause)
+	if err != nil {
+		ran.Log.Errorf("build NGReset failed: %s", err.Error())
+		return
+	}
+	SendToRan(ran, pkt)
+}
+
 func SendNGResetAcknowledge(ran *context.AmfRan, partOfNGInterface *ngapType.UEAssociatedLogicalNGConnectionList,
 	criticalityDiagnostics *ngapType.CriticalityDiagnostics,
 ) {
@@ -148,6 +179,18 @@ func SendNGResetAcknowledge(ran *context.AmfRan, partOfNGInterface *ngapType.UEA
 
 func SendDownlinkNasTransport(ue *context.RanUe, nasPdu []byte,
 	mobilityRestrictionList *ngapType.MobilityRestrictionList,
+) {
+	sendDownlinkNasTransport(ue, nasPdu, mobilityRestrictionList, nil)
+}
+
+// SendN1N2DownlinkNasTransport sends a NAS PDU transferred for origin, which is notified when
+// the NG-RAN reports the PDU as not delivered
+func SendN1N2DownlinkNasTransport(ue *context.RanUe, nasPdu []byte, origin *context.DlNasOrigin) {
+	sendDownlinkNasTransport(ue, nasPdu, nil, origin)
+}
+
+func sendDownlinkNasTransport(ue *context.RanUe, nasPdu []byte,
+	mobilityRestrictionList *ngapType.MobilityRestrictionList, origin *context.DlNasOrigin,
 ) {
 	if ue == nil {
 		logger.NgapLog.Errorln("RanUe is nil")
@@ -165,6 +208,7 @@ func SendDownlinkNasTransport(ue *context.RanUe, nasPdu []byte,
 		ue.Log.Errorf("build DownlinkNasTransport failed: %s", err.Error())
 		return
 	}
+	ue.BufferDlNas(nasPdu, origin)
 	SendToRanUe(ue, pkt)
 }
 
@@ -341,6 +385,15 @@ func SendInitialContextSetupRequest(
 
 	amfUe.RanUe[anType].Log.Infoln("send Initial Context Setup Request")
 
+	if anType == models.ACCESSTYPE__3_GPP_ACCESS {
+		if coreNetworkAssistanceInfo == nil {
+			coreNetworkAssistanceInfo = buildCoreNetworkAssistanceInformationForInactive(amfUe)
+		}
+		if rrcInactiveTransitionReportRequest == nil {
+			rrcInactiveTransitionReportRequest = buildRRCInactiveTransitionReportRequest(amfUe)
+		}
+	}
+
 	if pduSessionResourceSetupRequestList != nil {
 		if len(pduSessionResourceSetupRequestList.List) > context.MaxNumOfPDUSessions {
 			amfUe.RanUe[anType].Log.Errorln("Pdu List out of range")
@@ -416,6 +469,7 @@ func SendHandoverPreparationFailure(sourceUe *context.RanUe, cause ngapType.Caus
 	amfUe.SetOnGoing(sourceUe.Ran.AnType, &context.OnGoingProcedureWithPrio{
 		Procedure: context.OnGoingProcedureNothing,
 	})
+	amfUe.FailProcedure(context.KpiN2Handover, context.KpiCauseTypeNgap, context.KpiNgapCause(&cause))
 	pkt, err := BuildHandoverPreparationFailure(sourceUe, cause, criticalityDiagnostics)
 	if err != nil {
 		sourceUe.Log.Errorf("build HandoverPreparationFailure failed: %s", err.Error())
@@ -512,6 +566,15 @@ func SendPathSwitchRequestAcknowledge(
 
 	ue.Log.Infoln("send Path Switch Request Acknowledge")
 
+	if ue.AmfUe != nil {
+		if coreNetworkAssistanceInformation == nil {
+			coreNetworkAssistanceInformation = buildCoreNetworkAssistanceInformationForInactive(ue.AmfUe)
+		}
+		if rrcInactiveTransitionReportRequest == nil {
+			rrcInactiveTransitionReportRequest = buildRRCInactiveTransitionReportRequest(ue.AmfUe)
+		}
+	}
+
 	if len(pduSessionResourceSwitchedList.List) > context.MaxNumOfPDUSessions {
 		ue.Log.Errorln("Pdu List out of range")
 		return
@@ -589,57 +652,105 @@ func SendDownlinkRanStatusTransfer(ue *context.RanUe, container ngapType.RANStat
 // is associated with non-3GPP access, the AMF sends a Paging message with associated access "non-3GPP" to
 // NG-RAN node(s) via 3GPP access.
 // more paging policy with 3gpp/non-3gpp access is described in TS 23.501 5.6.8
+// The NG-RAN nodes paged on each T3513 attempt follow the paging strategy of the AMF.
 func SendPaging(ue *context.AmfUe, ngapBuf []byte) {
-	// var pagingPriority *ngapType.PagingPriority
 	if ue == nil {
 		logger.NgapLog.Errorln("AmfUe is nil")
 		return
 	}
 
-	// if ppi != nil {
-	// pagingPriority = new(ngapType.PagingPriority)
-	// pagingPriority.Value = aper.Enumerated(*ppi)
-	// }
-	// pkt, err := BuildPaging(ue, pagingPriority, pagingOriginNon3GPP)
-	// if err != nil {
-	// 	ngaplog.Errorf("build Paging failed: %s", err.Error())
-	// }
-	taiList := ue.RegistrationArea[models.ACCESSTYPE__3_GPP_ACCESS]
-	context.AMF_Self().AmfRanPool.Range(func(key, value interface{}) bool {
-		ran := value.(*context.AmfRan)
-		for _, item := range ran.SupportedTAListSnapshot() {
-			if context.InTaiList(item.Tai, taiList) {
-				ue.GmmLog.Infof("send Paging to TAI(%+v, Tac:%+v)",
-					item.Tai.PlmnId, item.Tai.Tac)
-				SendToRan(ran, ngapBuf)
-				break
-			}
+	highPriority := context.AMF_Self().PagingHighPriority(ue)
+	ue.PagingState = &context.PagingState{HighPriority: highPriority}
+	ue.StartProcedure(context.KpiPaging)
+	pageStage(ue, ngapBuf, 0, context.TimerState{})
+}
+
+// pageStage sends Paging to the NG-RAN nodes of the first paging stage from stage on that has
+// any, skipping the stages without, and runs T3513 for the attempts of the stage. The last
+// stage is always used, so that the paging procedure ends as without paging stages. With T3513
+// disabled the NG-RAN nodes of all stages from stage on are paged at once. A state
+// restored from the DB continues the attempts of the stage without paging again.
+func pageStage(ue *context.AmfUe, ngapBuf []byte, stage int, state context.TimerState) {
+	amfSelf := context.AMF_Self()
+	if ue.PagingState == nil {
+		ue.PagingState = &context.PagingState{Stage: stage}
+	}
+	stages := amfSelf.PagingStages(ue.PagingState.HighPriority)
+	if stage >= len(stages) {
+		stage = len(stages) - 1
+	}
+	if !amfSelf.T3513Cfg.Enable {
+		// without T3513 the paging cannot escalate, the NG-RAN nodes of all stages are paged at once
+		targets := amfSelf.PagingTargetsOfStages(ue, stages[stage:])
+		ue.PagingState.Stage, ue.PagingState.Scope = len(stages)-1, stages[len(stages)-1].Scope
+		if state.ExpireTimes == 0 {
+			sendPagingToTargets(ue, ngapBuf, targets)
+		}
+		return
+	}
+	targets := amfSelf.PagingTargets(ue, stages[stage].Scope)
+	for len(targets) == 0 && stage < len(stages)-1 {
+		ue.GmmLog.Debugf("no NG-RAN node to page at %s", stages[stage].Scope)
+		stage++
+		targets = amfSelf.PagingTargets(ue, stages[stage].Scope)
+	}
+	ue.PagingState.Stage, ue.PagingState.Scope = stage, stages[stage].Scope
+
+	if state.ExpireTimes == 0 {
+		sendPagingToTargets(ue, ngapBuf, targets)
+	}
+	startT3513(ue, ngapBuf, stages[stage], state)
+}
+
+func sendPagingToTargets(ue *context.AmfUe, ngapBuf []byte, targets []*context.AmfRan) {
+	if len(targets) == 0 {
+		return
+	}
+	state := ue.PagingState
+	for _, ran := range targets {
+		ue.GmmLog.Infof("send Paging to RAN[%s] (%s)", ran.Name, state.Scope)
+		SendToRan(ran, ngapBuf)
+	}
+	metrics.IncrementPagingAttempts(state.Scope, state.PriorityLabel())
+}
+
+func startT3513(ue *context.AmfUe, ngapBuf []byte, stage factory.PagingStageConfig, state context.TimerState) {
+	state.MaxRetryTimes = int32(stage.Attempts - 1)
+	state.Payload = ngapBuf
+	ue.RecordTimerFlight(context.TimerT3513, context.FlightRecordTimerStarted)
+	ue.T3513 = context.NewTimerFromState(stage.Timeout, state, func(expireTimes int32) {
+		ue.RecordTimerFlight(context.TimerT3513, context.FlightRecordTimerExpired)
+		ue.GmmLog.Warnf("T3513 expires, retransmit Paging (retry: %d)", expireTimes)
+		sendPagingToTargets(ue, ngapBuf, context.AMF_Self().PagingTargets(ue, stage.Scope))
+	}, func() {
+		ue.RecordTimerFlight(context.TimerT3513, context.FlightRecordTimerAborted)
+		pagingState := ue.PagingState
+		if pagingState != nil && pagingState.Stage < len(context.AMF_Self().PagingStages(pagingState.HighPriority))-1 {
+			ue.GmmLog.Warnf("T3513 expires %d times, escalate paging beyond %s", state.MaxRetryTimes+1, stage.Scope)
+			pageStage(ue, ngapBuf, pagingState.Stage+1, context.TimerState{})
+			return
+		}
+		ue.GmmLog.Warnf("T3513 expires %d times, abort paging procedure", state.MaxRetryTimes+1)
+		ue.T3513 = nil // clear the timer
+		ue.PagingState = nil
+		ue.FailProcedure(context.KpiPaging, context.KpiCauseTypeAmf, "t3513_expiry")
+		if ue.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS).Procedure != context.OnGoingProcedureN2Handover {
+			callback.SendN1N2TransferFailureNotification(ue, models.N1N2MESSAGETRANSFERCAUSE_UE_NOT_RESPONDING)
 		}
-		return true
 	})
+}
 
-	if context.AMF_Self().T3513Cfg.Enable {
-		cfg := context.AMF_Self().T3513Cfg
-		ue.T3513 = context.NewTimer(cfg.ExpireTime, cfg.MaxRetryTimes, func(expireTimes int32) {
-			ue.GmmLog.Warnf("T3513 expires, retransmit Paging (retry: %d)", expireTimes)
-			context.AMF_Self().AmfRanPool.Range(func(key, value interface{}) bool {
-				ran := value.(*context.AmfRan)
-				for _, item := range ran.SupportedTAListSnapshot() {
-					if context.InTaiList(item.Tai, taiList) {
-						SendToRan(ran, ngapBuf)
-						break
-					}
-				}
-				return true
-			})
-		}, func() {
-			ue.GmmLog.Warnf("T3513 expires %d times, abort paging procedure", cfg.MaxRetryTimes)
-			ue.T3513 = nil // clear the timer
-			if ue.GetOnGoing(models.ACCESSTYPE__3_GPP_ACCESS).Procedure != context.OnGoingProcedureN2Handover {
-				callback.SendN1N2TransferFailureNotification(ue, models.N1N2MESSAGETRANSFERCAUSE_UE_NOT_RESPONDING)
+// re-arm paging of UE contexts restored from the DB
+func init() {
+	context.RegisterTimerRearmFunc(context.TimerT3513, func(ue *context.AmfUe, state context.TimerState) {
+		if context.AMF_Self().T3513Cfg.Enable {
+			stage := 0
+			if ue.PagingState != nil {
+				stage = ue.PagingState.Stage
 			}
-		})
-	}
+			pageStage(ue, state.Payload, stage, state)
+		}
+	})
 }
 
 // TS 23.502 4.2.2.2.3
@@ -737,6 +848,60 @@ func SendAMFStatusIndication(ran *context.AmfRan, unavailableGUAMIList ngapType.
 	SendToRan(ran, pkt)
 }
 
+func SendOverloadStart(
+	ran *context.AmfRan, overloadAction aper.Enumerated, trafficLoadReduction int64, overloadedSnssaiList []models.Snssai,
+) {
+	if ran == nil {
+		logger.NgapLog.Errorln("Ran is nil")
+		return
+	}
+
+	ran.Log.Infoln("send Overload Start")
+
+	pkt, err := BuildOverloadStart(overloadAction, trafficLoadReduction, overloadedSnssaiList)
+	if err != nil {
+		ran.Log.Errorf("build OverloadStart failed: %s", err.Error())
+		return
+	}
+	SendToRan(ran, pkt)
+}
+
+// SendAmfOverloadStart applies the AMF overload control policy to ran: non-emergency
+// mobile originated traffic is reduced on every S-NSSAI served by the AMF.
+func SendAmfOverloadStart(ran *context.AmfRan) {
+	amfSelf := context.AMF_Self()
+	var snssaiList []models.Snssai
+	seen := make(map[string]struct{})
+	for _, plmnItem := range amfSelf.PlmnSupportList {
+		for _, snssai := range plmnItem.SNssaiList {
+			key := fmt.Sprintf("%d-%s", snssai.Sst, snssai.GetSd())
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			snssaiList = append(snssaiList, snssai)
+		}
+	}
+	SendOverloadStart(ran, ngapType.OverloadActionPresentRejectNonEmergencyMoDt,
+		amfSelf.OverloadControl.TrafficLoadReduction(), snssaiList)
+}
+
+func SendOverloadStop(ran *context.AmfRan) {
+	if ran == nil {
+		logger.NgapLog.Errorln("Ran is nil")
+		return
+	}
+
+	ran.Log.Infoln("send Overload Stop")
+
+	pkt, err := BuildOverloadStop()
+	if err != nil {
+		ran.Log.Errorf("build OverloadStop failed: %s", err.Error())
+		return
+	}
+	SendToRan(ran, pkt)
+}
+
 // SONConfigurationTransfer = sONConfigurationTransfer from uplink Ran Configuration Transfer
 func SendDownlinkRanConfigurationTransfer(ran *context.AmfRan, transfer *ngapType.SONConfigurationTransfer) {
 	if ran == nil {
@@ -796,3 +961,91 @@ func SendLocationReportingControl(
 	}
 	SendToRanUe(ue, pkt)
 }
+
+// SendUERadioCapabilityIDMappingResponse sends the UE radio capability of a UE radio capability
+// ID the NG-RAN node asked for (TS 38.413 8.14.4)
+func SendUERadioCapabilityIDMappingResponse(ran *context.AmfRan, uERadioCapabilityID ngapType.UERadioCapabilityID,
+	uERadioCapability []byte,
+) {
+	if ran == nil {
+		logger.NgapLog.Errorln("Ran is nil")
+		return
+	}
+
+	ran.Log.Infoln("send UE Radio Capability ID Mapping Response")
+
+	pkt, err := BuildUERadioCapabilityIDMappingResponse(uERadioCapabilityID, uERadioCapability)
+	if err != nil {
+		ran.Log.Errorf("build UERadioCapabilityIDMappingResponse failed: %s", err.Error())
+		return
+	}
+	SendToRan(ran, pkt)
+}
+
+// SendTraceStart activates the trace session of the UE at the NG-RAN node that holds its UE
+// context (TS 32.422 4.2.2.9)
+func SendTraceStart(ue *context.RanUe) {
+	if ue == nil {
+		logger.NgapLog.Errorln("RanUe is nil")
+		return
+	}
+	if ue.AmfUe == nil {
+		ue.Log.Errorln("AmfUe is nil")
+		return
+	}
+
+	ue.Log.Infoln("send Trace Start")
+
+	traceActivation, ok := buildTraceActivation(ue.AmfUe)
+	if !ok {
+		ue.Log.Errorln("no valid trace data for Trace Start")
+		return
+	}
+	pkt, err := BuildTraceStart(ue, traceActivation)
+	if err != nil {
+		ue.Log.Errorf("build TraceStart failed: %s", err.Error())
+		return
+	}
+	SendToRanUe(ue, pkt)
+}
+
+// SendUEContextModificationRequest hands the NG-RAN node the security key of the UE's new 5G NAS
+// security context
+func SendUEContextModificationRequest(ue *context.RanUe) {
+	if ue == nil {
+		logger.NgapLog.Errorln("RanUe is nil")
+		return
+	}
+
+	ue.Log.Infoln("send UE Context Modification Request")
+
+	pkt, err := BuildUEContextModificationRequest(ue)
+	if err != nil {
+		ue.Log.Errorf("build UEContextModificationRequest failed: %s", err.Error())
+		return
+	}
+	SendToRanUe(ue, pkt)
+}
+
+// SendDeactivateTrace ends the trace session identified by traceData and the Trace Recording
+// Session Reference trsr at the NG-RAN node
+func SendDeactivateTrace(ue *context.RanUe, traceData models.TraceData, trsr string) {
+	if ue == nil {
+		logger.NgapLog.Errorln("RanUe is nil")
+		return
+	}
+
+	ue.Log.Infoln("send Deactivate Trace")
+
+	traceActivation := ngapConvert.TraceDataToNgap(traceData, trsr)
+	if len(traceActivation.NGRANTraceID.Value) != 8 {
+		ue.Log.Errorf("invalid NG-RAN Trace ID for trace reference %s", traceData.TraceRef)
+		return
+	}
+	pkt, err := BuildDeactivateTrace(ue, traceActivation.NGRANTraceID)
+	if err != nil {
+		ue.Log.Errorf("build DeactivateTrace failed: %s", err.Error())
+		return
+	}
+	SendToRanUe(ue, pkt)
+}
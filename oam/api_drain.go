@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	ctxt "context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/producer"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+)
+
+// HTTPAmfDrain starts the AMF planned removal procedure. The AMF terminates once
+// all UE contexts have been handed over or released.
+func HTTPAmfDrain(c *gin.Context) {
+	setCorsHeader(c)
+
+	if context.AMF_Self().IsDraining() {
+		problemDetails := openapiUtils.ProblemDetails("Conflict", http.StatusConflict, "AMF drain already in progress")
+		c.JSON(http.StatusConflict, problemDetails)
+		return
+	}
+	logger.ProducerLog.Infoln("AMF drain requested by OAM")
+	go producer.AmfDrainProcedure(ctxt.Background())
+	c.JSON(http.StatusAccepted, nil)
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/producer"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+)
+
+// HTTPGetGnbs lists the NG-RAN nodes connected to the AMF.
+func HTTPGetGnbs(c *gin.Context) {
+	setCorsHeader(c)
+
+	rans := context.AMF_Self().AmfRanList()
+	gnbs := make([]context.GnbInfo, 0, len(rans))
+	for _, ran := range rans {
+		gnbs = append(gnbs, ran.Info())
+	}
+	c.JSON(http.StatusOK, gnbs)
+}
+
+// findGnb returns the NG-RAN node of the id path parameter, or responds with 404 Not Found.
+func findGnb(c *gin.Context) (*context.AmfRan, bool) {
+	ran, ok := context.AMF_Self().AmfRanFindByInventoryId(c.Params.ByName("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, openapiUtils.ProblemDetailsContextNotFound("gNB not found"))
+	}
+	return ran, ok
+}
+
+// HTTPGetGnb returns an NG-RAN node with its UE-associated NG connections.
+func HTTPGetGnb(c *gin.Context) {
+	setCorsHeader(c)
+
+	if ran, ok := findGnb(c); ok {
+		c.JSON(http.StatusOK, ran.Detail())
+	}
+}
+
+// HTTPDisconnectGnb administratively disconnects an NG-RAN node, with an NG Reset by default
+// or by closing its SCTP association with ?method=close.
+func HTTPDisconnectGnb(c *gin.Context) {
+	setCorsHeader(c)
+
+	ran, ok := findGnb(c)
+	if !ok {
+		return
+	}
+	method := c.DefaultQuery("method", producer.GnbDisconnectNgReset)
+	err := producer.DisconnectGnb(ran, method)
+	switch {
+	case errors.Is(err, producer.ErrInvalidGnbDisconnect):
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+	case errors.Is(err, producer.ErrGnbAssociationNotOwned):
+		c.JSON(http.StatusConflict, openapiUtils.ProblemDetails("Conflict", http.StatusConflict, err.Error()))
+	case err != nil:
+		logger.ProducerLog.Errorf("disconnect gNB %s failed: %+v", ran.GnbId, err)
+		c.JSON(http.StatusInternalServerError, openapiUtils.ProblemDetailsSystemFailure(err.Error()))
+	default:
+		logger.ProducerLog.Warnf("gNB %s disconnected by OAM: %s", ran.GnbId, method)
+		c.JSON(http.StatusAccepted, nil)
+	}
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/logger"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogLevels reports the log encoding and the log level of every subsystem.
+type LogLevels struct {
+	Encoding string            `json:"encoding"`
+	Levels   map[string]string `json:"levels"`
+}
+
+// SubsystemLogLevel is the log level of one subsystem.
+type SubsystemLogLevel struct {
+	Subsystem string `json:"subsystem,omitempty"`
+	Level     string `json:"level"`
+}
+
+// HTTPGetLogLevels returns the log encoding and the log level of every subsystem.
+func HTTPGetLogLevels(c *gin.Context) {
+	setCorsHeader(c)
+
+	c.JSON(http.StatusOK, LogLevels{
+		Encoding: logger.Encoding(),
+		Levels:   logger.SubsystemLevels(),
+	})
+}
+
+// HTTPSetLogLevel changes the log level of one subsystem at runtime, e.g. to debug NGAP
+// without raising the level of the other subsystems.
+func HTTPSetLogLevel(c *gin.Context) {
+	setCorsHeader(c)
+
+	subsystem := c.Params.ByName("subsystem")
+	if !logger.IsSubsystem(subsystem) {
+		c.JSON(http.StatusNotFound, openapiUtils.ProblemDetails("Not Found", http.StatusNotFound,
+			"unknown log subsystem "+subsystem))
+		return
+	}
+	var req SubsystemLogLevel
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+		return
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+		return
+	}
+	if err = logger.SetSubsystemLevel(subsystem, level); err != nil {
+		c.JSON(http.StatusNotFound, openapiUtils.ProblemDetails("Not Found", http.StatusNotFound, err.Error()))
+		return
+	}
+	logger.ProducerLog.Warnf("log level of %s set to %s by OAM", subsystem, level)
+	c.JSON(http.StatusOK, SubsystemLogLevel{Subsystem: subsystem, Level: level.String()})
+}
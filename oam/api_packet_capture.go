@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/packetcapture"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+)
+
+const pcapngContentType = "application/x-pcapng"
+
+// packetCaptures returns the packet capture manager, or responds with 404 Not Found when
+// packet captures are disabled by the configuration.
+func packetCaptures(c *gin.Context) (*packetcapture.Manager, bool) {
+	manager := context.AMF_Self().PacketCapture
+	if manager == nil {
+		problemDetails := openapiUtils.ProblemDetails("Not Found", http.StatusNotFound, "packet capture is disabled")
+		c.JSON(http.StatusNotFound, problemDetails)
+		return nil, false
+	}
+	return manager, true
+}
+
+// HTTPStartPacketCapture starts capturing the NGAP PDUs of a gNB or a UE, and optionally the
+// plain NAS messages of the UEs, to a pcapng file.
+func HTTPStartPacketCapture(c *gin.Context) {
+	setCorsHeader(c)
+
+	manager, ok := packetCaptures(c)
+	if !ok {
+		return
+	}
+	var req packetcapture.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+		return
+	}
+	info, err := manager.Start(req)
+	switch {
+	case errors.Is(err, packetcapture.ErrInvalidRequest):
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+	case errors.Is(err, packetcapture.ErrNasDecryptionDenied):
+		c.JSON(http.StatusForbidden, openapiUtils.ProblemDetails("Forbidden", http.StatusForbidden, err.Error()))
+	case err != nil:
+		logger.ProducerLog.Errorf("start packet capture failed: %+v", err)
+		c.JSON(http.StatusInternalServerError, openapiUtils.ProblemDetailsSystemFailure(err.Error()))
+	default:
+		logger.ProducerLog.Warnf("packet capture %s started by OAM: gNB[%s] SUPI[%s] decryptNas[%t]",
+			info.Id, info.GnbId, info.Supi, info.DecryptNas)
+		c.JSON(http.StatusCreated, info)
+	}
+}
+
+// HTTPGetPacketCaptures lists the active and stopped packet captures.
+func HTTPGetPacketCaptures(c *gin.Context) {
+	setCorsHeader(c)
+
+	if manager, ok := packetCaptures(c); ok {
+		c.JSON(http.StatusOK, manager.List())
+	}
+}
+
+// HTTPGetPacketCaptureFile returns the pcapng file of a packet capture.
+func HTTPGetPacketCaptureFile(c *gin.Context) {
+	setCorsHeader(c)
+
+	manager, ok := packetCaptures(c)
+	if !ok {
+		return
+	}
+	info, ok := manager.Get(c.Params.ByName("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, openapiUtils.ProblemDetailsContextNotFound("packet capture not found"))
+		return
+	}
+	c.Header("Content-Type", pcapngContentType)
+	c.FileAttachment(info.Path, info.Id+".pcapng")
+}
+
+// HTTPStopPacketCapture stops a packet capture; its file is kept.
+func HTTPStopPacketCapture(c *gin.Context) {
+	setCorsHeader(c)
+
+	manager, ok := packetCaptures(c)
+	if !ok {
+		return
+	}
+	info, ok := manager.Stop(c.Params.ByName("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, openapiUtils.ProblemDetailsContextNotFound("packet capture not found"))
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
@@ -79,7 +79,7 @@ func HTTPAmfInstanceDown(c *gin.Context) {
 	if nfInstanceId, exists := c.Params.Get("nfid"); exists {
 		req.Params["nfid"] = nfInstanceId
 		self := context.AMF_Self()
-		if self.EnableDbStore {
+		if self.Drsm != nil {
 			self.Drsm.DeletePod(nfInstanceId)
 		}
 		c.JSON(http.StatusOK, nil)
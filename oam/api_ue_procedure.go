@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/producer"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+)
+
+// HTTPRunUeProcedure runs a network procedure on a UE and returns its outcome. The
+// optional JSON body carries the parameters of the procedure.
+func HTTPRunUeProcedure(c *gin.Context) {
+	setCorsHeader(c)
+
+	supi := c.Param("supi")
+	procedure := context.OamProcedure(c.Param("procedure"))
+	var params producer.OamProcedureParams
+	if err := c.ShouldBindJSON(&params); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+		return
+	}
+
+	logger.ProducerLog.Warnf("%s procedure requested on UE %s by OAM", procedure, supi)
+	result, err := producer.RunOamProcedure(c.Request.Context(), supi, procedure, params)
+	switch {
+	case errors.Is(err, producer.ErrInvalidOamProcedure):
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+	case errors.Is(err, context.ErrUeContextNotFound):
+		c.JSON(http.StatusNotFound, openapiUtils.ProblemDetailsContextNotFound(err.Error()))
+	case errors.Is(err, producer.ErrOamProcedureNotApplicable):
+		c.JSON(http.StatusConflict, openapiUtils.ProblemDetails("Conflict", http.StatusConflict, err.Error()))
+	case err != nil:
+		logger.ProducerLog.Errorf("%s procedure on UE %s failed: %+v", procedure, supi, err)
+		c.JSON(http.StatusInternalServerError, openapiUtils.ProblemDetailsSystemFailure(err.Error()))
+	default:
+		c.JSON(http.StatusOK, result)
+	}
+}
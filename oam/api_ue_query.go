@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// parseUeQuery returns the UE query of the request parameters.
+func parseUeQuery(c *gin.Context) (context.UeQuery, error) {
+	query := context.UeQuery{
+		GmmState: c.Query("gmmState"),
+		CmState:  models.CmState(strings.ToUpper(c.Query("cmState"))),
+		Tac:      c.Query("tac"),
+		GnbId:    c.Query("gnbId"),
+		Dnn:      c.Query("dnn"),
+		Cursor:   c.Query("cursor"),
+		Limit:    context.DefaultUeQueryLimit,
+	}
+	if query.CmState != "" && query.CmState != models.CMSTATE_CONNECTED && query.CmState != models.CMSTATE_IDLE {
+		return query, fmt.Errorf("invalid cmState %q", c.Query("cmState"))
+	}
+	if sst := c.Query("sst"); sst != "" {
+		if _, err := strconv.ParseUint(sst, 10, 8); err != nil {
+			return query, fmt.Errorf("invalid sst %q", sst)
+		}
+		query.Snssai = sst
+		if sd := c.Query("sd"); sd != "" {
+			query.Snssai += "-" + sd
+		}
+	} else if c.Query("sd") != "" {
+		return query, fmt.Errorf("sd requires sst")
+	}
+	for param, t := range map[string]*time.Time{"activeSince": &query.ActiveSince, "activeUntil": &query.ActiveUntil} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s %q: %w", param, value, err)
+			}
+			*t = parsed
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 || query.Limit > context.MaxUeQueryLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", context.MaxUeQueryLimit)
+		}
+	}
+	return query, nil
+}
+
+// HTTPGetUes returns a page of the UE contexts selected by the query parameters, or with
+// ?format=ndjson streams all of them, one JSON object per line.
+func HTTPGetUes(c *gin.Context) {
+	setCorsHeader(c)
+
+	query, err := parseUeQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, openapiUtils.ProblemDetailsMalformedRequestSyntax(err.Error()))
+		return
+	}
+	if c.Query("format") == "ndjson" || c.GetHeader("Accept") == ndjsonContentType {
+		exportUes(c, query)
+		return
+	}
+	page, err := context.QueryUeContexts(c.Request.Context(), query)
+	if err != nil {
+		logger.ProducerLog.Errorf("UE query failed: %+v", err)
+		c.JSON(http.StatusInternalServerError, openapiUtils.ProblemDetailsSystemFailure(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// exportUes streams the UE contexts selected by query, flushed every MaxUeQueryLimit
+// contexts. The export stops when the client goes away.
+func exportUes(c *gin.Context, query context.UeQuery) {
+	c.Header("Content-Type", ndjsonContentType)
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	exported := 0
+	err := context.ExportUeContexts(c.Request.Context(), query, func(ue context.UeSummary) error {
+		if err := encoder.Encode(ue); err != nil {
+			return err
+		}
+		if exported++; exported%context.MaxUeQueryLimit == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// the status is already sent, the truncated export is only logged
+		logger.ProducerLog.Warnf("UE export aborted after %d UEs: %+v", exported, err)
+		return
+	}
+	c.Writer.Flush()
+}
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package oam
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	openapiUtils "github.com/omec-project/openapi/v2/utils"
+)
+
+const jsonLinesContentType = "application/jsonl"
+
+// HTTPUeTrace returns the flight recorder of a UE, its latest NGAP and NAS messages, GMM state
+// transitions, timer events and SBI calls, oldest first. The records are returned as a JSON
+// array, or as JSON lines with format=jsonl or an Accept header of application/jsonl.
+func HTTPUeTrace(c *gin.Context) {
+	setCorsHeader(c)
+
+	amfSelf := context.AMF_Self()
+	if amfSelf.FlightRecorder == nil {
+		problemDetails := openapiUtils.ProblemDetails("Not Found", http.StatusNotFound, "UE flight recorder is disabled")
+		c.JSON(http.StatusNotFound, problemDetails)
+		return
+	}
+	supi := c.Params.ByName("supi")
+	ue, ok := amfSelf.AmfUeFindBySupi(supi)
+	if !ok {
+		problemDetails := openapiUtils.ProblemDetailsContextNotFound("UE context of " + supi + " not found")
+		c.JSON(http.StatusNotFound, problemDetails)
+		return
+	}
+
+	records := ue.FlightRecords()
+	if !wantsJsonLines(c) {
+		c.JSON(http.StatusOK, records)
+		return
+	}
+	c.Header("Content-Type", jsonLinesContentType)
+	c.Status(http.StatusOK)
+	if err := context.WriteFlightRecords(c.Writer, records); err != nil {
+		logger.ProducerLog.Errorf("write flight records of UE[%s] failed: %+v", supi, err)
+	}
+}
+
+func wantsJsonLines(c *gin.Context) bool {
+	return c.Query("format") == "jsonl" || strings.Contains(c.GetHeader("Accept"), jsonLinesContentType)
+}
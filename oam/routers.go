@@ -38,6 +38,8 @@ func AddService(engine *gin.Engine) *gin.RouterGroup {
 			group.DELETE(route.Pattern, route.HandlerFunc)
 		case "POST":
 			group.POST(route.Pattern, route.HandlerFunc)
+		case "PUT":
+			group.PUT(route.Pattern, route.HandlerFunc)
 		}
 	}
 	return group
@@ -81,10 +83,88 @@ var routes = Routes{
 		"/active-ues",
 		HTTPGetActiveUes,
 	},
+	{
+		"UE Query",
+		strings.ToUpper("get"),
+		"/ues",
+		HTTPGetUes,
+	},
+	{
+		"UE Procedure",
+		strings.ToUpper("post"),
+		"/ues/:supi/procedures/:procedure",
+		HTTPRunUeProcedure,
+	},
 	{
 		"Amf Instance Down Notification",
 		strings.ToUpper("post"),
 		"/amfInstanceDown/:nfid",
 		HTTPAmfInstanceDown,
 	},
+	{
+		"Amf Drain",
+		strings.ToUpper("post"),
+		"/drain",
+		HTTPAmfDrain,
+	},
+	{
+		"UE Trace",
+		strings.ToUpper("get"),
+		"/ue-trace/:supi",
+		HTTPUeTrace,
+	},
+	{
+		"Start Packet Capture",
+		strings.ToUpper("post"),
+		"/packet-captures",
+		HTTPStartPacketCapture,
+	},
+	{
+		"Packet Captures",
+		strings.ToUpper("get"),
+		"/packet-captures",
+		HTTPGetPacketCaptures,
+	},
+	{
+		"Packet Capture File",
+		strings.ToUpper("get"),
+		"/packet-captures/:id",
+		HTTPGetPacketCaptureFile,
+	},
+	{
+		"Stop Packet Capture",
+		strings.ToUpper("delete"),
+		"/packet-captures/:id",
+		HTTPStopPacketCapture,
+	},
+	{
+		"Log Levels",
+		strings.ToUpper("get"),
+		"/log-levels",
+		HTTPGetLogLevels,
+	},
+	{
+		"Set Log Level",
+		strings.ToUpper("put"),
+		"/log-levels/:subsystem",
+		HTTPSetLogLevel,
+	},
+	{
+		"gNB List",
+		strings.ToUpper("get"),
+		"/gnbs",
+		HTTPGetGnbs,
+	},
+	{
+		"Individual gNB",
+		strings.ToUpper("get"),
+		"/gnbs/:id",
+		HTTPGetGnb,
+	},
+	{
+		"Disconnect gNB",
+		strings.ToUpper("delete"),
+		"/gnbs/:id",
+		HTTPDisconnectGnb,
+	},
 }
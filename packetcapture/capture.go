@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package packetcapture writes the NGAP PDUs exchanged with selected gNBs or UEs, and
+// optionally the plain NAS messages of the UEs, to pcapng files for Wireshark.
+package packetcapture
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/omec-project/amf/tracerecording"
+)
+
+// Request selects what a capture records. Exactly one of GnbId and Supi must be set.
+type Request struct {
+	GnbId string `json:"gnbId,omitempty"`
+	Supi  string `json:"supi,omitempty"`
+	// MaxBytes is the size of the capture file at which the capture stops, capped by the
+	// configured maximum file size; 0 selects the maximum
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+	// DecryptNas adds the plain NAS messages of the UEs, after deciphering and before
+	// ciphering, to the capture
+	DecryptNas bool `json:"decryptNas,omitempty"`
+}
+
+// Info describes a capture.
+type Info struct {
+	Request
+	Id      string    `json:"id"`
+	Path    string    `json:"path"`
+	Start   time.Time `json:"start"`
+	Bytes   int64     `json:"bytes"`
+	Packets int64     `json:"packets"`
+	Active  bool      `json:"active"`
+	// why the capture stopped, if it is not active
+	StopReason string `json:"stopReason,omitempty"`
+}
+
+// errors returned by Manager.Start
+var (
+	ErrInvalidRequest      = errors.New("invalid packet capture request")
+	ErrNasDecryptionDenied = errors.New("NAS decryption is not allowed by the configuration")
+)
+
+type capture struct {
+	info Info
+	file *os.File
+	buf  *bufio.Writer
+	enc  pcapngWriter
+}
+
+// Manager runs the packet captures started over OAM. It is safe for concurrent use.
+type Manager struct {
+	dir                string
+	maxFileSize        int64
+	allowNasDecryption bool
+
+	mu       sync.Mutex
+	nextId   int
+	captures map[string]*capture
+	// number of active captures, so that messages are not inspected while nothing is captured
+	active atomic.Int32
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// NewManager returns a Manager writing capture files of at most maxFileSize bytes to dir.
+// Plain NAS messages are only captured if allowNasDecryption is set.
+func NewManager(dir string, maxFileSize int64, allowNasDecryption bool) *Manager {
+	return &Manager{
+		dir:                dir,
+		maxFileSize:        maxFileSize,
+		allowNasDecryption: allowNasDecryption,
+		captures:           make(map[string]*capture),
+	}
+}
+
+// Start starts a capture and creates its capture file.
+func (m *Manager) Start(req Request) (Info, error) {
+	if (req.GnbId == "") == (req.Supi == "") {
+		return Info{}, fmt.Errorf("%w: exactly one of gnbId and supi must be set", ErrInvalidRequest)
+	}
+	if req.MaxBytes < 0 {
+		return Info{}, fmt.Errorf("%w: maxBytes must not be negative", ErrInvalidRequest)
+	}
+	if req.DecryptNas && !m.allowNasDecryption {
+		return Info{}, ErrNasDecryptionDenied
+	}
+	if req.MaxBytes == 0 || req.MaxBytes > m.maxFileSize {
+		req.MaxBytes = m.maxFileSize
+	}
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return Info{}, fmt.Errorf("create packet capture directory: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextId++
+	start := time.Now()
+	c := &capture{info: Info{Request: req, Id: fmt.Sprintf("%d", m.nextId), Start: start, Active: true}}
+	target := "gnb-" + req.GnbId
+	if req.Supi != "" {
+		target = req.Supi
+	}
+	name := fmt.Sprintf("%s_%s_%s.pcapng", c.info.Id, unsafeFileNameChars.ReplaceAllString(target, "_"),
+		start.UTC().Format("20060102T150405"))
+	c.info.Path = filepath.Join(m.dir, name)
+	file, err := os.OpenFile(c.info.Path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return Info{}, fmt.Errorf("create packet capture file: %w", err)
+	}
+	c.file, c.buf = file, bufio.NewWriter(file)
+	c.enc = pcapngWriter{w: c.buf}
+	n, err := c.enc.writeHeader()
+	if err == nil {
+		err = c.buf.Flush()
+	}
+	if err != nil {
+		file.Close()
+		return Info{}, fmt.Errorf("write packet capture file header: %w", err)
+	}
+	c.info.Bytes = int64(n)
+
+	m.captures[c.info.Id] = c
+	m.active.Add(1)
+	return c.info, nil
+}
+
+// Stop stops the capture id and closes its capture file, which is kept.
+func (m *Manager) Stop(id string) (Info, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.captures[id]
+	if !ok {
+		return Info{}, false
+	}
+	m.stop(c, "stopped")
+	return c.info, true
+}
+
+// Get returns the capture id.
+func (m *Manager) Get(id string) (Info, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.captures[id]
+	if !ok {
+		return Info{}, false
+	}
+	return c.info, true
+}
+
+// List returns the active and stopped captures.
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]Info, 0, len(m.captures))
+	for _, c := range m.captures {
+		infos = append(infos, c.info)
+	}
+	return infos
+}
+
+// Close stops all captures.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.captures {
+		m.stop(c, "stopped")
+	}
+}
+
+// CaptureNgap adds an NGAP PDU exchanged with the gNB gnbId to the captures of the gNB and,
+// if the PDU is UE-associated and the SUPI known, of the UE.
+func (m *Manager) CaptureNgap(gnbId, supi string, direction tracerecording.Direction, pdu []byte) {
+	m.capture(gnbId, supi, direction, dissectorNgap, false, pdu)
+}
+
+// CaptureNas adds a plain NAS message of a UE to the captures of the UE and of the gNB it is
+// connected to that decrypt NAS.
+func (m *Manager) CaptureNas(gnbId, supi string, direction tracerecording.Direction, plainNas []byte) {
+	m.capture(gnbId, supi, direction, dissectorNas, true, plainNas)
+}
+
+func (m *Manager) capture(gnbId, supi string, direction tracerecording.Direction, dissector string, nas bool,
+	pdu []byte,
+) {
+	if m.active.Load() == 0 {
+		return
+	}
+	now := time.Now()
+	comment := directionName(direction) + " gNB " + gnbId
+	if supi != "" {
+		comment += " " + supi
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.captures {
+		if !c.info.Active || (nas && !c.info.DecryptNas) {
+			continue
+		}
+		if (c.info.GnbId == "" || c.info.GnbId != gnbId) && (c.info.Supi == "" || c.info.Supi != supi) {
+			continue
+		}
+		m.write(c, now, dissector, comment, pdu)
+	}
+}
+
+// write must be called with mu held
+func (m *Manager) write(c *capture, t time.Time, dissector, comment string, pdu []byte) {
+	// an enhanced packet block is at most 64 bytes larger than the PDU and its comment
+	if c.info.Bytes+int64(len(pdu)+len(dissector)+len(comment))+64 > c.info.MaxBytes {
+		m.stop(c, "size limit reached")
+		return
+	}
+	n, err := c.enc.writePacket(t, dissector, comment, pdu)
+	if err == nil {
+		err = c.buf.Flush()
+	}
+	c.info.Bytes += int64(n)
+	if err != nil {
+		m.stop(c, "write failed: "+err.Error())
+		return
+	}
+	c.info.Packets++
+}
+
+// stop must be called with mu held
+func (m *Manager) stop(c *capture, reason string) {
+	if !c.info.Active {
+		return
+	}
+	c.info.Active = false
+	c.info.StopReason = reason
+	m.active.Add(-1)
+	err := c.buf.Flush()
+	if closeErr := c.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && reason == "stopped" {
+		c.info.StopReason = "close failed: " + err.Error()
+	}
+}
+
+func directionName(direction tracerecording.Direction) string {
+	if direction == tracerecording.Uplink {
+		return "uplink"
+	}
+	return "downlink"
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package packetcapture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/omec-project/amf/tracerecording"
+)
+
+// NGSetupRequest, not a complete PDU
+var ngSetupRequest = []byte{0x00, 0x15, 0x00, 0x33}
+
+func TestCapture(t *testing.T) {
+	m := NewManager(t.TempDir(), 1<<20, false)
+	if _, err := m.Start(Request{GnbId: "gnb1", Supi: "imsi-208930000000001"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected a request with gNB and SUPI to be rejected, got %v", err)
+	}
+	if _, err := m.Start(Request{Supi: "imsi-208930000000001", DecryptNas: true}); !errors.Is(err, ErrNasDecryptionDenied) {
+		t.Fatalf("expected NAS decryption to be denied, got %v", err)
+	}
+
+	info, err := m.Start(Request{GnbId: "gnb1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.MaxBytes != 1<<20 {
+		t.Errorf("expected the maximum file size, got %d", info.MaxBytes)
+	}
+	m.CaptureNgap("gnb1", "", tracerecording.Uplink, ngSetupRequest)
+	m.CaptureNgap("gnb2", "", tracerecording.Uplink, ngSetupRequest)
+	m.CaptureNas("gnb1", "imsi-208930000000001", tracerecording.Uplink, []byte{0x7e, 0x00, 0x41})
+	if info, _ = m.Stop(info.Id); info.Active || info.Packets != 1 {
+		t.Fatalf("expected a stopped capture of one packet, got %+v", info)
+	}
+	m.CaptureNgap("gnb1", "", tracerecording.Uplink, ngSetupRequest)
+
+	content, err := os.ReadFile(info.Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if int64(len(content)) != info.Bytes {
+		t.Errorf("expected %d bytes, got %d", info.Bytes, len(content))
+	}
+	if binary.LittleEndian.Uint32(content) != pcapngSectionHeaderBlock ||
+		binary.LittleEndian.Uint32(content[8:]) != pcapngByteOrderMagic {
+		t.Fatal("expected a pcapng section header block")
+	}
+	// section header block (28) and interface description block (20)
+	if linkType := binary.LittleEndian.Uint16(content[36:]); linkType != linkTypeWiresharkUpperPdu {
+		t.Errorf("unexpected link type %d", linkType)
+	}
+	packet := content[48:]
+	if binary.LittleEndian.Uint32(packet) != pcapngEnhancedPacketBlock ||
+		int(binary.LittleEndian.Uint32(packet[4:])) != len(packet) {
+		t.Fatal("expected a single enhanced packet block")
+	}
+	data := packet[28:]
+	if tag := binary.BigEndian.Uint16(data); tag != exportedPduTagProtoName || string(data[4:8]) != "ngap" {
+		t.Errorf("expected the ngap dissector, got %x", data[:8])
+	}
+	if !bytes.Equal(data[12:16], ngSetupRequest) {
+		t.Errorf("expected the NGAP PDU after the exported PDU tags, got %x", data[12:16])
+	}
+	if !bytes.Contains(packet, []byte("uplink gNB gnb1")) {
+		t.Error("expected a comment with the direction and gNB")
+	}
+}
+
+func TestCaptureSizeLimit(t *testing.T) {
+	m := NewManager(t.TempDir(), 1<<20, true)
+	info, err := m.Start(Request{Supi: "imsi-208930000000001", MaxBytes: 256, DecryptNas: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 10 {
+		m.CaptureNas("gnb1", "imsi-208930000000001", tracerecording.Downlink, []byte{0x7e, 0x00, 0x42, 0x01})
+	}
+	if info, _ = m.Get(info.Id); info.Active || info.StopReason != "size limit reached" || info.Bytes > 256 {
+		t.Errorf("expected the capture to stop at the size limit, got %+v", info)
+	}
+	if info.Packets == 0 {
+		t.Error("expected the plain NAS messages to be captured until the limit")
+	}
+}
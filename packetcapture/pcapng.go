@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package packetcapture
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// The capture files are pcapng (draft-ietf-opsawg-pcapng) with a single interface of link
+// type LINKTYPE_WIRESHARK_UPPER_PDU. Every packet starts with the exported PDU tags naming
+// the dissector Wireshark hands the PDU to, so NGAP PDUs and plain NAS messages are dissected
+// without SCTP and IP framing. All pcapng integers are little endian, the exported PDU tags
+// big endian.
+const (
+	pcapngSectionHeaderBlock        = 0x0a0d0d0a
+	pcapngInterfaceDescriptionBlock = 0x00000001
+	pcapngEnhancedPacketBlock       = 0x00000006
+	pcapngByteOrderMagic            = 0x1a2b3c4d
+
+	pcapngOptEndOfOpt = 0
+	pcapngOptComment  = 1
+
+	linkTypeWiresharkUpperPdu = 252
+
+	exportedPduTagEndOfOpt  = 0
+	exportedPduTagProtoName = 12
+
+	// Wireshark dissectors of the captured PDUs
+	dissectorNgap = "ngap"
+	dissectorNas  = "nas-5gs"
+)
+
+type pcapngWriter struct {
+	w io.Writer
+}
+
+// writeHeader writes the section header block and the interface description block.
+func (p *pcapngWriter) writeHeader() (int, error) {
+	shb := binary.LittleEndian.AppendUint32(nil, pcapngSectionHeaderBlock)
+	shb = binary.LittleEndian.AppendUint32(shb, 28)
+	shb = binary.LittleEndian.AppendUint32(shb, pcapngByteOrderMagic)
+	shb = binary.LittleEndian.AppendUint16(shb, 1)                  // major version
+	shb = binary.LittleEndian.AppendUint16(shb, 0)                  // minor version
+	shb = binary.LittleEndian.AppendUint64(shb, 0xffffffffffffffff) // section length not specified
+	shb = binary.LittleEndian.AppendUint32(shb, 28)
+
+	idb := binary.LittleEndian.AppendUint32(shb, pcapngInterfaceDescriptionBlock)
+	idb = binary.LittleEndian.AppendUint32(idb, 20)
+	idb = binary.LittleEndian.AppendUint16(idb, linkTypeWiresharkUpperPdu)
+	idb = binary.LittleEndian.AppendUint16(idb, 0) // reserved
+	idb = binary.LittleEndian.AppendUint32(idb, 0) // no snap length
+	// no options: timestamps have the default if_tsresol of microseconds
+	idb = binary.LittleEndian.AppendUint32(idb, 20)
+	return p.w.Write(idb)
+}
+
+// writePacket writes pdu as an enhanced packet block for dissector with an optional comment.
+func (p *pcapngWriter) writePacket(t time.Time, dissector, comment string, pdu []byte) (int, error) {
+	data := binary.BigEndian.AppendUint16(nil, exportedPduTagProtoName)
+	data = binary.BigEndian.AppendUint16(data, uint16(padded(len(dissector))))
+	data = appendPadded(data, []byte(dissector))
+	data = binary.BigEndian.AppendUint16(data, exportedPduTagEndOfOpt)
+	data = binary.BigEndian.AppendUint16(data, 0)
+	data = append(data, pdu...)
+
+	var options []byte
+	if comment != "" {
+		options = binary.LittleEndian.AppendUint16(options, pcapngOptComment)
+		options = binary.LittleEndian.AppendUint16(options, uint16(len(comment)))
+		options = appendPadded(options, []byte(comment))
+		options = binary.LittleEndian.AppendUint32(options, pcapngOptEndOfOpt)
+	}
+
+	length := uint32(32 + padded(len(data)) + len(options))
+	micros := uint64(t.UnixMicro())
+	block := binary.LittleEndian.AppendUint32(make([]byte, 0, length), pcapngEnhancedPacketBlock)
+	block = binary.LittleEndian.AppendUint32(block, length)
+	block = binary.LittleEndian.AppendUint32(block, 0) // interface
+	block = binary.LittleEndian.AppendUint32(block, uint32(micros>>32))
+	block = binary.LittleEndian.AppendUint32(block, uint32(micros))
+	block = binary.LittleEndian.AppendUint32(block, uint32(len(data))) // captured length
+	block = binary.LittleEndian.AppendUint32(block, uint32(len(data))) // original length
+	block = appendPadded(block, data)
+	block = append(block, options...)
+	block = binary.LittleEndian.AppendUint32(block, length)
+	return p.w.Write(block)
+}
+
+// padded returns n rounded up to a multiple of 4
+func padded(n int) int {
+	return (n + 3) &^ 3
+}
+
+func appendPadded(buf, data []byte) []byte {
+	buf = append(buf, data...)
+	return append(buf, make([]byte, padded(len(data))-len(data))...)
+}
@@ -54,7 +54,7 @@ func SmContextHandler(ctx ctxt.Context, s1, s2 string, msg interface{}) (interfa
 	return nil, "", nil, nil
 }
 
-func HandleSmContextStatusNotify(request *httpwrapper.Request) *httpwrapper.Response {
+func HandleSmContextStatusNotify(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
 	var ue *context.AmfUe
 	var ok bool
 	logger.ProducerLog.Infoln("[AMF] handle SmContext Status Notify")
@@ -75,6 +75,7 @@ func HandleSmContextStatusNotify(request *httpwrapper.Request) *httpwrapper.Resp
 		ReqUri:      pduSessionIDString,
 		Msg:         smContextStatusNotification,
 		Result:      make(chan context.SbiResponseMsg, 10),
+		Context:     ctx,
 	}
 	ue.EventChannel.UpdateSbiHandler(SmContextHandler)
 	ue.EventChannel.SubmitMessage(sbiMsg)
@@ -183,7 +184,7 @@ func SmContextStatusNotifyProcedure(ctx ctxt.Context, guti string, pduSessionID
 				smContext.DeleteULNASTransport()
 			}()
 		} else {
-			ue.SmContextList.Delete(pduSessionID)
+			ue.DeleteSmContext(pduSessionID)
 		}
 	} else {
 		invalidParam := models.NewInvalidParam("StatusInfo.ResourceStatus")
@@ -197,7 +198,7 @@ func SmContextStatusNotifyProcedure(ctx ctxt.Context, guti string, pduSessionID
 	return nil
 }
 
-func HandleAmPolicyControlUpdateNotifyUpdate(request *httpwrapper.Request) *httpwrapper.Response {
+func HandleAmPolicyControlUpdateNotifyUpdate(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
 	var ue *context.AmfUe
 	var ok bool
 	logger.ProducerLog.Infoln("handle AM Policy Control Update Notify [Policy update notification]")
@@ -216,6 +217,7 @@ func HandleAmPolicyControlUpdateNotifyUpdate(request *httpwrapper.Request) *http
 		ReqUri:      "",
 		Msg:         policyUpdate,
 		Result:      make(chan context.SbiResponseMsg, 10),
+		Context:     ctx,
 	}
 	ue.EventChannel.UpdateSbiHandler(SmContextHandler)
 	ue.EventChannel.SubmitMessage(sbiMsg)
@@ -292,7 +294,7 @@ func AmPolicyControlUpdateNotifyUpdateProcedure(polAssoID string,
 }
 
 // TS 29.507 4.2.4.3
-func HandleAmPolicyControlUpdateNotifyTerminate(request *httpwrapper.Request) *httpwrapper.Response {
+func HandleAmPolicyControlUpdateNotifyTerminate(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
 	var ue *context.AmfUe
 	logger.ProducerLog.Infoln("handle AM Policy Control Update Notify [Request for termination of the policy association]")
 
@@ -310,6 +312,7 @@ func HandleAmPolicyControlUpdateNotifyTerminate(request *httpwrapper.Request) *h
 		ReqUri:      "",
 		Msg:         terminationNotification,
 		Result:      make(chan context.SbiResponseMsg, 10),
+		Context:     ctx,
 	}
 	ue.EventChannel.UpdateSbiHandler(SmContextHandler)
 	ue.EventChannel.SubmitMessage(sbiMsg)
@@ -349,12 +352,12 @@ func AmPolicyControlUpdateNotifyTerminateProcedure(ctx ctxt.Context, polAssoID s
 }
 
 // TS 23.502 4.2.2.2.3 Registration with AMF re-allocation
-func HandleN1MessageNotify(request *httpwrapper.Request) *httpwrapper.Response {
+func HandleN1MessageNotify(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
 	logger.ProducerLog.Infoln("[AMF] handle N1 Message Notify")
 
 	n1MessageNotify := request.Body.(models.N1MessageNotifyRequest)
 
-	problemDetails := N1MessageNotifyProcedure(n1MessageNotify)
+	problemDetails := N1MessageNotifyProcedure(ctx, n1MessageNotify)
 	if problemDetails != nil {
 		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
 	} else {
@@ -362,7 +365,7 @@ func HandleN1MessageNotify(request *httpwrapper.Request) *httpwrapper.Response {
 	}
 }
 
-func N1MessageNotifyProcedure(n1MessageNotify models.N1MessageNotifyRequest) *models.ProblemDetails {
+func N1MessageNotifyProcedure(ctx ctxt.Context, n1MessageNotify models.N1MessageNotifyRequest) *models.ProblemDetails {
 	logger.ProducerLog.Debugf("n1MessageNotify: %+v", n1MessageNotify)
 
 	amfSelf := context.AMF_Self()
@@ -411,7 +414,8 @@ func N1MessageNotifyProcedure(n1MessageNotify models.N1MessageNotifyRequest) *mo
 		if err != nil {
 			logger.ProducerLog.Errorf("read N1 Message Failed: %+v", err)
 		}
-		nas.HandleNAS(ctxt.Background(), ranUe, ngapType.ProcedureCodeInitialUEMessage, nasPdu)
+		// the registration continues after the response to the notification
+		nas.HandleNAS(ctxt.WithoutCancel(ctx), ranUe, ngapType.ProcedureCodeInitialUEMessage, nasPdu)
 	}()
 	return nil
 }
@@ -481,6 +485,7 @@ func HandleDeregistrationNotification(ctx ctxt.Context, request *httpwrapper.Req
 					ReqUri:      reqUri,
 					Msg:         nil,
 					Result:      make(chan context.SbiResponseMsg, 10),
+					Context:     ctx,
 				}
 				ue.EventChannel.UpdateSbiHandler(HandleOAMPurgeUEContextRequest)
 				ue.EventChannel.SubmitMessage(sbiMsg)
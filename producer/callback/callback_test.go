@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	amf_context "github.com/omec-project/amf/context"
 	"github.com/omec-project/openapi/v2"
@@ -142,3 +144,151 @@ func TestSendAmfStatusChangeNotifyUsesExactCallbackURI(t *testing.T) {
 		t.Fatalf("unexpected status change %q", receivedBody.GetAmfStatusInfoList()[0].GetStatusChange())
 	}
 }
+
+func TestSendAmfStatusChangeNotifyRetriesFailedDelivery(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	defer func(interval time.Duration) { amfStatusNotifyRetryInterval = interval }(amfStatusNotifyRetryInterval)
+	amfStatusNotifyRetryInterval = time.Millisecond
+
+	guami := models.Guami{PlmnId: models.PlmnIdNid{Mcc: "001", Mnc: "01"}, AmfId: "cafe01"}
+	subscription := models.NewSubscriptionDataAmf(server.URL + "/status/change")
+	subscription.SetGuamiList([]models.Guami{guami})
+
+	amfSelf := amf_context.AMF_Self()
+	amfSelf.AMFStatusSubscriptions.Store("retry-subscription", *subscription)
+	defer amfSelf.AMFStatusSubscriptions.Delete("retry-subscription")
+
+	SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_AVAILABLE, []models.Guami{guami})
+
+	if attempts.Load() != 2 {
+		t.Fatalf("expected notification to be retried once, got %d attempts", attempts.Load())
+	}
+	if _, ok := amfSelf.FindAMFStatusSubscription("retry-subscription"); !ok {
+		t.Fatal("expected subscription to be kept after successful retry")
+	}
+}
+
+func TestSendAmfStatusChangeNotifyExpiresDeadSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	guami := models.Guami{PlmnId: models.PlmnIdNid{Mcc: "001", Mnc: "01"}, AmfId: "cafe01"}
+	subscription := models.NewSubscriptionDataAmf(server.URL + "/status/change")
+	subscription.SetGuamiList([]models.Guami{guami})
+	otherGuami := models.Guami{PlmnId: models.PlmnIdNid{Mcc: "001", Mnc: "02"}, AmfId: "cafe01"}
+	otherSubscription := models.NewSubscriptionDataAmf(server.URL + "/other")
+	otherSubscription.SetGuamiList([]models.Guami{otherGuami})
+
+	amfSelf := amf_context.AMF_Self()
+	amfSelf.AMFStatusSubscriptions.Store("1001", *subscription)
+	amfSelf.AMFStatusSubscriptions.Store("1002", *otherSubscription)
+	defer amfSelf.AMFStatusSubscriptions.Delete("1001")
+	defer amfSelf.AMFStatusSubscriptions.Delete("1002")
+
+	SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_UNAVAILABLE, []models.Guami{guami})
+
+	if _, ok := amfSelf.FindAMFStatusSubscription("1001"); ok {
+		t.Fatal("expected subscription with unknown callback to be expired")
+	}
+	if _, ok := amfSelf.FindAMFStatusSubscription("1002"); !ok {
+		t.Fatal("expected subscription for other GUAMIs not to be notified")
+	}
+}
+
+func TestSendAmfEventNotifyReportsSubscribedEvents(t *testing.T) {
+	received := make(chan models.AmfEventNotification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var notification models.AmfEventNotification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			t.Errorf("failed to decode event notification: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		received <- notification
+	}))
+	defer server.Close()
+
+	remainReports := int32(1)
+	ue := &amf_context.AmfUe{
+		Supi:         "imsi-208930000000001",
+		Reachability: models.UEREACHABILITY_REACHABLE,
+		EventSubscriptionsInfo: map[string]*amf_context.AmfUeEventSubscription{
+			"1": {
+				RemainReports: &remainReports,
+				EventSubscription: models.NewExtAmfEventSubscription([]models.AmfEvent{
+					{Type: models.AMFEVENTTYPE_REACHABILITY_REPORT},
+					{Type: models.AMFEVENTTYPE_LOCATION_REPORT},
+				}, server.URL+"/events", "corr-1", "nf-1"),
+			},
+			"2": {
+				EventSubscription: models.NewExtAmfEventSubscription([]models.AmfEvent{
+					{Type: models.AMFEVENTTYPE_LOCATION_REPORT},
+				}, server.URL+"/other", "corr-2", "nf-1"),
+			},
+		},
+	}
+
+	SendAmfEventNotify(ue, models.AMFEVENTTYPE_CONNECTIVITY_STATE_REPORT, models.AMFEVENTTYPE_REACHABILITY_REPORT)
+
+	select {
+	case notification := <-received:
+		if notification.GetNotifyCorrelationId() != "corr-1" {
+			t.Errorf("expected correlation ID corr-1, got %q", notification.GetNotifyCorrelationId())
+		}
+		if len(notification.ReportList) != 1 {
+			t.Fatalf("expected 1 report, got %d", len(notification.ReportList))
+		}
+		report := notification.ReportList[0]
+		if report.Type != models.AMFEVENTTYPE_REACHABILITY_REPORT || report.GetReachability() != models.UEREACHABILITY_REACHABLE {
+			t.Errorf("unexpected report: %+v", report)
+		}
+		if report.State.Active {
+			t.Error("expected the last report of the subscription to be inactive")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event notification not received")
+	}
+	if _, ok := ue.EventSubscriptionsInfo["1"]; ok {
+		t.Error("expected the exhausted subscription to be removed")
+	}
+	if _, ok := ue.EventSubscriptionsInfo["2"]; !ok {
+		t.Error("expected the unrelated subscription to be kept")
+	}
+}
+
+func TestSendN1N2DeliveryFailureNotification(t *testing.T) {
+	received := models.NewN1N2MsgTxfrFailureNotificationWithDefaults()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+			t.Fatalf("failed to decode callback body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	SendN1N2DeliveryFailureNotification(&amf_context.DlNasOrigin{
+		N1MessageClass:   models.N1MESSAGECLASS_SM,
+		PduSessionId:     5,
+		FailureNotifyUri: server.URL + "/n1n2/failure",
+		N1n2MsgDataUri:   "/namf-comm/v1/ue-contexts/imsi-208930000000001/n1-n2-messages",
+	}, models.N1N2MESSAGETRANSFERCAUSE_N1_MSG_NOT_TRANSFERRED)
+
+	if received.GetCause() != models.N1N2MESSAGETRANSFERCAUSE_N1_MSG_NOT_TRANSFERRED {
+		t.Errorf("expected cause N1_MSG_NOT_TRANSFERRED, got %q", received.GetCause())
+	}
+	if received.GetN1n2MsgDataUri() != "/namf-comm/v1/ue-contexts/imsi-208930000000001/n1-n2-messages" {
+		t.Errorf("unexpected N1N2 message data URI %q", received.GetN1n2MsgDataUri())
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package callback
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	amf_context "github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+// SendAmfEventNotify sends a Namf_EventExposure Notify (TS 29.518 5.3.2.4.1) with a report of
+// the current state of ue to each of its event subscriptions that includes one of eventTypes.
+// Only connectivity state and reachability reports are built; subscriptions with a bounded
+// number of reports are removed from the UE once the last one was sent.
+func SendAmfEventNotify(ue *amf_context.AmfUe, eventTypes ...models.AmfEventType) {
+	now := time.Now().UTC()
+	for subscriptionID, ueSubscription := range ue.EventSubscriptionsInfo {
+		subscription := ueSubscription.EventSubscription
+		if subscription == nil || subscription.EventNotifyUri == "" {
+			continue
+		}
+		var reports []models.AmfEventReport
+		for _, event := range subscription.EventList {
+			if !slices.Contains(eventTypes, event.Type) {
+				continue
+			}
+			report, ok := newAmfEventReport(ue, event.Type, now)
+			if !ok {
+				continue
+			}
+			report.SetSubscriptionId(subscriptionID)
+			report.SetAnyUe(ueSubscription.AnyUe)
+			reports = append(reports, report)
+		}
+		if len(reports) == 0 {
+			continue
+		}
+
+		if remainReports := ueSubscription.RemainReports; remainReports != nil {
+			*remainReports--
+			for i := range reports {
+				reports[i].State.SetRemainReports(*remainReports)
+				reports[i].State.SetActive(*remainReports > 0)
+			}
+			if *remainReports <= 0 {
+				delete(ue.EventSubscriptionsInfo, subscriptionID)
+			}
+		}
+
+		notification := models.AmfEventNotification{ReportList: reports}
+		if subscription.NotifyCorrelationId != "" {
+			notification.SetNotifyCorrelationId(subscription.NotifyCorrelationId)
+		}
+		go deliverAmfEventNotify(subscription.EventNotifyUri, notification)
+	}
+}
+
+func newAmfEventReport(ue *amf_context.AmfUe, eventType models.AmfEventType, timeStamp time.Time) (
+	models.AmfEventReport, bool,
+) {
+	report := models.AmfEventReport{
+		Type:      eventType,
+		State:     models.AmfEventState{Active: true},
+		TimeStamp: timeStamp,
+	}
+	report.SetSupi(ue.GetSupi())
+	switch eventType {
+	case models.AMFEVENTTYPE_CONNECTIVITY_STATE_REPORT:
+		report.SetCmInfoList(ue.GetCmInfo())
+	case models.AMFEVENTTYPE_REACHABILITY_REPORT:
+		if ue.Reachability == "" {
+			return report, false
+		}
+		report.SetReachability(ue.Reachability)
+	default:
+		return report, false
+	}
+	return report, true
+}
+
+func deliverAmfEventNotify(eventNotifyUri string, notification models.AmfEventNotification) {
+	logger.CallbackLog.Infof("send AMF Event Notify to %s", eventNotifyUri)
+	httpResponse, err := postCallbackJSON(context.Background(), eventNotifyUri, notification)
+	defer closeCallbackResponseBody(httpResponse)
+	if err != nil || (httpResponse != nil && httpResponse.StatusCode >= http.StatusMultipleChoices) {
+		logCallbackResponseError(httpResponse, err)
+	}
+}
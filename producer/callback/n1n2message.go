@@ -73,6 +73,26 @@ func SendN1N2TransferFailureNotification(ue *amf_context.AmfUe, cause models.N1N
 	logCallbackResponseError(httpResponse, err)
 }
 
+// SendN1N2DeliveryFailureNotification tells the NF service consumer that transferred a NAS PDU
+// with N1N2MessageTransfer that the NG-RAN could not deliver it (TS 23.502 4.2.3.3)
+func SendN1N2DeliveryFailureNotification(origin *amf_context.DlNasOrigin, cause models.N1N2MessageTransferCause) {
+	if origin == nil || origin.FailureNotifyUri == "" {
+		logger.CallbackLog.Warnln("N1N2 Message Transfer Failure Notification not sent")
+		return
+	}
+
+	n1N2MsgTxfrFailureNotification := models.N1N2MsgTxfrFailureNotification{
+		Cause:          cause,
+		N1n2MsgDataUri: origin.N1n2MsgDataUri,
+	}
+	httpResponse, err := postCallbackJSON(context.Background(), origin.FailureNotifyUri, n1N2MsgTxfrFailureNotification)
+	defer closeCallbackResponseBody(httpResponse)
+	if err == nil && httpResponse != nil && httpResponse.StatusCode < 300 {
+		return
+	}
+	logCallbackResponseError(httpResponse, err)
+}
+
 func SendN1MessageNotify(ue *amf_context.AmfUe, n1class models.N1MessageClass, n1Msg []byte,
 	registerContext *models.RegistrationContextContainer,
 ) {
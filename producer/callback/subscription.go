@@ -8,38 +8,122 @@ package callback
 
 import (
 	"context"
+	"net/http"
 	"reflect"
+	"sync"
+	"time"
 
 	amf_context "github.com/omec-project/amf/context"
 	"github.com/omec-project/amf/logger"
 	"github.com/omec-project/openapi/v2/models"
 )
 
+const (
+	// delivery attempts per notification before it counts as failed
+	amfStatusNotifyMaxAttempts = 3
+	// consecutive failed notifications after which a subscription is expired
+	amfStatusNotifyMaxFailures = 3
+)
+
+var (
+	amfStatusNotifyRetryInterval = time.Second
+	// map[subscriptionID]int: consecutive notifications that could not be delivered
+	amfStatusNotifyFailures sync.Map
+)
+
+// SendAmfStatusChangeNotify sends an AMF Status Change Notification (TS 29.518 5.3.2.2.2)
+// to every subscriber of one of the GUAMIs in guamiList. Deliveries are retried with a
+// linear back-off; subscriptions whose callback URI is gone or keeps failing are expired.
+// It returns once every notification was delivered or given up.
 func SendAmfStatusChangeNotify(amfStatus models.StatusChange, guamiList []models.Guami) {
+	if len(guamiList) == 0 {
+		return
+	}
 	amfSelf := amf_context.AMF_Self()
 
+	var wg sync.WaitGroup
 	amfSelf.AMFStatusSubscriptions.Range(func(key, value interface{}) bool {
+		subscriptionID := key.(string)
 		subscriptionData := value.(models.SubscriptionDataAmf)
-		amfStatusNotification := models.AmfStatusChangeNotification{}
-		amfStatusInfo := models.AmfStatusInfo{}
+		amfStatusInfo := models.AmfStatusInfo{
+			StatusChange: amfStatus,
+		}
 
 		for _, guami := range guamiList {
 			for _, subGumi := range subscriptionData.GuamiList {
 				if reflect.DeepEqual(guami, subGumi) {
-					// AMF status is available
 					amfStatusInfo.GuamiList = append(amfStatusInfo.GuamiList, guami)
 				}
 			}
 		}
+		if len(amfStatusInfo.GuamiList) == 0 {
+			return true
+		}
 
-		amfStatusInfo.StatusChange = amfStatus
-
-		amfStatusNotification.AmfStatusInfoList = append(amfStatusNotification.AmfStatusInfoList, amfStatusInfo)
-
-		logger.ProducerLog.Infof("[AMF] Send Amf Status Change Notify to %s", subscriptionData.AmfStatusUri)
-		httpResponse, err := postCallbackJSON(context.Background(), subscriptionData.AmfStatusUri, amfStatusNotification)
-		defer closeCallbackResponseBody(httpResponse)
-		logCallbackResponseError(httpResponse, err)
+		amfStatusNotification := models.AmfStatusChangeNotification{
+			AmfStatusInfoList: []models.AmfStatusInfo{amfStatusInfo},
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliverAmfStatusChangeNotify(subscriptionID, subscriptionData.AmfStatusUri, amfStatusNotification)
+		}()
 		return true
 	})
+	wg.Wait()
+}
+
+func deliverAmfStatusChangeNotify(subscriptionID, amfStatusUri string,
+	amfStatusNotification models.AmfStatusChangeNotification,
+) {
+	for attempt := 1; ; attempt++ {
+		logger.ProducerLog.Infof("[AMF] Send Amf Status Change Notify to %s", amfStatusUri)
+		httpResponse, err := postCallbackJSON(context.Background(), amfStatusUri, amfStatusNotification)
+		statusCode := 0
+		if httpResponse != nil {
+			statusCode = httpResponse.StatusCode
+		}
+		closeCallbackResponseBody(httpResponse)
+
+		switch {
+		case err == nil && statusCode < http.StatusMultipleChoices:
+			amfStatusNotifyFailures.Delete(subscriptionID)
+			return
+		case statusCode == http.StatusNotFound || statusCode == http.StatusGone:
+			// the NF service consumer no longer knows this callback
+			expireAmfStatusSubscription(subscriptionID, amfStatusUri)
+			return
+		case attempt >= amfStatusNotifyMaxAttempts || !isRetryableCallbackFailure(statusCode, err):
+			logCallbackResponseError(httpResponse, err)
+			recordAmfStatusNotifyFailure(subscriptionID, amfStatusUri)
+			return
+		}
+		logger.ProducerLog.Warnf("Amf Status Change Notify to %s failed (attempt %d), retrying", amfStatusUri, attempt)
+		time.Sleep(amfStatusNotifyRetryInterval * time.Duration(attempt))
+	}
+}
+
+func isRetryableCallbackFailure(statusCode int, err error) bool {
+	return err != nil || statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
+
+func recordAmfStatusNotifyFailure(subscriptionID, amfStatusUri string) {
+	failures := 1
+	if value, ok := amfStatusNotifyFailures.Load(subscriptionID); ok {
+		failures += value.(int)
+	}
+	if failures >= amfStatusNotifyMaxFailures {
+		expireAmfStatusSubscription(subscriptionID, amfStatusUri)
+		return
+	}
+	amfStatusNotifyFailures.Store(subscriptionID, failures)
+}
+
+func expireAmfStatusSubscription(subscriptionID, amfStatusUri string) {
+	amfStatusNotifyFailures.Delete(subscriptionID)
+	if _, ok := amf_context.AMF_Self().FindAMFStatusSubscription(subscriptionID); !ok {
+		return
+	}
+	logger.ProducerLog.Warnf("expire AMF status subscription[%s]: callback %s unreachable", subscriptionID, amfStatusUri)
+	amf_context.AMF_Self().DeleteAMFStatusSubscription(subscriptionID)
 }
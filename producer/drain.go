@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/amf/producer/callback"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2/models"
+)
+
+var createUEContextForDrain = consumer.CreateUEContextRequest
+
+const (
+	maxConcurrentDrainUes = 16
+	drainUeTimeout        = 35 * time.Second
+	drainPollInterval     = 500 * time.Millisecond
+)
+
+// AmfDrainProcedure runs the AMF planned removal procedure (TS 23.501 5.21.2.2):
+// the served GUAMIs are reported unavailable to every gNB together with the backup
+// AMF name and to the AMF status change subscribers, registered UE contexts are handed over
+// to the backup AMF, UE-associated NG connections are released, and the procedure waits
+// until the gNBs confirmed the release or DrainTimeout expires. New UEs are rejected while the drain is running.
+// If a drain is already in progress, AmfDrainProcedure waits for it to complete.
+func AmfDrainProcedure(ctx ctxt.Context) {
+	amfSelf := context.AMF_Self()
+	if !amfSelf.StartDrain() {
+		<-amfSelf.DrainDone()
+		return
+	}
+	defer amfSelf.FinishDrain()
+	logger.ProducerLog.Infof("AMF drain started, backup AMF [%s]", amfSelf.BackupAmfName)
+	if !amfSelf.EnableDbStore && amfSelf.BackupAmfUri == "" {
+		logger.ProducerLog.Warnln("neither UE context store nor backup AMF URI configured, UE contexts are not handed over and UEs register again with the backup AMF")
+	}
+
+	unavailableGuamis := slices.Clone(amfSelf.ServedGuamiList)
+	unavailableGuamiList := ngap_message.BuildUnavailableGUAMIList(unavailableGuamis, amfSelf.BackupAmfName)
+	amfSelf.AmfRanPool.Range(func(key, value any) bool {
+		ngap_message.SendAMFStatusIndication(value.(*context.AmfRan), unavailableGuamiList)
+		return true
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		callback.SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_UNAVAILABLE, unavailableGuamis)
+	}()
+
+	sem := make(chan struct{}, maxConcurrentDrainUes)
+	amfSelf.UePool.Range(func(key, value any) bool {
+		ue := value.(*context.AmfUe)
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			drainUe(ctx, ue)
+		}()
+		return true
+	})
+	wg.Wait()
+
+	deadline := time.Now().Add(amfSelf.DrainTimeout)
+	for amfSelf.RanUeCount() > 0 {
+		if time.Now().After(deadline) {
+			logger.ProducerLog.Warnf("AMF drain timed out with %d RAN UEs still connected", amfSelf.RanUeCount())
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(drainPollInterval):
+		}
+	}
+	logger.ProducerLog.Infoln("AMF drain completed")
+}
+
+func drainUe(ctx ctxt.Context, ue *context.AmfUe) {
+	if ue.EventChannel == nil {
+		return
+	}
+	sbiMsg := context.SbiMsg{
+		UeContextId: ue.GetSupi(),
+		ReqUri:      "",
+		Msg:         nil,
+		Result:      make(chan context.SbiResponseMsg, 1),
+		Context:     ctx,
+	}
+	ue.EventChannel.UpdateSbiHandler(HandleDrainUeContext)
+	ue.EventChannel.SubmitMessage(sbiMsg)
+	select {
+	case <-sbiMsg.Result:
+	case <-time.After(drainUeTimeout):
+		ue.ProducerLog.Warnln("timed out draining UE context")
+	case <-ctx.Done():
+	}
+}
+
+// HandleDrainUeContext runs on the UE EventChannel during AMF drain: a registered UE
+// context is handed over to the backup AMF (TS 23.501 5.21.2.2.1) and the UE-associated NG
+// connections are released. A backup AMF sharing the UE context store restores the context
+// from it when the UE next reaches it, otherwise the context is pushed to the backup AMF with
+// Namf_Communication_CreateUEContext.
+func HandleDrainUeContext(ctx ctxt.Context, supi, reqUri string, msg any) (any, string, any, any) {
+	amfSelf := context.AMF_Self()
+	ue, ok := amfSelf.AmfUeFindBySupi(supi)
+	if !ok {
+		return nil, "", nil, nil
+	}
+
+	if ue.State[models.ACCESSTYPE__3_GPP_ACCESS].Is(context.Registered) {
+		handOverUeContext(ctx, ue)
+	}
+
+	for _, ranUe := range ue.RanUe {
+		ngap_message.SendUEContextReleaseCommand(ranUe, context.UeContextN2NormalRelease,
+			ngapType.CausePresentMisc, ngapType.CauseMiscPresentOmIntervention)
+	}
+	return nil, "", nil, nil
+}
+
+// handOverUeContext hands the context of a registered ue over to the backup AMF, through the
+// shared UE context store if there is one and with Namf_Communication otherwise
+func handOverUeContext(ctx ctxt.Context, ue *context.AmfUe) {
+	amfSelf := context.AMF_Self()
+	if amfSelf.EnableDbStore {
+		if err := context.HandOverContextInDB(ctx, ue); err != nil {
+			ue.ProducerLog.Errorf("hand over UE context to backup AMF failed: %+v", err)
+		} else {
+			ue.ProducerLog.Infof("UE context handed over to backup AMF [%s]", amfSelf.BackupAmfName)
+		}
+		return
+	}
+	if amfSelf.BackupAmfUri == "" {
+		return
+	}
+	_, problemDetails, err := createUEContextForDrain(ctx, ue, amfSelf.BackupAmfUri)
+	if problemDetails != nil {
+		ue.ProducerLog.Errorf("push UE context to backup AMF failed problem[%+v]", problemDetails)
+	} else if err != nil {
+		ue.ProducerLog.Errorf("push UE context to backup AMF error: %+v", err)
+	} else {
+		ue.ProducerLog.Infof("UE context pushed to backup AMF [%s]", amfSelf.BackupAmfName)
+	}
+}
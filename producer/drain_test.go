@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+)
+
+// TestHandleDrainUeContextHandsOverToBackupAmf checks what the backup AMF finds in the shared
+// UE context store once a registered UE was drained: the context it looks up by the 5G-GUTI
+// the UE presents, and a lease it can take although the draining AMF held it.
+func TestHandleDrainUeContextHandsOverToBackupAmf(t *testing.T) {
+	self := context.AMF_Self()
+	savedDbStore := self.EnableDbStore
+	store := context.NewMemoryUeContextStore()
+	self.EnableDbStore = true
+	context.SetUeContextStore(store, 0, 1, time.Minute)
+	defer func() { self.EnableDbStore = savedDbStore }()
+
+	const (
+		supi = "imsi-208930100007498"
+		guti = "20893cafe0000000002"
+	)
+	ctx := ctxt.Background()
+	ue := self.NewAmfUe(supi)
+	defer ue.Remove()
+	ue.SetGuti(guti)
+	ue.State[models.ACCESSTYPE__3_GPP_ACCESS] = fsm.NewState(context.Registered)
+
+	// the draining AMF owns the stored context
+	rec, err := context.NewUeContextRecord(ue)
+	if err != nil {
+		t.Fatalf("encode UE context: %v", err)
+	}
+	rec.Owner, rec.LeaseExpiry = "amf-0", time.Now().Add(time.Hour)
+	if err = store.Put(ctx, rec); err != nil {
+		t.Fatalf("store UE context: %v", err)
+	}
+
+	HandleDrainUeContext(ctx, supi, "", nil)
+
+	rec, err = store.Get(ctx, context.UeContextKey{Guti: guti})
+	if err != nil {
+		t.Fatalf("backup AMF lookup by 5G-GUTI: %v", err)
+	}
+	var stored struct {
+		Supi   string `json:"supi"`
+		Guti   string `json:"guti"`
+		Custom struct {
+			State map[models.AccessType]string `json:"state"`
+		} `json:"customFieldsAmfUe"`
+	}
+	if err = json.Unmarshal(rec.Data, &stored); err != nil {
+		t.Fatalf("decode stored UE context: %v", err)
+	}
+	if stored.Supi != supi || stored.Guti != guti ||
+		stored.Custom.State[models.ACCESSTYPE__3_GPP_ACCESS] != string(context.Registered) {
+		t.Errorf("unexpected UE context handed over: %+v", stored)
+	}
+	if rec.Owner != "" {
+		t.Errorf("handed over UE context still owned by %q", rec.Owner)
+	}
+	granted, err := store.LeaseOwnership(ctx, supi, "backup-amf", time.Minute)
+	if err != nil || !granted {
+		t.Errorf("backup AMF not granted the lease: granted=%v err=%v", granted, err)
+	}
+}
+
+func TestHandleDrainUeContextPushesToBackupAmf(t *testing.T) {
+	self := context.AMF_Self()
+	savedDbStore, savedBackupAmfUri := self.EnableDbStore, self.BackupAmfUri
+	self.EnableDbStore = false
+	self.BackupAmfUri = "http://backup-amf:29518"
+	defer func() { self.EnableDbStore, self.BackupAmfUri = savedDbStore, savedBackupAmfUri }()
+
+	origCreateUEContext := createUEContextForDrain
+	defer func() { createUEContextForDrain = origCreateUEContext }()
+	var pushedSupi, pushedUri string
+	createUEContextForDrain = func(ctx ctxt.Context, ue *context.AmfUe, targetAmfUri string) (
+		*models.UeContextCreatedData, *models.ProblemDetails, error,
+	) {
+		pushedSupi, pushedUri = ue.GetSupi(), targetAmfUri
+		return &models.UeContextCreatedData{}, nil, nil
+	}
+
+	const supi = "imsi-208930100007499"
+	ue := self.NewAmfUe(supi)
+	defer ue.Remove()
+	ue.State[models.ACCESSTYPE__3_GPP_ACCESS] = fsm.NewState(context.Registered)
+
+	HandleDrainUeContext(ctxt.Background(), supi, "", nil)
+
+	if pushedSupi != supi || pushedUri != self.BackupAmfUri {
+		t.Errorf("expected UE context %s pushed to %s, got %q pushed to %q", supi, self.BackupAmfUri, pushedSupi, pushedUri)
+	}
+}
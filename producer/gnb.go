@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/omec-project/amf/context"
+	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/ngap/v2/ngapType"
+)
+
+// methods of an administrative gNB disconnect
+const (
+	GnbDisconnectNgReset = "ngReset"
+	GnbDisconnectClose   = "close"
+)
+
+var (
+	ErrInvalidGnbDisconnect = errors.New("invalid gNB disconnect method")
+	// the SCTP association of the gNB is terminated by the SCTP load balancer
+	ErrGnbAssociationNotOwned = errors.New("SCTP association not terminated by the AMF")
+)
+
+// DisconnectGnb administratively disconnects an NG-RAN node. With GnbDisconnectNgReset the
+// whole NG interface is reset (TS 38.413 8.7.4.2.1) and the UE-associated NG connections of
+// the RAN are released, the NG connection itself is kept. With GnbDisconnectClose the SCTP
+// association is closed, and the RAN context is removed once its connection handler exits.
+func DisconnectGnb(ran *context.AmfRan, method string) error {
+	switch method {
+	case GnbDisconnectNgReset:
+		ngap_message.SendNGReset(ran, ngapType.Cause{
+			Present: ngapType.CausePresentMisc,
+			Misc:    &ngapType.CauseMisc{Value: ngapType.CauseMiscPresentOmIntervention},
+		})
+		ran.RemoveAllUeInRan()
+	case GnbDisconnectClose:
+		if ran.Conn == nil {
+			return ErrGnbAssociationNotOwned
+		}
+		ran.Log.Infoln("close SCTP association")
+		if err := ran.Conn.Close(); err != nil {
+			return fmt.Errorf("close SCTP association: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidGnbDisconnect, method)
+	}
+	return nil
+}
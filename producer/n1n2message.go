@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/omec-project/amf/context"
 	gmm_message "github.com/omec-project/amf/gmm/message"
@@ -273,7 +274,13 @@ func N1N2MessageTransferProcedure(ueContextID string, reqUri string,
 			}
 			if n2Info == nil {
 				ue.ProducerLog.Debugln("forward N1 Message to UE")
-				ngap_message.SendDownlinkNasTransport(ue.RanUe[anType], nasPdu, nil)
+				origin := &context.DlNasOrigin{
+					N1MessageClass:   requestData.N1MessageContainer.GetN1MessageClass(),
+					PduSessionId:     requestData.GetPduSessionId(),
+					FailureNotifyUri: requestData.GetN1n2FailureTxfNotifURI(),
+					N1n2MsgDataUri:   context.AMF_Self().GetIPv4Uri() + reqUri,
+				}
+				ngap_message.SendN1N2DownlinkNasTransport(ue.RanUe[anType], nasPdu, origin)
 				n1n2MessageTransferRspData = models.NewN1N2MessageTransferRspData(models.N1N2MESSAGETRANSFERCAUSE_N1_N2_TRANSFER_INITIATED)
 				return n1n2MessageTransferRspData, "", nil, nil
 			}
@@ -332,9 +339,13 @@ func N1N2MessageTransferProcedure(ueContextID string, reqUri string,
 		return nil, "", nil, transferErr
 	}
 	// 504: the UE in MICO mode or the UE is only registered over Non-3GPP access and its state is CM-IDLE
-	if !ue.State[models.ACCESSTYPE__3_GPP_ACCESS].Is(context.Registered) {
+	if !ue.State[models.ACCESSTYPE__3_GPP_ACCESS].Is(context.Registered) || ue.MicoMode {
 		probDetails := utils.ProblemDetailsWithCause("UE not reachable", http.StatusGatewayTimeout, "UE is not reachable", utils.CauseUeNotReachable)
 		transferErr = models.NewN1N2MessageTransferError(*probDetails)
+		if maxWaitTime := ue.EstimatedMaxWaitTime(); maxWaitTime > 0 {
+			maxWaitingTime := int32(maxWaitTime / time.Second)
+			transferErr.ErrInfo = &models.N1N2MsgTxfrErrDetail{MaxWaitingTime: &maxWaitingTime}
+		}
 		return nil, "", nil, transferErr
 	}
 
@@ -388,6 +399,8 @@ func N1N2MessageTransferProcedure(ueContextID string, reqUri string,
 				return nil, "", problemDetails, nil
 			}
 			ngap_message.SendPaging(ue, pkg)
+			// keep the pending N1N2 message and T3513 across an AMF instance failure
+			context.StoreContextInDB(ue)
 		}
 		// TODO: WAITING_FOR_ASYNCHRONOUS_TRANSFER
 		return n1n2MessageTransferRspData, locationHeader, nil, nil
@@ -417,6 +430,7 @@ func N1N2MessageTransferProcedure(ueContextID string, reqUri string,
 					return nil, "", problemDetails, nil
 				}
 				gmm_message.SendNotification(ue.RanUe[models.ACCESSTYPE__3_GPP_ACCESS], nasMsg)
+				context.StoreContextInDB(ue)
 			}
 			return n1n2MessageTransferRspData, locationHeader, nil, nil
 		} else {
@@ -452,6 +466,7 @@ func N1N2MessageTransferProcedure(ueContextID string, reqUri string,
 				return nil, "", problemDetails, nil
 			}
 			ngap_message.SendPaging(ue, pkg)
+			context.StoreContextInDB(ue)
 			return n1n2MessageTransferRspData, locationHeader, nil, nil
 		}
 	}
@@ -589,6 +604,7 @@ func N1N2MessageSubscribeProcedure(ueContextID string,
 	} else {
 		ueN1N2InfoSubscriptionCreatedData.N1n2NotifySubscriptionId = strconv.Itoa(int(newSubscriptionID))
 		ue.N1N2MessageSubscription.Store(newSubscriptionID, ueN1N2InfoSubscriptionCreateData)
+		context.StoreContextInDB(ue)
 	}
 	return ueN1N2InfoSubscriptionCreatedData, nil
 }
@@ -616,6 +632,13 @@ func N1N2MessageUnSubscribeProcedure(ueContextID string, subscriptionID string)
 		return problemDetails
 	}
 
-	ue.N1N2MessageSubscription.Delete(subscriptionID)
+	// subscriptions are stored with the int64 ID allocated in N1N2MessageSubscribeProcedure
+	id, err := strconv.ParseInt(subscriptionID, 10, 64)
+	if err != nil {
+		return utils.ProblemDetailsWithCause("Subscription not found", http.StatusNotFound, "N1N2 message subscription not found", utils.CauseSubscriptionNotFound)
+	}
+	ue.N1N2MessageSubscription.Delete(id)
+	ue.N1N2MessageSubscribeIDGenerator.FreeID(id)
+	context.StoreContextInDB(ue)
 	return nil
 }
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"net/http"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/gmm"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/openapi/v2/utils"
+	"github.com/omec-project/util/httpwrapper"
+)
+
+// HandleNssaaNotification handles the slice re-authentication and revocation notifications
+// of the NSSAAF (TS 29.526 5.2.2.3, 5.2.2.4). The procedure itself runs on the UE goroutine.
+func HandleNssaaNotification(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
+	notification := request.Body.(consumer.SliceAuthNotification)
+	logger.ProducerLog.Infof("handle NSSAA notification [%s]", notification.NotifType)
+
+	switch notification.NotifType {
+	case consumer.SliceAuthNotifTypeReauth, consumer.SliceAuthNotifTypeRevocation:
+	case "":
+		problemDetails := utils.ProblemDetailsMandatoryIeMissing("Missing IE [NotifType] in SliceAuthNotification")
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	default:
+		problemDetails := utils.ProblemDetailsNotImplemented("Unsupported [NotifType] in SliceAuthNotification")
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+
+	supi := request.Params["supi"]
+	if notification.Supi != "" {
+		supi = notification.Supi
+	}
+	ue, ok := context.AMF_Self().AmfUeFindBySupi(supi)
+	if !ok {
+		problemDetails := utils.ProblemDetailsContextNotFound("UE context not found")
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+
+	sbiMsg := context.SbiMsg{
+		UeContextId: ue.GetSupi(),
+		ReqUri:      notification.NotifType,
+		Msg:         notification.Snssai,
+		Result:      make(chan context.SbiResponseMsg, 10),
+		Context:     ctx,
+	}
+	ue.EventChannel.UpdateSbiHandler(HandleNssaaNotificationProcedure)
+	ue.EventChannel.SubmitMessage(sbiMsg)
+	msg := <-sbiMsg.Result
+	if msg.ProblemDetails != nil {
+		problemDetails := msg.ProblemDetails.(*models.ProblemDetails)
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+	return httpwrapper.NewResponse(http.StatusNoContent, nil, nil)
+}
+
+func HandleNssaaNotificationProcedure(ctx ctxt.Context, supi, notifType string, msg any) (any, string, any, any) {
+	ue, ok := context.AMF_Self().AmfUeFindBySupi(supi)
+	if !ok {
+		return nil, "", utils.ProblemDetailsContextNotFound("UE context not found"), nil
+	}
+	snssai := msg.(models.Snssai)
+
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	if !ue.State[anType].Is(context.Registered) && ue.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Is(context.Registered) {
+		anType = models.ACCESSTYPE_NON_3_GPP_ACCESS
+	}
+
+	var err error
+	if notifType == consumer.SliceAuthNotifTypeRevocation {
+		err = gmm.RevokeNssaa(ctx, ue, anType, snssai)
+	} else {
+		err = gmm.StartNssaaReauthentication(ue, anType, snssai)
+	}
+	if err != nil {
+		ue.GmmLog.Errorf("NSSAA %s for S-NSSAI %+v failed: %v", notifType, snssai, err)
+		return nil, "", utils.ProblemDetailsMandatoryIeIncorrect(err.Error()), nil
+	}
+	return nil, "", nil, nil
+}
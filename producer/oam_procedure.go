@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/gmm"
+	ngap_message "github.com/omec-project/amf/ngap/message"
+	"github.com/omec-project/ngap/v2/aper"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+)
+
+// how long the outcome of an OAM procedure is awaited
+const (
+	DefaultOamProcedureTimeout = 30 * time.Second
+	MaxOamProcedureTimeout     = 5 * time.Minute
+)
+
+var (
+	ErrInvalidOamProcedure = errors.New("invalid OAM procedure")
+	// the procedure cannot run on the UE in its current state
+	ErrOamProcedureNotApplicable = errors.New("OAM procedure not applicable")
+)
+
+// NgapCause is an NGAP cause (TS 38.413 9.3.1.2); Group is radioNetwork, transport, nas,
+// protocol or misc
+type NgapCause struct {
+	Group string          `json:"group"`
+	Value aper.Enumerated `json:"value"`
+}
+
+// ngapCauseGroups are the NGAP cause groups and the highest value of each group
+var ngapCauseGroups = map[string]struct {
+	present  int
+	maxValue aper.Enumerated
+}{
+	"radioNetwork": {ngapType.CausePresentRadioNetwork, ngapType.CauseRadioNetworkPresentEredcapUeNotSupported},
+	"transport":    {ngapType.CausePresentTransport, ngapType.CauseTransportPresentUnspecified},
+	"nas":          {ngapType.CausePresentNas, ngapType.CauseNasPresentMobileIABNotAuthorized},
+	"protocol":     {ngapType.CausePresentProtocol, ngapType.CauseProtocolPresentUnspecified},
+	"misc":         {ngapType.CausePresentMisc, ngapType.CauseMiscPresentUnspecified},
+}
+
+// OamProcedureParams are the parameters of an OAM procedure, fields not used by the
+// procedure are ignored
+type OamProcedureParams struct {
+	// deregistration: re-registration required indication, true if unset
+	ReRegistrationRequired *bool `json:"reRegistrationRequired,omitempty"`
+	// deregistration: 5GMM cause of the Deregistration Request, none if zero
+	Cause5GMM uint8 `json:"cause5GMM,omitempty"`
+	// ueContextRelease: cause of the UE Context Release Command, misc om-intervention if unset
+	NgapCause *NgapCause `json:"ngapCause,omitempty"`
+	// seconds the outcome is awaited, DefaultOamProcedureTimeout if zero
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// oamProcedureRequest is the message of an OAM procedure on the UE EventChannel
+type oamProcedureRequest struct {
+	procedure context.OamProcedure
+	params    OamProcedureParams
+}
+
+// timeout returns how long the outcome of the procedure is awaited
+func (params OamProcedureParams) timeout() time.Duration {
+	if params.Timeout == 0 {
+		return DefaultOamProcedureTimeout
+	}
+	return time.Duration(params.Timeout) * time.Second
+}
+
+// validate checks the parameters of procedure
+func (params OamProcedureParams) validate(procedure context.OamProcedure) error {
+	switch procedure {
+	case context.OamProcedureDeregistration, context.OamProcedureReauthentication,
+		context.OamProcedureGutiReallocation, context.OamProcedurePaging:
+	case context.OamProcedureUeContextRelease:
+		if cause := params.NgapCause; cause != nil {
+			group, ok := ngapCauseGroups[cause.Group]
+			if !ok {
+				return fmt.Errorf("%w: unknown NGAP cause group %q", ErrInvalidOamProcedure, cause.Group)
+			}
+			if cause.Value < 0 || cause.Value > group.maxValue {
+				return fmt.Errorf("%w: NGAP cause value %d out of range of group %s",
+					ErrInvalidOamProcedure, cause.Value, cause.Group)
+			}
+		}
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidOamProcedure, procedure)
+	}
+	if params.Timeout < 0 || params.timeout() > MaxOamProcedureTimeout {
+		return fmt.Errorf("%w: timeout must be between 1 and %d seconds",
+			ErrInvalidOamProcedure, int(MaxOamProcedureTimeout.Seconds()))
+	}
+	return nil
+}
+
+// RunOamProcedure runs procedure on the UE identified by supi over 3GPP access, and returns
+// its outcome once the procedure ended or the timeout of params expired. The procedure is
+// started on the UE EventChannel; its outcome is awaited outside of it, as the procedure
+// completes with the NAS and NGAP messages handled on the EventChannel.
+func RunOamProcedure(ctx ctxt.Context, supi string, procedure context.OamProcedure,
+	params OamProcedureParams,
+) (context.OamProcedureResult, error) {
+	if err := params.validate(procedure); err != nil {
+		return context.OamProcedureResult{}, err
+	}
+	ue, ok := context.AMF_Self().AmfUeFindBySupi(supi)
+	if !ok {
+		return context.OamProcedureResult{}, context.ErrUeContextNotFound
+	}
+	if ue.EventChannel == nil {
+		return context.OamProcedureResult{}, fmt.Errorf("%w: UE has no event channel", ErrOamProcedureNotApplicable)
+	}
+
+	sbiMsg := context.SbiMsg{
+		UeContextId: ue.GetSupi(),
+		ReqUri:      "",
+		Msg:         oamProcedureRequest{procedure: procedure, params: params},
+		Result:      make(chan context.SbiResponseMsg, 1),
+		Context:     ctx,
+	}
+	ue.EventChannel.UpdateSbiHandler(HandleOamProcedure)
+	ue.EventChannel.SubmitMessage(sbiMsg)
+	msg := <-sbiMsg.Result
+	if err, ok := msg.TransferErr.(error); ok {
+		return context.OamProcedureResult{}, err
+	}
+
+	var result context.OamProcedureResult
+	switch resp := msg.RespData.(type) {
+	case context.OamProcedureResult:
+		result = resp
+	case <-chan context.OamProcedureResult:
+		select {
+		case result = <-resp:
+		case <-time.After(params.timeout()):
+			ue.CancelOamProcedure(procedure)
+			result = context.OamProcedureResult{
+				Procedure: procedure,
+				Supi:      ue.GetSupi(),
+				Outcome:   context.OamOutcomeTimeout,
+			}
+		case <-ctx.Done():
+			ue.CancelOamProcedure(procedure)
+			return context.OamProcedureResult{}, ctx.Err()
+		}
+	}
+	summary := ue.Summary()
+	result.GmmState, result.CmState, result.Guti = summary.GmmState, summary.CmState, summary.Guti
+	return result, nil
+}
+
+// HandleOamProcedure runs on the UE EventChannel and starts an OAM procedure. It returns the
+// channel receiving the outcome of the procedure, or the outcome itself if the procedure
+// ended at once.
+func HandleOamProcedure(ctx ctxt.Context, supi, reqUri string, msg any) (any, string, any, any) {
+	ue, ok := context.AMF_Self().AmfUeFindBySupi(supi)
+	if !ok {
+		return nil, "", nil, context.ErrUeContextNotFound
+	}
+	request := msg.(oamProcedureRequest)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	if request.procedure != context.OamProcedureUeContextRelease && !ue.State[anType].Is(context.Registered) {
+		return nil, "", nil, fmt.Errorf("%w: UE is in GMM state %s", ErrOamProcedureNotApplicable,
+			ue.State[anType].Current())
+	}
+
+	switch request.procedure {
+	case context.OamProcedureDeregistration:
+		if ue.CmIdle(anType) {
+			// there is no NAS signalling connection to the UE, it is deregistered locally
+			if err := startNwInitiatedDeregistration(ctx, ue, anType, request.params); err != nil {
+				return nil, "", nil, err
+			}
+			return context.OamProcedureResult{
+				Procedure: request.procedure,
+				Supi:      supi,
+				Outcome:   context.OamOutcomeCompleted,
+				Detail:    "UE in CM-IDLE deregistered locally",
+			}, "", nil, nil
+		}
+	case context.OamProcedureReauthentication:
+		if ue.CmIdle(anType) {
+			return nil, "", nil, fmt.Errorf("%w: UE is CM-IDLE", ErrOamProcedureNotApplicable)
+		}
+		if onGoing := ue.GetOnGoing(anType).Procedure; onGoing != context.OnGoingProcedureNothing {
+			return nil, "", nil, fmt.Errorf("%w: %s procedure ongoing", ErrOamProcedureNotApplicable, onGoing)
+		}
+	case context.OamProcedureGutiReallocation, context.OamProcedureUeContextRelease:
+		if ue.CmIdle(anType) {
+			return nil, "", nil, fmt.Errorf("%w: UE is CM-IDLE", ErrOamProcedureNotApplicable)
+		}
+	case context.OamProcedurePaging:
+		if ue.CmConnect(anType) {
+			return nil, "", nil, fmt.Errorf("%w: UE is CM-CONNECTED", ErrOamProcedureNotApplicable)
+		}
+	}
+
+	result, err := ue.StartOamProcedure(request.procedure)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %w", ErrOamProcedureNotApplicable, err)
+	}
+	if err = startOamProcedure(ctx, ue, anType, request); err != nil {
+		ue.CancelOamProcedure(request.procedure)
+		return nil, "", nil, err
+	}
+	ue.ProducerLog.Infof("%s procedure started by OAM", request.procedure)
+	return result, "", nil, nil
+}
+
+// startOamProcedure sends the first message of the OAM procedure to the UE
+func startOamProcedure(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType,
+	request oamProcedureRequest,
+) error {
+	switch request.procedure {
+	case context.OamProcedureDeregistration:
+		return startNwInitiatedDeregistration(ctx, ue, anType, request.params)
+	case context.OamProcedureReauthentication:
+		return gmm.StartReauthentication(ctx, ue, anType)
+	case context.OamProcedureGutiReallocation:
+		if err := gmm.ReallocateGuti(ue, anType); err != nil {
+			return fmt.Errorf("%w: %w", ErrOamProcedureNotApplicable, err)
+		}
+	case context.OamProcedurePaging:
+		ue.SetOnGoing(anType, &context.OnGoingProcedureWithPrio{
+			Procedure: context.OnGoingProcedurePaging,
+		})
+		pkg, err := ngap_message.BuildPaging(ue, nil, false)
+		if err != nil {
+			return fmt.Errorf("build Paging: %w", err)
+		}
+		ngap_message.SendPaging(ue, pkg)
+	case context.OamProcedureUeContextRelease:
+		causePresent, cause := ngapType.CausePresentMisc, ngapType.CauseMiscPresentOmIntervention
+		if request.params.NgapCause != nil {
+			causePresent = ngapCauseGroups[request.params.NgapCause.Group].present
+			cause = request.params.NgapCause.Value
+		}
+		ngap_message.SendUEContextReleaseCommand(ue.GetRanUe(anType), context.UeContextN2NormalRelease,
+			causePresent, cause)
+	}
+	return nil
+}
+
+// startNwInitiatedDeregistration starts a network initiated deregistration with the
+// re-registration indication and 5GMM cause of params
+func startNwInitiatedDeregistration(ctx ctxt.Context, ue *context.AmfUe, anType models.AccessType,
+	params OamProcedureParams,
+) error {
+	reRegistrationRequired := true
+	if params.ReRegistrationRequired != nil {
+		reRegistrationRequired = *params.ReRegistrationRequired
+	}
+	return gmm.GmmFSM.SendEvent(ctx, ue.State[anType], gmm.NwInitiatedDeregistrationEvent, fsm.ArgsType{
+		gmm.ArgAmfUe:                  ue,
+		gmm.ArgAccessType:             anType,
+		gmm.ArgReRegistrationRequired: reRegistrationRequired,
+		gmm.ArgCause5GMM:              params.Cause5GMM,
+	})
+}
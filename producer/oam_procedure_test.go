@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/factory"
+	"github.com/omec-project/amf/gmm"
+	ngaputil "github.com/omec-project/amf/ngap/util"
+	"github.com/omec-project/nas/v2"
+	"github.com/omec-project/nas/v2/nasMessage"
+	"github.com/omec-project/ngap/v2/ngapType"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/util/fsm"
+)
+
+// gmmFSM is the GMM FSM of the AMF, which init replaces with a mock
+var gmmFSM = gmm.GmmFSM
+
+// useGmmFSM makes the GMM procedures started by OAM run through the GMM FSM of the AMF
+func useGmmFSM(t *testing.T) {
+	t.Helper()
+	mockFSM := gmm.GmmFSM
+	gmm.GmmFSM = gmmFSM
+	t.Cleanup(func() { gmm.GmmFSM = mockFSM })
+
+	enableKafka := factory.AmfConfig.Configuration.KafkaInfo.EnableKafka
+	disabled := false
+	factory.AmfConfig.Configuration.KafkaInfo.EnableKafka = &disabled
+	t.Cleanup(func() { factory.AmfConfig.Configuration.KafkaInfo.EnableKafka = enableKafka })
+}
+
+// newOamProcedureTestUe returns a UE registered and in CM-CONNECTED over 3GPP access
+func newOamProcedureTestUe(t *testing.T, supi string) *context.AmfUe {
+	t.Helper()
+	ran := context.NewAmfRanDefault()
+	ran.AnType = models.ACCESSTYPE__3_GPP_ACCESS
+	ran.Conn = &ngaputil.TestConn{}
+	ranUe, err := ran.NewRanUe(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ue := context.AMF_Self().NewAmfUe(supi)
+	t.Cleanup(ue.Remove)
+	ue.AttachRanUe(ranUe)
+	ue.PlmnId = models.PlmnId{Mcc: "208", Mnc: "93"}
+	ue.State[models.ACCESSTYPE__3_GPP_ACCESS] = fsm.NewState(context.Registered)
+	return ue
+}
+
+// oamProcedureResult returns the result channel of the OAM procedure started by HandleOamProcedure
+func oamProcedureResult(t *testing.T, response any, err any) <-chan context.OamProcedureResult {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, ok := response.(<-chan context.OamProcedureResult)
+	if !ok {
+		t.Fatalf("expected the OAM procedure to be started, got %+v", response)
+	}
+	return result
+}
+
+func TestOamProcedureParamsValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		procedure context.OamProcedure
+		params    OamProcedureParams
+		valid     bool
+	}{
+		{"deregistration", context.OamProcedureDeregistration, OamProcedureParams{Cause5GMM: 3}, true},
+		{"unknown procedure", "detach", OamProcedureParams{}, false},
+		{"negative timeout", context.OamProcedurePaging, OamProcedureParams{Timeout: -1}, false},
+		{"timeout too long", context.OamProcedurePaging, OamProcedureParams{Timeout: 3600}, false},
+		{
+			"release cause", context.OamProcedureUeContextRelease,
+			OamProcedureParams{NgapCause: &NgapCause{Group: "nas", Value: ngapType.CauseNasPresentDeregister}}, true,
+		},
+		{
+			"unknown cause group", context.OamProcedureUeContextRelease,
+			OamProcedureParams{NgapCause: &NgapCause{Group: "radio", Value: 0}}, false,
+		},
+		{
+			"cause value out of range", context.OamProcedureUeContextRelease,
+			OamProcedureParams{NgapCause: &NgapCause{Group: "transport", Value: 2}}, false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.params.validate(tc.procedure)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidOamProcedure) {
+				t.Errorf("expected ErrInvalidOamProcedure, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunOamProcedureUnknownUe(t *testing.T) {
+	_, err := RunOamProcedure(ctxt.Background(), "imsi-208930000009999", context.OamProcedurePaging, OamProcedureParams{})
+	if !errors.Is(err, context.ErrUeContextNotFound) {
+		t.Errorf("expected ErrUeContextNotFound, got %v", err)
+	}
+}
+
+func TestHandleOamProcedureReauthenticationFailure(t *testing.T) {
+	useGmmFSM(t)
+	nrf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer nrf.Close()
+	amfSelf := context.AMF_Self()
+	nrfUri := amfSelf.NrfUri
+	amfSelf.NrfUri = nrf.URL
+	defer func() { amfSelf.NrfUri = nrfUri }()
+
+	supi := "imsi-208930100007601"
+	ue := newOamProcedureTestUe(t, supi)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+
+	response, _, _, err := HandleOamProcedure(ctxt.Background(), supi, "",
+		oamProcedureRequest{procedure: context.OamProcedureReauthentication})
+	result := oamProcedureResult(t, response, err)
+	select {
+	case r := <-result:
+		if r.Outcome != context.OamOutcomeFailed {
+			t.Errorf("expected the re-authentication to fail, got %s", r.Outcome)
+		}
+	default:
+		t.Fatal("expected the re-authentication to end without an AUSF")
+	}
+	if !ue.State[anType].Is(context.Registered) {
+		t.Errorf("expected GMM state Registered, got %s", ue.State[anType].Current())
+	}
+	if procedure := ue.GetOnGoing(anType).Procedure; procedure != context.OnGoingProcedureNothing {
+		t.Errorf("expected no ongoing procedure, got %s", procedure)
+	}
+}
+
+func TestHandleOamProcedureGutiReallocation(t *testing.T) {
+	useGmmFSM(t)
+	supi := "imsi-208930100007602"
+	ue := newOamProcedureTestUe(t, supi)
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	amfSelf := context.AMF_Self()
+	oldGuti := ue.GetGuti()
+
+	response, _, _, err := HandleOamProcedure(ctxt.Background(), supi, "",
+		oamProcedureRequest{procedure: context.OamProcedureGutiReallocation})
+	result := oamProcedureResult(t, response, err)
+	if ue.GetGuti() == oldGuti || ue.GetOldGuti() != oldGuti {
+		t.Fatalf("expected a new 5G-GUTI besides %s, got %s", oldGuti, ue.GetGuti())
+	}
+
+	message := nas.NewGmmMessage()
+	message.GmmHeader.SetMessageType(nas.MsgTypeConfigurationUpdateComplete)
+	message.ConfigurationUpdateComplete = nasMessage.NewConfigurationUpdateComplete(0)
+	if err := gmm.GmmFSM.SendEvent(ctxt.Background(), ue.State[anType], gmm.GmmMessageEvent, fsm.ArgsType{
+		gmm.ArgAmfUe:         ue,
+		gmm.ArgAccessType:    anType,
+		gmm.ArgNASMessage:    message,
+		gmm.ArgProcedureCode: ngapType.ProcedureCodeUplinkNASTransport,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-result:
+		if r.Outcome != context.OamOutcomeCompleted {
+			t.Errorf("expected the 5G-GUTI reallocation to complete, got %s", r.Outcome)
+		}
+	default:
+		t.Fatal("expected the 5G-GUTI reallocation to end on Configuration Update Complete")
+	}
+	if _, ok := amfSelf.AmfUeFindByGuti(oldGuti); ok {
+		t.Error("expected the old 5G-GUTI not to identify the UE anymore")
+	}
+	if found, ok := amfSelf.AmfUeFindByGuti(ue.GetGuti()); !ok || found != ue {
+		t.Error("expected the new 5G-GUTI to identify the UE")
+	}
+}
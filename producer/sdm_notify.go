@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"net/http"
+	"strings"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/gmm"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/openapi/v2/utils"
+	"github.com/omec-project/util/httpwrapper"
+)
+
+// HandleSdmChangeNotification handles the Nudm_SDM_Notification of the UDM (TS 29.503
+// 5.2.2.3.2). A change of the AM data refreshes it and delivers the new SoR and UPU
+// information to the UE, a change of the trace data activates or deactivates trace; the
+// procedure itself runs on the UE goroutine.
+func HandleSdmChangeNotification(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
+	notification := request.Body.(models.ModificationNotification)
+	logger.ProducerLog.Infof("handle SDM change notification [%s]", notification.GetSubscriptionId())
+
+	if amDataChanged, traceDataChanged := changedSdmResources(notification); !amDataChanged && !traceDataChanged {
+		return httpwrapper.NewResponse(http.StatusNoContent, nil, nil)
+	}
+
+	ue, ok := context.AMF_Self().AmfUeFindBySupi(request.Params["supi"])
+	if !ok {
+		problemDetails := utils.ProblemDetailsContextNotFound("UE context not found")
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+
+	sbiMsg := context.SbiMsg{
+		UeContextId: ue.GetSupi(),
+		ReqUri:      notification.GetSubscriptionId(),
+		Msg:         notification,
+		Result:      make(chan context.SbiResponseMsg, 10),
+		Context:     ctx,
+	}
+	ue.EventChannel.UpdateSbiHandler(HandleSdmChangeNotificationProcedure)
+	ue.EventChannel.SubmitMessage(sbiMsg)
+	msg := <-sbiMsg.Result
+	if msg.ProblemDetails != nil {
+		problemDetails := msg.ProblemDetails.(*models.ProblemDetails)
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+	return httpwrapper.NewResponse(http.StatusNoContent, nil, nil)
+}
+
+func HandleSdmChangeNotificationProcedure(ctx ctxt.Context, supi, subscriptionId string, msg any) (any, string, any, any) {
+	ue, ok := context.AMF_Self().AmfUeFindBySupi(supi)
+	if !ok {
+		return nil, "", utils.ProblemDetailsContextNotFound("UE context not found"), nil
+	}
+	if subscriptionId != "" && ue.SdmSubscriptionId != "" && subscriptionId != ue.SdmSubscriptionId {
+		ue.GmmLog.Warnf("SDM change notification for unknown subscription [%s]", subscriptionId)
+	}
+
+	amDataChanged, traceDataChanged := changedSdmResources(msg.(models.ModificationNotification))
+	if traceDataChanged {
+		if err := gmm.RefreshTraceData(ctx, ue); err != nil {
+			ue.GmmLog.Errorln(err)
+			return nil, "", utils.ProblemDetailsSystemFailure(err.Error()), nil
+		}
+	}
+	if !amDataChanged {
+		return nil, "", nil, nil
+	}
+
+	problemDetails, err := consumer.SDMGetAmData(ctx, ue)
+	if problemDetails != nil {
+		ue.GmmLog.Errorf("SDM_Get AmData Failed Problem[%+v]", problemDetails)
+		return nil, "", problemDetails, nil
+	} else if err != nil {
+		ue.GmmLog.Errorf("SDM_Get AmData Error[%+v]", err)
+		return nil, "", utils.ProblemDetailsSystemFailure(err.Error()), nil
+	}
+
+	anType := models.ACCESSTYPE__3_GPP_ACCESS
+	if !ue.State[anType].Is(context.Registered) && ue.State[models.ACCESSTYPE_NON_3_GPP_ACCESS].Is(context.Registered) {
+		anType = models.ACCESSTYPE_NON_3_GPP_ACCESS
+	}
+	if !ue.State[anType].Is(context.Registered) {
+		return nil, "", nil, nil
+	}
+	if err := gmm.DeliverSorAndUpu(ue, anType); err != nil {
+		ue.GmmLog.Errorf("SoR/UPU delivery failed: %+v", err)
+		return nil, "", utils.ProblemDetailsMandatoryIeIncorrect(err.Error()), nil
+	}
+	return nil, "", nil, nil
+}
+
+func changedSdmResources(notification models.ModificationNotification) (amDataChanged, traceDataChanged bool) {
+	for _, item := range notification.NotifyItems {
+		switch {
+		case strings.HasSuffix(item.ResourceId, "/am-data"):
+			amDataChanged = true
+		case strings.HasSuffix(item.ResourceId, "/trace-data"):
+			traceDataChanged = true
+		}
+	}
+	return amDataChanged, traceDataChanged
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package producer
+
+import (
+	ctxt "context"
+	"fmt"
+	"net/http"
+
+	"github.com/omec-project/amf/consumer"
+	"github.com/omec-project/amf/context"
+	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/openapi/v2/models"
+	"github.com/omec-project/openapi/v2/utils"
+	"github.com/omec-project/util/httpwrapper"
+)
+
+// UE policy association notifications, handled on the UE goroutine
+const (
+	uePolicyNotifyUpdate    = "update"
+	uePolicyNotifyTerminate = "terminate"
+)
+
+// TS 29.525 4.2.4.2
+func HandleUePolicyControlUpdateNotifyUpdate(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
+	logger.ProducerLog.Infoln("handle UE Policy Control Update Notify [Policy update notification]")
+	return handleUePolicyControlNotify(ctx, request, uePolicyNotifyUpdate)
+}
+
+// TS 29.525 4.2.4.3
+func HandleUePolicyControlUpdateNotifyTerminate(ctx ctxt.Context, request *httpwrapper.Request) *httpwrapper.Response {
+	logger.ProducerLog.Infoln("handle UE Policy Control Update Notify [Request for termination of the policy association]")
+	return handleUePolicyControlNotify(ctx, request, uePolicyNotifyTerminate)
+}
+
+func handleUePolicyControlNotify(ctx ctxt.Context, request *httpwrapper.Request, notifyType string) *httpwrapper.Response {
+	polAssoID := request.Params["polAssoId"]
+	ue, ok := context.AMF_Self().AmfUeFindByUePolicyAssociationID(polAssoID)
+	if !ok {
+		problemDetails := utils.ProblemDetailsContextNotFound(fmt.Sprintf("Policy Association ID[%s] Not Found", polAssoID))
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+
+	sbiMsg := context.SbiMsg{
+		UeContextId: polAssoID,
+		ReqUri:      notifyType,
+		Msg:         request.Body,
+		Result:      make(chan context.SbiResponseMsg, 10),
+		Context:     ctx,
+	}
+	ue.EventChannel.UpdateSbiHandler(UePolicyControlNotifyProcedure)
+	ue.EventChannel.SubmitMessage(sbiMsg)
+	msg := <-sbiMsg.Result
+	if msg.ProblemDetails != nil {
+		problemDetails := msg.ProblemDetails.(*models.ProblemDetails)
+		return httpwrapper.NewResponse(int(problemDetails.GetStatus()), nil, problemDetails)
+	}
+	return httpwrapper.NewResponse(http.StatusNoContent, nil, nil)
+}
+
+func UePolicyControlNotifyProcedure(ctx ctxt.Context, polAssoID, notifyType string, msg any) (any, string, any, any) {
+	ue, ok := context.AMF_Self().AmfUeFindByUePolicyAssociationID(polAssoID)
+	if !ok {
+		problemDetails := utils.ProblemDetailsContextNotFound(fmt.Sprintf("Policy Association ID[%s] Not Found", polAssoID))
+		return nil, "", problemDetails, nil
+	}
+
+	switch notifyType {
+	case uePolicyNotifyUpdate:
+		ue.UePolicyTriggers = msg.(consumer.UePolicyUpdate).Triggers
+	case uePolicyNotifyTerminate:
+		logger.CallbackLog.Infof("Cause of UE Policy termination[%+v]", msg.(models.TerminationNotification).Cause)
+		problemDetails, err := consumer.UEPolicyControlDelete(ctx, ue)
+		if problemDetails != nil {
+			ue.ProducerLog.Errorf("UE Policy Control Delete Failed Problem[%+v]", problemDetails)
+		} else if err != nil {
+			ue.ProducerLog.Errorf("UE Policy Control Delete Error[%v]", err.Error())
+		}
+	}
+	return nil, "", nil, nil
+}
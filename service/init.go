@@ -15,6 +15,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -36,6 +37,7 @@ import (
 	ngap_service "github.com/omec-project/amf/ngap/service"
 	"github.com/omec-project/amf/oam"
 	"github.com/omec-project/amf/polling"
+	"github.com/omec-project/amf/producer"
 	"github.com/omec-project/amf/producer/callback"
 	"github.com/omec-project/amf/tracing"
 	"github.com/omec-project/amf/util"
@@ -49,6 +51,7 @@ import (
 	utilLogger "github.com/omec-project/util/logger"
 	"github.com/urfave/cli/v3"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap/zapcore"
 )
 
 type AMF struct{}
@@ -92,6 +95,7 @@ func (amf *AMF) Initialize(ctx ctxt.Context, c *cli.Command) error {
 	}
 
 	amf.setLogLevel()
+	amf.setLogging()
 
 	// Initiating a server for profiling
 	if factory.AmfConfig.Configuration.DebugProfilePort != 0 {
@@ -126,6 +130,29 @@ func (amf *AMF) setLogLevel() {
 	utilLogger.ApplyLogSetting("Util", cfgLogger.Util, utilLogger.UtilLog, utilLogger.SetLogLevel)
 }
 
+// setLogging applies the log encoding and the per-subsystem levels, which override the
+// AMF level of the logger section
+func (amf *AMF) setLogging() {
+	cfgLogging := factory.AmfConfig.Configuration.Logging
+	if cfgLogging == nil {
+		return
+	}
+
+	if err := logger.SetEncoding(cfgLogging.Encoding); err != nil {
+		logger.InitLog.Errorln(err)
+	}
+	for subsystem, level := range cfgLogging.Levels {
+		zapLevel, err := zapcore.ParseLevel(level)
+		if err != nil {
+			logger.InitLog.Errorf("invalid log level of %s: %v", subsystem, err)
+			continue
+		}
+		if err = logger.SetSubsystemLevel(subsystem, zapLevel); err != nil {
+			logger.InitLog.Errorln(err)
+		}
+	}
+}
+
 func (amf *AMF) Start() {
 	logger.InitLog.Infoln("server started")
 	var err error
@@ -142,6 +169,8 @@ func (amf *AMF) Start() {
 		AllowAllOrigins:  true,
 		MaxAge:           86400,
 	}))
+	router.Use(metrics.SbiServerMetrics())
+	router.Use(tracing.ExtractTraceContext())
 
 	httpcallback.AddService(router)
 	oam.AddService(router)
@@ -166,7 +195,8 @@ func (amf *AMF) Start() {
 
 	self := amfContext.AMF_Self()
 	util.InitAmfContext(self)
-	if self.EnableDbStore {
+	// DRSM shares ID ranges between the AMF instances using the same MongoDB
+	if self.EnableDbStore && factory.AmfConfig.Configuration.UeContextStore.Backend == factory.UeContextStoreMongoDB {
 		self.Drsm, err = util.InitDrsm()
 		if err != nil {
 			logger.InitLog.Errorf("initialise DRSM failed, %v", err.Error())
@@ -217,11 +247,13 @@ func (amf *AMF) Start() {
 			case <-ctx.Done():
 				return
 			case cfg := <-contextUpdateChan:
+				oldGuamiList := slices.Clone(self.ServedGuamiList)
 				err = amfContext.UpdateAmfContext(self, cfg)
 				if err != nil {
 					logger.PollConfigLog.Errorf("AMF context update failed: %v", err)
 				} else {
 					logger.PollConfigLog.Debugln("AMF context updated from WebConsole config")
+					notifyGuamiChanges(self, oldGuamiList)
 				}
 			}
 		}
@@ -240,12 +272,40 @@ func (amf *AMF) Start() {
 		nrfCache.InitNrfCaching(self.NrfCacheEvictionInterval*time.Second, consumer.SendNfDiscoveryToNrfCacheQuery)
 	}
 
+	if racs := factory.AmfConfig.Configuration.Racs; racs != nil && racs.Enabled && racs.UcmfUri != "" {
+		self.Ucmf = consumer.NewUcmfClient(racs.UcmfUri)
+	}
+
 	if self.EnableSctpLb {
 		go StartGrpcServer(ctx, self.SctpGrpcPort)
 	}
 
 	if self.EnableDbStore {
-		go amfContext.SetupAmfCollection()
+		go func() {
+			if err := amfContext.SetupUeContextStore(factory.AmfConfig.Configuration.UeContextStore); err != nil {
+				logger.InitLog.Errorf("setup UE context store failed: %v", err)
+				return
+			}
+			// recovery after restart: subscriptions restored from the DB learn that
+			// the GUAMIs served by this AMF are available again
+			if amfContext.LoadAMFStatusSubscriptionsFromDB() > 0 && !self.IsDraining() {
+				callback.SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_AVAILABLE, slices.Clone(self.ServedGuamiList))
+			}
+		}()
+	}
+
+	if self.OverloadControl != nil {
+		go self.OverloadControl.Run(ctx, func() {
+			self.AmfRanPool.Range(func(key, value any) bool {
+				ngap_message.SendAmfOverloadStart(value.(*amfContext.AmfRan))
+				return true
+			})
+		}, func() {
+			self.AmfRanPool.Range(func(key, value any) bool {
+				ngap_message.SendOverloadStop(value.(*amfContext.AmfRan))
+				return true
+			})
+		})
 	}
 
 	var tracerProvider *sdktrace.TracerProvider
@@ -253,7 +313,14 @@ func (amf *AMF) Start() {
 	signalChannel := make(chan os.Signal, 1)
 	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-signalChannel
+		select {
+		case <-signalChannel:
+			// planned removal: hand UEs over to the backup AMF before terminating
+			if factory.AmfConfig.Configuration.PlannedRemoval != nil || self.IsDraining() {
+				producer.AmfDrainProcedure(ctx)
+			}
+		case <-self.DrainDone():
+		}
 		amf.Terminate(cancelServices, &wg, tracerProvider)
 		os.Exit(0)
 	}()
@@ -286,6 +353,23 @@ func (amf *AMF) Start() {
 	}
 }
 
+// notifyGuamiChanges reports the GUAMIs added or removed by a WebConsole config
+// update to the AMF status change subscribers
+func notifyGuamiChanges(self *amfContext.AMFContext, oldGuamiList []models.Guami) {
+	if self.IsDraining() {
+		return
+	}
+	added, removed := amfContext.DiffGuamiList(oldGuamiList, self.ServedGuamiList)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	logger.PollConfigLog.Infof("served GUAMIs changed: %d available, %d unavailable", len(added), len(removed))
+	go func() {
+		callback.SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_UNAVAILABLE, removed)
+		callback.SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_AVAILABLE, added)
+	}()
+}
+
 // Used in AMF planned removal procedure
 func (amf *AMF) Terminate(cancelServices ctxt.CancelFunc, wg *sync.WaitGroup, tracerProvider *sdktrace.TracerProvider) {
 	logger.InitLog.Infoln("terminating AMF")
@@ -293,23 +377,31 @@ func (amf *AMF) Terminate(cancelServices ctxt.CancelFunc, wg *sync.WaitGroup, tr
 
 	ctx := ctxt.Background()
 	cancelServices()
-	// TODO: forward registered UE contexts to target AMF in the same AMF set if there is one
 
 	// deregister with NRF
 	nfregistration.DeregisterNF(ctx)
 
-	// send AMF status indication to ran to notify ran that this AMF will be unavailable
-	logger.InitLog.Infoln("send AMF Status Indication to Notify RANs due to AMF terminating")
-	unavailableGuamiList := ngap_message.BuildUnavailableGUAMIList(amfSelf.ServedGuamiList)
-	amfSelf.AmfRanPool.Range(func(key, value any) bool {
-		ran := value.(*amfContext.AmfRan)
-		ngap_message.SendAMFStatusIndication(ran, unavailableGuamiList)
-		return true
-	})
+	// send AMF status indication to ran to notify ran that this AMF will be unavailable,
+	// unless the drain procedure already did so with the backup AMF name
+	if !amfSelf.IsDraining() {
+		logger.InitLog.Infoln("send AMF Status Indication to Notify RANs due to AMF terminating")
+		unavailableGuamiList := ngap_message.BuildUnavailableGUAMIList(amfSelf.ServedGuamiList, amfSelf.BackupAmfName)
+		amfSelf.AmfRanPool.Range(func(key, value any) bool {
+			ran := value.(*amfContext.AmfRan)
+			ngap_message.SendAMFStatusIndication(ran, unavailableGuamiList)
+			return true
+		})
+	}
 
 	ngap_service.Stop()
+	amfContext.CloseUeContextStore()
+	if amfSelf.PacketCapture != nil {
+		amfSelf.PacketCapture.Close()
+	}
 
-	callback.SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_UNAVAILABLE, amfSelf.ServedGuamiList)
+	if !amfSelf.IsDraining() {
+		callback.SendAmfStatusChangeNotify(models.STATUSCHANGE_AMF_UNAVAILABLE, amfSelf.ServedGuamiList)
+	}
 
 	amfSelf.NfStatusSubscriptions.Range(func(nfInstanceId, v any) bool {
 		if subscriptionId, ok := amfSelf.NfStatusSubscriptions.Load(nfInstanceId); ok {
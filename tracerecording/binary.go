@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package tracerecording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The binary trace collection file starts with binaryMagic, a version octet and the session
+// information, followed by one entry per record. All integers are big endian and strings and
+// payloads are preceded by their length:
+//
+//	header:  magic(8) version(1) startTime(8) traceRef trsr supi pei   (strings: len(2) data)
+//	message: type=1(1) time(8) protocol(1) direction(1) name(len 2) payload(len 4)
+//	cell:    type=2(1) time(8) ngranTraceId cgi tceIpAddress supi pei   (strings: len(2) data)
+//
+// Times are nanoseconds since the Unix epoch.
+const (
+	binaryMagic   = "AMFTRACE"
+	binaryVersion = 1
+
+	binaryEntryMessage          = 1
+	binaryEntryCellTrafficTrace = 2
+)
+
+type binaryEncoder struct {
+	w io.Writer
+}
+
+func (e *binaryEncoder) writeHeader(info SessionInfo, start time.Time) error {
+	buf := append([]byte(binaryMagic), binaryVersion)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(start.UnixNano()))
+	buf = appendStrings(buf, info.TraceRef, info.Trsr, info.Supi, info.Pei)
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func (e *binaryEncoder) writeRecord(rec Record) error {
+	buf := []byte{binaryEntryMessage}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(rec.Time.UnixNano()))
+	buf = append(buf, byte(rec.Protocol), byte(rec.Direction))
+	buf = appendStrings(buf, rec.Name)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(rec.Payload)))
+	buf = append(buf, rec.Payload...)
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func (e *binaryEncoder) writeCellTrafficTrace(cellTrafficTrace CellTrafficTrace) error {
+	buf := []byte{binaryEntryCellTrafficTrace}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(cellTrafficTrace.Time.UnixNano()))
+	buf = appendStrings(buf, cellTrafficTrace.NgranTraceId, cellTrafficTrace.Cgi,
+		cellTrafficTrace.TceIpAddress, cellTrafficTrace.Supi, cellTrafficTrace.Pei)
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func (e *binaryEncoder) writeFooter(time.Time) error {
+	return nil
+}
+
+func appendStrings(buf []byte, strs ...string) []byte {
+	for _, s := range strs {
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+		buf = append(buf, s...)
+	}
+	return buf
+}
+
+// BinaryFile is the content of a binary trace collection file.
+type BinaryFile struct {
+	Info              SessionInfo
+	Start             time.Time
+	Records           []Record
+	CellTrafficTraces []CellTrafficTrace
+}
+
+// ReadBinary reads a trace collection file written in the binary format.
+func ReadBinary(r io.Reader) (*BinaryFile, error) {
+	d := &binaryDecoder{r: bufio.NewReader(r)}
+	magic := d.bytes(len(binaryMagic))
+	if d.err == nil && string(magic) != binaryMagic {
+		return nil, fmt.Errorf("not a binary trace collection file")
+	}
+	if version := d.uint8(); d.err == nil && version != binaryVersion {
+		return nil, fmt.Errorf("unsupported binary trace collection file version %d", version)
+	}
+	file := &BinaryFile{Start: d.time()}
+	file.Info.TraceRef, file.Info.Trsr, file.Info.Supi, file.Info.Pei = d.string(), d.string(), d.string(), d.string()
+	if d.err != nil {
+		return nil, fmt.Errorf("read binary trace collection file header: %w", d.err)
+	}
+
+	for {
+		entryType, err := d.r.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return file, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch entryType {
+		case binaryEntryMessage:
+			rec := Record{Time: d.time(), Protocol: Protocol(d.uint8()), Direction: Direction(d.uint8())}
+			rec.Name = d.string()
+			if n := d.uint32(); n > 0 {
+				rec.Payload = d.bytes(int(n))
+			}
+			file.Records = append(file.Records, rec)
+		case binaryEntryCellTrafficTrace:
+			cellTrafficTrace := CellTrafficTrace{Time: d.time()}
+			cellTrafficTrace.NgranTraceId, cellTrafficTrace.Cgi, cellTrafficTrace.TceIpAddress =
+				d.string(), d.string(), d.string()
+			cellTrafficTrace.Supi, cellTrafficTrace.Pei = d.string(), d.string()
+			file.CellTrafficTraces = append(file.CellTrafficTraces, cellTrafficTrace)
+		default:
+			return nil, fmt.Errorf("unknown binary trace entry type %d", entryType)
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("read binary trace entry: %w", d.err)
+		}
+	}
+}
+
+// binaryDecoder keeps the first read error so that entries can be decoded without checking
+// every field
+type binaryDecoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *binaryDecoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+func (d *binaryDecoder) uint8() uint8 {
+	if b := d.bytes(1); d.err == nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *binaryDecoder) uint16() uint16 {
+	if b := d.bytes(2); d.err == nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *binaryDecoder) uint32() uint32 {
+	if b := d.bytes(4); d.err == nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *binaryDecoder) time() time.Time {
+	if b := d.bytes(8); d.err == nil {
+		return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+	}
+	return time.Time{}
+}
+
+func (d *binaryDecoder) string() string {
+	return string(d.bytes(int(d.uint16())))
+}
@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package tracerecording writes the trace collection files of subscriber and equipment trace
+// sessions (TS 32.422, TS 32.423). A file holds the NAS and NGAP messages of one trace
+// recording session, either in the XML format of TS 32.423 Annex A or in a compact binary
+// format that can be read back with ReadBinary.
+package tracerecording
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Trace collection file formats
+const (
+	FormatXML    = "xml"
+	FormatBinary = "binary"
+)
+
+// Protocol of a recorded message
+type Protocol uint8
+
+const (
+	ProtocolNAS Protocol = iota + 1
+	ProtocolNGAP
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolNAS:
+		return "NAS"
+	case ProtocolNGAP:
+		return "NGAP"
+	}
+	return fmt.Sprintf("Protocol(%d)", uint8(p))
+}
+
+// Direction of a recorded message, seen from the AMF
+type Direction uint8
+
+const (
+	Uplink Direction = iota + 1
+	Downlink
+)
+
+// SessionInfo identifies a trace recording session and the traced UE.
+type SessionInfo struct {
+	TraceRef string // Trace Reference, MCC+MNC-TraceID as in TraceData
+	Trsr     string // Trace Recording Session Reference, 4 hex digits
+	Supi     string
+	Pei      string
+	// MessageNamesOnly drops the message contents, for trace depths that only record
+	// which messages were exchanged
+	MessageNamesOnly bool
+}
+
+// Record is a message recorded in a trace recording session.
+type Record struct {
+	Time      time.Time
+	Protocol  Protocol
+	Direction Direction
+	Name      string
+	Payload   []byte
+}
+
+// CellTrafficTrace is the cell and Trace Collection Entity an NG-RAN node reported for a
+// trace session with the Cell Traffic Trace procedure (TS 32.422 4.2.2.10).
+type CellTrafficTrace struct {
+	Time         time.Time
+	NgranTraceId string // hex NG-RAN Trace ID, Trace Reference followed by the TRSR
+	Cgi          string
+	TceIpAddress string
+	Supi         string
+	Pei          string
+}
+
+type encoder interface {
+	writeHeader(info SessionInfo, start time.Time) error
+	writeRecord(rec Record) error
+	writeCellTrafficTrace(cellTrafficTrace CellTrafficTrace) error
+	writeFooter(end time.Time) error
+}
+
+// Session is an open trace recording session. It is safe for concurrent use.
+type Session struct {
+	Info SessionInfo
+	Path string
+
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	enc  encoder
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// NewSession creates the trace collection file of a trace recording session in dir.
+func NewSession(dir, format string, info SessionInfo) (*Session, error) {
+	var ext string
+	switch format {
+	case FormatXML, "":
+		format, ext = FormatXML, ".xml"
+	case FormatBinary:
+		ext = ".trc"
+	default:
+		return nil, fmt.Errorf("unknown trace collection file format %q", format)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create trace directory: %w", err)
+	}
+
+	start := time.Now()
+	name := fmt.Sprintf("%s_%s_%s%s", unsafeFileNameChars.ReplaceAllString(info.TraceRef, "_"),
+		unsafeFileNameChars.ReplaceAllString(info.Trsr, "_"), start.UTC().Format("20060102T150405.000000000"), ext)
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("create trace collection file: %w", err)
+	}
+
+	s := &Session{Info: info, Path: path, file: file, buf: bufio.NewWriter(file)}
+	if format == FormatXML {
+		s.enc = &xmlEncoder{w: s.buf}
+	} else {
+		s.enc = &binaryEncoder{w: s.buf}
+	}
+	if err = s.enc.writeHeader(info, start); err == nil {
+		err = s.buf.Flush()
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("write trace collection file header: %w", err)
+	}
+	return s, nil
+}
+
+// Record appends a message to the trace collection file. The payload is written before
+// Record returns, so the caller may reuse it.
+func (s *Session) Record(rec Record) error {
+	if s.Info.MessageNamesOnly {
+		rec.Payload = nil
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return fmt.Errorf("trace recording session %s is closed", s.Info.Trsr)
+	}
+	if err := s.enc.writeRecord(rec); err != nil {
+		return err
+	}
+	return s.buf.Flush()
+}
+
+// RecordCellTrafficTrace appends the cell and Trace Collection Entity reported by the NG-RAN.
+func (s *Session) RecordCellTrafficTrace(cellTrafficTrace CellTrafficTrace) error {
+	if cellTrafficTrace.Time.IsZero() {
+		cellTrafficTrace.Time = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return fmt.Errorf("trace recording session %s is closed", s.Info.Trsr)
+	}
+	if err := s.enc.writeCellTrafficTrace(cellTrafficTrace); err != nil {
+		return err
+	}
+	return s.buf.Flush()
+}
+
+// Close completes and closes the trace collection file.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.enc.writeFooter(time.Now())
+	if flushErr := s.buf.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	return err
+}
+
+// 5GMM message types of TS 24.501 table 9.7.1
+var gmmMessageNames = map[uint8]string{
+	0x41: "RegistrationRequest",
+	0x42: "RegistrationAccept",
+	0x43: "RegistrationComplete",
+	0x44: "RegistrationReject",
+	0x45: "DeregistrationRequestUEOriginating",
+	0x46: "DeregistrationAcceptUEOriginating",
+	0x47: "DeregistrationRequestUETerminated",
+	0x48: "DeregistrationAcceptUETerminated",
+	0x4c: "ServiceRequest",
+	0x4d: "ServiceReject",
+	0x4e: "ServiceAccept",
+	0x4f: "ControlPlaneServiceRequest",
+	0x50: "NetworkSliceSpecificAuthenticationCommand",
+	0x51: "NetworkSliceSpecificAuthenticationComplete",
+	0x52: "NetworkSliceSpecificAuthenticationResult",
+	0x54: "ConfigurationUpdateCommand",
+	0x55: "ConfigurationUpdateComplete",
+	0x56: "AuthenticationRequest",
+	0x57: "AuthenticationResponse",
+	0x58: "AuthenticationReject",
+	0x59: "AuthenticationFailure",
+	0x5a: "AuthenticationResult",
+	0x5b: "IdentityRequest",
+	0x5c: "IdentityResponse",
+	0x5d: "SecurityModeCommand",
+	0x5e: "SecurityModeComplete",
+	0x5f: "SecurityModeReject",
+	0x64: "5GMMStatus",
+	0x65: "Notification",
+	0x66: "NotificationResponse",
+	0x67: "ULNASTransport",
+	0x68: "DLNASTransport",
+}
+
+// NasMessageName returns the name of a plain 5GMM NAS message.
+func NasMessageName(plainNas []byte) string {
+	// extended protocol discriminator, security header type and message type
+	if len(plainNas) < 3 || plainNas[0] != 0x7e {
+		return "NAS"
+	}
+	if name, ok := gmmMessageNames[plainNas[2]]; ok {
+		return name
+	}
+	return fmt.Sprintf("5GMM(0x%02x)", plainNas[2])
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package tracerecording
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+)
+
+var registrationRequest = []byte{0x7e, 0x00, 0x41, 0x79, 0x00, 0x0d}
+
+func TestXMLSession(t *testing.T) {
+	session, err := NewSession(t.TempDir(), FormatXML, SessionInfo{TraceRef: "20893-abcdef", Trsr: "0001"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.Record(Record{Protocol: ProtocolNAS, Direction: Uplink, Name: NasMessageName(registrationRequest),
+		Payload: registrationRequest}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.RecordCellTrafficTrace(CellTrafficTrace{NgranTraceId: "02f839abcdef0001", Supi: "imsi-208930000000001"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.Record(Record{Protocol: ProtocolNGAP, Direction: Downlink}); err == nil {
+		t.Fatal("expected recording into a closed session to fail")
+	}
+
+	content, err := os.ReadFile(session.Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var file struct {
+		Session struct {
+			TraceRef string `xml:"traceRef,attr"`
+			Trsr     string `xml:"tRSR,attr"`
+			Msgs     []struct {
+				Name      string `xml:"name,attr"`
+				Initiator string `xml:"initiator"`
+				RawMsg    struct {
+					Protocol string `xml:"protocol,attr"`
+					Value    string `xml:",chardata"`
+				} `xml:"rawMsg"`
+				Supi string `xml:"supi"`
+			} `xml:"msg"`
+		} `xml:"traceRecSession"`
+	}
+	if err = xml.Unmarshal(content, &file); err != nil {
+		t.Fatalf("invalid trace collection file: %v\n%s", err, content)
+	}
+	if file.Session.TraceRef != "20893-abcdef" || file.Session.Trsr != "0001" || len(file.Session.Msgs) != 2 {
+		t.Fatalf("unexpected trace collection file:\n%s", content)
+	}
+	msg := file.Session.Msgs[0]
+	if msg.Name != "RegistrationRequest" || msg.Initiator != "UE" || msg.RawMsg.Protocol != "NAS" ||
+		msg.RawMsg.Value != "7e004179000d" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if file.Session.Msgs[1].Name != "CellTrafficTrace" || file.Session.Msgs[1].Supi != "imsi-208930000000001" {
+		t.Errorf("unexpected cell traffic trace %+v", file.Session.Msgs[1])
+	}
+}
+
+func TestBinarySession(t *testing.T) {
+	info := SessionInfo{TraceRef: "20893-abcdef", Trsr: "00ff", Supi: "imsi-208930000000001", MessageNamesOnly: true}
+	session, err := NewSession(t.TempDir(), FormatBinary, info)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.Record(Record{Protocol: ProtocolNGAP, Direction: Downlink, Name: "InitialContextSetup",
+		Payload: []byte{0x00, 0x0e}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.RecordCellTrafficTrace(CellTrafficTrace{NgranTraceId: "02f839abcdef00ff", Cgi: "02f839000000001"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = session.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(session.Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := ReadBinary(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Info.TraceRef != info.TraceRef || file.Info.Trsr != info.Trsr || file.Info.Supi != info.Supi {
+		t.Errorf("unexpected session info %+v", file.Info)
+	}
+	if len(file.Records) != 1 || file.Records[0].Name != "InitialContextSetup" ||
+		file.Records[0].Protocol != ProtocolNGAP || file.Records[0].Direction != Downlink {
+		t.Fatalf("unexpected records %+v", file.Records)
+	}
+	if file.Records[0].Payload != nil {
+		t.Errorf("expected the payload to be dropped, got %x", file.Records[0].Payload)
+	}
+	if len(file.CellTrafficTraces) != 1 || file.CellTrafficTraces[0].Cgi != "02f839000000001" {
+		t.Errorf("unexpected cell traffic traces %+v", file.CellTrafficTraces)
+	}
+
+	if _, err = ReadBinary(bytes.NewReader(content[:len(content)-3])); err == nil {
+		t.Error("expected a truncated file to fail")
+	}
+	if _, err = ReadBinary(strings.NewReader("<?xml")); err == nil {
+		t.Error("expected an XML file to be rejected")
+	}
+}
+
+func TestNewSessionUnknownFormat(t *testing.T) {
+	if _, err := NewSession(t.TempDir(), "pcap", SessionInfo{}); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
+
+func TestNasMessageName(t *testing.T) {
+	if name := NasMessageName(registrationRequest); name != "RegistrationRequest" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if name := NasMessageName([]byte{0x7e, 0x00, 0x99}); name != "5GMM(0x99)" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if name := NasMessageName([]byte{0x2e, 0x01}); name != "NAS" {
+		t.Errorf("unexpected name %s", name)
+	}
+}
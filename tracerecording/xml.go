@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2026 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package tracerecording
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	traceDataNamespace = "http://www.3gpp.org/ftp/specs/archive/32_series/32.423#traceData"
+	fileFormatVersion  = "32.423 V17.0.0"
+	vendorName         = "ONF"
+)
+
+// xmlEncoder writes the trace collection file of TS 32.423 Annex A. Messages are streamed,
+// so the file header and the opening tags are written first and closed by the footer.
+type xmlEncoder struct {
+	w     io.Writer
+	start time.Time
+}
+
+type xmlTraceCollec struct {
+	XMLName   xml.Name `xml:"traceCollec"`
+	BeginTime string   `xml:"beginTime,attr,omitempty"`
+	EndTime   string   `xml:"endTime,attr,omitempty"`
+}
+
+type xmlRawMsg struct {
+	Protocol string `xml:"protocol,attr"`
+	Version  string `xml:"version,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type xmlMsg struct {
+	XMLName         xml.Name   `xml:"msg"`
+	ChangeTime      string     `xml:"changeTime,attr"`
+	VendorExtension bool       `xml:"vendorExtension,attr"`
+	Function        string     `xml:"function,attr"`
+	Name            string     `xml:"name,attr"`
+	Initiator       string     `xml:"initiator"`
+	RawMsg          *xmlRawMsg `xml:"rawMsg,omitempty"`
+}
+
+// cell traffic trace reports are not part of the 32.423 schema and are written as a vendor
+// extension
+type xmlCellTrafficTrace struct {
+	XMLName         xml.Name `xml:"msg"`
+	ChangeTime      string   `xml:"changeTime,attr"`
+	VendorExtension bool     `xml:"vendorExtension,attr"`
+	Function        string   `xml:"function,attr"`
+	Name            string   `xml:"name,attr"`
+	Initiator       string   `xml:"initiator"`
+	NgranTraceId    string   `xml:"ngranTraceId"`
+	Cgi             string   `xml:"cgi,omitempty"`
+	TceIpAddress    string   `xml:"tceIpAddress,omitempty"`
+	Supi            string   `xml:"supi,omitempty"`
+	Pei             string   `xml:"pei,omitempty"`
+}
+
+func (e *xmlEncoder) writeHeader(info SessionInfo, start time.Time) error {
+	e.start = start
+	header, err := xml.Marshal(xmlTraceCollec{BeginTime: start.Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, "%s\n<traceCollecFile xmlns=\"%s\">\n"+
+		"<fileHeader fileFormatVersion=\"%s\" vendorName=\"%s\">%s</fileHeader>\n"+
+		"<traceRecSession traceRef=\"%s\" tRSR=\"%s\" stime=\"%s\">\n",
+		xml.Header[:len(xml.Header)-1], traceDataNamespace, fileFormatVersion, vendorName, header,
+		escapeAttr(info.TraceRef), escapeAttr(info.Trsr), start.Format(time.RFC3339Nano))
+	return err
+}
+
+func (e *xmlEncoder) writeRecord(rec Record) error {
+	msg := xmlMsg{
+		ChangeTime: e.changeTime(rec.Time),
+		Function:   "AMF",
+		Name:       rec.Name,
+		Initiator:  initiator(rec.Protocol, rec.Direction),
+	}
+	if rec.Payload != nil {
+		msg.RawMsg = &xmlRawMsg{
+			Protocol: rec.Protocol.String(),
+			Version:  "17",
+			Value:    hex.EncodeToString(rec.Payload),
+		}
+	}
+	return e.writeElement(msg)
+}
+
+func (e *xmlEncoder) writeCellTrafficTrace(cellTrafficTrace CellTrafficTrace) error {
+	return e.writeElement(xmlCellTrafficTrace{
+		ChangeTime:      e.changeTime(cellTrafficTrace.Time),
+		VendorExtension: true,
+		Function:        "AMF",
+		Name:            "CellTrafficTrace",
+		Initiator:       "NG-RAN",
+		NgranTraceId:    cellTrafficTrace.NgranTraceId,
+		Cgi:             cellTrafficTrace.Cgi,
+		TceIpAddress:    cellTrafficTrace.TceIpAddress,
+		Supi:            cellTrafficTrace.Supi,
+		Pei:             cellTrafficTrace.Pei,
+	})
+}
+
+func (e *xmlEncoder) writeFooter(end time.Time) error {
+	footer, err := xml.Marshal(xmlTraceCollec{EndTime: end.Format(time.RFC3339)})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, "</traceRecSession>\n<fileFooter>%s</fileFooter>\n</traceCollecFile>\n", footer)
+	return err
+}
+
+func (e *xmlEncoder) writeElement(v any) error {
+	element, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.w, "%s\n", element)
+	return err
+}
+
+// changeTime is the offset of the message from the start of the session in seconds
+func (e *xmlEncoder) changeTime(t time.Time) string {
+	return fmt.Sprintf("%.3f", t.Sub(e.start).Seconds())
+}
+
+func initiator(protocol Protocol, direction Direction) string {
+	switch {
+	case direction == Downlink:
+		return "AMF"
+	case protocol == ProtocolNGAP:
+		return "NG-RAN"
+	}
+	return "UE"
+}
+
+func escapeAttr(s string) string {
+	var b strings.Builder
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -6,7 +6,9 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"net/http"
 
+	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
@@ -57,3 +59,18 @@ func InitTracer(ctx context.Context, cfg TelemetryConfig) (*sdktrace.TracerProvi
 
 	return tp, nil
 }
+
+// ExtractTraceContext returns a gin middleware that continues the W3C trace context of the
+// incoming SBI requests, so that the spans of their handlers are part of the consumer's trace.
+func ExtractTraceContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// InjectTraceContext adds the W3C trace context of ctx to the headers of an outgoing SBI request.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2026 Intel Corporation
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02},
+		SpanID:     trace.SpanID{0x03},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/namf-callback/v1/smContextStatus/guti/1", nil)
+	InjectTraceContext(trace.ContextWithSpanContext(context.Background(), parent), req.Header)
+	if req.Header.Get("traceparent") == "" {
+		t.Fatal("expected a traceparent header")
+	}
+
+	var extracted trace.SpanContext
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ExtractTraceContext())
+	router.POST("/namf-callback/v1/smContextStatus/:guti/:pduSessionId", func(c *gin.Context) {
+		extracted = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if extracted.TraceID() != parent.TraceID() || extracted.SpanID() != parent.SpanID() || !extracted.IsRemote() {
+		t.Errorf("expected the remote span context %v, got %v", parent, extracted)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/omec-project/amf/context"
 	"github.com/omec-project/amf/factory"
 	"github.com/omec-project/amf/logger"
+	"github.com/omec-project/amf/packetcapture"
 	"github.com/omec-project/nas/v2/security"
 	"github.com/omec-project/openapi/v2/models"
 	"github.com/omec-project/util/drsm"
@@ -169,6 +170,7 @@ func InitAmfContext(amfContext *context.AMFContext) {
 	amfContext.T3513Cfg = configuration.T3513
 	amfContext.T3522Cfg = configuration.T3522
 	amfContext.T3550Cfg = configuration.T3550
+	amfContext.T3555Cfg = configuration.T3555
 	amfContext.T3560Cfg = configuration.T3560
 	amfContext.T3565Cfg = configuration.T3565
 	amfContext.EnableSctpLb = configuration.EnableSctpLb
@@ -181,6 +183,41 @@ func InitAmfContext(amfContext *context.AMFContext) {
 			amfContext.NrfCacheEvictionInterval = time.Duration(configuration.NrfCacheEvictionInterval)
 		}
 	}
+	if configuration.OverloadControl != nil && configuration.OverloadControl.Enabled {
+		amfContext.OverloadControl = context.NewOverloadControl(*configuration.OverloadControl)
+	}
+	amfContext.PowerSaving = configuration.PowerSaving
+	amfContext.Paging = configuration.Paging
+	if configuration.SignallingTrace != nil && configuration.SignallingTrace.Enabled {
+		amfContext.SignallingTrace = configuration.SignallingTrace
+	}
+	if configuration.FlightRecorder != nil && configuration.FlightRecorder.Enabled {
+		amfContext.FlightRecorder = configuration.FlightRecorder
+	}
+	if pc := configuration.PacketCapture; pc != nil && pc.Enabled {
+		amfContext.PacketCapture = packetcapture.NewManager(pc.Directory, pc.MaxFileSize, pc.AllowNasDecryption)
+	}
+	if racs := configuration.Racs; racs != nil && racs.Enabled {
+		amfContext.RacsSupportedByRan = true
+		amfContext.UeRadioCapabilityDictionary = context.NewUeRadioCapabilityDictionary(racs.MaxDictionaryEntries)
+		// a UCMF, if configured, is set up by the service
+		if racs.UcmfUri == "" && racs.DictionaryFile != "" {
+			ucmf, err := context.NewFileUcmfClient(racs.DictionaryFile)
+			if err != nil {
+				logger.UtilLog.Errorf("load UE radio capability ID dictionary failed: %+v", err)
+			} else {
+				amfContext.Ucmf = ucmf
+			}
+		}
+	}
+	amfContext.DrainTimeout = 30 * time.Second
+	if configuration.PlannedRemoval != nil {
+		amfContext.BackupAmfName = configuration.PlannedRemoval.BackupAmfName
+		amfContext.BackupAmfUri = configuration.PlannedRemoval.BackupAmfUri
+		if configuration.PlannedRemoval.DrainTimeout > 0 {
+			amfContext.DrainTimeout = configuration.PlannedRemoval.DrainTimeout
+		}
+	}
 }
 
 func getIntAlgOrder(integrityOrder []string) (intOrder []uint8) {